@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sjc5/hwy-go/router"
+)
+
+// liveReloadEnvVar holds the live reload event stream URL for the app run by
+// hwy dev. The scaffolded template subscribes to it and reloads on messages.
+const liveReloadEnvVar = "HWY_LIVE_RELOAD_URL"
+
+const devPollInterval = 500 * time.Millisecond
+
+var devSkippedDirs = map[string]bool{".git": true, "node_modules": true}
+
+func dev(cfg config, reloadAddr string) error {
+	logger := router.DefaultLogger
+	if err := router.Build(cfg.buildOptions(true)); err != nil {
+		logger.Error("build failed", "error", err)
+	}
+
+	reloader := &liveReloader{clients: make(map[chan struct{}]bool)}
+	go func() {
+		if err := http.ListenAndServe(reloadAddr, reloader); err != nil {
+			logger.Error("live reload server stopped", "error", err)
+		}
+	}()
+
+	app := &devApp{binPath: filepath.Join(os.TempDir(), "hwy-dev-app"), reloadURL: "http://" + reloadAddr + "/"}
+	if err := app.restart(); err != nil {
+		logger.Error("error starting app", "error", err)
+	}
+	defer app.stop()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	isFrontendFile := func(path string) bool {
+		return path == filepath.Clean(cfg.ClientEntry) || strings.HasPrefix(path, filepath.Clean(cfg.PagesSrcDir)+string(filepath.Separator))
+	}
+	skippedDirs := map[string]bool{filepath.Clean(cfg.UnhashedOutDir): true, filepath.Clean(cfg.HashedOutDir): true}
+	frontendModTime, goModTime := getLatestModTimes(skippedDirs, isFrontendFile)
+
+	ticker := time.NewTicker(devPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case <-ticker.C:
+		}
+		latestFrontend, latestGo := getLatestModTimes(skippedDirs, isFrontendFile)
+		if !latestFrontend.After(frontendModTime) && !latestGo.After(goModTime) {
+			continue
+		}
+		if latestFrontend.After(frontendModTime) {
+			frontendModTime = latestFrontend
+			// On failure, restart anyway to show the error overlay
+			if err := router.Build(cfg.buildOptions(true)); err != nil {
+				logger.Error("build failed", "error", err)
+			}
+		}
+		goModTime = latestGo
+		// Always restart, as the app may embed the build output
+		if err := app.restart(); err != nil {
+			logger.Error("error restarting app", "error", err)
+			continue
+		}
+		// Give the app a moment to start listening
+		time.Sleep(devPollInterval)
+		reloader.broadcast()
+	}
+}
+
+// getLatestModTimes walks the working directory, returning the latest
+// modification times of frontend files and of Go files
+func getLatestModTimes(skippedDirs map[string]bool, isFrontendFile func(string) bool) (frontend time.Time, goFiles time.Time) {
+	filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != "." && (devSkippedDirs[d.Name()] || skippedDirs[path]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		isGoFile := strings.HasSuffix(path, ".go")
+		if !isGoFile && !isFrontendFile(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if isGoFile && info.ModTime().After(goFiles) {
+			goFiles = info.ModTime()
+		} else if !isGoFile && info.ModTime().After(frontend) {
+			frontend = info.ModTime()
+		}
+		return nil
+	})
+	return frontend, goFiles
+}
+
+// devApp builds and runs the app binary directly (rather than via go run),
+// so restarting kills the actual server process
+type devApp struct {
+	binPath   string
+	reloadURL string
+	cmd       *exec.Cmd
+}
+
+func (a *devApp) restart() error {
+	build := exec.Command("go", "build", "-o", a.binPath, ".")
+	build.Stdout, build.Stderr = os.Stdout, os.Stderr
+	if err := build.Run(); err != nil {
+		return err
+	}
+	a.stop()
+	a.cmd = exec.Command(a.binPath)
+	a.cmd.Stdout, a.cmd.Stderr = os.Stdout, os.Stderr
+	a.cmd.Env = append(os.Environ(), liveReloadEnvVar+"="+a.reloadURL)
+	return a.cmd.Start()
+}
+
+func (a *devApp) stop() {
+	if a.cmd == nil || a.cmd.Process == nil {
+		return
+	}
+	a.cmd.Process.Kill()
+	a.cmd.Wait()
+	a.cmd = nil
+}
+
+// liveReloader is an event stream that sends a message to every connected
+// browser on broadcast
+type liveReloader struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]bool
+}
+
+func (l *liveReloader) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	reload := make(chan struct{}, 1)
+	l.mu.Lock()
+	l.clients[reload] = true
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		delete(l.clients, reload)
+		l.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-reload:
+			fmt.Fprint(w, "data: reload\n\n")
+			rc.Flush()
+		}
+	}
+}
+
+func (l *liveReloader) broadcast() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for client := range l.clients {
+		select {
+		case client <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Command hwy scaffolds, builds, and serves Hwy projects.
+//
+//	hwy new <dir>      scaffold a project
+//	hwy build          build the client and paths file
+//	hwy dev            build, run, and rebuild/restart on changes
+//	hwy routes         print the built routes as a table
+//	hwy generate-ts    generate TypeScript types for your data funcs
+//
+// Commands read their options from hwy.json in the working directory (see
+// config), falling back to the scaffold's layout.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/sjc5/hwy-go/router"
+)
+
+const configFile = "hwy.json"
+
+type config struct {
+	PagesSrcDir       string `json:"pagesSrcDir"`
+	ClientEntry       string `json:"clientEntry"`
+	HashedOutDir      string `json:"hashedOutDir"`
+	UnhashedOutDir    string `json:"unhashedOutDir"`
+	ClientEntryOut    string `json:"clientEntryOut"`
+	GeneratedTSOutDir string `json:"generatedTSOutDir"`
+	UsePreactCompat   bool   `json:"usePreactCompat"`
+	ServiceWorker     bool   `json:"serviceWorker"`
+	// Must match Hwy.Prefix
+	Prefix string `json:"prefix,omitempty"`
+}
+
+var defaultConfig = config{
+	PagesSrcDir:       "pages",
+	ClientEntry:       "client.entry.tsx",
+	HashedOutDir:      "dist/public",
+	UnhashedOutDir:    "dist",
+	ClientEntryOut:    "dist/public",
+	GeneratedTSOutDir: "generated",
+}
+
+func readConfig(path string) (config, error) {
+	cfg := defaultConfig
+	configBytes, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(configBytes, &cfg); err != nil {
+		return cfg, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c config) buildOptions(isDev bool) router.BuildOptions {
+	return router.BuildOptions{
+		IsDev:             isDev,
+		ClientEntry:       c.ClientEntry,
+		PagesSrcDir:       c.PagesSrcDir,
+		HashedOutDir:      c.HashedOutDir,
+		UnhashedOutDir:    c.UnhashedOutDir,
+		ClientEntryOut:    c.ClientEntryOut,
+		UsePreactCompat:   c.UsePreactCompat,
+		GeneratedTSOutDir: c.GeneratedTSOutDir,
+		ServiceWorker:     c.ServiceWorker,
+		Prefix:            c.Prefix,
+	}
+}
+
+const usage = `Usage: hwy <command> [flags]
+
+Commands:
+  new <dir>      scaffold a project
+  build          build the client and paths file
+  dev            build, run, and rebuild/restart on changes
+  routes         print the built routes as a table
+  generate-ts    generate TypeScript types for your data funcs
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "hwy:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+	command, args := args[0], args[1:]
+
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	configPath := flags.String("config", configFile, "path to the config file")
+	isDev := flags.Bool("dev", false, "build in development mode (build only)")
+	reloadAddr := flags.String("reload-addr", "localhost:35729", "live reload server address (dev only)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := readConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "new":
+		if flags.NArg() != 1 {
+			return errors.New("usage: hwy new <dir>")
+		}
+		return scaffold(flags.Arg(0), stdout)
+	case "build":
+		return router.Build(cfg.buildOptions(*isDev))
+	case "dev":
+		return dev(cfg, *reloadAddr)
+	case "routes":
+		return printRoutes(cfg, stdout)
+	case "generate-ts":
+		return generateTS()
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", command, usage)
+	}
+}
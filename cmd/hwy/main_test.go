@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sjc5/hwy-go/router"
+)
+
+func TestReadConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := readConfig(filepath.Join(dir, configFile))
+	if err != nil || cfg != defaultConfig {
+		t.Fatalf("expected defaults for a missing config file, got %+v, %v", cfg, err)
+	}
+
+	path := filepath.Join(dir, configFile)
+	os.WriteFile(path, []byte(`{"pagesSrcDir": "src/pages"}`), 0o644)
+	cfg, err = readConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PagesSrcDir != "src/pages" || cfg.ClientEntry != defaultConfig.ClientEntry {
+		t.Errorf("expected overridden pages dir and default client entry, got %+v", cfg)
+	}
+}
+
+func TestScaffold(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app")
+	var stdout bytes.Buffer
+	if err := run([]string{"new", dir}, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{configFile, "main.go", "client.entry.tsx", "pages/_index.ui.tsx", "dist/index.go.html", "dist/public/.gitkeep"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be scaffolded: %v", name, err)
+		}
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", scaffoldMainGo, 0); err != nil {
+		t.Errorf("scaffolded main.go doesn't parse: %v", err)
+	}
+	if _, err := template.New("root").Parse(scaffoldRootTemplate); err != nil {
+		t.Errorf("scaffolded root template doesn't parse: %v", err)
+	}
+	if err := scaffold(dir, &stdout); err == nil {
+		t.Error("expected scaffolding into a non-empty dir to fail")
+	}
+}
+
+func TestPrintRoutes(t *testing.T) {
+	dir := t.TempDir()
+	pathsFileBytes, _ := json.Marshal(router.PathsFile{
+		Paths:   router.GetPathsFromPageFiles("_index.ui.tsx", "tiger/$tiger_id.ui.tsx"),
+		BuildID: "123",
+	})
+	os.WriteFile(filepath.Join(dir, "hwy_paths.json"), pathsFileBytes, 0o644)
+
+	var stdout bytes.Buffer
+	if err := printRoutes(config{UnhashedOutDir: dir}, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"PATTERN", "/_index", "/tiger/$tiger_id", "dynamic-layout", "2 routes, build 123"} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("expected %q in routes output:\n%s", expected, stdout.String())
+		}
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	if err := run([]string{"nope", "-config", filepath.Join(t.TempDir(), configFile)}, &bytes.Buffer{}); err == nil {
+		t.Error("expected an error for an unknown command")
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+func scaffold(dir string, stdout io.Writer) error {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s is not empty", dir)
+	}
+
+	configBytes, err := json.MarshalIndent(defaultConfig, "", "\t")
+	if err != nil {
+		return err
+	}
+	files := map[string]string{
+		configFile:             string(configBytes) + "\n",
+		"main.go":              scaffoldMainGo,
+		"client.entry.tsx":     scaffoldClientEntry,
+		"pages/_index.ui.tsx":  scaffoldIndexPage,
+		"dist/index.go.html":   scaffoldRootTemplate,
+		"package.json":         scaffoldPackageJSON,
+		".gitignore":           scaffoldGitignore,
+		"dist/public/.gitkeep": "",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(stdout, "Created %s. Next:\n\n  cd %s\n  go mod init <module> && go get github.com/sjc5/hwy-go\n  npm install\n  hwy dev\n", dir, dir)
+	return nil
+}
+
+const scaffoldMainGo = `package main
+
+import (
+	"embed"
+	"flag"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/sjc5/hwy-go"
+)
+
+//go:embed all:dist
+var distFS embed.FS
+
+var dataFuncsMap = hwy.DataFuncsMap{
+	"/_index": {
+		Loader: func(props *hwy.LoaderProps) (any, error) {
+			return "Hello from Hwy!", nil
+		},
+	},
+}
+
+func main() {
+	// Used by "hwy generate-ts", as only the app knows its data funcs
+	generateTS := flag.Bool("hwy-generate-ts", false, "generate TypeScript types and exit")
+	flag.Parse()
+	if *generateTS {
+		err := hwy.GenerateTypeScript(hwy.BuildOptions{DataFuncsMap: dataFuncsMap, GeneratedTSOutDir: "generated"})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	dist, err := fs.Sub(distFS, "dist")
+	if err != nil {
+		log.Fatal(err)
+	}
+	liveReloadURL := os.Getenv("HWY_LIVE_RELOAD_URL") // set by "hwy dev"
+	h := hwy.Hwy{
+		IsDev:                liveReloadURL != "",
+		FS:                   dist,
+		DataFuncsMap:         dataFuncsMap,
+		RootTemplateLocation: "index.go.html",
+		RootTemplateData:     map[string]any{"LiveReloadURL": liveReloadURL},
+	}
+	if err := h.Initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	public, err := fs.Sub(dist, "public")
+	if err != nil {
+		log.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/public/", http.StripPrefix("/public/", http.FileServerFS(public)))
+	mux.Handle("/", h.GetRootHandler())
+
+	log.Println("listening on http://localhost:8080")
+	log.Fatal(http.ListenAndServe(":8080", mux))
+}
+`
+
+const scaffoldClientEntry = `import { createElement, type ReactNode } from "react";
+import { createRoot, hydrateRoot } from "react-dom/client";
+
+const x = (globalThis as any)[Symbol.for("__hwy_internal__")];
+
+// Import URLs are relative to /public/ unless Hwy.AssetBasePrefix is set
+const modules = await Promise.all(
+	(x.importURLs as string[]).map((url) => import(url.startsWith("/") ? "/public" + url : url)),
+);
+
+// Nest each route's component inside its parent's
+let app: ReactNode = null;
+for (let i = modules.length - 1; i >= 0; i--) {
+	app = createElement(modules[i].default, { loaderData: x.loadersData[i] }, app);
+}
+
+// Hydrate markup server-rendered via Hwy.ComponentRenderer, if any
+const root = document.getElementById("root")!;
+if (root.hasChildNodes()) {
+	hydrateRoot(root, app);
+} else {
+	createRoot(root).render(app);
+}
+`
+
+const scaffoldIndexPage = `export default function Index({ loaderData }: { loaderData: string }) {
+	return <h1>{loaderData}</h1>;
+}
+`
+
+const scaffoldRootTemplate = `<!doctype html>
+<html {{.HTMLAttributes}}>
+	<head>
+		<meta charset="utf-8" />
+		{{.HeadElements}}
+		{{.CriticalCSS}}
+		{{.Stylesheets}}
+		{{.SSRInnerHTML}}
+		{{.ClientEntryScript}}
+		{{.ServiceWorkerScript}}
+		{{if .LiveReloadURL}}<script{{if .CSPNonce}} nonce="{{.CSPNonce}}"{{end}}>new EventSource({{.LiveReloadURL}}).onmessage = () => location.reload();</script>{{end}}
+	</head>
+	<body {{.BodyAttributes}}>
+		<div id="root">{{.ComponentsHTML}}</div>
+		{{.DeferredStylesheets}}
+	</body>
+</html>
+`
+
+const scaffoldPackageJSON = `{
+	"private": true,
+	"type": "module",
+	"dependencies": {
+		"react": "^18.3.1",
+		"react-dom": "^18.3.1"
+	},
+	"devDependencies": {
+		"@types/react": "^18.3.1",
+		"@types/react-dom": "^18.3.0"
+	}
+}
+`
+
+const scaffoldGitignore = `node_modules
+dist/hwy_paths.json
+dist/hwy_build_error.json
+dist/public/*
+!dist/public/.gitkeep
+generated
+`
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sjc5/hwy-go/router"
+)
+
+func printRoutes(cfg config, stdout io.Writer) error {
+	pathsFileBytes, err := os.ReadFile(filepath.Join(cfg.UnhashedOutDir, "hwy_paths.json"))
+	if err != nil {
+		return fmt.Errorf("error reading paths file (run hwy build first): %w", err)
+	}
+	pathsFile, err := router.DecodePathsFile(pathsFileBytes)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATTERN\tTYPE\tSOURCE\tOUTPUT\tDEPS")
+	for _, path := range pathsFile.Paths {
+		deps := 0
+		if path.Deps != nil {
+			deps = len(*path.Deps)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", path.Pattern, path.PathType, path.SrcPath, path.OutPath, deps)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "\n%d routes, build %s\n", len(pathsFile.Paths), pathsFile.BuildID)
+	return nil
+}
+
+// generateTSFlag is handled by the scaffolded main.go, as only the app knows
+// its DataFuncsMap
+const generateTSFlag = "-hwy-generate-ts"
+
+func generateTS() error {
+	cmd := exec.Command("go", "run", ".", generateTSFlag)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go run . %s: %w (does your main package handle %s?)",
+			generateTSFlag, err, strings.TrimPrefix(generateTSFlag, "-"))
+	}
+	return nil
+}
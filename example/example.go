@@ -0,0 +1,140 @@
+// Package example is a small Hwy app (a zoo), serving as living
+// documentation, and as the test bed for features exercised end to end over
+// HTTP, like redirects, errors, and JSON navigations (see hwytest.NewServer).
+//
+// To run without a build, its paths file is generated from its page files
+// when it starts, so routes have no import URLs or deps, and its pages
+// aren't hydrated.
+package example
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"slices"
+	"testing/fstest"
+
+	"github.com/sjc5/hwy-go"
+)
+
+//go:embed all:pages index.go.html
+var appFS embed.FS
+
+type Tiger struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+var tigers = map[string]Tiger{
+	"1": {ID: "1", Name: "Tony"},
+	"2": {ID: "2", Name: "Shere Khan"},
+}
+
+var ErrEnclosureClosed = errors.New("enclosure closed")
+
+// KeeperCookie lets requests into /keepers, which redirects others home
+const KeeperCookie = "keeper"
+
+var DataFuncsMap = hwy.DataFuncsMap{
+	"/_index": {
+		Loader: func(*hwy.LoaderProps) (any, error) {
+			return "Welcome to the zoo", nil
+		},
+	},
+	"/tigers": {
+		Loader: func(*hwy.LoaderProps) (any, error) {
+			names := make([]string, 0, len(tigers))
+			for _, tiger := range tigers {
+				names = append(names, tiger.Name)
+			}
+			slices.Sort(names)
+			return names, nil
+		},
+		Head: func(*hwy.HeadProps) (*[]hwy.HeadBlock, error) {
+			return &[]hwy.HeadBlock{{Title: "Tigers"}}, nil
+		},
+	},
+	"/tigers/$tiger_id": {
+		Loader: func(props *hwy.LoaderProps) (any, error) {
+			tiger, ok := tigers[(*props.Params)["tiger_id"]]
+			if !ok {
+				return nil, hwy.NotFound()
+			}
+			return tiger, nil
+		},
+		Head: func(props *hwy.HeadProps) (*[]hwy.HeadBlock, error) {
+			return &[]hwy.HeadBlock{{Title: props.LoaderData.(Tiger).Name}}, nil
+		},
+	},
+	"/keepers": {
+		Guard: func(props *hwy.GuardProps) (*hwy.GuardOutcome, error) {
+			if _, err := props.Request.Cookie(KeeperCookie); err != nil {
+				return hwy.Redirect("/"), nil
+			}
+			return nil, nil
+		},
+	},
+	"/enclosure": {
+		Loader: func(*hwy.LoaderProps) (any, error) {
+			return nil, ErrEnclosureClosed
+		},
+	},
+}
+
+// Redirects keeps the zoo's old tiger URLs working
+var Redirects = []hwy.RedirectRule{
+	{From: "/tiger/$tiger_id", To: "/tigers/$tiger_id"},
+}
+
+// New returns the zoo, initialized
+func New() (hwy.Hwy, error) {
+	fsys, err := getFS()
+	if err != nil {
+		return hwy.Hwy{}, err
+	}
+	h := hwy.Hwy{
+		FS:                   fsys,
+		DataFuncsMap:         DataFuncsMap,
+		Redirects:            Redirects,
+		RootTemplateLocation: "index.go.html",
+	}
+	if err := h.Initialize(); err != nil {
+		return hwy.Hwy{}, err
+	}
+	return h, nil
+}
+
+// getFS returns the root template alongside a paths file generated from the
+// page files, in place of a build's output
+func getFS() (fs.FS, error) {
+	var pageFiles []string
+	pages, err := fs.Sub(appFS, "pages")
+	if err != nil {
+		return nil, err
+	}
+	err = fs.WalkDir(pages, ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			pageFiles = append(pageFiles, path)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	pathsFileBytes, err := json.Marshal(hwy.PathsFile{
+		Paths:   hwy.GetPathsFromPageFiles(pageFiles...),
+		BuildID: "example",
+	})
+	if err != nil {
+		return nil, err
+	}
+	rootTemplate, err := fs.ReadFile(appFS, "index.go.html")
+	if err != nil {
+		return nil, err
+	}
+	return fstest.MapFS{
+		"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes},
+		"index.go.html":  &fstest.MapFile{Data: rootTemplate},
+	}, nil
+}
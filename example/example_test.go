@@ -0,0 +1,123 @@
+package example
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sjc5/hwy-go/hwytest"
+)
+
+func Example() {
+	h, err := New()
+	if err != nil {
+		panic(err)
+	}
+	server := httptest.NewServer(h.GetRootHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/tigers/1")
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Println(resp.StatusCode, regexp.MustCompile(`<title>.*</title>`).FindString(string(body)))
+	// Output: 200 <title>Tony</title>
+}
+
+func newServer(t *testing.T) *hwytest.Server {
+	h, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hwytest.NewServer(t, h)
+}
+
+func TestPageLoads(t *testing.T) {
+	server := newServer(t)
+
+	resp, body := server.Get("/tigers/2")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "<title>Shere Khan</title>") {
+		t.Errorf("expected the tiger's page, got %d: %s", resp.StatusCode, body)
+	}
+	if !strings.Contains(body, `x.buildID = "example"`) {
+		t.Error("expected the route data to be inlined for the client")
+	}
+
+	// Loaders returning hwy.NotFound render the nearest catch route
+	resp, body = server.Get("/tigers/3")
+	if resp.StatusCode != http.StatusNotFound || !strings.Contains(body, `x.pattern = "/$"`) {
+		t.Errorf("expected the catch route with a 404, got %d: %s", resp.StatusCode, body)
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	server := newServer(t)
+
+	resp, _ := server.Get("/tiger/2")
+	if resp.StatusCode != http.StatusMovedPermanently || resp.Header.Get("Location") != "/tigers/2" {
+		t.Errorf("expected the redirect rule's 301, got %d to %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	resp, _ = server.Get("/keepers")
+	if resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != "/" {
+		t.Errorf("expected the guard's redirect home, got %d to %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	r := httptest.NewRequest("GET", "/keepers", nil)
+	r.AddCookie(&http.Cookie{Name: KeeperCookie, Value: "1"})
+	if resp, _ = server.Do(r); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected keepers to be let in, got %d", resp.StatusCode)
+	}
+
+	// JSON navigations get redirects as data, for the client router to follow
+	resp, routeData := server.GetJSON("/keepers")
+	if resp.StatusCode != http.StatusOK || routeData.GuardOutcome == nil || routeData.GuardOutcome.RedirectTo != "/" {
+		t.Errorf("expected the guard outcome in the route data, got %d: %+v", resp.StatusCode, routeData.GuardOutcome)
+	}
+	_, routeData = server.GetJSON("/tiger/2")
+	if routeData.GuardOutcome == nil || !strings.HasPrefix(routeData.GuardOutcome.RedirectTo, "/tigers/2?") {
+		t.Errorf("expected the redirect rule's outcome in the route data, got %+v", routeData.GuardOutcome)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	server := newServer(t)
+
+	_, routeData := server.GetJSON("/enclosure")
+	if routeData.OutermostErrorBoundaryIndex != -1 || (*routeData.LoadersData)[0] != nil {
+		t.Errorf("expected the failed loader's data dropped, with no error boundary to catch it, got %+v", routeData)
+	}
+	resp, body := server.Get("/enclosure")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "x.outermostErrorBoundaryIndex =  -1") {
+		t.Errorf("expected the error to be rendered by the client, got %d: %s", resp.StatusCode, body)
+	}
+}
+
+func TestJSONNavigations(t *testing.T) {
+	server := newServer(t)
+
+	resp, routeData := server.GetJSON("/tigers/1")
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("expected JSON, got %q", resp.Header.Get("Content-Type"))
+	}
+	if !slices.Equal(*routeData.Patterns, []string{"/tigers", "/tigers/$tiger_id"}) || (*routeData.Params)["tiger_id"] != "1" {
+		t.Errorf("expected the tiger's routes and params, got %v %v", *routeData.Patterns, *routeData.Params)
+	}
+	if names := (*routeData.LoadersData)[0]; fmt.Sprint(names) != "[Shere Khan Tony]" {
+		t.Errorf("expected the layout's loader data, got %v", names)
+	}
+	if routeData.Title != "Tony" {
+		t.Errorf("expected the deepest head's title, got %q", routeData.Title)
+	}
+
+	resp, routeData = server.GetJSON("/nope")
+	if resp.StatusCode != http.StatusOK || routeData.Pattern != "/$" || (*routeData.SplatSegments)[0] != "nope" {
+		t.Errorf("expected the ultimate catch route, got %d: %+v", resp.StatusCode, routeData)
+	}
+}
@@ -0,0 +1,3 @@
+export default function Index({ loaderData }: { loaderData: string }) {
+	return <h1>{loaderData}</h1>;
+}
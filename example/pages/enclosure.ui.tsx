@@ -0,0 +1,3 @@
+export default function Enclosure() {
+	return <h1>Enclosure</h1>;
+}
@@ -0,0 +1,3 @@
+export default function Keepers() {
+	return <h1>Keepers only</h1>;
+}
@@ -0,0 +1,15 @@
+import type { ReactNode } from "react";
+
+export default function Tigers({ loaderData, children }: { loaderData: string[]; children: ReactNode }) {
+	return (
+		<>
+			<h1>Tigers</h1>
+			<ul>
+				{loaderData.map((name) => (
+					<li key={name}>{name}</li>
+				))}
+			</ul>
+			{children}
+		</>
+	);
+}
@@ -0,0 +1,3 @@
+export default function Tiger({ loaderData }: { loaderData: { id: string; name: string } }) {
+	return <h2>{loaderData.name}</h2>;
+}
@@ -3,6 +3,14 @@ package hwy
 import router "github.com/sjc5/hwy-go/router"
 
 type BuildOptions = router.BuildOptions
+type BuildResult = router.BuildResult
+type BuildArtifact = router.BuildArtifact
+type BuildTask = router.BuildTask
+type BuildValidationError = router.BuildValidationError
+type SecondaryClientEntry = router.SecondaryClientEntry
+type ClientEntryOutput = router.ClientEntryOutput
+type LegacyOutput = router.LegacyOutput
+type BuildError = router.BuildError
 type Hwy = router.Hwy
 type HeadBlock = router.HeadBlock
 type DataFuncsMap = router.DataFuncsMap
@@ -14,6 +22,152 @@ type PathsFile = router.PathsFile
 type Loader = router.Loader
 type Action = router.Action
 type Head = router.Head
+type LoaderStrategy = router.LoaderStrategy
+type Services = router.Services
+type ServicesHook = router.ServicesHook
+type UserResolver = router.UserResolver
+type Guard = router.Guard
+type GuardProps = router.GuardProps
+type GuardOutcome = router.GuardOutcome
+type PermanentRedirectError = router.PermanentRedirectError
+type HeadersFunc = router.HeadersFunc
+type HeadersProps = router.HeadersProps
+type RawResponse = router.RawResponse
+type RedirectRule = router.RedirectRule
+type RewriteRule = router.RewriteRule
+type DataProps = router.DataProps
+type I18nConfig = router.I18nConfig
+type HeadDedupeKey = router.HeadDedupeKey
+type Noindex = router.Noindex
+type Logger = router.Logger
+type TracerProvider = router.TracerProvider
+type Tracer = router.Tracer
+type Span = router.Span
+type SpanAttribute = router.SpanAttribute
+type MetricsCollector = router.MetricsCollector
+type PrometheusCollector = router.PrometheusCollector
+type MatchExplanation = router.MatchExplanation
+type MatchCandidate = router.MatchCandidate
+type CompiledPattern = router.CompiledPattern
+type MatchResult = router.MatchResult
+type PathsSnapshotEntry = router.PathsSnapshotEntry
+type RouteAmbiguity = router.RouteAmbiguity
+type DeadRoute = router.DeadRoute
+type Route = router.Route
+type RouteDataFuncs = router.RouteDataFuncs
+type Option = router.Option
+type Clock = router.Clock
+type FakeClock = router.FakeClock
+type TimerClock = router.TimerClock
+type ResolveOptions = router.ResolveOptions
+type ResolvedRouteData = router.ResolvedRouteData
+type Fetcher = router.Fetcher
+type FetchResponse = router.FetchResponse
+type CircuitBreaker = router.CircuitBreaker
+type StaleOnError = router.StaleOnError
+type Invocation = router.Invocation
+type InvocationRecorder = router.InvocationRecorder
+type ErrorPhase = router.ErrorPhase
+type ErrorReport = router.ErrorReport
+type PanicError = router.PanicError
+type ErrorRenderPlan = router.ErrorRenderPlan
+type Breadcrumb = router.Breadcrumb
+type BreadcrumbFunc = router.BreadcrumbFunc
+type BreadcrumbProps = router.BreadcrumbProps
+type FragmentFunc = router.FragmentFunc
+type FragmentProps = router.FragmentProps
+type EventStream = router.EventStream
+type EventStreamProps = router.EventStreamProps
+type Event = router.Event
+type EventWriter = router.EventWriter
+type WebSocketHandler = router.WebSocketHandler
+type WebSocketProps = router.WebSocketProps
+type WebSocketConn = router.WebSocketConn
+type WebSocketMessageType = router.WebSocketMessageType
+type PrefetchMetricsCollector = router.PrefetchMetricsCollector
+type JobMetricsCollector = router.JobMetricsCollector
+type ExperimentMetricsCollector = router.ExperimentMetricsCollector
+type PayloadMetricsCollector = router.PayloadMetricsCollector
+type Experiment = router.Experiment
+type Experiments = router.Experiments
+type PathVariant = router.PathVariant
+type Job = router.Job
+type ParamError = router.ParamError
+type QueryError = router.QueryError
+type LoaderDataTransform = router.LoaderDataTransform
+type Encoder = router.Encoder
+type Serialization = router.Serialization
+type KeyCase = router.KeyCase
+type EncoderFunc = router.EncoderFunc
+type Compression = router.Compression
+type Compressor = router.Compressor
+type RootRenderer = router.RootRenderer
+type RootRenderProps = router.RootRenderProps
+type HTMLTemplateRenderer = router.HTMLTemplateRenderer
+type TemplComponent = router.TemplComponent
+type TemplRenderer = router.TemplRenderer
+type ComponentRenderer = router.ComponentRenderer
+type ComponentRenderInput = router.ComponentRenderInput
+type Island = router.Island
+type Images = router.Images
+type ImageEncoder = router.ImageEncoder
+type Preload = router.Preload
+type Tenants = router.Tenants
+type NegativeCachePolicy = router.NegativeCachePolicy
+type RateLimit = router.RateLimit
+type RateLimiter = router.RateLimiter
+type TokenBucketLimiter = router.TokenBucketLimiter
+type ClientHints = router.ClientHints
+type ClientHintsConfig = router.ClientHintsConfig
+type SecurityHeaders = router.SecurityHeaders
+type FrameOptions = router.FrameOptions
+type DevError = router.DevError
+type InitializeError = router.InitializeError
+type SourceSnippet = router.SourceSnippet
+type SourceLine = router.SourceLine
+
+const LoaderStrategyParallel = router.LoaderStrategyParallel
+const LoaderStrategySequential = router.LoaderStrategySequential
+const LoaderStrategyBounded = router.LoaderStrategyBounded
+const KeyCaseCamel = router.KeyCaseCamel
+const KeyCaseSnake = router.KeyCaseSnake
+const ErrorPhaseGuard = router.ErrorPhaseGuard
+const ErrorPhaseLoader = router.ErrorPhaseLoader
+const ErrorPhaseAction = router.ErrorPhaseAction
+const ErrorPhaseHead = router.ErrorPhaseHead
+const ErrorPhaseHeaders = router.ErrorPhaseHeaders
+const ErrorPhaseEventStream = router.ErrorPhaseEventStream
+const ReturnToParam = router.ReturnToParam
+const ErrorPhaseWebSocket = router.ErrorPhaseWebSocket
+const ErrorPhaseJob = router.ErrorPhaseJob
+const FrameOptionsDeny = router.FrameOptionsDeny
+const FrameOptionsSameOrigin = router.FrameOptionsSameOrigin
+const ClientEntryFileName = router.ClientEntryFileName
+const LegacyOutDirName = router.LegacyOutDirName
+const BuildIDPlaceholder = router.BuildIDPlaceholder
+const PathsFileSchemaVersion = router.PathsFileSchemaVersion
+const EmbeddedPathsFileConst = router.EmbeddedPathsFileConst
+const ErrorPhaseBuild = router.ErrorPhaseBuild
+const BuildErrorFileName = router.BuildErrorFileName
+const ServiceWorkerFileName = router.ServiceWorkerFileName
+const ServiceWorkerPath = router.ServiceWorkerPath
+const DeviceClassMobile = router.DeviceClassMobile
+const DeviceClassTablet = router.DeviceClassTablet
+const DeviceClassDesktop = router.DeviceClassDesktop
+const WebSocketText = router.WebSocketText
+const WebSocketBinary = router.WebSocketBinary
+const FragmentPatternHeader = router.FragmentPatternHeader
+const ProtocolHeader = router.ProtocolHeader
+const ProtocolVersion = router.ProtocolVersion
+const SpanRouteData = router.SpanRouteData
+const SpanMatch = router.SpanMatch
+const SpanGuard = router.SpanGuard
+const SpanLoader = router.SpanLoader
+const SpanAction = router.SpanAction
+const SpanHead = router.SpanHead
+const SpanSerialize = router.SpanSerialize
+const SpanFetch = router.SpanFetch
+const SpanRenderComponents = router.SpanRenderComponents
 
 var Build = router.Build
 var GenerateTypeScript = router.GenerateTypeScript
@@ -21,3 +175,92 @@ var NewLRUCache = router.NewLRUCache
 var GetIsJSONRequest = router.GetIsJSONRequest
 var GetHeadElements = router.GetHeadElements
 var GetSSRInnerHTML = router.GetSSRInnerHTML
+var NewServices = router.NewServices
+var Redirect = router.Redirect
+var RedirectExternal = router.RedirectExternal
+var RequireUser = router.RequireUser
+var Deny = router.Deny
+var NotFound = router.NotFound
+var NewDownload = router.NewDownload
+var NewTokenBucketLimiter = router.NewTokenBucketLimiter
+var IsNotFound = router.IsNotFound
+var Gone = router.Gone
+var IsGone = router.IsGone
+var PermanentRedirect = router.PermanentRedirect
+var ResolvePattern = router.ResolvePattern
+var NewCanonicalHeadBlock = router.NewCanonicalHeadBlock
+var DefaultHeadDedupeKeys = router.DefaultHeadDedupeKeys
+var DefaultNoindexStatuses = router.DefaultNoindexStatuses
+var GetAttributesHTML = router.GetAttributesHTML
+var NewSlogLogger = router.NewSlogLogger
+var Match = router.Match
+var CompilePattern = router.CompilePattern
+var RankRoutes = router.RankRoutes
+var FindRouteAmbiguities = router.FindRouteAmbiguities
+var FindDeadRoutes = router.FindDeadRoutes
+var RegisterRoutes = router.RegisterRoutes
+var WithDataFuncs = router.WithDataFuncs
+var WithDefaultHeadBlocks = router.WithDefaultHeadBlocks
+var WithInvocationRecorder = router.WithInvocationRecorder
+var NewFakeClock = router.NewFakeClock
+var DefaultForwardHeaders = router.DefaultForwardHeaders
+var DecodePathsFile = router.DecodePathsFile
+var GetPathsFromPageFiles = router.GetPathsFromPageFiles
+var GetClientEntryFileName = router.GetClientEntryFileName
+var GetPathsSnapshot = router.GetPathsSnapshot
+var WritePathsSnapshot = router.WritePathsSnapshot
+var CheckPathsSnapshot = router.CheckPathsSnapshot
+var DiffPathsSnapshots = router.DiffPathsSnapshots
+var GetBreadcrumbs = router.GetBreadcrumbs
+var GetIsEventStreamRequest = router.GetIsEventStreamRequest
+var GetIsWebSocketRequest = router.GetIsWebSocketRequest
+var GetIsPrefetchRequest = router.GetIsPrefetchRequest
+var GetCSPNonce = router.GetCSPNonce
+var GetTenant = router.GetTenant
+var ClassifyDevice = router.ClassifyDevice
+var DefaultClientHints = router.DefaultClientHints
+var Translate = router.Translate
+var GetIntegrity = router.GetIntegrity
+var ErrNotInitialized = router.ErrNotInitialized
+var ErrEmptyManifest = router.ErrEmptyManifest
+var ErrUnsupportedSchema = router.ErrUnsupportedSchema
+var ErrDuplicatePattern = router.ErrDuplicatePattern
+var ErrUnboundDataFuncs = router.ErrUnboundDataFuncs
+var ErrAmbiguousDataFuncs = router.ErrAmbiguousDataFuncs
+var ErrMissingBuildSource = router.ErrMissingBuildSource
+var ErrAmbiguousRoutes = router.ErrAmbiguousRoutes
+var ErrDeadRoute = router.ErrDeadRoute
+var ErrInvalidRouteStruct = router.ErrInvalidRouteStruct
+var ErrCircuitOpen = router.ErrCircuitOpen
+var ErrStaleBuild = router.ErrStaleBuild
+var ErrMissingFallback = router.ErrMissingFallback
+var ErrUnknownHeadProfile = router.ErrUnknownHeadProfile
+var ErrMissingAsset = router.ErrMissingAsset
+var ErrAssetIntegrity = router.ErrAssetIntegrity
+var ErrMissingMessages = router.ErrMissingMessages
+var ErrMissingLoader = router.ErrMissingLoader
+var ErrMissingActionTypes = router.ErrMissingActionTypes
+var ErrDisallowedHeadTag = router.ErrDisallowedHeadTag
+var ErrUnsafeRedirect = router.ErrUnsafeRedirect
+var ErrMissingPayloadKey = router.ErrMissingPayloadKey
+var ErrUnknownClientEntry = router.ErrUnknownClientEntry
+var ErrInvalidLegacyTarget = router.ErrInvalidLegacyTarget
+var ErrShuttingDown = router.ErrShuttingDown
+var ErrInvalidJob = router.ErrInvalidJob
+var ErrInitializeClone = router.ErrInitializeClone
+var ErrMissingParam = router.ErrMissingParam
+var ErrInvalidUUID = router.ErrInvalidUUID
+var ErrMissingQueryParam = router.ErrMissingQueryParam
+var ErrQueryOutOfRange = router.ErrQueryOutOfRange
+var ErrQueryNotAllowed = router.ErrQueryNotAllowed
+var ErrWebSocketMessageTooLarge = router.ErrWebSocketMessageTooLarge
+var ErrImageWidthNotAllowed = router.ErrImageWidthNotAllowed
+var ErrImageFormatNotAllowed = router.ErrImageFormatNotAllowed
+var NewPrometheusCollector = router.NewPrometheusCollector
+var RedactServerOnly = router.RedactServerOnly
+var DefaultCompressibleTypes = router.DefaultCompressibleTypes
+var DefaultMetricsBuckets = router.DefaultMetricsBuckets
+var DefaultImageWidths = router.DefaultImageWidths
+
+func ProvideService[T any](s *Services, value T) { router.ProvideService(s, value) }
+func GetService[T any](s *Services) (T, bool)    { return router.GetService[T](s) }
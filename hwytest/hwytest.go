@@ -0,0 +1,194 @@
+// Package hwytest builds in-memory Hwy instances from literal route tables,
+// for testing routes and data functions without running a build.
+//
+// Hwy keeps its route table in package state, so tests using hwytest must
+// not run in parallel with each other.
+package hwytest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sjc5/hwy-go/router"
+)
+
+// New initializes h (any field may be set, except FS) with routes built from
+// page files relative to the pages directory, e.g.
+// []string{"_index.ui.tsx", "tiger/$tiger_id.ui.tsx", "$.ui.tsx"}
+func New(t testing.TB, h router.Hwy, pageFiles ...string) router.Hwy {
+	t.Helper()
+	pathsFile := router.PathsFile{
+		Paths:   router.GetPathsFromPageFiles(pageFiles...),
+		BuildID: "hwytest",
+	}
+	pathsFileBytes, err := json.Marshal(pathsFile)
+	if err != nil {
+		t.Fatalf("hwytest: marshaling paths: %v", err)
+	}
+	h.FS = fstest.MapFS{
+		"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes},
+	}
+	if err := h.Initialize(); err != nil {
+		t.Fatalf("hwytest: initializing: %v", err)
+	}
+	return h
+}
+
+// NewDataProps returns props as a data func would receive them for r
+func NewDataProps(r *http.Request, params map[string]string, splatSegments ...string) router.DataProps {
+	if params == nil {
+		params = map[string]string{}
+	}
+	if splatSegments == nil {
+		splatSegments = []string{}
+	}
+	return router.DataProps{
+		Request:       r,
+		Params:        &params,
+		SplatSegments: &splatSegments,
+	}
+}
+
+// NewFormRequest returns a request with an url-encoded form body, as
+// submitted to an action
+func NewFormRequest(method string, target string, form url.Values) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+// GetRouteData runs h.GetRouteData for r, failing the test on error
+func GetRouteData(t testing.TB, h router.Hwy, r *http.Request) (*router.GetRouteDataOutput, *httptest.ResponseRecorder) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	routeData, err := h.GetRouteData(w, r)
+	if err != nil {
+		t.Fatalf("hwytest: getting route data for %s: %v", r.URL.Path, err)
+	}
+	return routeData, w
+}
+
+// Server serves a Hwy under httptest (see NewServer)
+type Server struct {
+	*httptest.Server
+	t      testing.TB
+	prefix string
+}
+
+// NewServer serves h.GetRootHandler() under httptest until the test ends,
+// e.g. for end to end tests of redirects, errors, and JSON navigations. Its
+// client doesn't follow redirects, so tests can assert them.
+func NewServer(t testing.TB, h router.Hwy) *Server {
+	server := httptest.NewServer(h.GetRootHandler())
+	t.Cleanup(server.Close)
+	server.Client().CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	prefix := h.Prefix
+	if prefix == "" {
+		prefix = router.HwyPrefix
+	}
+	return &Server{Server: server, t: t, prefix: prefix}
+}
+
+// Do sends r (e.g. from httptest.NewRequest or NewFormRequest) to the server,
+// returning the response with its body read, and failing the test on error
+func (s *Server) Do(r *http.Request) (*http.Response, string) {
+	s.t.Helper()
+	serverURL, err := url.Parse(s.URL)
+	if err != nil {
+		s.t.Fatalf("hwytest: parsing server URL: %v", err)
+	}
+	r = r.Clone(r.Context())
+	r.RequestURI = ""
+	r.URL.Scheme, r.URL.Host, r.Host = serverURL.Scheme, serverURL.Host, serverURL.Host
+	resp, err := s.Client().Do(r)
+	if err != nil {
+		s.t.Fatalf("hwytest: requesting %s: %v", r.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		s.t.Fatalf("hwytest: reading response to %s: %v", r.URL.Path, err)
+	}
+	return resp, string(body)
+}
+
+// Get requests target (e.g. "/tigers?sort=name") as a full page load
+func (s *Server) Get(target string) (*http.Response, string) {
+	s.t.Helper()
+	return s.Do(httptest.NewRequest("GET", target, nil))
+}
+
+// GetJSON requests target as the client router does when navigating to it,
+// decoding the route data
+func (s *Server) GetJSON(target string) (*http.Response, *router.GetRouteDataOutput) {
+	s.t.Helper()
+	r := httptest.NewRequest("GET", target, nil)
+	query := r.URL.Query()
+	query.Set(s.prefix+"json", "1")
+	r.URL.RawQuery = query.Encode()
+	resp, body := s.Do(r)
+	var routeData router.GetRouteDataOutput
+	if err := json.Unmarshal([]byte(body), &routeData); err != nil {
+		s.t.Fatalf("hwytest: decoding route data for %s (status %d): %v", target, resp.StatusCode, err)
+	}
+	return resp, &routeData
+}
+
+// AssertMatch checks that path matches routes of the given path types, in
+// order, with the given params (nil params are not checked)
+func AssertMatch(t testing.TB, h router.Hwy, path string, pathTypes []string, params map[string]string) {
+	t.Helper()
+	explanation := h.ExplainMatch(path)
+
+	candidates := make(map[string]router.MatchCandidate, len(explanation.Candidates))
+	for _, candidate := range explanation.Candidates {
+		candidates[candidate.Pattern] = candidate
+	}
+	gotPathTypes := make([]string, 0, len(explanation.MatchedRoutes))
+	for _, pattern := range explanation.MatchedRoutes {
+		gotPathTypes = append(gotPathTypes, candidates[pattern].PathType)
+	}
+	if !slices.Equal(gotPathTypes, pathTypes) {
+		t.Errorf("%s: expected path types %v, got %v (%v)", path, pathTypes, gotPathTypes, explanation.MatchedRoutes)
+	}
+
+	if params == nil || len(explanation.MatchedRoutes) == 0 {
+		return
+	}
+	lastPattern := explanation.MatchedRoutes[len(explanation.MatchedRoutes)-1]
+	gotParams := candidates[lastPattern].Params
+	if len(gotParams) != len(params) {
+		t.Errorf("%s: expected params %v, got %v", path, params, gotParams)
+		return
+	}
+	for key, value := range params {
+		if gotParams[key] != value {
+			t.Errorf("%s: expected param %s to be %q, got %q", path, key, value, gotParams[key])
+		}
+	}
+}
+
+// AssertPathsSnapshot fails the test if the routes in pagesSrcDir differ from
+// snapshotFile. Set HWY_UPDATE_SNAPSHOTS=1 to write the snapshot instead.
+func AssertPathsSnapshot(t testing.TB, pagesSrcDir string, snapshotFile string) {
+	t.Helper()
+	if os.Getenv("HWY_UPDATE_SNAPSHOTS") != "" {
+		if err := router.WritePathsSnapshot(pagesSrcDir, snapshotFile); err != nil {
+			t.Fatalf("hwytest: writing paths snapshot: %v", err)
+		}
+		return
+	}
+	if err := router.CheckPathsSnapshot(pagesSrcDir, snapshotFile); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,45 @@
+package hwytest
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/sjc5/hwy-go/router"
+)
+
+func TestHarness(t *testing.T) {
+	h := New(t, router.Hwy{
+		DataFuncsMap: router.DataFuncsMap{
+			"/tiger/$tiger_id": {
+				Loader: func(props *router.LoaderProps) (any, error) {
+					return "tiger " + (*props.Params)["tiger_id"], nil
+				},
+				Action: func(props *router.ActionProps) (any, error) {
+					return props.Request.FormValue("name"), nil
+				},
+			},
+		},
+	},
+		"_index.ui.tsx",
+		"$.ui.tsx",
+		"tiger.ui.tsx",
+		"tiger/$tiger_id.ui.tsx",
+	)
+
+	AssertMatch(t, h, "/", []string{router.PathTypeIndex}, nil)
+	AssertMatch(t, h, "/tiger/123", []string{router.PathTypeStaticLayout, router.PathTypeDynamicLayout}, map[string]string{"tiger_id": "123"})
+	AssertMatch(t, h, "/nope", []string{router.PathTypeUltimateCatch}, nil)
+
+	routeData, _ := GetRouteData(t, h, NewFormRequest("POST", "/tiger/123", url.Values{"name": {"stripes"}}))
+	if got := (*routeData.LoadersData)[1]; got != "tiger 123" {
+		t.Errorf("expected loader data %q, got %v", "tiger 123", got)
+	}
+	if got := (*routeData.ActionData)[1]; got != "stripes" {
+		t.Errorf("expected action data %q, got %v", "stripes", got)
+	}
+
+	props := NewDataProps(nil, map[string]string{"tiger_id": "1"})
+	if (*props.Params)["tiger_id"] != "1" || len(*props.SplatSegments) != 0 {
+		t.Errorf("unexpected data props: %+v", props)
+	}
+}
@@ -0,0 +1,50 @@
+package router
+
+import (
+	"net/http"
+	"time"
+)
+
+type accessLogWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *accessLogWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessLogWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// logAccess logs one request to Hwy.AccessLogger. The matched route pattern
+// is logged instead of the raw path, so entries aggregate by route.
+func (h Hwy) logAccess(r *http.Request, w *accessLogWriter, pattern string, startTime time.Time) {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	h.AccessLogger.Info("request",
+		"method", r.Method,
+		"pattern", pattern,
+		"status", status,
+		"duration", time.Since(startTime),
+		"bytes", w.bytes,
+		"buildID", instanceBuildID,
+	)
+}
@@ -0,0 +1,26 @@
+package router
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccessLogUsesRoutePattern(t *testing.T) {
+	var buf bytes.Buffer
+	h := Hwy{AccessLogger: NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))}
+
+	r := httptest.NewRequest("GET", "/tiger/access-log-test?__hwy_internal__json=1", nil)
+	h.GetRootHandler().ServeHTTP(httptest.NewRecorder(), r)
+
+	for _, expected := range []string{"method=GET", "pattern=/tiger/$tiger_id", "status=200", "bytes="} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in access log, got %q", expected, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "access-log-test") {
+		t.Errorf("expected the raw path not to be logged, got %q", buf.String())
+	}
+}
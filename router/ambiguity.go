@@ -0,0 +1,125 @@
+package router
+
+import (
+	"slices"
+	"strings"
+)
+
+// RouteAmbiguity is a pair of competing routes that both match URL with the
+// same Score at the same depth, so which one wins comes down to RankRoutes'
+// tie breakers rather than to the shapes of the routes. Renaming either page
+// file (e.g. making a param segment static) resolves it.
+type RouteAmbiguity struct {
+	Tenant string // empty for the base tree
+	URL    string // a representative URL both routes match
+	Winner string
+	Loser  string
+}
+
+// FindRouteAmbiguities simulates a representative URL for every pair of
+// competing routes of the same depth and reports the pairs that tie on Score
+// for it. Static layouts (which render alongside whatever else matches) and
+// the ultimate catch route never compete, so are left out.
+func FindRouteAmbiguities(paths []JSONSafePath) []RouteAmbiguity {
+	var ambiguities []RouteAmbiguity
+	for i, a := range paths {
+		for _, b := range paths[i+1:] {
+			if ambiguity, ok := getRouteAmbiguity(a, b); ok {
+				ambiguities = append(ambiguities, ambiguity)
+			}
+		}
+	}
+	return ambiguities
+}
+
+// findBuildAmbiguities runs FindRouteAmbiguities over the base tree and over
+// each tenant's tree (its page files merged over the base tree by pattern),
+// reporting for tenants only the pairs involving one of their own routes
+func findBuildAmbiguities(paths []JSONSafePath, tenantPaths map[string][]JSONSafePath) []RouteAmbiguity {
+	ambiguities := FindRouteAmbiguities(paths)
+	tenants := make([]string, 0, len(tenantPaths))
+	for tenant := range tenantPaths {
+		tenants = append(tenants, tenant)
+	}
+	slices.Sort(tenants)
+	for _, tenant := range tenants {
+		overrides := tenantPaths[tenant]
+		merged := slices.DeleteFunc(slices.Clone(paths), func(path JSONSafePath) bool {
+			return slices.ContainsFunc(overrides, func(override JSONSafePath) bool {
+				return override.Pattern == path.Pattern
+			})
+		})
+		merged = append(merged, overrides...)
+		for _, ambiguity := range FindRouteAmbiguities(merged) {
+			isOwn := slices.ContainsFunc(overrides, func(override JSONSafePath) bool {
+				return override.Pattern == ambiguity.Winner || override.Pattern == ambiguity.Loser
+			})
+			if isOwn {
+				ambiguity.Tenant = tenant
+				ambiguities = append(ambiguities, ambiguity)
+			}
+		}
+	}
+	return ambiguities
+}
+
+func getRouteAmbiguity(a, b JSONSafePath) (RouteAmbiguity, bool) {
+	if !getIsCompetingPath(a) || !getIsCompetingPath(b) || len(*a.Segments) != len(*b.Segments) {
+		return RouteAmbiguity{}, false
+	}
+	segments := make([]string, 0, len(*a.Segments))
+	for i, segment := range *a.Segments {
+		shared, ok := getSharedSegment(segment, (*b.Segments)[i])
+		if !ok {
+			return RouteAmbiguity{}, false
+		}
+		if shared != "" {
+			segments = append(segments, shared)
+		}
+	}
+	url := "/" + strings.Join(segments, "/")
+	aResult, bResult := Match(a.Pattern, url), Match(b.Pattern, url)
+	if !aResult.Matches || !bResult.Matches || aResult.Score != bResult.Score {
+		return RouteAmbiguity{}, false
+	}
+	ranked := RankRoutes([]*MatchingPath{
+		{Pattern: a.Pattern, Score: aResult.Score},
+		{Pattern: b.Pattern, Score: bResult.Score},
+	})
+	return RouteAmbiguity{URL: url, Winner: ranked[0].Pattern, Loser: ranked[1].Pattern}, true
+}
+
+func getIsCompetingPath(path JSONSafePath) bool {
+	return path.Segments != nil && path.PathType != PathTypeStaticLayout && path.PathType != PathTypeUltimateCatch
+}
+
+// getSharedSegment returns a path segment matched by both pattern segments
+// (each static, a param, a compound segment, a splat, or "" for an index)
+func getSharedSegment(a, b string) (string, bool) {
+	for _, candidate := range []string{getSampleSegment(a), getSampleSegment(b)} {
+		if getIsSegmentMatch(a, candidate) && getIsSegmentMatch(b, candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+func getSampleSegment(segment string) string {
+	if getIsCompoundSegment(segment) {
+		return compoundParamRe.ReplaceAllString(segment, "x")
+	}
+	if strings.HasPrefix(segment, "$") {
+		return "x"
+	}
+	return segment
+}
+
+func getIsSegmentMatch(segment string, pathSegment string) bool {
+	if compound := parseCompoundSegment(segment); compound != nil {
+		return compound.matches(pathSegment)
+	}
+	if segment == "" || !strings.HasPrefix(segment, "$") {
+		return segment == pathSegment
+	}
+	return pathSegment != ""
+}
@@ -0,0 +1,45 @@
+package router
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindRouteAmbiguities(t *testing.T) {
+	paths := GetPathsFromPageFiles(
+		"$.ui.tsx",
+		"about.ui.tsx",
+		"$lang/docs/$page.ui.tsx",
+		"blog/$x/$y.ui.tsx",
+		"users/$id.ui.tsx",
+		"users/$name.ui.tsx",
+		"users/me.ui.tsx",
+		"blog/latest-$id.ui.tsx",
+		"blog/$slug-$id.ui.tsx",
+		"blog/$slug/_index.ui.tsx",
+	)
+	expected := []RouteAmbiguity{
+		{URL: "/blog/docs/x", Winner: "/$lang/docs/$page", Loser: "/blog/$x/$y"},
+		{URL: "/users/x", Winner: "/users/$id", Loser: "/users/$name"},
+		{URL: "/blog/latest-x", Winner: "/blog/latest-$id", Loser: "/blog/$slug-$id"},
+	}
+	if ambiguities := FindRouteAmbiguities(paths); !reflect.DeepEqual(ambiguities, expected) {
+		t.Errorf("expected %v, got %v", expected, ambiguities)
+	}
+
+	// Only the pairs involving a tenant's own routes are reported for it
+	tenantPaths := map[string][]JSONSafePath{
+		"acme": GetPathsFromPageFiles("users/$handle.ui.tsx", "blog/$a/$b.ui.tsx"),
+		"beta": GetPathsFromPageFiles("about.ui.tsx"),
+	}
+	basePaths := GetPathsFromPageFiles("users/$id.ui.tsx", "blog/$a/$b.ui.tsx", "$lang/docs/$page.ui.tsx")
+	ambiguities := findBuildAmbiguities(basePaths, tenantPaths)
+	expected = []RouteAmbiguity{
+		{URL: "/blog/docs/x", Winner: "/$lang/docs/$page", Loser: "/blog/$a/$b"},
+		{Tenant: "acme", URL: "/users/x", Winner: "/users/$handle", Loser: "/users/$id"},
+		{Tenant: "acme", URL: "/blog/docs/x", Winner: "/$lang/docs/$page", Loser: "/blog/$a/$b"},
+	}
+	if !reflect.DeepEqual(ambiguities, expected) {
+		t.Errorf("expected %v, got %v", expected, ambiguities)
+	}
+}
@@ -0,0 +1,61 @@
+package router
+
+import (
+	"net/url"
+	"strings"
+)
+
+// BuildIDPlaceholder is replaced with the build ID in Hwy.AssetBasePrefix
+const BuildIDPlaceholder = "{buildID}"
+
+// getAssetBasePrefix returns "" when AssetBasePrefix is unset, otherwise the
+// prefix with its build ID filled in and a trailing slash
+func (h Hwy) getAssetBasePrefix() string {
+	if h.AssetBasePrefix == "" {
+		return ""
+	}
+	prefix := strings.ReplaceAll(h.AssetBasePrefix, BuildIDPlaceholder, instanceBuildID)
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// getAssetOrigin returns the origin of AssetBasePrefix (as a CSP source), or
+// "" if it's relative
+func (h Hwy) getAssetOrigin() string {
+	u, err := url.Parse(h.getAssetBasePrefix())
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	if u.Scheme == "" {
+		return u.Host
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// GetAssetURL returns the URL of a built file (e.g. a dep or the client
+// entry), under AssetBasePrefix if set, otherwise under /public/
+func (h Hwy) GetAssetURL(fileName string) string {
+	if prefix := h.getAssetBasePrefix(); prefix != "" {
+		return prefix + strings.TrimPrefix(fileName, "/")
+	}
+	return "/public/" + strings.TrimPrefix(fileName, "/")
+}
+
+// withAssetBasePrefix doesn't modify importURLs, which may be cached
+func (h Hwy) withAssetBasePrefix(importURLs *[]string) *[]string {
+	prefix := h.getAssetBasePrefix()
+	if prefix == "" || importURLs == nil {
+		return importURLs
+	}
+	prefixed := make([]string, len(*importURLs))
+	for i, importURL := range *importURLs {
+		if importURL == "" || importURL == "/" {
+			prefixed[i] = importURL
+			continue
+		}
+		prefixed[i] = prefix + strings.TrimPrefix(importURL, "/")
+	}
+	return &prefixed
+}
@@ -0,0 +1,89 @@
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Set by the fixture build's AfterBuild hook
+var fixtureBuildResult *BuildResult
+
+func TestAfterBuildHook(t *testing.T) {
+	if fixtureBuildResult == nil || fixtureBuildResult.BuildID == "" || fixtureBuildResult.HashedOutDir != "../tmp/out" {
+		t.Errorf("expected AfterBuild to receive the build result, got %+v", fixtureBuildResult)
+	}
+}
+
+// Set by the fixture build's other hooks, in the order they ran
+var fixtureBuildHooks []string
+var fixtureArtifacts []BuildArtifact
+
+// The fixture build's paths file, as read right after the build (TestRouter
+// removes the fixtures once it's done)
+var fixturePathsFileBytes []byte
+
+func TestBuildHooks(t *testing.T) {
+	buildID := fixtureBuildResult.BuildID
+	if len(fixtureBuildHooks) < 3 || fixtureBuildHooks[0] != "before "+buildID || fixtureBuildHooks[1] != "metafile "+buildID {
+		t.Fatalf("expected BeforeBuild then AfterMetafile, got %v", fixtureBuildHooks)
+	}
+	if fixtureArtifacts[0].Name != ClientEntryFileName || fixtureArtifacts[0].Path != "../tmp/out/"+ClientEntryFileName {
+		t.Errorf("expected the client entry first, got %+v", fixtureArtifacts[0])
+	}
+	for _, artifact := range fixtureArtifacts[1:] {
+		if artifact.Name == ClientEntryFileName || !strings.HasPrefix(artifact.Name, "hwy_") {
+			t.Errorf("unexpected artifact %s", artifact.Name)
+		}
+	}
+	if len(fixtureArtifacts) != len(fixtureBuildHooks)-2 {
+		t.Errorf("expected OnArtifact to run last, got %v", fixtureBuildHooks)
+	}
+
+	if !strings.Contains(string(fixturePathsFileBytes), `"augmented.js"`) {
+		t.Error("expected AfterMetafile's changes to be written to the paths file")
+	}
+}
+
+func TestAssetBasePrefix(t *testing.T) {
+	withRouteTable(t, nil) // restores the build ID afterwards
+	instanceBuildID = "build-1"
+
+	h := Hwy{AssetBasePrefix: "https://cdn.example.com/builds/{buildID}"}
+	importURLs := []string{"/a.js", "/b.js"}
+	prefixed := h.withAssetBasePrefix(&importURLs)
+	if (*prefixed)[0] != "https://cdn.example.com/builds/build-1/a.js" || (*prefixed)[1] != "https://cdn.example.com/builds/build-1/b.js" {
+		t.Errorf("unexpected prefixed import URLs %v", *prefixed)
+	}
+	if importURLs[0] != "/a.js" {
+		t.Error("expected original import URLs not to be modified")
+	}
+	if got := h.GetAssetURL(ClientEntryFileName); got != "https://cdn.example.com/builds/build-1/hwy_client_entry.js" {
+		t.Errorf("unexpected client entry URL %q", got)
+	}
+	if got := (Hwy{}).GetAssetURL("hwy_chunk__1.js"); got != "/public/hwy_chunk__1.js" {
+		t.Errorf("expected default /public/ URL, got %q", got)
+	}
+
+	routeData := &GetRouteDataOutput{AssetBasePrefix: h.getAssetBasePrefix(), Deps: &[]string{"hwy_chunk__1.js"}}
+	ssrInnerHTML, err := GetSSRInnerHTML(routeData, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(*ssrInnerHTML), `x.assetBasePrefix = "https://cdn.example.com/builds/build-1/" || "/public/"`) {
+		t.Errorf("expected asset base prefix in SSR script, got %s", *ssrInnerHTML)
+	}
+}
+
+func TestAssetBasePrefixInRouteData(t *testing.T) {
+	h := Hwy{AssetBasePrefix: "https://cdn.example.com/"}
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/tiger/assets-1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, importURL := range *routeData.ImportURLs {
+		if !strings.HasPrefix(importURL, "https://cdn.example.com/") {
+			t.Errorf("expected prefixed import URL, got %q", importURL)
+		}
+	}
+}
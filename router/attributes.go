@@ -0,0 +1,77 @@
+package router
+
+import (
+	"html"
+	"html/template"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Head functions may return blocks with Tag "html" or "body" to contribute
+// attributes (lang, dir, class, etc.) to those elements. These are merged
+// parent to child (children win) and exposed as GetRouteDataOutput's
+// HTMLAttributes and BodyAttributes, rather than being rendered in the head.
+
+func mergeAttributes(into map[string]string, from map[string]string) map[string]string {
+	if into == nil {
+		into = make(map[string]string, len(from))
+	}
+	for key, value := range from {
+		into[key] = value
+	}
+	return into
+}
+
+// GetAttributesHTML renders attributes as escaped, sorted key="value" pairs,
+// for use inside a root template's <html> or <body> tag
+func GetAttributesHTML(attributes map[string]string) template.HTMLAttr {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		if isValidAttributeName(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(key + `="` + html.EscapeString(attributes[key]) + `"`)
+	}
+	return template.HTMLAttr(sb.String())
+}
+
+func isValidAttributeName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		isAlphaNumeric := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isAlphaNumeric && r != '-' && r != '_' && r != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+func getPermittedHeadTags(extraPermittedHeadTags []string) []string {
+	if len(extraPermittedHeadTags) == 0 {
+		return permittedTags
+	}
+	return append(slices.Clone(permittedTags), extraPermittedHeadTags...)
+}
+
+// filterHeadBlocks drops blocks whose tag is neither permitted nor html/body
+func filterHeadBlocks(blocks *[]*HeadBlock, extraPermittedHeadTags []string) *[]*HeadBlock {
+	permittedHeadTags := getPermittedHeadTags(extraPermittedHeadTags)
+	filtered := make([]*HeadBlock, 0, len(*blocks))
+	for _, block := range *blocks {
+		isAttributesBlock := block.Tag == "html" || block.Tag == "body"
+		if len(block.Title) > 0 || isAttributesBlock || slices.Contains(permittedHeadTags, block.Tag) {
+			filtered = append(filtered, block)
+		}
+	}
+	return &filtered
+}
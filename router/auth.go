@@ -0,0 +1,50 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// UserResolver runs once per request, before matching, returning the
+// signed-in user, or nil when signed out. An error fails the request.
+type UserResolver func(r *http.Request) (any, error)
+
+const defaultLoginRoute = "/login"
+
+// ReturnToParam is the query param RequireUser passes the requested URL in
+const ReturnToParam = "returnTo"
+
+// resolveUser sets the request's user in scope, and in the "user" AdHocData
+func (h Hwy) resolveUser(r *http.Request, scope *requestScope) error {
+	scope.loginRoute = h.LoginRoute
+	if h.ResolveUser == nil {
+		return nil
+	}
+	user, err := h.ResolveUser(r)
+	if err != nil {
+		return err
+	}
+	scope.user = user
+	if user != nil {
+		scope.adHocData["user"] = user
+	}
+	return nil
+}
+
+// RequireUser is a Guard redirecting signed-out requests (per
+// Hwy.ResolveUser) to Hwy.LoginRoute, with the requested URL in the
+// ReturnToParam query param
+func RequireUser(props *GuardProps) (*GuardOutcome, error) {
+	if props.User != nil {
+		return nil, nil
+	}
+	loginRoute := defaultLoginRoute
+	if props.scope != nil && props.scope.loginRoute != "" {
+		loginRoute = props.scope.loginRoute
+	}
+	returnTo := *props.Request.URL
+	query := returnTo.Query()
+	query.Del(instancePrefix + "json")
+	returnTo.RawQuery = query.Encode()
+	return Redirect(loginRoute + "?" + url.Values{ReturnToParam: {returnTo.RequestURI()}}.Encode()), nil
+}
@@ -0,0 +1,92 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testUser struct {
+	Name string `json:"name"`
+}
+
+func TestResolveUser(t *testing.T) {
+	var resolves int
+	var loaderUser, headUser any
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			loaderUser = props.User
+			return nil, nil
+		},
+		Head: func(props *HeadProps) (*[]HeadBlock, error) {
+			headUser = props.User
+			return &[]HeadBlock{}, nil
+		},
+	})
+	h := Hwy{ResolveUser: func(r *http.Request) (any, error) {
+		resolves++
+		if r.Header.Get("Authorization") == "" {
+			return nil, nil
+		}
+		return &testUser{Name: "Simba"}, nil
+	}}
+
+	r := httptest.NewRequest("GET", "/lion/signed-in", nil)
+	r.Header.Set("Authorization", "Bearer simba")
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolves != 1 {
+		t.Errorf("expected the user to be resolved once, got %d", resolves)
+	}
+	if user, ok := loaderUser.(*testUser); !ok || user.Name != "Simba" || headUser != loaderUser {
+		t.Errorf("expected data funcs to get the user, got %v %v", loaderUser, headUser)
+	}
+	if user := (*routeData.AdHocData)["user"]; user == nil || *user != loaderUser {
+		t.Errorf("expected the user in AdHocData, got %v", routeData.AdHocData)
+	}
+
+	routeData, err = h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/signed-out", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routeData.AdHocData != nil || loaderUser != nil {
+		t.Error("expected no user when signed out")
+	}
+
+	errResolve := errors.New("session store down")
+	h.ResolveUser = func(r *http.Request) (any, error) { return nil, errResolve }
+	if _, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/failing", nil)); !errors.Is(err, errResolve) {
+		t.Errorf("expected the resolver's error, got %v", err)
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{Guard: RequireUser})
+	h := Hwy{
+		ResolveUser: func(r *http.Request) (any, error) {
+			if r.Header.Get("Authorization") == "" {
+				return nil, nil
+			}
+			return &testUser{Name: "Simba"}, nil
+		},
+		LoginRoute: "/sign-in",
+	}
+
+	routeData, err := h.GetRouteData(nil, httptest.NewRequest("GET", "/lion/private?tab=cubs&"+HwyPrefix+"json=1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "/sign-in?returnTo=%2Flion%2Fprivate%3Ftab%3Dcubs"
+	if outcome := routeData.GuardOutcome; outcome == nil || outcome.RedirectTo != expected {
+		t.Errorf("expected a redirect to %s, got %+v", expected, outcome)
+	}
+
+	r := httptest.NewRequest("GET", "/lion/private", nil)
+	r.Header.Set("Authorization", "Bearer simba")
+	if routeData, err = h.GetRouteData(nil, r); err != nil || routeData.GuardOutcome != nil {
+		t.Errorf("expected signed-in requests through, got %+v %v", routeData.GuardOutcome, err)
+	}
+}
@@ -0,0 +1,154 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// getBenchPageFiles returns a route table of roughly 5*sections routes
+func getBenchPageFiles(sections int) []string {
+	pageFiles := []string{"_index.ui.tsx", "$.ui.tsx"}
+	for i := 0; i < sections; i++ {
+		pageFiles = append(pageFiles,
+			fmt.Sprintf("section%d.ui.tsx", i),
+			fmt.Sprintf("section%d/_index.ui.tsx", i),
+			fmt.Sprintf("section%d/$item_id.ui.tsx", i),
+			fmt.Sprintf("section%d/$item_id/_index.ui.tsx", i),
+			fmt.Sprintf("section%d/$item_id/$.ui.tsx", i),
+		)
+	}
+	return pageFiles
+}
+
+var benchRouteTables = []struct {
+	name     string
+	sections int
+}{
+	{"small", 5},
+	{"medium", 100},
+	{"huge", 2_000},
+}
+
+// withRouteTable swaps in a route table for the duration of the test
+func withRouteTable(tb testing.TB, pageFiles []string) {
+	prevPaths, prevCache := instancePaths, gmpdCache
+	prevBuildID, prevClientEntryDeps := instanceBuildID, instanceClientEntryDeps
+	prevIntegrity, prevInitErr := instanceIntegrity, instanceInitErr
+	prevCSSBundle, prevCriticalCSS := instanceClientEntryCSSBundle, instanceClientEntryCriticalCSS
+	prevClientEntries, prevLegacy := instanceClientEntries, instanceLegacy
+	prevClock := instanceClock
+	paths := make([]Path, 0, len(pageFiles))
+	for _, jsonSafePath := range GetPathsFromPageFiles(pageFiles...) {
+		paths = append(paths, Path{
+			Pattern:  jsonSafePath.Pattern,
+			Segments: jsonSafePath.Segments,
+			PathType: jsonSafePath.PathType,
+			compiled: CompilePattern(jsonSafePath.Pattern),
+		})
+	}
+	instancePaths = &paths
+	gmpdCache = NewLRUCache(gmpdCacheSize)
+	tb.Cleanup(func() {
+		instancePaths, gmpdCache = prevPaths, prevCache
+		instanceBuildID, instanceClientEntryDeps = prevBuildID, prevClientEntryDeps
+		instanceIntegrity, instanceInitErr = prevIntegrity, prevInitErr
+		instanceClientEntryCSSBundle, instanceClientEntryCriticalCSS = prevCSSBundle, prevCriticalCSS
+		instanceClientEntries, instanceLegacy = prevClientEntries, prevLegacy
+		instanceClock = prevClock
+	})
+}
+
+const benchPath = "/section3/123/a/b"
+
+func BenchmarkGetInitialMatchingPaths(b *testing.B) {
+	for _, table := range benchRouteTables {
+		b.Run(table.name, func(b *testing.B) {
+			withRouteTable(b, getBenchPageFiles(table.sections))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				getInitialMatchingPaths(instancePaths, benchPath)
+			}
+		})
+	}
+}
+
+func BenchmarkGetMatchingPathsInternal(b *testing.B) {
+	for _, table := range benchRouteTables {
+		b.Run(table.name, func(b *testing.B) {
+			withRouteTable(b, getBenchPageFiles(table.sections))
+			initialMatchingPaths := getInitialMatchingPaths(instancePaths, benchPath)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				getMatchingPathsInternal(initialMatchingPaths, benchPath)
+			}
+		})
+	}
+}
+
+func BenchmarkGetRouteData(b *testing.B) {
+	for _, table := range benchRouteTables {
+		b.Run(table.name, func(b *testing.B) {
+			withRouteTable(b, getBenchPageFiles(table.sections))
+			r := httptest.NewRequest("GET", benchPath, nil)
+			w := httptest.NewRecorder()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				Hwy{}.GetRouteData(w, r)
+			}
+		})
+	}
+}
+
+func TestMatchAllocationBudgets(t *testing.T) {
+	withRouteTable(t, getBenchPageFiles(2_000))
+
+	compiled := CompilePattern("/section1/$item_id")
+	split := splitPath(benchPath)
+	if allocs := testing.AllocsPerRun(100, func() { compiled.matchSplitPath(split) }); allocs != 0 {
+		t.Errorf("expected non-matching patterns not to allocate, got %v allocs", allocs)
+	}
+
+	// Cost should scale with the number of matches, not the size of the route table
+	if allocs := testing.AllocsPerRun(100, func() { getInitialMatchingPaths(instancePaths, benchPath) }); allocs > 32 {
+		t.Errorf("expected at most 32 allocs to find initial matches, got %v", allocs)
+	}
+
+	r := &http.Request{Method: "GET", URL: &url.URL{Path: benchPath}}
+	getMatchingPathItem(r)
+	if allocs := testing.AllocsPerRun(100, func() { getMatchingPathItem(r) }); allocs > 0 {
+		t.Errorf("expected cached matching to not allocate, got %v allocs", allocs)
+	}
+}
+
+func BenchmarkGetHeadElements(b *testing.B) {
+	routeData := &GetRouteDataOutput{
+		Title: "Tiger",
+		MetaHeadBlocks: &[]*HeadBlock{
+			{Tag: "meta", Attributes: map[string]string{"name": "description", "content": "Tigers"}},
+			{Tag: "meta", Attributes: map[string]string{"property": "og:title", "content": "Tiger"}},
+		},
+		RestHeadBlocks: &[]*HeadBlock{
+			{Tag: "link", Attributes: map[string]string{"rel": "canonical", "href": "https://example.com/tiger"}},
+		},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetHeadElements(routeData)
+	}
+}
+
+func BenchmarkGetSSRInnerHTML(b *testing.B) {
+	withRouteTable(b, getBenchPageFiles(5))
+	routeData, _ := Hwy{}.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", benchPath, nil))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GetSSRInnerHTML(routeData, false)
+	}
+}
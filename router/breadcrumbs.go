@@ -0,0 +1,72 @@
+package router
+
+import (
+	"net/http"
+)
+
+type Breadcrumb struct {
+	Label   string `json:"label"`
+	Href    string `json:"href"`
+	Pattern string `json:"pattern"`
+}
+
+// BreadcrumbFunc returns the breadcrumb label of its route for the current
+// request (an empty label skips the route)
+type BreadcrumbFunc func(*BreadcrumbProps) string
+
+type BreadcrumbProps struct {
+	DataProps
+	LoaderData any
+	Handle     map[string]any
+}
+
+// GetBreadcrumbs walks the matched routes outermost first, labeling each with
+// its DataFuncs.Breadcrumb func or, failing that, a "breadcrumb" string in
+// its Handle. Routes without a label are skipped, as are index routes whose
+// label repeats their parent layout's.
+func GetBreadcrumbs(r *http.Request, a *ActivePathData) []Breadcrumb {
+	if a.MatchingPaths == nil {
+		return nil
+	}
+	var params map[string]string
+	if a.Params != nil {
+		params = *a.Params
+	}
+	var splatSegments []string
+	if a.SplatSegments != nil {
+		splatSegments = *a.SplatSegments
+	}
+
+	var breadcrumbs []Breadcrumb
+	for i, path := range *a.MatchingPaths {
+		label := getBreadcrumbLabel(r, a, i, path)
+		if label == "" {
+			continue
+		}
+		href := ResolvePattern(path.Pattern, params, splatSegments)
+		if a.scope != nil {
+			href = getLocalizedURL(a.scope.i18n, "", a.scope.locale, href)
+		}
+		if n := len(breadcrumbs); n > 0 && breadcrumbs[n-1].Href == href && breadcrumbs[n-1].Label == label {
+			continue
+		}
+		breadcrumbs = append(breadcrumbs, Breadcrumb{Label: label, Href: href, Pattern: path.Pattern})
+	}
+	return breadcrumbs
+}
+
+func getBreadcrumbLabel(r *http.Request, a *ActivePathData, i int, path *DecoratedPath) string {
+	if path.DataFuncs != nil && path.DataFuncs.Breadcrumb != nil {
+		var loaderData any
+		if a.LoadersData != nil && i < len(*a.LoadersData) {
+			loaderData = (*a.LoadersData)[i]
+		}
+		return path.DataFuncs.Breadcrumb(&BreadcrumbProps{
+			DataProps:  a.scope.newDataProps(r, a.Params, a.SplatSegments),
+			LoaderData: loaderData,
+			Handle:     path.Handle,
+		})
+	}
+	label, _ := path.Handle["breadcrumb"].(string)
+	return label
+}
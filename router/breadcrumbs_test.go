@@ -0,0 +1,46 @@
+package router
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBreadcrumbs(t *testing.T) {
+	setTestDataFuncs(t, "/tiger", &DataFuncs{
+		Handle: map[string]any{"breadcrumb": "Tigers"},
+	})
+	setTestDataFuncs(t, "/tiger/$tiger_id", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return "Tiger " + (*props.Params)["tiger_id"], nil
+		},
+		Breadcrumb: func(props *BreadcrumbProps) string {
+			return props.LoaderData.(string)
+		},
+	})
+	for i, path := range *instancePaths {
+		if path.Pattern == "/tiger" {
+			(*instancePaths)[i].Handle = path.DataFuncs.Handle
+			t.Cleanup(func() { (*instancePaths)[i].Handle = nil })
+		}
+	}
+
+	routeData, err := Hwy{}.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/tiger/breadcrumbs-test", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Breadcrumb{
+		{Label: "Tigers", Href: "/tiger", Pattern: "/tiger"},
+		{Label: "Tiger breadcrumbs-test", Href: "/tiger/breadcrumbs-test", Pattern: "/tiger/$tiger_id"},
+	}
+	if len(routeData.Breadcrumbs) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, routeData.Breadcrumbs)
+	}
+	for i := range expected {
+		if routeData.Breadcrumbs[i] != expected[i] {
+			t.Errorf("expected breadcrumb %d to be %v, got %v", i, expected[i], routeData.Breadcrumbs[i])
+		}
+	}
+	if _, ok := (*routeData.AdHocData)["breadcrumbs"]; !ok {
+		t.Errorf("expected breadcrumbs in ad hoc data")
+	}
+}
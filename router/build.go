@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -25,95 +26,214 @@ type BuildOptions struct {
 	UsePreactCompat   bool
 	DataFuncsMap      DataFuncsMap
 	GeneratedTSOutDir string
+	Logger            Logger // defaults to DefaultLogger
+	Metrics           MetricsCollector
+	// Computes Subresource Integrity hashes for the client entry and all
+	// chunks, for serving assets from a third-party CDN
+	Integrity bool
+	// Selectors whose rules, from the client entry's CSS, are inlined as
+	// critical CSS on every document request (see DataFuncs.CriticalCSS)
+	CriticalCSS []string
+	// Defaults to HwyPrefix. Must match Hwy.Prefix.
+	Prefix string
+	// Writes a service worker (see ServiceWorkerFileName) precaching this
+	// build's assets, for Hwy.ServiceWorker to serve and register
+	ServiceWorker bool
+	// Tenant name to a pages directory whose page files replace (by pattern)
+	// or add to those of PagesSrcDir for that tenant (see Hwy.Tenants)
+	TenantPagesDirs map[string]string
+	// Leaves page files that are never served (see BuildResult.DeadRoutes)
+	// out of the build and the paths file
+	PruneDeadRoutes bool
+	// Secondary client entries, each loaded in place of ClientEntry by the
+	// routes under its subtree or naming it in DataFuncs.ClientEntry
+	ClientEntries []SecondaryClientEntry
+	// Also builds every entry point for these older esbuild targets (e.g.
+	// "es2017", "chrome64", "safari11.1"), into LegacyOutDirName in
+	// HashedOutDir, for requests Hwy.IsLegacyBrowser picks out. Both builds
+	// are ES modules, as esbuild only code-splits those, so the legacy build
+	// only lowers syntax (other than import(), which the client router needs).
+	LegacyTargets []string
+	// Defaults to the system clock (see Clock)
+	Clock Clock
+	// Returns the build ID, which defaults to the Unix time in seconds, e.g.
+	// a commit hash, or a constant for snapshot tests
+	BuildIDFunc func() string
+	// Runs before the page files are read and bundled, e.g. to generate
+	// sources. An error fails the build.
+	BeforeBuild func(buildID string) error
+	// Runs once the metafile is processed, before the paths file is written,
+	// e.g. to add to it. An error fails the build.
+	AfterMetafile func(*PathsFile) error
+	// Runs for each emitted asset (the client entry, then each file in
+	// HashedOutDir, including sourcemaps) once the build output is written,
+	// e.g. to checksum or upload it. An error fails the build.
+	OnArtifact func(BuildArtifact) error
+	// Runs once the build output is written, e.g. to upload the out dirs to
+	// the CDN behind Hwy.AssetBasePrefix. An error fails the build.
+	AfterBuild func(*BuildResult) error
+	// Also writes the paths file as a Go source file at this path (e.g.
+	// "hwy_paths_gen.go"), declaring EmbeddedPathsFileConst in package
+	// PathsGoPackage (defaults to "main"), for Hwy.EmbeddedPathsFile
+	PathsGoFile    string
+	PathsGoPackage string
+	// Caches the bundles (and their metafiles) in this directory across
+	// builds, restoring them rather than running esbuild again while none of
+	// their inputs or options have changed, e.g. for CI or cold dev starts
+	CacheDir string
+	// Also runs GenerateTypeScript, alongside bundling
+	WithTypeScript bool
+	// Extra work (e.g. sitemap generation or prerendering) run concurrently
+	// with the rest of the build, each task's duration logged like its own
+	// stages'
+	Tasks []BuildTask
+	// Only checks the page files, client entries, data funcs, and (with
+	// WithTypeScript or GeneratedTSOutDir) TypeScript generation, without
+	// running hooks or esbuild or writing anything, e.g. for a pre-commit
+	// hook. Every problem, conflicting routes included, is returned in a
+	// *BuildValidationError.
+	DryRun bool
+}
+
+type BuildResult struct {
+	BuildID        string
+	HashedOutDir   string
+	UnhashedOutDir string
+	ClientEntryOut string
+	// Pairs of routes whose precedence depends on tie-breaking, each also
+	// logged as a warning
+	Ambiguities []RouteAmbiguity
+	// Page files never served (see FindDeadRoutes), each also logged as a
+	// warning, and left out of the build with BuildOptions.PruneDeadRoutes
+	DeadRoutes []DeadRoute
+	// Source files bundled without contributing to any output
+	TreeShakenInputs []string
 }
 
 func walkPages(pagesSrcDir string) []JSONSafePath {
 	var paths []JSONSafePath
 	filepath.WalkDir(pagesSrcDir, func(patternArg string, d fs.DirEntry, err error) error {
-		cleanPatternArg := filepath.Clean(strings.TrimPrefix(patternArg, pagesSrcDir))
-		isPageFile := strings.Contains(cleanPatternArg, ".ui.")
-		if !isPageFile {
-			return nil
-		}
-		ext := filepath.Ext(cleanPatternArg)
-		preExtDelineator := ".ui"
-		pattern := strings.TrimSuffix(cleanPatternArg, preExtDelineator+ext)
-		isIndex := false
-		patternToSplit := strings.TrimPrefix(pattern, "/")
-
-		// Clean out double underscore segments
-		segmentsInitWithDubUnderscores := strings.Split(patternToSplit, "/")
-		segmentsInit := make([]string, 0, len(segmentsInitWithDubUnderscores))
-		for _, segment := range segmentsInitWithDubUnderscores {
-			if strings.HasPrefix(segment, "__") {
-				continue
-			}
-			segmentsInit = append(segmentsInit, segment)
+		if path, ok := getPathFromPageFile(pagesSrcDir, patternArg); ok {
+			paths = append(paths, path)
 		}
+		return nil
+	})
+	return mergeVariantPaths(paths)
+}
 
-		segments := make([]SegmentObj, len(segmentsInit))
-		for i, segmentStr := range segmentsInit {
-			isSplat := false
-			if segmentStr == "$" {
-				isSplat = true
-			}
-			if segmentStr == "_index" {
-				segmentStr = ""
-				isIndex = true
-			}
-			segmentType := "normal"
-			if isSplat {
-				segmentType = "splat"
-			} else if strings.HasPrefix(segmentStr, "$") {
-				segmentType = "dynamic"
-			} else if isIndex {
-				segmentType = "index"
-			}
-			segments[i] = SegmentObj{
-				SegmentType: segmentType,
-				Segment:     segmentStr,
-			}
+// GetPathsFromPageFiles returns the paths Build would generate for the given
+// page files, relative to the pages directory (e.g. "tiger/$tiger_id.ui.tsx")
+func GetPathsFromPageFiles(pageFiles ...string) []JSONSafePath {
+	paths := make([]JSONSafePath, 0, len(pageFiles))
+	for _, pageFile := range pageFiles {
+		if path, ok := getPathFromPageFile("", "/"+strings.TrimPrefix(pageFile, "/")); ok {
+			paths = append(paths, path)
 		}
-		segmentStrs := make([]string, len(segments))
-		for i, segment := range segments {
-			segmentStrs[i] = segment.Segment
+	}
+	return mergeVariantPaths(paths)
+}
+
+func getPathFromPageFile(pagesSrcDir string, patternArg string) (JSONSafePath, bool) {
+	cleanPatternArg := filepath.Clean(strings.TrimPrefix(patternArg, pagesSrcDir))
+	isPageFile := strings.Contains(cleanPatternArg, ".ui.")
+	if !isPageFile {
+		return JSONSafePath{}, false
+	}
+	ext := filepath.Ext(cleanPatternArg)
+	preExtDelineator := ".ui"
+	patternWithVariant := strings.TrimSuffix(cleanPatternArg, preExtDelineator+ext)
+	pattern, variant := cutVariant(patternWithVariant)
+	isIndex := false
+	patternToSplit := strings.TrimPrefix(pattern, "/")
+
+	// Clean out double underscore segments
+	segmentsInitWithDubUnderscores := strings.Split(patternToSplit, "/")
+	segmentsInit := make([]string, 0, len(segmentsInitWithDubUnderscores))
+	for _, segment := range segmentsInitWithDubUnderscores {
+		if strings.HasPrefix(segment, "__") {
+			continue
 		}
-		SrcPath := filepath.Join(pagesSrcDir, pattern) + preExtDelineator + ext
-		truthySegments := []string{}
-		for _, segment := range segmentStrs {
-			if segment != "" {
-				truthySegments = append(truthySegments, segment)
-			}
+		segmentsInit = append(segmentsInit, segment)
+	}
+
+	segments := make([]SegmentObj, len(segmentsInit))
+	for i, segmentStr := range segmentsInit {
+		if literal, ok := cutSegmentEscape(segmentStr); ok {
+			segments[i] = SegmentObj{SegmentType: "normal", Segment: escapeLiteralSegment(literal)}
+			continue
 		}
-		patternToUse := "/" + strings.Join(truthySegments, "/")
-		if patternToUse != "/" && strings.HasSuffix(patternToUse, "/") {
-			patternToUse = strings.TrimSuffix(patternToUse, "/")
+		isSplat := false
+		if segmentStr == "$" {
+			isSplat = true
 		}
-		pathType := PathTypeStaticLayout
-		if isIndex {
-			pathType = PathTypeIndex
-			if patternToUse == "/" {
-				patternToUse += "_index"
-			} else {
-				patternToUse += "/_index"
-			}
-		} else if segments[len(segments)-1].SegmentType == "splat" {
-			pathType = PathTypeNonUltimateSplat
-		} else if segments[len(segments)-1].SegmentType == "dynamic" {
-			pathType = PathTypeDynamicLayout
-		}
-		if patternToUse == "/$" {
-			pathType = PathTypeUltimateCatch
-		}
-		paths = append(paths, JSONSafePath{
-			Pattern:  patternToUse,
-			Segments: &segmentStrs,
-			PathType: pathType,
-			SrcPath:  SrcPath,
-		})
-		return nil
-	})
-	return paths
+		if segmentStr == "_index" {
+			segmentStr = ""
+			isIndex = true
+		}
+		segmentType := "normal"
+		if isSplat {
+			segmentType = "splat"
+		} else if strings.HasPrefix(segmentStr, "$") || getIsCompoundSegment(segmentStr) {
+			segmentType = "dynamic"
+		} else if isIndex {
+			segmentType = "index"
+		}
+		segments[i] = SegmentObj{
+			SegmentType: segmentType,
+			Segment:     segmentStr,
+		}
+	}
+	segmentStrs := make([]string, len(segments))
+	for i, segment := range segments {
+		segmentStrs[i] = segment.Segment
+	}
+	SrcPath := filepath.Join(pagesSrcDir, patternWithVariant) + preExtDelineator + ext
+	truthySegments := []string{}
+	for _, segment := range segmentStrs {
+		if segment != "" {
+			truthySegments = append(truthySegments, segment)
+		}
+	}
+	patternToUse := "/" + strings.Join(truthySegments, "/")
+	if patternToUse != "/" && strings.HasSuffix(patternToUse, "/") {
+		patternToUse = strings.TrimSuffix(patternToUse, "/")
+	}
+	pathType := PathTypeStaticLayout
+	if isIndex {
+		pathType = PathTypeIndex
+		if patternToUse == "/" {
+			patternToUse += "_index"
+		} else {
+			patternToUse += "/_index"
+		}
+	} else if segments[len(segments)-1].SegmentType == "splat" {
+		pathType = PathTypeNonUltimateSplat
+	} else if segments[len(segments)-1].SegmentType == "dynamic" {
+		pathType = PathTypeDynamicLayout
+	}
+	if patternToUse == "/$" {
+		pathType = PathTypeUltimateCatch
+	}
+	return JSONSafePath{
+		Pattern:  patternToUse,
+		Segments: &segmentStrs,
+		PathType: pathType,
+		SrcPath:  SrcPath,
+		variant:  variant,
+	}, true
+}
+
+// cutSegmentEscape returns the literal segment of a page file segment escaped
+// with a leading "[$]", "[_]", or "[__]", so "[$]index" is routed as "$index"
+// rather than as a param, "[_]index" as "_index" rather than as an index
+// route, and "[__]drafts" as "__drafts" rather than being left out
+func cutSegmentEscape(segment string) (string, bool) {
+	for _, prefix := range []string{"$", "__", "_"} {
+		if rest, ok := strings.CutPrefix(segment, "["+prefix+"]"); ok {
+			return prefix + rest, true
+		}
+	}
+	return "", false
 }
 
 func writePathsToDisk(pagesSrcDir string, pathsJSONOut string) error {
@@ -154,16 +274,35 @@ type MetafileImport struct {
 }
 
 type MetafileJSON struct {
+	Inputs  map[string]struct{} `json:"inputs"`
 	Outputs map[ImportPath]struct {
 		Imports    []MetafileImport `json:"imports"`
 		EntryPoint string           `json:"entryPoint"`
+		CSSBundle  ImportPath       `json:"cssBundle"`
+		Inputs     map[string]struct {
+			BytesInOutput int `json:"bytesInOutput"`
+		} `json:"inputs"`
 	} `json:"outputs"`
 }
 
 type PathsFile struct {
+	// See PathsFileSchemaVersion. Files without one are version 1.
+	SchemaVersion   int            `json:"schemaVersion,omitempty"`
 	Paths           []JSONSafePath `json:"paths"`
 	ClientEntryDeps []ImportPath   `json:"clientEntryDeps"`
 	BuildID         string         `json:"buildID"`
+	// File base name to SRI hash, when BuildOptions.Integrity is set
+	Integrity map[string]string `json:"integrity,omitempty"`
+
+	ClientEntryCSSBundle   string `json:"clientEntryCSSBundle,omitempty"`
+	ClientEntryCriticalCSS string `json:"clientEntryCriticalCSS,omitempty"`
+	// Secondary client entries (see BuildOptions.ClientEntries), by name
+	ClientEntries map[string]ClientEntryOutput `json:"clientEntries,omitempty"`
+	// Legacy builds (see BuildOptions.LegacyTargets), by modern file name
+	Legacy map[string]LegacyOutput `json:"legacy,omitempty"`
+
+	// Override routes from BuildOptions.TenantPagesDirs, by tenant
+	TenantPaths map[string][]JSONSafePath `json:"tenantPaths,omitempty"`
 }
 
 func GenerateTypeScript(opts BuildOptions) error {
@@ -191,15 +330,138 @@ func GenerateTypeScript(opts BuildOptions) error {
 		OutDest:   opts.GeneratedTSOutDir,
 		RouteDefs: routeDefs,
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	return writePrefixTypeScript(opts.GeneratedTSOutDir, opts.getPrefix())
+}
+
+func (opts BuildOptions) getPrefix() string {
+	if opts.Prefix != "" {
+		return opts.Prefix
+	}
+	return HwyPrefix
+}
+
+// writePrefixTypeScript writes hwy_prefix.ts, for client code reading the
+// globals set by GetSSRInnerHTML (e.g. globalThis[HWY_SYMBOL].loadersData)
+// or making JSON requests
+func writePrefixTypeScript(outDir string, prefix string) error {
+	prefixJSON, err := json.Marshal(prefix)
+	if err != nil {
+		return err
+	}
+	ts := fmt.Sprintf(`// Generated by Hwy. Do not edit.
+export const HWY_PREFIX = %s;
+export const HWY_SYMBOL = Symbol.for(HWY_PREFIX);
+export const HWY_JSON_PARAM = HWY_PREFIX + "json";
+`, prefixJSON)
+	return os.WriteFile(filepath.Join(outDir, "hwy_prefix.ts"), []byte(ts), os.ModePerm)
+}
+
+// BuildError is returned by Build for bundling errors
+type BuildError struct {
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	LineText string `json:"lineText,omitempty"`
+}
+
+func (e *BuildError) Error() string {
+	if e.File == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+func newBuildError(message api.Message) *BuildError {
+	buildErr := &BuildError{Message: message.Text}
+	if message.Location != nil {
+		buildErr.File = message.Location.File
+		buildErr.Line = message.Location.Line
+		buildErr.Column = message.Location.Column
+		buildErr.LineText = message.Location.LineText
+	}
+	return buildErr
 }
 
 func Build(opts BuildOptions) error {
+	err := build(opts)
+	if opts.IsDev && !opts.DryRun {
+		if writeErr := writeBuildErrorFile(opts.UnhashedOutDir, err); writeErr != nil {
+			opts.getLogger().Error("error writing build error file", "error", writeErr)
+		}
+	}
+	return err
+}
+
+// writeBuildErrorFile writes err for the dev error overlay, or removes the
+// file when err is nil
+func writeBuildErrorFile(unhashedOutDir string, err error) error {
+	path := filepath.Join(unhashedOutDir, BuildErrorFileName)
+	if err == nil {
+		if removeErr := os.Remove(path); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+			return removeErr
+		}
+		return nil
+	}
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		buildErr = &BuildError{Message: err.Error()}
+	}
+	buildErrBytes, marshalErr := json.Marshal(buildErr)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	if mkdirErr := os.MkdirAll(unhashedOutDir, os.ModePerm); mkdirErr != nil {
+		return mkdirErr
+	}
+	return os.WriteFile(path, buildErrBytes, os.ModePerm)
+}
+
+func build(opts BuildOptions) error {
 	startTime := time.Now()
-	buildID := fmt.Sprintf("%d", startTime.Unix())
-	Log.Infof("new build id: %s", buildID)
+	buildID := opts.getBuildID()
+	logger := opts.getLogger()
+	logger.Info("new build", "buildID", buildID)
+	if opts.DryRun {
+		err := validateBuild(opts)
+		logBuildStage(logger, "dry run", startTime)
+		return err
+	}
+	if opts.BeforeBuild != nil {
+		if err := opts.BeforeBuild(buildID); err != nil {
+			return err
+		}
+	}
+	baseResult := BuildResult{
+		BuildID:        buildID,
+		HashedOutDir:   opts.HashedOutDir,
+		UnhashedOutDir: opts.UnhashedOutDir,
+		ClientEntryOut: opts.ClientEntryOut,
+	}
+
+	// Work not depending on the page files runs alongside reading and
+	// bundling them
+	tasks := &buildTasks{logger: logger}
+	defer tasks.wait()
+	if opts.WithTypeScript {
+		tasks.run("typescript", func() error {
+			return GenerateTypeScript(opts)
+		})
+	}
+	for _, task := range opts.Tasks {
+		if !task.AfterBundle {
+			result := baseResult
+			tasks.run(task.Name, func() error {
+				return task.Run(&result)
+			})
+		}
+	}
 
+	stageStart := time.Now()
 	pathsJSONOut := filepath.Join(opts.UnhashedOutDir, "hwy_paths.json")
 	err := writePathsToDisk(opts.PagesSrcDir, pathsJSONOut)
 	if err != nil {
@@ -217,10 +479,95 @@ func Build(opts BuildOptions) error {
 	if err != nil {
 		return err
 	}
-	entryPoints := make([]string, 0, len(*paths)+1)
+	var tenantPaths map[string][]JSONSafePath
+	if len(opts.TenantPagesDirs) > 0 {
+		tenantPaths = make(map[string][]JSONSafePath, len(opts.TenantPagesDirs))
+		for tenant, pagesDir := range opts.TenantPagesDirs {
+			tenantPaths[tenant] = walkPages(pagesDir)
+		}
+	}
+	// Data funcs may also be keyed by page file (see resolveDataFuncsMap)
+	pathLists := [][]JSONSafePath{*paths}
+	for _, tenant := range getSortedKeys(tenantPaths) {
+		pathLists = append(pathLists, tenantPaths[tenant])
+	}
+	dataFuncsMap, keyProblems := resolveDataFuncsMap(opts.DataFuncsMap, getSrcPathPatterns(pathLists...))
+	if len(keyProblems) > 0 {
+		return errors.Join(keyProblems...)
+	}
+	ambiguities := findBuildAmbiguities(*paths, tenantPaths)
+	for _, ambiguity := range ambiguities {
+		logger.Warn("ambiguous routes: precedence depends on tie-breaking, consider renaming a page file",
+			"url", ambiguity.URL, "winner", ambiguity.Winner, "loser", ambiguity.Loser, "tenant", ambiguity.Tenant)
+	}
+	deadRoutes := FindDeadRoutes(*paths)
+	for tenant, tenantPaths := range tenantPaths {
+		for _, deadRoute := range FindDeadRoutes(tenantPaths) {
+			deadRoute.Tenant = tenant
+			deadRoutes = append(deadRoutes, deadRoute)
+		}
+	}
+	for _, deadRoute := range deadRoutes {
+		logger.Warn("dead route: page file never served, as a route of the same shape outranks it",
+			"srcPath", deadRoute.SrcPath, "pattern", deadRoute.Pattern, "shadowedBy", deadRoute.ShadowedBy, "tenant", deadRoute.Tenant)
+	}
+	if opts.PruneDeadRoutes && len(deadRoutes) > 0 {
+		*paths = pruneDeadRoutes(*paths, deadRoutes)
+		for tenant := range tenantPaths {
+			tenantPaths[tenant] = pruneDeadRoutes(tenantPaths[tenant], deadRoutes)
+		}
+	}
+	// The base paths first, then each tenant's
+	allPaths := [][]JSONSafePath{*paths}
+	for _, tenantPaths := range tenantPaths {
+		allPaths = append(allPaths, tenantPaths)
+	}
+
+	criticalSelectors := make(map[string][]string)
+	for _, paths := range allPaths {
+		for i, path := range paths {
+			if dataFuncs, ok := dataFuncsMap[path.Pattern]; ok {
+				paths[i].Handle = dataFuncs.Handle
+				paths[i].Static = dataFuncs.Static
+				criticalSelectors[path.Pattern] = dataFuncs.CriticalCSS
+				for _, srcPath := range dataFuncs.Islands {
+					paths[i].Islands = append(paths[i].Islands, Island{
+						Name:    getIslandName(srcPath),
+						SrcPath: srcPath,
+					})
+				}
+			}
+		}
+	}
+	for _, paths := range allPaths {
+		if err := setPathClientEntries(paths, opts.ClientEntries, dataFuncsMap); err != nil {
+			return err
+		}
+	}
+	entryPoints := make([]string, 0, len(*paths)+len(opts.ClientEntries)+1)
 	entryPoints = append(entryPoints, opts.ClientEntry)
-	for _, path := range *paths {
-		entryPoints = append(entryPoints, path.SrcPath)
+	secondaryClientEntries := make(map[string]string, len(opts.ClientEntries)) // src path to name
+	for _, clientEntry := range opts.ClientEntries {
+		entryPoints = append(entryPoints, clientEntry.SrcPath)
+		secondaryClientEntries[clientEntry.SrcPath] = clientEntry.Name
+	}
+	for _, paths := range allPaths {
+		for _, path := range paths {
+			entryPoints = append(entryPoints, path.SrcPath)
+			for _, variant := range path.Variants {
+				entryPoints = append(entryPoints, variant.SrcPath)
+			}
+		}
+	}
+	// Islands shared between routes are built once
+	for _, paths := range allPaths {
+		for _, path := range paths {
+			for _, island := range path.Islands {
+				if !slices.Contains(entryPoints, island.SrcPath) {
+					entryPoints = append(entryPoints, island.SrcPath)
+				}
+			}
+		}
 	}
 	// clear hashed out dir
 	// __TODO consider using a hwy_internal dir instead of in root
@@ -235,7 +582,7 @@ func Build(opts BuildOptions) error {
 		alias["react-dom"] = "preact/compat"
 		alias["react/jsx-runtime"] = "preact/jsx-runtime"
 	}
-	result := api.Build(api.BuildOptions{
+	esbuildOpts := api.BuildOptions{
 		Format:      api.FormatESModule,
 		Bundle:      true,
 		TreeShaking: api.TreeShakingTrue,
@@ -255,47 +602,215 @@ func Build(opts BuildOptions) error {
 		EntryNames:        "hwy_entry__[hash]",
 		Metafile:          true,
 		Alias:             alias,
-	})
-	if len(result.Errors) > 0 {
-		return errors.New(result.Errors[0].Text)
-	}
-	metafileJSONMap := MetafileJSON{}
-	err = json.Unmarshal([]byte(result.Metafile), &metafileJSONMap)
-	if err != nil {
-		return err
+		// Imports of .wasm files resolve to the URLs of hashed copies, relative
+		// to the importing module (so for use with new URL(url, import.meta.url))
+		Loader:     map[string]api.Loader{".wasm": api.LoaderFile},
+		AssetNames: "hwy_asset__[hash]",
 	}
+	logBuildStage(logger, "routes", stageStart)
 
-	hwyClientEntry := ""
+	var legacy *legacyBuild
+	if len(opts.LegacyTargets) > 0 {
+		legacyOpts := esbuildOpts
+		tasks.run("legacy bundle", func() error {
+			var err error
+			legacy, err = buildLegacy(legacyOpts, opts)
+			return err
+		})
+	}
+	var metafileJSONMap MetafileJSON
+	var treeShakenInputs []string
 	hwyClientEntryDeps := []string{}
-	for key, output := range metafileJSONMap.Outputs {
-		entryPoint := output.EntryPoint
-		deps, err := findAllDependencies(&metafileJSONMap, key)
+	hwyClientEntryCSSBundle := ""
+	hwyClientEntryCriticalCSS := ""
+	var clientEntryOutputs map[string]ClientEntryOutput
+	var clientEntryFileBytes []byte
+	clientEntryFileNames := []string{ClientEntryFileName}
+	secondaryClientEntryBytes := make(map[string][]byte, len(opts.ClientEntries))
+	tasks.run("bundle", func() error {
+		var err error
+		workers := &workerBuilds{}
+		var cacheHit bool
+		metafileJSONMap, cacheHit, err = bundle(esbuildOpts, workers, opts.getBuildCacheDir("modern"))
 		if err != nil {
 			return err
 		}
-		if opts.ClientEntry == entryPoint {
-			hwyClientEntry = filepath.Base(key)
-			depsWithoutClientEntry := make([]string, 0, len(deps)-1)
-			for _, dep := range deps {
-				if dep != hwyClientEntry {
-					depsWithoutClientEntry = append(depsWithoutClientEntry, dep)
-				}
+		if cacheHit {
+			logger.Info("build cache hit: inputs unchanged, bundle restored", "stage", "bundle")
+		}
+		treeShakenInputs = getTreeShakenInputs(&metafileJSONMap)
+		if len(treeShakenInputs) > 0 {
+			logger.Info("tree-shaken modules: bundled without contributing to any output", "srcPaths", treeShakenInputs)
+		}
+
+		hwyClientEntry := ""
+		// Secondary client entry name to its output in HashedOutDir
+		secondaryClientEntryFiles := make(map[string]string, len(opts.ClientEntries))
+		if len(opts.ClientEntries) > 0 {
+			clientEntryOutputs = make(map[string]ClientEntryOutput, len(opts.ClientEntries))
+		}
+		for key, output := range metafileJSONMap.Outputs {
+			entryPoint := output.EntryPoint
+			deps, err := findAllDependencies(&metafileJSONMap, key)
+			if err != nil {
+				return err
 			}
-			hwyClientEntryDeps = depsWithoutClientEntry
-		} else {
-			for i, path := range *paths {
-				if path.SrcPath == entryPoint {
-					(*paths)[i].OutPath = filepath.Base(key)
-					(*paths)[i].Deps = &deps
+			deps = append(deps, workers.getWorkerDeps(&metafileJSONMap, deps)...)
+			if opts.ClientEntry == entryPoint {
+				hwyClientEntry = filepath.Base(key)
+				depsWithoutClientEntry := make([]string, 0, len(deps)-1)
+				for _, dep := range deps {
+					if dep != hwyClientEntry {
+						depsWithoutClientEntry = append(depsWithoutClientEntry, dep)
+					}
+				}
+				hwyClientEntryDeps = depsWithoutClientEntry
+				if output.CSSBundle != "" {
+					hwyClientEntryCSSBundle = filepath.Base(output.CSSBundle)
+					hwyClientEntryCriticalCSS, err = getCriticalCSSFromFile(output.CSSBundle, opts.CriticalCSS)
+					if err != nil {
+						return err
+					}
+				}
+			} else if name, ok := secondaryClientEntries[entryPoint]; ok {
+				secondaryClientEntryFiles[name] = filepath.Base(key)
+				clientEntryOutput := ClientEntryOutput{Deps: slices.DeleteFunc(deps, func(dep string) bool {
+					return dep == filepath.Base(key)
+				})}
+				if output.CSSBundle != "" {
+					clientEntryOutput.CSSBundle = filepath.Base(output.CSSBundle)
+					clientEntryOutput.CriticalCSS, err = getCriticalCSSFromFile(output.CSSBundle, opts.CriticalCSS)
+					if err != nil {
+						return err
+					}
 				}
+				clientEntryOutputs[name] = clientEntryOutput
+			} else {
+				for _, paths := range allPaths {
+					for i, path := range paths {
+						if path.SrcPath == entryPoint {
+							paths[i].OutPath = filepath.Base(key)
+							paths[i].Deps = &deps
+							if output.CSSBundle != "" {
+								paths[i].CSSBundle = filepath.Base(output.CSSBundle)
+								paths[i].CriticalCSS, err = getCriticalCSSFromFile(output.CSSBundle, criticalSelectors[path.Pattern])
+								if err != nil {
+									return err
+								}
+							}
+						}
+						for _, variant := range path.Variants {
+							if variant.SrcPath == entryPoint {
+								variant.OutPath = filepath.Base(key)
+								variant.Deps = &deps
+								if output.CSSBundle != "" {
+									variant.CSSBundle = filepath.Base(output.CSSBundle)
+									variant.CriticalCSS, err = getCriticalCSSFromFile(output.CSSBundle, criticalSelectors[path.Pattern])
+									if err != nil {
+										return err
+									}
+								}
+							}
+						}
+						for j, island := range path.Islands {
+							if island.SrcPath == entryPoint {
+								paths[i].Islands[j].OutPath = filepath.Base(key)
+								paths[i].Islands[j].Deps = &deps
+							}
+						}
+					}
+				}
+			}
+		}
+		// Mv file at path stored in hwyClientEntry var to ../ in OutDir
+		clientEntryFileBytes, err = os.ReadFile(filepath.Join(opts.HashedOutDir, hwyClientEntry))
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filepath.Join(opts.ClientEntryOut, ClientEntryFileName), clientEntryFileBytes, os.ModePerm)
+		if err != nil {
+			return err
+		}
+		err = os.Remove(filepath.Join(opts.HashedOutDir, hwyClientEntry))
+		if err != nil {
+			return err
+		}
+		for _, clientEntry := range opts.ClientEntries {
+			hashedFile := filepath.Join(opts.HashedOutDir, secondaryClientEntryFiles[clientEntry.Name])
+			fileBytes, err := os.ReadFile(hashedFile)
+			if err != nil {
+				return err
+			}
+			fileName := GetClientEntryFileName(clientEntry.Name)
+			err = os.WriteFile(filepath.Join(opts.ClientEntryOut, fileName), fileBytes, os.ModePerm)
+			if err != nil {
+				return err
+			}
+			err = os.Remove(hashedFile)
+			if err != nil {
+				return err
+			}
+			clientEntryFileNames = append(clientEntryFileNames, fileName)
+			secondaryClientEntryBytes[fileName] = fileBytes
+		}
+		return nil
+	})
+	if err := tasks.wait(); err != nil {
+		return err
+	}
+
+	stageStart = time.Now()
+	var legacyOutputs map[string]LegacyOutput
+	if legacy != nil {
+		legacyOutputs, err = legacy.getOutputs(opts, &metafileJSONMap)
+		if err != nil {
+			return err
+		}
+	}
+
+	var integrity map[string]string
+	if opts.Integrity {
+		integrity, err = getDirIntegrity(opts.HashedOutDir)
+		if err != nil {
+			return err
+		}
+		if legacyOutputs != nil {
+			legacyIntegrity, err := getDirIntegrity(filepath.Join(opts.HashedOutDir, LegacyOutDirName))
+			if err != nil {
+				return err
+			}
+			for name, hash := range legacyIntegrity {
+				integrity[path.Join(LegacyOutDirName, name)] = hash
 			}
 		}
+		integrity[ClientEntryFileName] = getSRIHash(clientEntryFileBytes)
+		for fileName, fileBytes := range secondaryClientEntryBytes {
+			integrity[fileName] = getSRIHash(fileBytes)
+		}
 	}
-	pathsAsJSON, err := json.Marshal(PathsFile{
+
+	pathsFile := &PathsFile{
+		SchemaVersion:   PathsFileSchemaVersion,
 		Paths:           *paths,
 		ClientEntryDeps: hwyClientEntryDeps,
 		BuildID:         buildID,
-	})
+		Integrity:       integrity,
+
+		ClientEntryCSSBundle:   hwyClientEntryCSSBundle,
+		ClientEntryCriticalCSS: hwyClientEntryCriticalCSS,
+		ClientEntries:          clientEntryOutputs,
+		Legacy:                 legacyOutputs,
+
+		TenantPaths: tenantPaths,
+	}
+	if opts.AfterMetafile != nil {
+		err = opts.AfterMetafile(pathsFile)
+		if err != nil {
+			return err
+		}
+	}
+	pathsAsJSON, err := json.Marshal(pathsFile)
 	if err != nil {
 		return err
 	}
@@ -303,23 +818,50 @@ func Build(opts BuildOptions) error {
 	if err != nil {
 		return err
 	}
+	logBuildStage(logger, "paths file", stageStart)
 
-	// Mv file at path stored in hwyClientEntry var to ../ in OutDir
-	clientEntryFileBytes, err := os.ReadFile(filepath.Join(opts.HashedOutDir, hwyClientEntry))
-	if err != nil {
-		return err
+	result := baseResult
+	result.Ambiguities = ambiguities
+	result.DeadRoutes = deadRoutes
+	result.TreeShakenInputs = treeShakenInputs
+	if opts.PathsGoFile != "" {
+		tasks.run("paths go file", func() error {
+			return writePathsGoFile(opts.PathsGoFile, opts.PathsGoPackage, buildID, pathsAsJSON)
+		})
 	}
-
-	err = os.WriteFile(filepath.Join(opts.ClientEntryOut, "hwy_client_entry.js"), clientEntryFileBytes, os.ModePerm)
-	if err != nil {
-		return err
+	if opts.ServiceWorker {
+		tasks.run("service worker", func() error {
+			return writeServiceWorker(opts.UnhashedOutDir, opts.HashedOutDir, clientEntryFileNames, buildID, opts.getPrefix())
+		})
 	}
-	err = os.Remove(filepath.Join(opts.HashedOutDir, hwyClientEntry))
-	if err != nil {
+	if opts.OnArtifact != nil {
+		tasks.run("artifacts", func() error {
+			return emitArtifacts(opts.OnArtifact, opts.ClientEntryOut, clientEntryFileNames, opts.HashedOutDir)
+		})
+	}
+	for _, task := range opts.Tasks {
+		if task.AfterBundle {
+			result := result
+			tasks.run(task.Name, func() error {
+				return task.Run(&result)
+			})
+		}
+	}
+	if err := tasks.wait(); err != nil {
 		return err
 	}
 
-	Log.Infof("build completed in %s", time.Since(startTime))
+	if opts.AfterBuild != nil {
+		err = opts.AfterBuild(&result)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Info("build completed", "duration", time.Since(startTime))
+	if opts.Metrics != nil {
+		opts.Metrics.ObserveBuild(time.Since(startTime))
+	}
 	return nil
 }
 
@@ -353,3 +895,16 @@ func findAllDependencies(metafile *MetafileJSON, entry ImportPath) ([]ImportPath
 	}
 	return cleanResults, nil
 }
+
+// getCriticalCSSFromFile extracts the critical CSS (see extractCriticalCSS)
+// from a built stylesheet
+func getCriticalCSSFromFile(cssBundle string, selectors []string) (string, error) {
+	if len(selectors) == 0 {
+		return "", nil
+	}
+	cssBytes, err := os.ReadFile(cssBundle)
+	if err != nil {
+		return "", err
+	}
+	return extractCriticalCSS(string(cssBytes), selectors), nil
+}
@@ -0,0 +1,183 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Version of the build cache's format, part of its keys
+const buildCacheVersion = "2"
+
+const buildCacheEntryFileName = "hwy_build_cache.json"
+
+// buildCacheEntry is the last build cached in a cache dir, whose output files
+// are kept in its "files" directory
+type buildCacheEntry struct {
+	Key       string              `json:"key"`
+	Inputs    []string            `json:"inputs"` // of the build and its workers
+	Files     []string            `json:"files"`  // in the build's Outdir
+	Metafile  json.RawMessage     `json:"metafile"`
+	Workers   map[string]string   `json:"workers"`
+	Importers map[string][]string `json:"importers"`
+}
+
+// bundle runs esbuildOpts (with workers' plugin), returning its metafile.
+// With a cacheDir, its output and metafile are cached there, and restored
+// rather than bundled again while esbuildOpts and the contents of all its
+// inputs are unchanged. As esbuild splits chunks across all entry points, a
+// change to any input bundles them all again.
+func bundle(esbuildOpts api.BuildOptions, workers *workerBuilds, cacheDir string) (MetafileJSON, bool, error) {
+	esbuildOpts.Plugins = []api.Plugin{workers.plugin()}
+	metafile := MetafileJSON{}
+	if cacheDir != "" {
+		if entry, ok := restoreBuildCache(esbuildOpts, cacheDir); ok {
+			if err := json.Unmarshal(entry.Metafile, &metafile); err != nil {
+				return metafile, false, err
+			}
+			workers.outputs = entry.Workers
+			workers.importers = entry.Importers
+			return metafile, true, nil
+		}
+	}
+	result := api.Build(esbuildOpts)
+	if len(result.Errors) > 0 {
+		return metafile, false, newBuildError(result.Errors[0])
+	}
+	if err := json.Unmarshal([]byte(result.Metafile), &metafile); err != nil {
+		return metafile, false, err
+	}
+	if cacheDir != "" {
+		if err := saveBuildCache(esbuildOpts, cacheDir, result.Metafile, &metafile, workers); err != nil {
+			return metafile, false, err
+		}
+	}
+	return metafile, false, nil
+}
+
+// getBuildCacheKey hashes esbuildOpts, other than its plugins (the workers'
+// plugin, whose output is keyed by its inputs), so any option affecting the
+// output is part of the key, and the paths and contents of inputs
+func getBuildCacheKey(esbuildOpts api.BuildOptions, inputs []string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	esbuildOpts.Plugins = nil
+	// Worker importers are absolute paths, so the cwd is part of the key
+	optsJSON, err := json.Marshal([]any{buildCacheVersion, cwd, esbuildOpts})
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(optsJSON)
+	for _, input := range inputs {
+		// Leaves out virtual modules, e.g. "<runtime>"
+		if strings.ContainsAny(input, ":<") {
+			continue
+		}
+		contents, err := os.ReadFile(input)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "\x00%s\x00%d\x00", input, len(contents))
+		h.Write(contents)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// restoreBuildCache copies the output files of the build cached in cacheDir
+// to esbuildOpts.Outdir, if esbuildOpts and its inputs are unchanged
+func restoreBuildCache(esbuildOpts api.BuildOptions, cacheDir string) (*buildCacheEntry, bool) {
+	entryBytes, err := os.ReadFile(filepath.Join(cacheDir, buildCacheEntryFileName))
+	if err != nil {
+		return nil, false
+	}
+	entry := &buildCacheEntry{}
+	if err := json.Unmarshal(entryBytes, entry); err != nil {
+		return nil, false
+	}
+	// An input missing or unreadable since is a miss too
+	key, err := getBuildCacheKey(esbuildOpts, entry.Inputs)
+	if err != nil || key != entry.Key {
+		return nil, false
+	}
+	if err := os.MkdirAll(esbuildOpts.Outdir, os.ModePerm); err != nil {
+		return nil, false
+	}
+	for _, file := range entry.Files {
+		if err := copyFile(filepath.Join(cacheDir, "files", file), filepath.Join(esbuildOpts.Outdir, file)); err != nil {
+			return nil, false
+		}
+	}
+	return entry, true
+}
+
+// saveBuildCache caches a build's output files in esbuildOpts.Outdir, and
+// its metafile, in cacheDir, replacing the build cached there
+func saveBuildCache(esbuildOpts api.BuildOptions, cacheDir string, metafileJSON string, metafile *MetafileJSON, workers *workerBuilds) error {
+	var inputs []string
+	for input := range metafile.Inputs {
+		inputs = append(inputs, input)
+	}
+	inputs = append(inputs, workers.inputs...)
+	slices.Sort(inputs)
+	inputs = slices.Compact(inputs)
+	key, err := getBuildCacheKey(esbuildOpts, inputs)
+	if err != nil {
+		return err
+	}
+	files := slices.Clone(workers.files)
+	for key := range metafile.Outputs {
+		files = append(files, filepath.Base(key))
+	}
+	slices.Sort(files)
+	files = slices.Compact(files)
+
+	filesDir := filepath.Join(cacheDir, "files")
+	if err := os.RemoveAll(filesDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filesDir, os.ModePerm); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := copyFile(filepath.Join(esbuildOpts.Outdir, file), filepath.Join(filesDir, file)); err != nil {
+			return err
+		}
+	}
+	entryBytes, err := json.Marshal(buildCacheEntry{
+		Key:       key,
+		Inputs:    inputs,
+		Files:     files,
+		Metafile:  json.RawMessage(metafileJSON),
+		Workers:   workers.outputs,
+		Importers: workers.importers,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(cacheDir, buildCacheEntryFileName), entryBytes, os.ModePerm)
+}
+
+func (opts BuildOptions) getBuildCacheDir(name string) string {
+	if opts.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(opts.CacheDir, name)
+}
+
+func copyFile(src, dst string) error {
+	contents, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, contents, os.ModePerm)
+}
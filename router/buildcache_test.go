@@ -0,0 +1,156 @@
+package router
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestBuildCache(t *testing.T) {
+	// Like the fixtures, relative to the working directory, as esbuild
+	// reports entry points
+	dir := "../tmp/buildcache"
+	os.RemoveAll(dir) // a cache left from a previous run would hit
+	files := map[string]string{
+		"client.entry.ts":   `console.log("entry")`,
+		"pages/tiger.ui.ts": `import { roar } from "../roar"; export const worker = new Worker(new URL("../workers/count.ts", import.meta.url)); export default roar`,
+		"pages/lion.ui.ts":  `import { roar } from "../roar"; export default roar + "!"`,
+		"roar.ts":           `export const roar = "shared-roar"`,
+		"workers/count.ts":  `self.onmessage = (e: MessageEvent) => self.postMessage(e.data + 1)`,
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(dir, "out")
+	runBuild := func() (string, PathsFile) {
+		var buf bytes.Buffer
+		err := build(BuildOptions{
+			PagesSrcDir:    filepath.Join(dir, "pages"),
+			HashedOutDir:   out,
+			UnhashedOutDir: out,
+			ClientEntryOut: out,
+			ClientEntry:    filepath.Join(dir, "client.entry.ts"),
+			CacheDir:       filepath.Join(dir, "cache"),
+			Logger:         NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pathsFileBytes, err := os.ReadFile(filepath.Join(out, "hwy_paths.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pathsFile, err := DecodePathsFile(pathsFileBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf.String(), *pathsFile
+	}
+	getPath := func(pathsFile PathsFile, pattern string) JSONSafePath {
+		for _, path := range pathsFile.Paths {
+			if path.Pattern == pattern {
+				return path
+			}
+		}
+		t.Fatalf("expected a %s path", pattern)
+		return JSONSafePath{}
+	}
+	getTiger := func(pathsFile PathsFile) JSONSafePath {
+		return getPath(pathsFile, "/tiger")
+	}
+
+	log, first := runBuild()
+	if strings.Contains(log, "build cache hit") {
+		t.Fatal("expected a miss on the first build")
+	}
+	log, second := runBuild()
+	if !strings.Contains(log, "build cache hit") {
+		t.Fatalf("expected a hit with unchanged inputs, got %s", log)
+	}
+	tiger := getTiger(second)
+	if tiger.OutPath != getTiger(first).OutPath || len(*tiger.Deps) != len(*getTiger(first).Deps) {
+		t.Errorf("expected the restored build to match, got %+v", tiger)
+	}
+	for _, dep := range *tiger.Deps {
+		if _, err := os.Stat(filepath.Join(out, dep)); err != nil {
+			t.Errorf("expected dep %s to be restored: %v", dep, err)
+		}
+	}
+
+	// The worker's inputs count too
+	if err := os.WriteFile(filepath.Join(dir, "workers/count.ts"), []byte(`self.onmessage = () => self.postMessage(0)`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	log, third := runBuild()
+	if strings.Contains(log, "build cache hit") {
+		t.Fatal("expected a miss once a worker's input changed")
+	}
+	if slices.Equal(*getTiger(third).Deps, *tiger.Deps) {
+		t.Errorf("expected the rebuilt worker in the deps, got %v", *getTiger(third).Deps)
+	}
+
+	// Changing one page bundles every entry point again, so modules it
+	// shares with the others stay in a single chunk
+	if err := os.WriteFile(filepath.Join(dir, "pages/lion.ui.ts"), []byte(`import { roar } from "../roar"; export default roar + "!!"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	log, fourth := runBuild()
+	if strings.Contains(log, "build cache hit") {
+		t.Fatal("expected a miss once a page changed")
+	}
+	if getPath(fourth, "/lion").OutPath == getPath(third, "/lion").OutPath {
+		t.Errorf("expected the lion page to be bundled again, got %+v", getPath(fourth, "/lion"))
+	}
+	var roarOutputs []string
+	outEntries, err := os.ReadDir(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, outEntry := range outEntries {
+		contents, err := os.ReadFile(filepath.Join(out, outEntry.Name()))
+		if err == nil && strings.HasSuffix(outEntry.Name(), ".js") && strings.Contains(string(contents), "shared-roar") {
+			roarOutputs = append(roarOutputs, outEntry.Name())
+		}
+	}
+	if len(roarOutputs) != 1 {
+		t.Errorf("expected the shared module in exactly one output, got %v", roarOutputs)
+	}
+	for _, path := range fourth.Paths {
+		for _, dep := range *path.Deps {
+			if _, err := os.Stat(filepath.Join(out, dep)); err != nil {
+				t.Errorf("expected dep %s of %s to be in the out dir: %v", dep, path.Pattern, err)
+			}
+		}
+	}
+}
+
+func TestBuildCacheKeyOptions(t *testing.T) {
+	base := api.BuildOptions{Format: api.FormatESModule, Splitting: true, EntryNames: "hwy_entry__[hash]"}
+	baseKey, err := getBuildCacheKey(base, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, change := range map[string]func(*api.BuildOptions){
+		"EntryPoints": func(o *api.BuildOptions) { o.EntryPoints = []string{"a.ts"} },
+		"Format":      func(o *api.BuildOptions) { o.Format = api.FormatIIFE },
+		"Splitting":   func(o *api.BuildOptions) { o.Splitting = false },
+		"JSX":         func(o *api.BuildOptions) { o.JSX = api.JSXAutomatic },
+		"EntryNames":  func(o *api.BuildOptions) { o.EntryNames = "[name]" },
+		"External":    func(o *api.BuildOptions) { o.External = []string{"react"} },
+	} {
+		changed := base
+		change(&changed)
+		if key, _ := getBuildCacheKey(changed, nil); key == baseKey {
+			t.Errorf("expected %s to be part of the key", name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package router
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// BuildArtifact is a file emitted by a build, for BuildOptions.OnArtifact
+type BuildArtifact struct {
+	// The file name clients request, e.g. "hwy_chunk__[hash].js",
+	// ClientEntryFileName, or "legacy/hwy_entry__[hash].js"
+	Name string
+	// Where the file was written
+	Path     string
+	Contents []byte
+}
+
+// emitArtifacts calls onArtifact with the client entries, then with each file
+// in hashedOutDir (including the legacy build), in name order
+func emitArtifacts(onArtifact func(BuildArtifact) error, clientEntryOut string, clientEntryFileNames []string, hashedOutDir string) error {
+	artifactPaths := make([]string, 0, len(clientEntryFileNames))
+	for _, fileName := range clientEntryFileNames {
+		artifactPaths = append(artifactPaths, filepath.Join(clientEntryOut, fileName))
+	}
+	err := filepath.WalkDir(hashedOutDir, func(entryPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// The out dirs may be shared, in which case the client entries are
+		// already covered
+		if !d.IsDir() && !slices.Contains(artifactPaths[:len(clientEntryFileNames)], entryPath) {
+			artifactPaths = append(artifactPaths, entryPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i, artifactPath := range artifactPaths {
+		contents, err := os.ReadFile(artifactPath)
+		if err != nil {
+			return err
+		}
+		name := filepath.Base(artifactPath)
+		if i >= len(clientEntryFileNames) {
+			name, _ = filepath.Rel(hashedOutDir, artifactPath)
+			name = filepath.ToSlash(name)
+		}
+		err = onArtifact(BuildArtifact{Name: name, Path: artifactPath, Contents: contents})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
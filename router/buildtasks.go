@@ -0,0 +1,57 @@
+package router
+
+import (
+	"sync"
+	"time"
+)
+
+// BuildTask is extra work Build runs alongside its own (e.g. generating a
+// sitemap, or prerendering pages), concurrently with whatever it doesn't
+// depend on
+type BuildTask struct {
+	Name string // for the build log
+	// Runs the task once the build output and paths file are written (e.g.
+	// to prerender pages from them), rather than alongside bundling
+	AfterBundle bool
+	// Before bundling, only the build ID and out dirs of the BuildResult are
+	// set. An error fails the build.
+	Run func(*BuildResult) error
+}
+
+// buildTasks runs the independent tasks of a build stage concurrently,
+// logging each one's duration
+type buildTasks struct {
+	logger Logger
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	err    error
+}
+
+func (t *buildTasks) run(name string, task func() error) {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		startTime := time.Now()
+		err := task()
+		logBuildStage(t.logger, name, startTime)
+		if err != nil {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.err == nil {
+				t.err = err
+			}
+		}
+	}()
+}
+
+// wait waits for the tasks run so far, returning the first error
+func (t *buildTasks) wait() error {
+	t.wg.Wait()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+func logBuildStage(logger Logger, stage string, startTime time.Time) {
+	logger.Info("build stage done", "stage", stage, "duration", time.Since(startTime))
+}
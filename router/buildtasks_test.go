@@ -0,0 +1,65 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Set by the fixture build's tasks, before and after bundling
+var fixtureTaskResults [2]*BuildResult
+
+// Set if the paths file couldn't be found when the task after bundling ran
+var fixtureTaskPathsFileErr error
+
+func TestBuildTasks(t *testing.T) {
+	before, after := fixtureTaskResults[0], fixtureTaskResults[1]
+	if before == nil || before.BuildID != fixtureBuildResult.BuildID || before.HashedOutDir != "../tmp/out" {
+		t.Fatalf("expected the task before bundling to receive the build ID and out dirs, got %+v", before)
+	}
+	if after == nil || after.BuildID != fixtureBuildResult.BuildID {
+		t.Fatalf("expected the task after bundling to receive the build result, got %+v", after)
+	}
+	if fixtureTaskPathsFileErr != nil {
+		t.Errorf("expected the paths file to be written before tasks after bundling: %v", fixtureTaskPathsFileErr)
+	}
+}
+
+func TestBuildTasksRunConcurrently(t *testing.T) {
+	var buf bytes.Buffer
+	tasks := &buildTasks{logger: NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))}
+	errFirst := errors.New("first")
+	// Every task must reach the barrier before any proceeds, so tasks run
+	// one at a time would never finish
+	var barrier sync.WaitGroup
+	barrier.Add(3)
+	for i, name := range []string{"a", "b", "c"} {
+		tasks.run(name, func() error {
+			barrier.Done()
+			barrier.Wait()
+			if i == 1 {
+				return errFirst
+			}
+			return nil
+		})
+	}
+	waited := make(chan error)
+	go func() { waited <- tasks.wait() }()
+	select {
+	case err := <-waited:
+		if !errors.Is(err, errFirst) {
+			t.Errorf("expected the task's error, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the tasks to run concurrently")
+	}
+	for _, name := range []string{"stage=a", "stage=b", "stage=c"} {
+		if !strings.Contains(buf.String(), name) {
+			t.Errorf("expected the duration of %s to be logged, got %s", name, buf.String())
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package router
+
+import (
+	"strings"
+)
+
+// ResolvePattern fills a route pattern with params and splat segments, e.g.
+// "/tiger/$tiger_id/_index" with tiger_id=123 becomes "/tiger/123"
+func ResolvePattern(pattern string, params map[string]string, splatSegments []string) string {
+	pattern = strings.TrimSuffix(pattern, "/_index")
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+	resolved := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		switch {
+		case segment == "":
+			continue
+		case segment == "$":
+			resolved = append(resolved, splatSegments...)
+		case getIsCompoundSegment(segment):
+			resolved = append(resolved, parseCompoundSegment(segment).resolve(segment, params))
+		case strings.HasPrefix(segment, "$"):
+			resolved = append(resolved, params[segment[1:]])
+		default:
+			resolved = append(resolved, segment)
+		}
+	}
+	return "/" + strings.Join(resolved, "/")
+}
+
+func (a *ActivePathData) getCanonicalPath() string {
+	if a.MatchingPaths == nil || len(*a.MatchingPaths) == 0 {
+		return "/"
+	}
+	lastPath := (*a.MatchingPaths)[len(*a.MatchingPaths)-1]
+	var params map[string]string
+	if a.Params != nil {
+		params = *a.Params
+	}
+	var splatSegments []string
+	if a.SplatSegments != nil {
+		splatSegments = *a.SplatSegments
+	}
+	return ResolvePattern(lastPath.Pattern, params, splatSegments)
+}
+
+// NewCanonicalHeadBlock returns a <link rel="canonical"> head block
+func NewCanonicalHeadBlock(href string) HeadBlock {
+	return HeadBlock{
+		Tag: "link",
+		Attributes: map[string]string{
+			"rel":  "canonical",
+			"href": href,
+		},
+	}
+}
+
+// CanonicalURL returns the absolute (if Hwy.SiteOrigin is set) URL of the
+// current page, built from the deepest matched route's pattern, in the
+// current locale
+func (p *HeadProps) CanonicalURL() string {
+	if p.scope == nil {
+		return getLocalizedURL(nil, "", "", p.canonicalPath)
+	}
+	return getLocalizedURL(p.scope.i18n, p.scope.siteOrigin, p.Locale, p.canonicalPath)
+}
+
+// CanonicalHeadBlock returns a canonical link to CanonicalURL
+func (p *HeadProps) CanonicalHeadBlock() HeadBlock {
+	return NewCanonicalHeadBlock(p.CanonicalURL())
+}
+
+// HreflangHeadBlocks returns alternate links to the current page in every
+// locale configured in Hwy.I18n (nil when not configured). These are the
+// same links Hwy adds automatically, so returning them is harmless.
+func (p *HeadProps) HreflangHeadBlocks() []HeadBlock {
+	if p.scope == nil {
+		return nil
+	}
+	return getHreflangHeadBlocks(p.scope.i18n, p.scope.siteOrigin, p.canonicalPath)
+}
@@ -0,0 +1,39 @@
+package router
+
+import (
+	"testing"
+)
+
+func TestResolvePattern(t *testing.T) {
+	cases := []struct {
+		pattern       string
+		params        map[string]string
+		splatSegments []string
+		expected      string
+	}{
+		{"/_index", nil, nil, "/"},
+		{"/tiger/$tiger_id/_index", map[string]string{"tiger_id": "123"}, nil, "/tiger/123"},
+		{"/bear/$bear_id/$", map[string]string{"bear_id": "1"}, []string{"2", "3"}, "/bear/1/2/3"},
+		{"/$", nil, []string{"a", "b"}, "/a/b"},
+	}
+	for _, c := range cases {
+		if resolved := ResolvePattern(c.pattern, c.params, c.splatSegments); resolved != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.pattern, c.expected, resolved)
+		}
+	}
+}
+
+func TestCanonicalURL(t *testing.T) {
+	scope := &requestScope{
+		locale:     "de",
+		i18n:       &I18nConfig{Locales: []string{"en", "de"}, DefaultLocale: "en"},
+		siteOrigin: "https://example.com",
+	}
+	props := HeadProps{
+		DataProps:     scope.newDataProps(nil, nil, nil),
+		canonicalPath: "/tiger/123",
+	}
+	if href := props.CanonicalHeadBlock().Attributes["href"]; href != "https://example.com/de/tiger/123" {
+		t.Errorf("expected https://example.com/de/tiger/123, got %s", href)
+	}
+}
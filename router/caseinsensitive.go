@@ -0,0 +1,69 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// getCanonicalCaseURL returns the URL to redirect r to when only its
+// lowercased path matches a route (per Hwy.CaseInsensitive), or "". Static
+// segments take the pattern's case, while param and splat values keep their
+// original case. Rewritten requests are left alone, as their original URL
+// can't be canonicalized by pattern.
+func getCanonicalCaseURL(r *http.Request, scope *requestScope) string {
+	realPath := getRealPath(r)
+	lowerPath := strings.ToLower(realPath)
+	if lowerPath == realPath {
+		return ""
+	}
+	if scope != nil && scope.localeFreePath != r.URL.Path {
+		return ""
+	}
+	lowerURL := *r.URL
+	lowerURL.Path = strings.ToLower(r.URL.Path)
+	lowerURL.RawPath = strings.ToLower(r.URL.RawPath)
+	lowerR := r.WithContext(r.Context())
+	lowerR.URL = &lowerURL
+	item, _ := lookupMatchingPathItem(lowerR, scope)
+	if getIsUnmatched(item) {
+		return ""
+	}
+	matchingPaths := *item.MatchingPaths
+	canonicalPath := getCanonicalCasePath(realPath, matchingPaths[len(matchingPaths)-1])
+	if scope != nil && scope.i18n != nil {
+		canonicalPath = strings.TrimSuffix(scope.i18n.getLocalePrefix(scope.locale)+canonicalPath, "/")
+	}
+	if r.URL.RawQuery != "" {
+		canonicalPath += "?" + r.URL.RawQuery
+	}
+	return canonicalPath
+}
+
+// getCanonicalCasePath gives realPath's static segments the case of path's
+// pattern, wherever they are in it. A mid-path splat shifts the path segments
+// of the pattern segments following it by the number of segments it covered.
+func getCanonicalCasePath(realPath string, path *MatchingPath) string {
+	segments := strings.Split(strings.Trim(realPath, "/"), "/")
+	offset := 0
+	for i, patternSegment := range *path.Segments {
+		if patternSegment == "$" {
+			if path.midSplat == nil {
+				break
+			}
+			offset = path.midSplat.end - path.midSplat.start - 1
+			continue
+		}
+		if i+offset >= len(segments) {
+			break
+		}
+		if patternSegment == "" || strings.Contains(patternSegment, "$") {
+			continue
+		}
+		segments[i+offset] = patternSegment
+	}
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(decodeSegment(segment))
+	}
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCaseInsensitiveRedirect(t *testing.T) {
+	h := Hwy{CaseInsensitive: true}
+	for _, c := range []struct {
+		url      string
+		expected string
+	}{
+		{"/Dashboard/Customers/ABC?tab=Orders", "/dashboard/customers/ABC?tab=Orders"},
+		{"/LION/", "/lion"},
+		{"/Bear/Baloo/Honey/Pot", "/bear/Baloo/Honey/Pot"},
+		{"/lion/Pride-Rock", ""},
+		{"/Nowhere/At/All", ""},
+	} {
+		routeData, err := h.GetRouteData(nil, httptest.NewRequest("GET", c.url, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		outcome := routeData.GuardOutcome
+		if c.expected == "" {
+			if outcome != nil {
+				t.Errorf("%s: expected no redirect, got %+v", c.url, outcome)
+			}
+			continue
+		}
+		if outcome == nil || outcome.Status != http.StatusPermanentRedirect || outcome.RedirectTo != c.expected {
+			t.Errorf("%s: expected a 308 to %s, got %+v", c.url, c.expected, outcome)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/LION", nil))
+	if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != "/lion" {
+		t.Errorf("expected a 308 to /lion, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	routeData, err := Hwy{}.GetRouteData(nil, httptest.NewRequest("GET", "/Dashboard/Customers", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routeData.GuardOutcome != nil {
+		t.Error("expected no redirect unless enabled")
+	}
+}
+
+func TestCaseInsensitiveRedirectMidPathSplat(t *testing.T) {
+	withRouteTable(t, []string{
+		"_index.ui.tsx",
+		"files.ui.tsx",
+		"files/$/preview.ui.tsx",
+		"files/$/preview/$size.ui.tsx",
+	})
+	h := Hwy{CaseInsensitive: true}
+	for _, c := range []struct {
+		url      string
+		expected string
+	}{
+		{"/Files/Docs/2024/Preview", "/files/Docs/2024/preview"},
+		{"/Files/Docs/2024/Preview/Large", "/files/Docs/2024/preview/Large"},
+	} {
+		routeData, err := h.GetRouteData(nil, httptest.NewRequest("GET", c.url, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if outcome := routeData.GuardOutcome; outcome == nil || outcome.RedirectTo != c.expected {
+			t.Errorf("%s: expected a redirect to %s, got %+v", c.url, c.expected, outcome)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+const (
+	defaultCircuitBreakerThreshold = 5
+	defaultCircuitBreakerCooldown  = 30 * time.Second
+)
+
+// CircuitBreaker stops calling a route's loader (see DataFuncs.CircuitBreaker)
+// once it fails Threshold times in a row, serving Fallback's data instead,
+// so one failing upstream degrades a widget rather than rendering the error
+// boundary on every request. Once Cooldown passes, a single request tries
+// the loader again: its success closes the breaker, and its failure reopens
+// it for another Cooldown. Open times are per Hwy.Clock.
+type CircuitBreaker struct {
+	// Consecutive failures opening the breaker. Defaults to 5.
+	Threshold int
+	// How long the breaker stays open. Defaults to 30 seconds.
+	Cooldown time.Duration
+	// Returns the loader data while the breaker is open (including for the
+	// failure opening it), given the loader's last error, or one wrapping
+	// ErrCircuitOpen. Nil fails with that error, without calling the loader.
+	Fallback func(props *LoaderProps, err error) (any, error)
+	// Reports whether a loader error counts as a failure. By default, all
+	// do, except NotFound, Gone, PermanentRedirect, and context.Canceled
+	// (e.g. the client going away).
+	IsFailure func(error) bool
+
+	mu             sync.Mutex
+	failures       int
+	openedAt       time.Time // zero when closed
+	isTrialRunning bool
+}
+
+// IsOpen reports whether the loader is being skipped, e.g. for health checks
+func (b *CircuitBreaker) IsOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openedAt.IsZero()
+}
+
+// call runs loader unless the breaker is open, logging state changes. It
+// also reports whether the result is Fallback's.
+func (b *CircuitBreaker) call(h Hwy, pattern string, props *LoaderProps, loader Loader) (any, bool, error) {
+	isAllowed, isTrial := b.allow(instanceClock.Now())
+	if !isAllowed {
+		return b.fallback(props, fmt.Errorf("%w: %s", ErrCircuitOpen, pattern))
+	}
+
+	isDone := false
+	defer func() {
+		if !isDone { // panicked, which counts as a failure
+			b.record(errors.New("panic"), isTrial)
+		}
+	}()
+	data, err := loader(props)
+	isDone = true
+
+	wasOpen, isOpen := b.record(err, isTrial)
+	switch {
+	case isOpen && !wasOpen:
+		h.getLogger().Warn("circuit breaker opened", "pattern", pattern, "error", err)
+	case wasOpen && !isOpen:
+		h.getLogger().Info("circuit breaker closed", "pattern", pattern)
+	}
+	if isOpen {
+		return b.fallback(props, err)
+	}
+	return data, false, err
+}
+
+// allow reports whether the loader may be called: always while closed, and
+// once open, for a single trial after Cooldown
+func (b *CircuitBreaker) allow(now time.Time) (bool, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return true, false
+	}
+	if b.isTrialRunning || now.Sub(b.openedAt) < b.getCooldown() {
+		return false, false
+	}
+	b.isTrialRunning = true
+	return true, true
+}
+
+// record counts err, returning whether the breaker was open, and is now
+func (b *CircuitBreaker) record(err error, isTrial bool) (bool, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasOpen := !b.openedAt.IsZero()
+	if isTrial {
+		b.isTrialRunning = false
+	}
+	if err == nil || !b.getIsFailure(err) {
+		if err == nil || isTrial {
+			b.failures = 0
+			b.openedAt = time.Time{}
+		}
+		return wasOpen, !b.openedAt.IsZero()
+	}
+	b.failures++
+	if isTrial || b.failures >= b.getThreshold() {
+		b.openedAt = instanceClock.Now()
+	}
+	return wasOpen, !b.openedAt.IsZero()
+}
+
+func (b *CircuitBreaker) fallback(props *LoaderProps, err error) (any, bool, error) {
+	if b.Fallback == nil {
+		return nil, false, err
+	}
+	data, err := b.Fallback(props, err)
+	return data, true, err
+}
+
+func (b *CircuitBreaker) getThreshold() int {
+	if b.Threshold > 0 {
+		return b.Threshold
+	}
+	return defaultCircuitBreakerThreshold
+}
+
+func (b *CircuitBreaker) getCooldown() time.Duration {
+	if b.Cooldown > 0 {
+		return b.Cooldown
+	}
+	return defaultCircuitBreakerCooldown
+}
+
+func (b *CircuitBreaker) getIsFailure(err error) bool {
+	if b.IsFailure != nil {
+		return b.IsFailure(err)
+	}
+	return getIsLoaderFailure(err)
+}
+
+// getIsLoaderFailure reports whether err suggests a failing loader (or
+// upstream), rather than a NotFound, Gone, or PermanentRedirect, or the
+// client going away
+func getIsLoaderFailure(err error) bool {
+	return err != nil && !getIsContentLifecycleError(err) && !errors.Is(err, context.Canceled)
+}
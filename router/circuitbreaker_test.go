@@ -0,0 +1,84 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	prevClock := instanceClock
+	instanceClock = clock
+	t.Cleanup(func() { instanceClock = prevClock })
+
+	errUpstream := errors.New("upstream down")
+	var calls int
+	var loaderErr error
+	breaker := &CircuitBreaker{
+		Threshold: 2,
+		Cooldown:  time.Minute,
+		Fallback: func(props *LoaderProps, err error) (any, error) {
+			return "fallback: " + err.Error(), nil
+		},
+	}
+	setTestDataFuncs(t, "/lion", &DataFuncs{
+		CircuitBreaker: breaker,
+		Loader: func(*LoaderProps) (any, error) {
+			calls++
+			return "roar", loaderErr
+		},
+	})
+
+	var requests int
+	get := func() any {
+		requests++
+		routeData, err := Hwy{}.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", fmt.Sprintf("/lion/breaker-%d", requests), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(*routeData.LoadersData) == 0 {
+			return nil
+		}
+		return (*routeData.LoadersData)[0]
+	}
+
+	loaderErr = errUpstream
+	if data := get(); data != nil || breaker.IsOpen() {
+		t.Fatalf("expected failures below the threshold to error, got %v", data)
+	}
+	if data := get(); data != "fallback: upstream down" || !breaker.IsOpen() {
+		t.Fatalf("expected the failure reaching the threshold to open the breaker, got %v", data)
+	}
+	if data := get(); data != "fallback: circuit breaker open: /lion" || calls != 2 {
+		t.Fatalf("expected the open breaker to skip the loader, got %v after %d calls", data, calls)
+	}
+
+	// A failed trial reopens the breaker for another cooldown
+	clock.Advance(time.Minute)
+	if data := get(); data != "fallback: upstream down" || calls != 3 || !breaker.IsOpen() {
+		t.Fatalf("expected a failed trial to reopen the breaker, got %v after %d calls", data, calls)
+	}
+	clock.Advance(30 * time.Second)
+	if get(); calls != 3 {
+		t.Fatal("expected the reopened breaker to skip the loader")
+	}
+
+	// A successful trial closes it
+	loaderErr = nil
+	clock.Advance(30 * time.Second)
+	if data := get(); data != "roar" || breaker.IsOpen() {
+		t.Fatalf("expected a successful trial to close the breaker, got %v", data)
+	}
+
+	// NotFound doesn't count as a failure
+	loaderErr = NotFound()
+	for range 3 {
+		get()
+	}
+	if breaker.IsOpen() {
+		t.Error("expected NotFound not to open the breaker")
+	}
+}
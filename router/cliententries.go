@@ -0,0 +1,112 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SecondaryClientEntry is a client entry loaded, in place of
+// BuildOptions.ClientEntry, by the routes under Subtree (e.g. a lightweight
+// entry for marketing pages), or by routes naming it in DataFuncs.ClientEntry
+type SecondaryClientEntry struct {
+	// Written to BuildOptions.ClientEntryOut as "hwy_client_entry_{Name}.js"
+	Name string
+	// Takes the same form as BuildOptions.ClientEntry
+	SrcPath string
+	// Pattern (e.g. "/marketing") whose route and descendants load this entry.
+	// The longest matching subtree wins. Empty for none.
+	Subtree string
+}
+
+// ClientEntryOutput is the build output of a secondary client entry, by name
+// in PathsFile.ClientEntries
+type ClientEntryOutput struct {
+	Deps        []ImportPath `json:"deps"`
+	CSSBundle   string       `json:"cssBundle,omitempty"`
+	CriticalCSS string       `json:"criticalCSS,omitempty"`
+}
+
+var ErrUnknownClientEntry = errors.New("unknown client entry")
+
+var instanceClientEntries map[string]ClientEntryOutput
+
+// GetClientEntryFileName returns the file name of the named secondary client
+// entry, or ClientEntryFileName for ""
+func GetClientEntryFileName(name string) string {
+	if name == "" {
+		return ClientEntryFileName
+	}
+	return "hwy_client_entry_" + name + ".js"
+}
+
+func getIsClientEntryFileName(fileName string) bool {
+	return fileName == ClientEntryFileName || strings.HasPrefix(fileName, "hwy_client_entry_")
+}
+
+// getClientEntryName returns the secondary client entry of the deepest
+// matched route, or "" for the main one
+func getClientEntryName(matchingPaths *[]*MatchingPath) string {
+	if matchingPaths == nil || len(*matchingPaths) == 0 {
+		return ""
+	}
+	return getKnownClientEntry((*matchingPaths)[len(*matchingPaths)-1].ClientEntry)
+}
+
+func (a *ActivePathData) getClientEntryName() string {
+	if a.MatchingPaths == nil || len(*a.MatchingPaths) == 0 {
+		return ""
+	}
+	return getKnownClientEntry((*a.MatchingPaths)[len(*a.MatchingPaths)-1].ClientEntry)
+}
+
+// getKnownClientEntry falls back to the main client entry for names missing
+// from the paths file
+func getKnownClientEntry(name string) string {
+	if _, ok := instanceClientEntries[name]; !ok {
+		return ""
+	}
+	return name
+}
+
+func getClientEntryOutput(name string) ClientEntryOutput {
+	if clientEntry, ok := instanceClientEntries[name]; ok && name != "" {
+		return clientEntry
+	}
+	output := ClientEntryOutput{CSSBundle: instanceClientEntryCSSBundle, CriticalCSS: instanceClientEntryCriticalCSS}
+	if instanceClientEntryDeps != nil {
+		output.Deps = *instanceClientEntryDeps
+	}
+	return output
+}
+
+// setPathClientEntries sets the secondary client entry of each path, by
+// DataFuncs.ClientEntry or else by the longest subtree containing it
+func setPathClientEntries(paths []JSONSafePath, clientEntries []SecondaryClientEntry, dataFuncsMap DataFuncsMap) error {
+	for i, path := range paths {
+		longestSubtree := -1
+		for _, clientEntry := range clientEntries {
+			if clientEntry.Subtree == "" || len(clientEntry.Subtree) <= longestSubtree {
+				continue
+			}
+			subtree := strings.TrimSuffix(clientEntry.Subtree, "/")
+			if path.Pattern == subtree || strings.HasPrefix(path.Pattern, subtree+"/") {
+				paths[i].ClientEntry = clientEntry.Name
+				longestSubtree = len(clientEntry.Subtree)
+			}
+		}
+		dataFuncs, ok := dataFuncsMap[path.Pattern]
+		if !ok || dataFuncs.ClientEntry == "" {
+			continue
+		}
+		isKnown := slices.ContainsFunc(clientEntries, func(clientEntry SecondaryClientEntry) bool {
+			return clientEntry.Name == dataFuncs.ClientEntry
+		})
+		if !isKnown {
+			return fmt.Errorf("%w %q for %s", ErrUnknownClientEntry, dataFuncs.ClientEntry, path.Pattern)
+		}
+		paths[i].ClientEntry = dataFuncs.ClientEntry
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSetPathClientEntries(t *testing.T) {
+	clientEntries := []SecondaryClientEntry{
+		{Name: "marketing", SrcPath: "marketing.entry.tsx", Subtree: "/marketing"},
+		{Name: "pricing", SrcPath: "pricing.entry.tsx", Subtree: "/marketing/pricing"},
+		{Name: "lite", SrcPath: "lite.entry.tsx"},
+	}
+	paths := GetPathsFromPageFiles("marketing.ui.tsx", "marketing/about.ui.tsx", "marketing/pricing/_index.ui.tsx", "marketingish.ui.tsx", "tiger.ui.tsx")
+	dataFuncsMap := DataFuncsMap{"/tiger": {ClientEntry: "lite"}}
+	if err := setPathClientEntries(paths, clientEntries, dataFuncsMap); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string, len(paths))
+	for _, path := range paths {
+		got[path.Pattern] = path.ClientEntry
+	}
+	expected := map[string]string{
+		"/marketing":                "marketing",
+		"/marketing/about":          "marketing",
+		"/marketing/pricing/_index": "pricing",
+		"/marketingish":             "",
+		"/tiger":                    "lite",
+	}
+	for pattern, clientEntry := range expected {
+		if got[pattern] != clientEntry {
+			t.Errorf("%s: expected client entry %q, got %q", pattern, clientEntry, got[pattern])
+		}
+	}
+
+	err := setPathClientEntries(paths, clientEntries, DataFuncsMap{"/tiger": {ClientEntry: "heavy"}})
+	if !errors.Is(err, ErrUnknownClientEntry) {
+		t.Errorf("expected ErrUnknownClientEntry, got %v", err)
+	}
+}
+
+func TestSecondaryClientEntry(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+
+	paths := GetPathsFromPageFiles("marketing/about.ui.tsx", "app.ui.tsx")
+	paths[0].ClientEntry = "marketing"
+	pathsFileBytes, _ := json.Marshal(PathsFile{
+		Paths:                paths,
+		ClientEntryDeps:      []string{"app-shell.js"},
+		ClientEntryCSSBundle: "app-shell.css",
+		ClientEntries: map[string]ClientEntryOutput{
+			"marketing": {Deps: []string{"marketing-lite.js"}, CSSBundle: "marketing.css"},
+		},
+	})
+	h := Hwy{FS: fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}}}
+	if err := h.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/marketing/about?secondary-entry", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routeData.ClientEntry != "marketing" || !slices.Equal(*routeData.Deps, []string{"marketing-lite.js"}) || !slices.Equal(routeData.CSSBundles, []string{"marketing.css"}) {
+		t.Errorf("expected the marketing entry's deps and stylesheet, got %q %v %v", routeData.ClientEntry, *routeData.Deps, routeData.CSSBundles)
+	}
+	if fileName := GetClientEntryFileName(routeData.ClientEntry); fileName != "hwy_client_entry_marketing.js" {
+		t.Errorf("unexpected file name %s", fileName)
+	}
+
+	routeData, err = h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/app?secondary-entry", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routeData.ClientEntry != "" || !slices.Equal(*routeData.Deps, []string{"app-shell.js"}) || !slices.Equal(routeData.CSSBundles, []string{"app-shell.css"}) {
+		t.Errorf("expected the main entry's deps and stylesheet, got %q %v %v", routeData.ClientEntry, *routeData.Deps, routeData.CSSBundles)
+	}
+}
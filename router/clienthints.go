@@ -0,0 +1,142 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	DeviceClassMobile  = "mobile"
+	DeviceClassTablet  = "tablet"
+	DeviceClassDesktop = "desktop"
+)
+
+// DefaultClientHints are requested (via Accept-CH) and varied on when
+// ClientHintsConfig.Hints is empty
+var DefaultClientHints = []string{
+	"Sec-CH-UA",
+	"Sec-CH-UA-Mobile",
+	"Sec-CH-UA-Platform",
+	"Sec-CH-Viewport-Width",
+	"Sec-CH-DPR",
+	"Sec-CH-Device-Memory",
+	"Save-Data",
+}
+
+// ClientHints are the request's parsed client hints, in DataProps. Browsers
+// only send most hints once asked to via Accept-CH, so the first request may
+// have few of them. Zero values mean the hint wasn't sent.
+type ClientHints struct {
+	Mobile        bool
+	Platform      string   // e.g. "Android"
+	Brands        []string // e.g. "Chromium", without versions
+	ViewportWidth int      // in CSS pixels
+	DPR           float64
+	DeviceMemory  float64 // in GiB
+	SaveData      bool
+	// Per ClientHintsConfig.Classify, e.g. DeviceClassMobile
+	DeviceClass string
+}
+
+type ClientHintsConfig struct {
+	// Defaults to ClassifyDevice
+	Classify func(*ClientHints) string
+	// Requested via Accept-CH and set as Vary on every response. Defaults to
+	// DefaultClientHints.
+	Hints []string
+	// Falls back to the User-Agent header to detect mobile devices when
+	// Sec-CH-UA-Mobile isn't sent (e.g. by Safari and Firefox), adding
+	// User-Agent to Vary
+	UserAgentFallback bool
+}
+
+// ClassifyDevice returns DeviceClassMobile for mobile devices or viewports
+// narrower than 768px, DeviceClassTablet for those narrower than 1024px, and
+// DeviceClassDesktop otherwise
+func ClassifyDevice(hints *ClientHints) string {
+	switch {
+	case hints.Mobile:
+		return DeviceClassMobile
+	case hints.ViewportWidth == 0:
+		return DeviceClassDesktop
+	case hints.ViewportWidth < 768:
+		return DeviceClassMobile
+	case hints.ViewportWidth < 1024:
+		return DeviceClassTablet
+	default:
+		return DeviceClassDesktop
+	}
+}
+
+// setClientHints parses r's client hints into scope and, when w isn't nil,
+// sets Accept-CH and Vary
+func (h Hwy) setClientHints(w http.ResponseWriter, r *http.Request, scope *requestScope) {
+	c := h.ClientHints
+	if c == nil {
+		return
+	}
+	hintNames := c.Hints
+	if len(hintNames) == 0 {
+		hintNames = DefaultClientHints
+	}
+	if w != nil {
+		w.Header().Set("Accept-CH", strings.Join(hintNames, ", "))
+		for _, name := range hintNames {
+			w.Header().Add("Vary", name)
+		}
+		if c.UserAgentFallback {
+			w.Header().Add("Vary", "User-Agent")
+		}
+	}
+
+	hints := parseClientHints(r.Header)
+	if c.UserAgentFallback && r.Header.Get("Sec-CH-UA-Mobile") == "" {
+		hints.Mobile = strings.Contains(r.Header.Get("User-Agent"), "Mobi")
+	}
+	classify := c.Classify
+	if classify == nil {
+		classify = ClassifyDevice
+	}
+	hints.DeviceClass = classify(hints)
+	scope.clientHints = hints
+}
+
+func parseClientHints(header http.Header) *ClientHints {
+	hints := &ClientHints{
+		Mobile:        header.Get("Sec-CH-UA-Mobile") == "?1",
+		Platform:      strings.Trim(header.Get("Sec-CH-UA-Platform"), `"`),
+		ViewportWidth: parseClientHintInt(getFirstHeader(header, "Sec-CH-Viewport-Width", "Viewport-Width")),
+		DPR:           parseClientHintFloat(getFirstHeader(header, "Sec-CH-DPR", "DPR")),
+		DeviceMemory:  parseClientHintFloat(getFirstHeader(header, "Sec-CH-Device-Memory", "Device-Memory")),
+		SaveData:      strings.EqualFold(header.Get("Save-Data"), "on"),
+	}
+	// e.g. "Chromium";v="124", "Not-A.Brand";v="99"
+	for _, entry := range strings.Split(header.Get("Sec-CH-UA"), ",") {
+		brand, _, _ := strings.Cut(entry, ";")
+		if brand = strings.Trim(strings.TrimSpace(brand), `"`); brand != "" {
+			hints.Brands = append(hints.Brands, brand)
+		}
+	}
+	return hints
+}
+
+// getFirstHeader returns the value of the first of names that is set
+func getFirstHeader(header http.Header, names ...string) string {
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func parseClientHintInt(value string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(value))
+	return max(n, 0)
+}
+
+func parseClientHintFloat(value string) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return max(f, 0)
+}
@@ -0,0 +1,100 @@
+package router
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseClientHints(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Sec-CH-UA", `"Chromium";v="124", "Google Chrome";v="124", "Not-A.Brand";v="99"`)
+	r.Header.Set("Sec-CH-UA-Mobile", "?1")
+	r.Header.Set("Sec-CH-UA-Platform", `"Android"`)
+	r.Header.Set("Viewport-Width", "412")
+	r.Header.Set("Sec-CH-DPR", "2.625")
+	r.Header.Set("Device-Memory", "4")
+	r.Header.Set("Save-Data", "on")
+
+	expected := &ClientHints{
+		Mobile:        true,
+		Platform:      "Android",
+		Brands:        []string{"Chromium", "Google Chrome", "Not-A.Brand"},
+		ViewportWidth: 412,
+		DPR:           2.625,
+		DeviceMemory:  4,
+		SaveData:      true,
+	}
+	if hints := parseClientHints(r.Header); !reflect.DeepEqual(hints, expected) {
+		t.Errorf("expected %+v, got %+v", expected, hints)
+	}
+}
+
+func TestClassifyDevice(t *testing.T) {
+	for _, c := range []struct {
+		hints    ClientHints
+		expected string
+	}{
+		{ClientHints{Mobile: true, ViewportWidth: 1200}, DeviceClassMobile},
+		{ClientHints{ViewportWidth: 500}, DeviceClassMobile},
+		{ClientHints{ViewportWidth: 800}, DeviceClassTablet},
+		{ClientHints{ViewportWidth: 1440}, DeviceClassDesktop},
+		{ClientHints{}, DeviceClassDesktop},
+	} {
+		if got := ClassifyDevice(&c.hints); got != c.expected {
+			t.Errorf("%+v: expected %s, got %s", c.hints, c.expected, got)
+		}
+	}
+}
+
+func TestClientHintsInDataProps(t *testing.T) {
+	var loaderHints, headHints *ClientHints
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			loaderHints = props.ClientHints
+			return nil, nil
+		},
+		Head: func(props *HeadProps) (*[]HeadBlock, error) {
+			headHints = props.ClientHints
+			return &[]HeadBlock{}, nil
+		},
+	})
+	h := Hwy{ClientHints: &ClientHintsConfig{
+		Hints:             []string{"Sec-CH-UA-Mobile", "Sec-CH-Viewport-Width"},
+		UserAgentFallback: true,
+	}}
+
+	r := httptest.NewRequest("GET", "/lion/client-hints", nil)
+	r.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148 Safari/604.1")
+	w := httptest.NewRecorder()
+	if _, err := h.GetRouteData(w, r); err != nil {
+		t.Fatal(err)
+	}
+	if loaderHints == nil || !loaderHints.Mobile || loaderHints.DeviceClass != DeviceClassMobile {
+		t.Errorf("expected a mobile device from the user agent, got %+v", loaderHints)
+	}
+	if headHints != loaderHints {
+		t.Error("expected heads to get the same client hints")
+	}
+	if got := w.Header().Get("Accept-CH"); got != "Sec-CH-UA-Mobile, Sec-CH-Viewport-Width" {
+		t.Errorf("unexpected Accept-CH %q", got)
+	}
+	if got := w.Header().Values("Vary"); !reflect.DeepEqual(got, []string{"Sec-CH-UA-Mobile", "Sec-CH-Viewport-Width", "User-Agent"}) {
+		t.Errorf("unexpected Vary %v", got)
+	}
+
+	h.ClientHints.Classify = func(hints *ClientHints) string {
+		if hints.SaveData {
+			return "lite"
+		}
+		return ClassifyDevice(hints)
+	}
+	r = httptest.NewRequest("GET", "/lion/client-hints", nil)
+	r.Header.Set("Save-Data", "on")
+	if _, err := h.GetRouteData(httptest.NewRecorder(), r); err != nil {
+		t.Fatal(err)
+	}
+	if loaderHints.DeviceClass != "lite" {
+		t.Errorf("expected the custom classifier's class, got %q", loaderHints.DeviceClass)
+	}
+}
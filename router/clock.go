@@ -0,0 +1,137 @@
+package router
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock tells Build and Hwy the time, for build IDs and cache expiry. Tests
+// can use a FakeClock for reproducible paths files, SSR output, and TTLs.
+// Durations (e.g. in logs and metrics) are always measured by the system
+// clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// TimerClock is optionally implemented by a Clock to also time scheduled
+// jobs (see Hwy.Schedule). Other clocks' jobs are timed by the system clock.
+type TimerClock interface {
+	Clock
+	// After is like time.After, plus a func that stops the timer
+	After(d time.Duration) (<-chan time.Time, func())
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) After(d time.Duration) (<-chan time.Time, func()) {
+	timer := time.NewTimer(d)
+	return timer.C, func() { timer.Stop() }
+}
+
+func getAfter(clock Clock, d time.Duration) (<-chan time.Time, func()) {
+	if timerClock, ok := clock.(TimerClock); ok {
+		return timerClock.After(d)
+	}
+	return systemClock{}.After(d)
+}
+
+func getClock(clock Clock) Clock {
+	if clock == nil {
+		return systemClock{}
+	}
+	return clock
+}
+
+// Set in Initialize
+var instanceClock Clock = systemClock{}
+
+// FakeClock is a TimerClock that only moves when told to, firing timers
+// that have come due. It's safe for concurrent use.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+	added  *sync.Cond
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+	c.fireDueTimers()
+}
+
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.fireDueTimers()
+}
+
+func (c *FakeClock) After(d time.Duration) (<-chan time.Time, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{at: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, timer)
+	c.fireDueTimers()
+	c.getAdded().Broadcast()
+	return timer.c, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.timers = slices.DeleteFunc(c.timers, func(t *fakeTimer) bool { return t == timer })
+	}
+}
+
+// WaitForTimers blocks until at least n timers are waiting to fire, e.g. so
+// a test advances the clock only once a job has scheduled its next run
+func (c *FakeClock) WaitForTimers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.getAdded().Wait()
+	}
+}
+
+func (c *FakeClock) getAdded() *sync.Cond {
+	if c.added == nil {
+		c.added = sync.NewCond(&c.mu)
+	}
+	return c.added
+}
+
+func (c *FakeClock) fireDueTimers() {
+	c.timers = slices.DeleteFunc(c.timers, func(timer *fakeTimer) bool {
+		if timer.at.After(c.now) {
+			return false
+		}
+		timer.c <- c.now
+		return true
+	})
+}
+
+// getBuildID defaults to the clock's Unix time, in seconds
+func (opts BuildOptions) getBuildID() string {
+	if opts.BuildIDFunc != nil {
+		return opts.BuildIDFunc()
+	}
+	return fmt.Sprintf("%d", getClock(opts.Clock).Now().Unix())
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestBuildID(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	if buildID := (BuildOptions{Clock: clock}).getBuildID(); buildID != "1700000000" {
+		t.Errorf("expected the clock's Unix time, got %q", buildID)
+	}
+	opts := BuildOptions{Clock: clock, BuildIDFunc: func() string { return "snapshot" }}
+	if buildID := opts.getBuildID(); buildID != "snapshot" {
+		t.Errorf("expected BuildIDFunc to win, got %q", buildID)
+	}
+}
+
+func TestHwyClockAndBuildID(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+	withNegativeCache(t, nil)
+
+	pathsFileBytes, _ := json.Marshal(PathsFile{Paths: GetPathsFromPageFiles("clock/_index.ui.tsx"), BuildID: "1"})
+	clock := NewFakeClock(time.Unix(0, 0))
+	h := &Hwy{
+		FS:              fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}},
+		Clock:           clock,
+		BuildIDFunc:     func() string { return "snapshot" },
+		ExpectedBuildID: "1",
+		NegativeCache:   &NegativeCachePolicy{TTL: time.Minute},
+	}
+	if err := h.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/clock", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routeData.BuildID != "snapshot" {
+		t.Errorf("expected the overridden build ID, got %q", routeData.BuildID)
+	}
+
+	lookupTestPath("/clock/expiring")
+	clock.Advance(59 * time.Second)
+	if !lookupTestPath("/clock/expiring") {
+		t.Fatal("expected the entry to be cached until the TTL passes on the clock")
+	}
+	clock.Advance(2 * time.Second)
+	if lookupTestPath("/clock/expiring") {
+		t.Error("expected the entry to expire once the TTL passes on the clock")
+	}
+}
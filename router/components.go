@@ -0,0 +1,57 @@
+package router
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+)
+
+// ComponentRenderer server-renders a route's components to HTML, typically
+// by running the built route modules in an embedded JS engine (goja,
+// QuickJS, V8 via a bridge, etc.), which Hwy doesn't depend on itself. Set
+// it as Hwy.ComponentRenderer and opt routes in with DataFuncs.SSRComponents.
+type ComponentRenderer interface {
+	RenderComponents(ctx context.Context, input *ComponentRenderInput) (template.HTML, error)
+}
+
+type ComponentRenderInput struct {
+	Request   *http.Request
+	RouteData *GetRouteDataOutput
+	// The route modules to render, nested outermost first, as paths relative
+	// to the hashed output dir (parallel to RouteData.LoadersData), including
+	// static routes' (which RouteData.ImportURLs blanks)
+	ImportURLs []string
+	// Parallel to ImportURLs, including the fields tagged `hwy:"ssr-only"` or
+	// `hwy:"encrypted"` that RouteData.LoadersData leaves out or encrypts
+	LoadersData []any
+}
+
+// renderComponents returns "" (so the client renders from scratch) unless
+// the deepest matched route opted in. Failures are logged rather than
+// failing the request, as the page still works without server markup.
+func (h Hwy) renderComponents(r *http.Request, routeData *GetRouteDataOutput) template.HTML {
+	if h.ComponentRenderer == nil || routeData.activePathData == nil || routeData.activePathData.ImportURLs == nil {
+		return ""
+	}
+	matchingPaths := *routeData.activePathData.MatchingPaths
+	if len(matchingPaths) == 0 {
+		return ""
+	}
+	deepest := matchingPaths[len(matchingPaths)-1]
+	if deepest.DataFuncs == nil || !deepest.DataFuncs.SSRComponents {
+		return ""
+	}
+	ctx, span := h.startSpan(r.Context(), SpanRenderComponents, SpanAttribute{Key: "hwy.pattern", Value: routeData.Pattern})
+	componentsHTML, err := h.ComponentRenderer.RenderComponents(ctx, &ComponentRenderInput{
+		Request:     r,
+		RouteData:   routeData,
+		ImportURLs:  *routeData.activePathData.ImportURLs,
+		LoadersData: *routeData.activePathData.LoadersData,
+	})
+	endSpan(span, err)
+	if err != nil {
+		h.getLogger().Error("component render error", "pattern", routeData.Pattern, "error", err)
+		return ""
+	}
+	return componentsHTML
+}
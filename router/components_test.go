@@ -0,0 +1,48 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testComponentRenderer struct {
+	err   error
+	input *ComponentRenderInput
+}
+
+func (c *testComponentRenderer) RenderComponents(ctx context.Context, input *ComponentRenderInput) (template.HTML, error) {
+	c.input = input
+	return `<h1>Server rendered</h1>`, c.err
+}
+
+func TestComponentRenderer(t *testing.T) {
+	tmpl := template.Must(template.New("root").Parse(`<div id="root">{{.ComponentsHTML}}</div>`))
+	renderer := &testComponentRenderer{}
+	h := Hwy{RootRenderer: HTMLTemplateRenderer{Template: tmpl}, ComponentRenderer: renderer}
+	serve := func(path string) string {
+		w := httptest.NewRecorder()
+		h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Body.String()
+	}
+
+	if body := serve("/lion/components-opted-out"); body != `<div id="root"></div>` || renderer.input != nil {
+		t.Errorf("expected no server markup for routes that didn't opt in, got %s", body)
+	}
+
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{SSRComponents: true})
+	if body := serve("/lion/components-opted-in"); body != `<div id="root"><h1>Server rendered</h1></div>` {
+		t.Errorf("expected server markup, got %s", body)
+	}
+	if renderer.input == nil || len(renderer.input.ImportURLs) != 2 || renderer.input.RouteData.Pattern != "/lion/$" {
+		t.Errorf("unexpected render input: %+v", renderer.input)
+	}
+
+	renderer.err = errors.New("engine crashed")
+	if body := serve("/lion/components-failing"); !strings.Contains(body, `<div id="root"></div>`) {
+		t.Errorf("expected fallback to client rendering, got %s", body)
+	}
+}
@@ -0,0 +1,84 @@
+package router
+
+import (
+	"regexp"
+	"strings"
+)
+
+// compoundSegment is a pattern segment holding several params, or params and
+// literal text, as in "$slug-$id", "$file.$ext", or "v$version". Each param
+// covers one or more characters, with earlier params taking as many as they
+// can ("my-post-42" matches "$slug-$id" as "my-post" and "42"). A lone param
+// is a plain dynamic segment, so "$customer-id" is a single param.
+type compoundSegment struct {
+	names []string
+	re    *regexp.Regexp
+}
+
+var compoundParamRe = regexp.MustCompile(`\$[A-Za-z0-9_]+`)
+
+// parseCompoundSegment returns nil unless segment is a compound segment
+func parseCompoundSegment(segment string) *compoundSegment {
+	count := strings.Count(segment, "$")
+	if count == 0 || (count == 1 && strings.HasPrefix(segment, "$")) {
+		return nil
+	}
+	locs := compoundParamRe.FindAllStringIndex(segment, -1)
+	if len(locs) == 0 {
+		return nil
+	}
+	s := &compoundSegment{names: make([]string, 0, len(locs))}
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range locs {
+		b.WriteString(regexp.QuoteMeta(segment[last:loc[0]]))
+		b.WriteString("(.+)")
+		s.names = append(s.names, segment[loc[0]+1:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(segment[last:]))
+	b.WriteString("$")
+	s.re = regexp.MustCompile(b.String())
+	return s
+}
+
+// getCompoundSegments returns the compound segment of each of segments (nil
+// for others), or nil if there are none
+func getCompoundSegments(segments []string) []*compoundSegment {
+	var compound []*compoundSegment
+	for i, segment := range segments {
+		if s := parseCompoundSegment(segment); s != nil {
+			if compound == nil {
+				compound = make([]*compoundSegment, len(segments))
+			}
+			compound[i] = s
+		}
+	}
+	return compound
+}
+
+func getIsCompoundSegment(segment string) bool {
+	return parseCompoundSegment(segment) != nil
+}
+
+func (s *compoundSegment) matches(pathSegment string) bool {
+	return s.re.MatchString(pathSegment)
+}
+
+// setParams sets the params pathSegment holds, decoded
+func (s *compoundSegment) setParams(params map[string]string, pathSegment string) {
+	values := s.re.FindStringSubmatch(pathSegment)
+	for i, name := range s.names {
+		if i+1 < len(values) {
+			params[name] = decodeSegment(values[i+1])
+		}
+	}
+}
+
+// resolve interpolates params into the segment
+func (s *compoundSegment) resolve(segment string, params map[string]string) string {
+	return compoundParamRe.ReplaceAllStringFunc(segment, func(param string) string {
+		return params[param[1:]]
+	})
+}
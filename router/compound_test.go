@@ -0,0 +1,65 @@
+package router
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMatchCompoundSegments(t *testing.T) {
+	for _, c := range []struct {
+		pattern, path string
+		expected      map[string]string
+	}{
+		{"/blog/$slug-$id", "/blog/my-first-post-42", map[string]string{"slug": "my-first-post", "id": "42"}},
+		{"/files/$file.$ext", "/files/archive.tar.gz", map[string]string{"file": "archive.tar", "ext": "gz"}},
+		{"/api/v$version/users", "/api/v2/users", map[string]string{"version": "2"}},
+		{"/customers/$customer-id", "/customers/abc", map[string]string{"customer-id": "abc"}},
+		{"/blog/$slug-$id", "/blog/caf%C3%A9-7", map[string]string{"slug": "café", "id": "7"}},
+	} {
+		result := Match(c.pattern, getMatchPath(httptest.NewRequest("GET", c.path, nil).URL))
+		if !result.Matches || !reflect.DeepEqual(result.Params, c.expected) {
+			t.Errorf("%s %s: expected %v, got %+v", c.pattern, c.path, c.expected, result)
+		}
+	}
+	for _, c := range [][2]string{
+		{"/blog/$slug-$id", "/blog/nodash"},
+		{"/blog/$slug-$id", "/blog/-42"},
+		{"/api/v$version/users", "/api/2/users"},
+	} {
+		if Match(c[0], c[1]).Matches {
+			t.Errorf("expected %s not to match %s", c[0], c[1])
+		}
+	}
+}
+
+func TestCompoundSegmentScoring(t *testing.T) {
+	withRouteTable(t, []string{
+		"_index.ui.tsx",
+		"$.ui.tsx",
+		"blog.ui.tsx",
+		"blog/$slug.ui.tsx",
+		"blog/$slug-$id.ui.tsx",
+		"blog/latest.ui.tsx",
+	})
+	paths := GetPathsFromPageFiles("blog/v$version.ui.tsx")
+	if paths[0].PathType != PathTypeDynamicLayout {
+		t.Errorf("expected compound segments to make dynamic layouts, got %s", paths[0].PathType)
+	}
+
+	for path, expected := range map[string]string{
+		"/blog/hello":    "/blog/$slug",
+		"/blog/hello-42": "/blog/$slug-$id",
+		"/blog/latest":   "/blog/latest",
+	} {
+		item := getMatchingPathItem(httptest.NewRequest("GET", path, nil))
+		matchingPaths := *item.MatchingPaths
+		if got := matchingPaths[len(matchingPaths)-1].Pattern; got != expected {
+			t.Errorf("%s: expected %s to win, got %s", path, expected, got)
+		}
+	}
+
+	if resolved := ResolvePattern("/blog/$slug-$id", map[string]string{"slug": "hello", "id": "42"}, nil); resolved != "/blog/hello-42" {
+		t.Errorf("expected compound segments to resolve, got %s", resolved)
+	}
+}
@@ -0,0 +1,264 @@
+package router
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Compressor is an extra content encoding Compression can negotiate, e.g.
+// brotli from a third-party package
+type Compressor struct {
+	Encoding string // the Content-Encoding token, e.g. "br"
+	// The returned writer is flushed on http.Flusher flushes if it has a
+	// Flush() error method
+	NewWriter func(w io.Writer) io.WriteCloser
+}
+
+// Compression configures response compression by its Middleware (and by
+// the root handler when set as Hwy.Compression). The zero value gzips
+// compressible responses of at least 1KB.
+type Compression struct {
+	// Smaller responses are sent as is. Defaults to 1024 bytes. Streamed
+	// responses are compressed from their first flush regardless.
+	MinSize int
+	// Defaults to DefaultCompressibleTypes. Parameters (e.g. charset) are
+	// ignored, and a trailing "/*" matches any subtype.
+	ContentTypes []string
+	// Preferred over gzip (which is always available) when the client
+	// accepts them equally, in order
+	Compressors []Compressor
+	// Defaults to gzip.DefaultCompression
+	GzipLevel int
+}
+
+// Event streams are left out, as proxies tend to buffer compressed streams
+var DefaultCompressibleTypes = []string{
+	"text/html",
+	"text/css",
+	"text/plain",
+	"text/javascript",
+	"application/javascript",
+	"application/json",
+	"application/manifest+json",
+	"image/svg+xml",
+}
+
+const defaultCompressionMinSize = 1024
+
+func (c Compression) Middleware(next http.Handler) http.Handler {
+	gzipLevel := c.GzipLevel
+	if gzipLevel == 0 {
+		gzipLevel = gzip.DefaultCompression
+	}
+	gzipPool := &sync.Pool{New: func() any {
+		gw, _ := gzip.NewWriterLevel(io.Discard, gzipLevel)
+		return gw
+	}}
+	compressors := append(append([]Compressor(nil), c.Compressors...), Compressor{
+		Encoding: "gzip",
+		NewWriter: func(w io.Writer) io.WriteCloser {
+			gw := gzipPool.Get().(*gzip.Writer)
+			gw.Reset(w)
+			return &pooledGzipWriter{Writer: gw, pool: gzipPool}
+		},
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		compressor := negotiateCompressor(r.Header.Get("Accept-Encoding"), compressors)
+		if compressor == nil || r.Method == http.MethodHead || GetIsWebSocketRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, config: &c, compressor: compressor}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+type pooledGzipWriter struct {
+	*gzip.Writer
+	pool *sync.Pool
+}
+
+func (w *pooledGzipWriter) Close() error {
+	err := w.Writer.Close()
+	w.pool.Put(w.Writer)
+	return err
+}
+
+// negotiateCompressor returns the accepted compressor with the highest
+// q-value (ties going to the earliest), or nil for none
+func negotiateCompressor(acceptEncoding string, compressors []Compressor) *Compressor {
+	if acceptEncoding == "" {
+		return nil
+	}
+	qValues := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		qValues[strings.ToLower(strings.TrimSpace(token))] = q
+	}
+	var best *Compressor
+	bestQ := 0.0
+	for i := range compressors {
+		q, ok := qValues[compressors[i].Encoding]
+		if !ok {
+			q = qValues["*"]
+		}
+		if q > bestQ {
+			best, bestQ = &compressors[i], q
+		}
+	}
+	return best
+}
+
+func (c *Compression) getMinSize() int {
+	if c.MinSize <= 0 {
+		return defaultCompressionMinSize
+	}
+	return c.MinSize
+}
+
+func (c *Compression) getIsCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	types := c.ContentTypes
+	if types == nil {
+		types = DefaultCompressibleTypes
+	}
+	for _, t := range types {
+		if prefix, ok := strings.CutSuffix(t, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter buffers the start of a response until it knows whether to
+// compress it: once MinSize is reached, on the first flush, or at the end
+type compressWriter struct {
+	http.ResponseWriter
+	config     *Compression
+	compressor *Compressor
+
+	status  int
+	buf     []byte
+	decided bool
+	writer  io.WriteCloser // nil once decided means uncompressed
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	// Informational responses (e.g. 103 Early Hints) go out immediately
+	if w.decided || (status >= 100 && status < 200) {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		if w.writer != nil {
+			return w.writer.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.config.getMinSize() {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// decide starts the response, compressed if bigEnough and otherwise
+// eligible, and writes out anything buffered so far
+func (w *compressWriter) decide(bigEnough bool) error {
+	w.decided = true
+	header := w.Header()
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	compress := bigEnough &&
+		status != http.StatusNoContent && status != http.StatusNotModified &&
+		header.Get("Content-Encoding") == "" &&
+		w.config.getIsCompressible(header.Get("Content-Type"))
+	if compress {
+		header.Set("Content-Encoding", w.compressor.Encoding)
+		header.Del("Content-Length")
+		w.writer = w.compressor.NewWriter(w.ResponseWriter)
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	var err error
+	if w.writer != nil {
+		_, err = w.writer.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// Flush commits to compressing (if eligible), as a flushing response is
+// likely a stream that will outgrow MinSize
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+	}
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decide(len(w.buf) >= w.config.getMinSize())
+	}
+	if w.writer != nil {
+		w.writer.Close()
+	}
+}
+
+// Hijack hands over the connection as is (e.g. for WebSockets)
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,127 @@
+package router
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	big := strings.Repeat("hello hwy ", 200)
+	handler := Compression{}.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/big":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, big[:500])
+			io.WriteString(w, big[500:])
+		case "/small":
+			w.Header().Set("Content-Type", "text/html")
+			io.WriteString(w, "tiny")
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, big)
+		case "/stream":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, "{")
+			http.NewResponseController(w).Flush()
+			io.WriteString(w, "}")
+		}
+	}))
+	serve := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	gunzip := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		t.Helper()
+		if w.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("expected gzip, got %q", w.Header().Get("Content-Encoding"))
+		}
+		gr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(gr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	w := serve("/big", "br;q=0.5, gzip")
+	if body := gunzip(t, w); body != big || w.Code != http.StatusNotFound {
+		t.Errorf("unexpected gzipped response: %d %q", w.Code, body)
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Error("expected Vary: Accept-Encoding")
+	}
+	if body := gunzip(t, serve("/stream", "gzip")); body != "{}" {
+		t.Errorf("expected flushed stream compressed regardless of size, got %q", body)
+	}
+
+	for path, acceptEncoding := range map[string]string{
+		"/big":   "gzip;q=0, identity",
+		"/small": "gzip",
+		"/image": "gzip",
+	} {
+		w := serve(path, acceptEncoding)
+		if w.Header().Get("Content-Encoding") != "" || w.Body.Len() == 0 {
+			t.Errorf("%s (%s): expected uncompressed response", path, acceptEncoding)
+		}
+	}
+}
+
+type testCompressor struct{ io.Writer }
+
+func (testCompressor) Close() error { return nil }
+
+func TestCompressionNegotiatesExtraCompressors(t *testing.T) {
+	handler := Compression{
+		MinSize: 1,
+		Compressors: []Compressor{{
+			Encoding:  "br",
+			NewWriter: func(w io.Writer) io.WriteCloser { return testCompressor{w} },
+		}},
+	}.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, "{}")
+	}))
+
+	for acceptEncoding, expected := range map[string]string{
+		"gzip, br":           "br",
+		"gzip, br;q=0.8":     "gzip",
+		"*":                  "br",
+		"deflate":            "",
+		"br;q=0, gzip;q=0.1": "gzip",
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if got := w.Header().Get("Content-Encoding"); got != expected {
+			t.Errorf("%q: expected %q, got %q", acceptEncoding, expected, got)
+		}
+	}
+}
+
+func TestRootHandlerCompression(t *testing.T) {
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return strings.Repeat("x", 2000), nil
+		},
+	})
+	h := Hwy{Compression: &Compression{}}
+	r := httptest.NewRequest("GET", "/lion/compression-test?"+HwyPrefix+"json=1", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w, r)
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected gzipped JSON, got headers %v", w.Header())
+	}
+}
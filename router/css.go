@@ -0,0 +1,278 @@
+package router
+
+import (
+	"html/template"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var instanceClientEntryCSSBundle string
+var instanceClientEntryCriticalCSS string
+
+// getCSSBundles returns the stylesheets of the client entry and the matched
+// routes, outermost first
+func (a *ActivePathData) getCSSBundles() []string {
+	var cssBundles []string
+	if clientEntry := getClientEntryOutput(a.getClientEntryName()); clientEntry.CSSBundle != "" {
+		cssBundles = append(cssBundles, clientEntry.CSSBundle)
+	}
+	if a.MatchingPaths == nil {
+		return cssBundles
+	}
+	for _, path := range *a.MatchingPaths {
+		if path.CSSBundle != "" && !slices.Contains(cssBundles, path.CSSBundle) {
+			cssBundles = append(cssBundles, path.CSSBundle)
+		}
+	}
+	return cssBundles
+}
+
+// getCriticalCSS returns the critical CSS of the client entry and the
+// matched routes, or "" if none of them has any
+func (a *ActivePathData) getCriticalCSS() string {
+	clientEntryCriticalCSS := getClientEntryOutput(a.getClientEntryName()).CriticalCSS
+	if a.MatchingPaths == nil {
+		return clientEntryCriticalCSS
+	}
+	var b strings.Builder
+	b.WriteString(clientEntryCriticalCSS)
+	for _, path := range *a.MatchingPaths {
+		b.WriteString(path.CriticalCSS)
+	}
+	return b.String()
+}
+
+var stylesheetsTmpl = template.Must(template.New("stylesheets").Parse(
+	`{{range .Stylesheets}}<link rel="{{$.Rel}}"{{if $.IsPreload}} as="style"{{end}} href="{{.URL}}"{{if .Integrity}} integrity="{{.Integrity}}" crossorigin="anonymous"{{end}}>{{end}}`,
+))
+
+var criticalCSSTmpl = template.Must(template.New("criticalcss").Parse(
+	`<style{{if .Nonce}} nonce="{{.Nonce}}"{{end}}>{{.CSS}}</style>`,
+))
+
+// getStylesheetsHTML returns the route's stylesheet links, plus (when any
+// matched route has critical CSS) a style block to inline and links to
+// place at the end of the body. In that case the head links only preload
+// the stylesheets, so they don't block the first paint.
+func (h Hwy) getStylesheetsHTML(routeData *GetRouteDataOutput) (criticalCSS, headLinks, deferredLinks template.HTML, err error) {
+	if len(routeData.CSSBundles) == 0 {
+		return "", "", "", nil
+	}
+	stylesheets := make([]map[string]string, 0, len(routeData.CSSBundles))
+	for _, cssBundle := range routeData.CSSBundles {
+		stylesheets = append(stylesheets, map[string]string{
+			"URL":       h.GetAssetURL(cssBundle),
+			"Integrity": GetIntegrity(cssBundle),
+		})
+	}
+	render := func(tmpl *template.Template, data map[string]any) (template.HTML, error) {
+		buf := getBuffer()
+		defer putBuffer(buf)
+		if err := tmpl.Execute(buf, data); err != nil {
+			return "", err
+		}
+		return template.HTML(buf.String()), nil
+	}
+
+	css := ""
+	if routeData.activePathData != nil {
+		css = routeData.activePathData.getCriticalCSS()
+	}
+	if css == "" {
+		headLinks, err = render(stylesheetsTmpl, map[string]any{"Stylesheets": stylesheets, "Rel": "stylesheet"})
+		return "", headLinks, "", err
+	}
+	// The CSS comes from the build output, not from users, so it's trusted
+	criticalCSS, err = render(criticalCSSTmpl, map[string]any{"CSS": template.CSS(css), "Nonce": routeData.nonce})
+	if err != nil {
+		return "", "", "", err
+	}
+	headLinks, err = render(stylesheetsTmpl, map[string]any{"Stylesheets": stylesheets, "Rel": "preload", "IsPreload": true})
+	if err != nil {
+		return "", "", "", err
+	}
+	deferredLinks, err = render(stylesheetsTmpl, map[string]any{"Stylesheets": stylesheets, "Rel": "stylesheet"})
+	return criticalCSS, headLinks, deferredLinks, err
+}
+
+// extractCriticalCSS returns the rules of css whose selectors start with one
+// of selectors (".hero" matches ".hero h1" and ".hero:hover", but not
+// ".hero-banner"; "*" matches everything), keeping their @media, @supports,
+// @layer, and @container wrappers. @font-face rules are always kept, and
+// other at-rules dropped.
+func extractCriticalCSS(css string, selectors []string) string {
+	if len(selectors) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, block := range splitCSSBlocks(stripCSSComments(css)) {
+		if !block.hasBody {
+			continue
+		}
+		atRule := getCSSAtRuleName(block.prelude)
+		switch {
+		case atRule == "@font-face":
+			b.WriteString(block.prelude + "{" + block.body + "}")
+		case atRule == "@media" || atRule == "@supports" || atRule == "@layer" || atRule == "@container":
+			if inner := extractCriticalCSS(block.body, selectors); inner != "" {
+				b.WriteString(block.prelude + "{" + inner + "}")
+			}
+		case strings.HasPrefix(atRule, "@"):
+			continue
+		default:
+			if getIsCriticalRule(block.prelude, selectors) {
+				b.WriteString(block.prelude + "{" + block.body + "}")
+			}
+		}
+	}
+	return b.String()
+}
+
+type cssBlock struct {
+	prelude string // the selectors or at-rule, trimmed
+	body    string // without the braces
+	hasBody bool   // false for statements like @import
+}
+
+// splitCSSBlocks splits css into its top-level rules and statements
+func splitCSSBlocks(css string) []cssBlock {
+	var blocks []cssBlock
+	start := 0
+	for i := 0; i < len(css); i++ {
+		switch css[i] {
+		case '"', '\'':
+			i = skipCSSString(css, i)
+		case ';':
+			if prelude := strings.TrimSpace(css[start:i]); prelude != "" {
+				blocks = append(blocks, cssBlock{prelude: prelude})
+			}
+			start = i + 1
+		case '{':
+			end := findCSSBlockEnd(css, i)
+			blocks = append(blocks, cssBlock{
+				prelude: strings.TrimSpace(css[start:i]),
+				body:    css[i+1 : end],
+				hasBody: true,
+			})
+			i = end
+			start = end + 1
+		}
+	}
+	return blocks
+}
+
+// findCSSBlockEnd returns the index of the brace closing the one at open, or
+// len(css) if it's unclosed
+func findCSSBlockEnd(css string, open int) int {
+	depth := 0
+	for i := open; i < len(css); i++ {
+		switch css[i] {
+		case '"', '\'':
+			i = skipCSSString(css, i)
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(css)
+}
+
+// skipCSSString returns the index of the quote closing the one at start
+func skipCSSString(css string, start int) int {
+	quote := css[start]
+	for i := start + 1; i < len(css); i++ {
+		switch css[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return len(css)
+}
+
+func stripCSSComments(css string) string {
+	if !strings.Contains(css, "/*") {
+		return css
+	}
+	var b strings.Builder
+	for i := 0; i < len(css); i++ {
+		switch {
+		case css[i] == '"' || css[i] == '\'':
+			end := min(skipCSSString(css, i), len(css)-1)
+			b.WriteString(css[i : end+1])
+			i = end
+		case strings.HasPrefix(css[i:], "/*"):
+			end := strings.Index(css[i+2:], "*/")
+			if end == -1 {
+				return b.String()
+			}
+			i += end + 3
+		default:
+			b.WriteByte(css[i])
+		}
+	}
+	return b.String()
+}
+
+func getIsCriticalRule(prelude string, selectors []string) bool {
+	for _, ruleSelector := range splitCSSSelectors(prelude) {
+		for _, selector := range selectors {
+			if selector == "*" {
+				return true
+			}
+			rest, ok := strings.CutPrefix(ruleSelector, selector)
+			if !ok {
+				continue
+			}
+			if next, _ := utf8.DecodeRuneInString(rest); rest == "" || !getIsCSSNameRune(next) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitCSSSelectors splits a selector list on top-level commas, leaving
+// those inside e.g. :is() alone
+func splitCSSSelectors(prelude string) []string {
+	var selectors []string
+	depth, start := 0, 0
+	for i := 0; i < len(prelude); i++ {
+		switch prelude[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				selectors = append(selectors, strings.TrimSpace(prelude[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(selectors, strings.TrimSpace(prelude[start:]))
+}
+
+func getIsCSSNameRune(r rune) bool {
+	return r == '-' || r == '_' || r >= utf8.RuneSelf ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// getCSSAtRuleName returns e.g. "@media" for "@media (min-width:40em)", or ""
+// if prelude isn't an at-rule
+func getCSSAtRuleName(prelude string) string {
+	if !strings.HasPrefix(prelude, "@") {
+		return ""
+	}
+	end := strings.IndexFunc(prelude[1:], func(r rune) bool { return !getIsCSSNameRune(r) })
+	if end == -1 {
+		return prelude
+	}
+	return prelude[:end+1]
+}
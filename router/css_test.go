@@ -0,0 +1,161 @@
+package router
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExtractCriticalCSS(t *testing.T) {
+	css := `/* header */
+@charset "utf-8";
+@import url("x.css");
+@font-face { font-family: "Inter"; src: url("inter.woff2"); }
+.hero, .card { color: red; }
+.hero-banner { color: blue; }
+.hero:hover { color: green; }
+nav a[href="{}"] { color: black; }
+@media (min-width: 40em) { .hero h1 { font-size: 2em; } .footer { margin: 0; } }
+@media print { .footer { display: none; } }
+@keyframes spin { from { transform: rotate(0); } }
+.content::before { content: "/* not a comment */"; }`
+
+	got := extractCriticalCSS(css, []string{".hero", "nav", ".content"})
+	want := `@font-face{ font-family: "Inter"; src: url("inter.woff2"); }` +
+		`.hero, .card{ color: red; }` +
+		`.hero:hover{ color: green; }` +
+		`nav a[href="{}"]{ color: black; }` +
+		`@media (min-width: 40em){.hero h1{ font-size: 2em; }}` +
+		`.content::before{ content: "/* not a comment */"; }`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if got := extractCriticalCSS(css, nil); got != "" {
+		t.Errorf("expected no critical CSS without selectors, got %s", got)
+	}
+}
+
+func TestBuildCriticalCSS(t *testing.T) {
+	// Relative, like the other fixtures, so paths match esbuild's metafile
+	dir := "../tmp/critical"
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	files := map[string]string{
+		"client.entry.tsx":    `import "./global.css";`,
+		"global.css":          `body { margin: 0; } .modal { position: fixed; }`,
+		"pages/_index.ui.tsx": `import "./home.css"; export default function Home() { return "home"; }`,
+		"pages/home.css":      `.hero { height: 100vh; } .testimonials { padding: 1em; }`,
+		"pages/about.ui.tsx":  `export default function About() { return "about"; }`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := Build(BuildOptions{
+		PagesSrcDir:    filepath.Join(dir, "pages"),
+		HashedOutDir:   filepath.Join(dir, "out"),
+		UnhashedOutDir: filepath.Join(dir, "out"),
+		ClientEntryOut: filepath.Join(dir, "out"),
+		ClientEntry:    filepath.Join(dir, "client.entry.tsx"),
+		CriticalCSS:    []string{"body"},
+		DataFuncsMap: DataFuncsMap{
+			"/_index": {CriticalCSS: []string{".hero"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pathsFileBytes, err := os.ReadFile(filepath.Join(dir, "out/hwy_paths.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pathsFile PathsFile
+	if err := json.Unmarshal(pathsFileBytes, &pathsFile); err != nil {
+		t.Fatal(err)
+	}
+	if pathsFile.ClientEntryCSSBundle == "" || !strings.Contains(pathsFile.ClientEntryCriticalCSS, "body") ||
+		strings.Contains(pathsFile.ClientEntryCriticalCSS, ".modal") {
+		t.Errorf("unexpected client entry CSS: %q, %q", pathsFile.ClientEntryCSSBundle, pathsFile.ClientEntryCriticalCSS)
+	}
+	for _, path := range pathsFile.Paths {
+		switch path.Pattern {
+		case "/_index":
+			if path.CSSBundle == "" || !strings.Contains(path.CriticalCSS, ".hero") || strings.Contains(path.CriticalCSS, ".testimonials") {
+				t.Errorf("unexpected index route CSS: %q, %q", path.CSSBundle, path.CriticalCSS)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "out", path.CSSBundle)); err != nil {
+				t.Errorf("expected CSS bundle to be written: %v", err)
+			}
+		case "/about":
+			if path.CSSBundle != "" || path.CriticalCSS != "" {
+				t.Errorf("expected no CSS for the about route, got %q, %q", path.CSSBundle, path.CriticalCSS)
+			}
+		}
+	}
+}
+
+func withTestCSS(t *testing.T, pattern, cssBundle, criticalCSS string) {
+	i := slices.IndexFunc(*instancePaths, func(path Path) bool { return path.Pattern == pattern })
+	original := (*instancePaths)[i]
+	originalEntryCSSBundle := instanceClientEntryCSSBundle
+	t.Cleanup(func() {
+		(*instancePaths)[i] = original
+		instanceClientEntryCSSBundle = originalEntryCSSBundle
+	})
+	(*instancePaths)[i].CSSBundle = cssBundle
+	(*instancePaths)[i].CriticalCSS = criticalCSS
+	instanceClientEntryCSSBundle = "hwy_entry__global.css"
+}
+
+func TestStylesheets(t *testing.T) {
+	tmpl := template.Must(template.New("root").Parse(
+		`<head>{{.CriticalCSS}}{{.Stylesheets}}</head><body>{{.DeferredStylesheets}}</body>`,
+	))
+	h := Hwy{RootRenderer: HTMLTemplateRenderer{Template: tmpl}}
+	serve := func(path string) string {
+		w := httptest.NewRecorder()
+		h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Body.String()
+	}
+
+	t.Run("without critical CSS", func(t *testing.T) {
+		withTestCSS(t, "/lion/$", "hwy_entry__lion.css", "")
+		want := `<head><link rel="stylesheet" href="/public/hwy_entry__global.css">` +
+			`<link rel="stylesheet" href="/public/hwy_entry__lion.css"></head><body></body>`
+		if body := serve("/lion/css-render-blocking"); body != want {
+			t.Errorf("got\n%s\nwant\n%s", body, want)
+		}
+	})
+
+	t.Run("with critical CSS", func(t *testing.T) {
+		withTestCSS(t, "/lion/$", "hwy_entry__lion.css", ".hero{height:100vh}")
+		want := `<head><style>.hero{height:100vh}</style>` +
+			`<link rel="preload" as="style" href="/public/hwy_entry__global.css">` +
+			`<link rel="preload" as="style" href="/public/hwy_entry__lion.css"></head>` +
+			`<body><link rel="stylesheet" href="/public/hwy_entry__global.css">` +
+			`<link rel="stylesheet" href="/public/hwy_entry__lion.css"></body>`
+		if body := serve("/lion/css-deferred"); body != want {
+			t.Errorf("got\n%s\nwant\n%s", body, want)
+		}
+
+		routeData, err := Hwy{}.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/css-json", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(routeData.CSSBundles, []string{"hwy_entry__global.css", "hwy_entry__lion.css"}) {
+			t.Errorf("unexpected CSS bundles: %v", routeData.CSSBundles)
+		}
+	})
+}
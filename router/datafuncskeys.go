@@ -0,0 +1,78 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var ErrAmbiguousDataFuncs = errors.New("ambiguous data funcs key")
+
+// getSrcPathPatterns maps the page files of pathLists to their patterns
+func getSrcPathPatterns(pathLists ...[]JSONSafePath) map[string]string {
+	srcPathPatterns := make(map[string]string)
+	for _, paths := range pathLists {
+		for _, path := range paths {
+			srcPathPatterns[filepath.ToSlash(path.SrcPath)] = path.Pattern
+		}
+	}
+	return srcPathPatterns
+}
+
+// resolveDataFuncsMap rekeys dataFuncsMap by pattern. Keys starting with "/"
+// are patterns, and preferred, as they survive reorganizing the page files.
+// Other keys name a page file, by its path relative to the pages directory
+// (e.g. "tiger/$tiger_id.ui.tsx") or as in the paths file, and are resolved
+// by srcPathPatterns (see getSrcPathPatterns). Keys naming no page file are
+// kept, for Initialize to report like patterns matching no route, while keys
+// naming several, or binding a route already bound, are returned as problems.
+func resolveDataFuncsMap(dataFuncsMap DataFuncsMap, srcPathPatterns map[string]string) (DataFuncsMap, []error) {
+	hasSrcPathKeys := false
+	for key := range dataFuncsMap {
+		if !strings.HasPrefix(key, "/") {
+			hasSrcPathKeys = true
+			break
+		}
+	}
+	if !hasSrcPathKeys {
+		return dataFuncsMap, nil
+	}
+
+	var problems []error
+	resolved := make(DataFuncsMap, len(dataFuncsMap))
+	boundBy := make(map[string]string, len(dataFuncsMap)) // pattern to key
+	for _, key := range getSortedKeys(dataFuncsMap) {
+		if strings.HasPrefix(key, "/") {
+			resolved[key] = dataFuncsMap[key]
+			boundBy[key] = key
+		}
+	}
+	for _, key := range getSortedKeys(dataFuncsMap) {
+		if strings.HasPrefix(key, "/") {
+			continue
+		}
+		var patterns []string
+		for srcPath, pattern := range srcPathPatterns {
+			if srcPath == key || strings.HasSuffix(srcPath, "/"+key) {
+				if !slices.Contains(patterns, pattern) {
+					patterns = append(patterns, pattern)
+				}
+			}
+		}
+		slices.Sort(patterns)
+		switch {
+		case len(patterns) == 0:
+			resolved[key] = dataFuncsMap[key]
+		case len(patterns) > 1:
+			problems = append(problems, fmt.Errorf("%w: %s names the page files of %s", ErrAmbiguousDataFuncs, key, strings.Join(patterns, ", ")))
+		case boundBy[patterns[0]] != "":
+			problems = append(problems, fmt.Errorf("%w: %s and %s both bind %s", ErrAmbiguousDataFuncs, boundBy[patterns[0]], key, patterns[0]))
+		default:
+			resolved[patterns[0]] = dataFuncsMap[key]
+			boundBy[patterns[0]] = key
+		}
+	}
+	return resolved, problems
+}
@@ -0,0 +1,68 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestResolveDataFuncsMap(t *testing.T) {
+	srcPathPatterns := getSrcPathPatterns([]JSONSafePath{
+		{Pattern: "/tiger/$tiger_id", SrcPath: "src/pages/tiger/$tiger_id.ui.tsx"},
+		{Pattern: "/lion/$id", SrcPath: "src/pages/lion/$id.ui.tsx"},
+		{Pattern: "/bear/$id", SrcPath: "src/pages/bear/$id.ui.tsx"},
+	})
+	resolved, problems := resolveDataFuncsMap(DataFuncsMap{
+		"/lion/$id":                    {Static: true},
+		"tiger/$tiger_id.ui.tsx":       {Handle: map[string]any{"by": "relative"}},
+		"src/pages/lion/$id.ui.tsx":    {},
+		"$id.ui.tsx":                   {},
+		"src/pages/missing/$id.ui.tsx": {},
+		"src/pages/bear/$id.ui.tsx":    {},
+	}, srcPathPatterns)
+	if resolved["/tiger/$tiger_id"].Handle["by"] != "relative" || !resolved["/lion/$id"].Static {
+		t.Errorf("expected keys rekeyed by pattern, with patterns winning, got %v", resolved)
+	}
+	if _, ok := resolved["src/pages/missing/$id.ui.tsx"]; !ok {
+		t.Error("expected keys naming no page file to be kept, to be reported as unbound")
+	}
+	if _, ok := resolved["/bear/$id"]; !ok {
+		t.Error("expected a page file key to bind its route")
+	}
+	// The lion key repeats a pattern, and "$id.ui.tsx" names several page files
+	if len(problems) != 2 || !errors.Is(problems[0], ErrAmbiguousDataFuncs) || !errors.Is(problems[1], ErrAmbiguousDataFuncs) {
+		t.Errorf("expected 2 ambiguous keys, got %v", problems)
+	}
+
+	dataFuncsMap := DataFuncsMap{"/lion/$id": {}}
+	if resolved, problems := resolveDataFuncsMap(dataFuncsMap, srcPathPatterns); len(problems) > 0 || len(resolved) != 1 {
+		t.Errorf("expected pattern keys to be left alone, got %v, %v", resolved, problems)
+	}
+}
+
+func TestInitializeResolvesPageFileKeys(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+
+	paths := GetPathsFromPageFiles("keyed/tiger.ui.tsx")
+	pathsFileBytes, _ := json.Marshal(PathsFile{Paths: paths, BuildID: "1"})
+	h := Hwy{
+		FS: fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}},
+		DataFuncsMap: DataFuncsMap{"keyed/tiger.ui.tsx": {Loader: func(*LoaderProps) (any, error) {
+			return "by page file", nil
+		}}},
+	}
+	if err := h.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/keyed/tiger?resolve-keys", nil))
+	if err != nil || (*routeData.LoadersData)[0] != "by page file" {
+		t.Errorf("expected the loader bound by page file to run, got %v", err)
+	}
+
+	h.DataFuncsMap["/keyed/tiger"] = DataFuncs{}
+	if err := h.Initialize(); !errors.Is(err, ErrAmbiguousDataFuncs) {
+		t.Errorf("expected ErrAmbiguousDataFuncs when both forms bind a route, got %v", err)
+	}
+}
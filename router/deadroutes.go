@@ -0,0 +1,94 @@
+package router
+
+import (
+	"slices"
+	"strings"
+)
+
+// DeadRoute is a page file whose route is never served, as a higher-ranked
+// route has the same shape (e.g. "users/$id" and "users/$handle", or a page
+// file repeating another's pattern from within a "__" directory)
+type DeadRoute struct {
+	Tenant     string // empty for the base tree
+	SrcPath    string
+	Pattern    string
+	ShadowedBy string // the src path of the page file served instead
+}
+
+// FindDeadRoutes reports the competing routes (see FindRouteAmbiguities) of
+// paths that lose to a route of the same shape for every URL
+func FindDeadRoutes(paths []JSONSafePath) []DeadRoute {
+	var deadRoutes []DeadRoute
+	byShape := make(map[string][]*MatchingPath)
+	var shapes []string
+	srcPaths := make(map[*MatchingPath]string, len(paths))
+	for _, path := range paths {
+		if !getIsCompetingPath(path) {
+			continue
+		}
+		shape := getRouteShape(path.Pattern)
+		if _, ok := byShape[shape]; !ok {
+			shapes = append(shapes, shape)
+		}
+		candidate := &MatchingPath{Pattern: path.Pattern}
+		srcPaths[candidate] = path.SrcPath
+		byShape[shape] = append(byShape[shape], candidate)
+	}
+	for _, shape := range shapes {
+		ranked := RankRoutes(byShape[shape])
+		for _, loser := range ranked[1:] {
+			deadRoutes = append(deadRoutes, DeadRoute{
+				SrcPath:    srcPaths[loser],
+				Pattern:    loser.Pattern,
+				ShadowedBy: srcPaths[ranked[0]],
+			})
+		}
+	}
+	return deadRoutes
+}
+
+// getRouteShape returns pattern with its param names left out, e.g.
+// "/users/:/posts/:-:" for "/users/$id/posts/$slug-$postID"
+func getRouteShape(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		switch {
+		case getIsCompoundSegment(segment):
+			segments[i] = compoundParamRe.ReplaceAllString(segment, ":")
+		case strings.HasPrefix(segment, "$") && segment != "$":
+			segments[i] = ":"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pruneDeadRoutes leaves deadRoutes out of paths
+func pruneDeadRoutes(paths []JSONSafePath, deadRoutes []DeadRoute) []JSONSafePath {
+	return slices.DeleteFunc(paths, func(path JSONSafePath) bool {
+		return slices.ContainsFunc(deadRoutes, func(deadRoute DeadRoute) bool {
+			return deadRoute.SrcPath == path.SrcPath
+		})
+	})
+}
+
+// getTreeShakenInputs returns the source files (outside node_modules) that
+// were bundled but contribute nothing to any output, e.g. unused modules or
+// barrel files only re-exporting others, sorted
+func getTreeShakenInputs(metafile *MetafileJSON) []string {
+	used := make(map[string]bool, len(metafile.Inputs))
+	for _, output := range metafile.Outputs {
+		for input, inputOutput := range output.Inputs {
+			if inputOutput.BytesInOutput > 0 {
+				used[input] = true
+			}
+		}
+	}
+	var treeShaken []string
+	for input := range metafile.Inputs {
+		if !used[input] && !strings.Contains(input, "node_modules/") && !strings.ContainsAny(input, ":<") {
+			treeShaken = append(treeShaken, input)
+		}
+	}
+	slices.Sort(treeShaken)
+	return treeShaken
+}
@@ -0,0 +1,43 @@
+package router
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestFindDeadRoutes(t *testing.T) {
+	paths := GetPathsFromPageFiles(
+		"users/$id.ui.tsx",
+		"users/$handle.ui.tsx",
+		"users/$slug-$id.ui.tsx",
+		"__marketing/about/_index.ui.tsx",
+		"about/_index.ui.tsx",
+		"tiger.ui.tsx",
+		"$.ui.tsx",
+	)
+	deadRoutes := FindDeadRoutes(paths)
+	expected := []DeadRoute{
+		{SrcPath: "/users/$id.ui.tsx", Pattern: "/users/$id", ShadowedBy: "/users/$handle.ui.tsx"},
+		{SrcPath: "/about/_index.ui.tsx", Pattern: "/about/_index", ShadowedBy: "/__marketing/about/_index.ui.tsx"},
+	}
+	if !slices.Equal(deadRoutes, expected) {
+		t.Errorf("expected %+v, got %+v", expected, deadRoutes)
+	}
+
+	pruned := pruneDeadRoutes(paths, deadRoutes)
+	if len(pruned) != 5 || slices.ContainsFunc(pruned, func(path JSONSafePath) bool { return path.Pattern == "/users/$id" }) {
+		t.Errorf("expected the dead routes pruned, got %+v", pruned)
+	}
+}
+
+func TestGetTreeShakenInputs(t *testing.T) {
+	var metafile MetafileJSON
+	json.Unmarshal([]byte(`{
+		"inputs": {"page.ts": {}, "util.ts": {}, "a.ts": {}, "b.ts": {}, "node_modules/lib/index.js": {}},
+		"outputs": {"out/page.js": {"inputs": {"page.ts": {"bytesInOutput": 16}, "util.ts": {"bytesInOutput": 0}, "a.ts": {"bytesInOutput": 11}}}}
+	}`), &metafile)
+	if treeShaken := getTreeShakenInputs(&metafile); !slices.Equal(treeShaken, []string{"b.ts", "util.ts"}) {
+		t.Errorf("unexpected tree-shaken inputs %v", treeShaken)
+	}
+}
@@ -0,0 +1,220 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DevError describes a loader, action, render, or build error in detail.
+// It is only ever sent to clients when Hwy.IsDev is set.
+type DevError struct {
+	Message string         `json:"message"`
+	Pattern string         `json:"pattern,omitempty"`
+	Phase   ErrorPhase     `json:"phase,omitempty"`
+	Stack   string         `json:"stack,omitempty"`
+	Source  *SourceSnippet `json:"source,omitempty"`
+}
+
+type SourceSnippet struct {
+	File  string       `json:"file"`
+	Line  int          `json:"line"`
+	Lines []SourceLine `json:"lines"`
+}
+
+type SourceLine struct {
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+}
+
+const ErrorPhaseBuild ErrorPhase = "build"
+
+// BuildErrorFileName is written next to the paths file when a dev build
+// fails (and removed once one succeeds), so the running app can show it
+const BuildErrorFileName = "hwy_build_error.json"
+
+const sourceSnippetContext = 3
+
+func newDevError(err error, pattern string, phase ErrorPhase) *DevError {
+	devErr := &DevError{Message: err.Error(), Pattern: pattern, Phase: phase}
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		devErr.Stack = string(panicErr.Stack)
+		devErr.Source = getSourceSnippetFromStack(devErr.Stack)
+	}
+	return devErr
+}
+
+// Matches stack frame lines like "\t/path/to/file.go:123 +0x1d"
+var stackFrameRegex = regexp.MustCompile(`^\t(.+\.go):(\d+)`)
+
+// getSourceSnippetFromStack returns the code that panicked, i.e. the first
+// frame after the runtime's panic frame
+func getSourceSnippetFromStack(stack string) *SourceSnippet {
+	lines := strings.Split(stack, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "panic(") {
+			continue
+		}
+		for _, frameLine := range lines[i+1:] {
+			match := stackFrameRegex.FindStringSubmatch(frameLine)
+			if match == nil || strings.Contains(match[1], "/runtime/") {
+				continue
+			}
+			lineNumber, _ := strconv.Atoi(match[2])
+			return getSourceSnippet(match[1], lineNumber)
+		}
+	}
+	return nil
+}
+
+// getSourceSnippet returns nil if the file can't be read (e.g. in a binary
+// built elsewhere)
+func getSourceSnippet(file string, line int) *SourceSnippet {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	fileLines := strings.Split(string(content), "\n")
+	if line < 1 || line > len(fileLines) {
+		return nil
+	}
+	snippet := &SourceSnippet{File: file, Line: line}
+	for n := max(1, line-sourceSnippetContext); n <= min(len(fileLines), line+sourceSnippetContext); n++ {
+		snippet.Lines = append(snippet.Lines, SourceLine{Number: n, Text: fileLines[n-1]})
+	}
+	return snippet
+}
+
+// getBuildDevError returns nil unless the last dev build failed
+func (h Hwy) getBuildDevError() *DevError {
+	if h.FS == nil {
+		return nil
+	}
+	buildErrorBytes, err := fs.ReadFile(h.FS, BuildErrorFileName)
+	if err != nil {
+		return nil
+	}
+	var buildErr BuildError
+	if err := json.Unmarshal(buildErrorBytes, &buildErr); err != nil {
+		return nil
+	}
+	devErr := &DevError{Message: buildErr.Message, Phase: ErrorPhaseBuild}
+	if buildErr.File != "" {
+		devErr.Source = getSourceSnippet(buildErr.File, buildErr.Line)
+		if devErr.Source == nil {
+			devErr.Source = &SourceSnippet{
+				File:  buildErr.File,
+				Line:  buildErr.Line,
+				Lines: []SourceLine{{Number: buildErr.Line, Text: buildErr.LineText}},
+			}
+		}
+	}
+	return devErr
+}
+
+func (h Hwy) serveDevError(w http.ResponseWriter, r *http.Request, devErr *DevError) {
+	if GetIsJSONRequest(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]*DevError{"devError": devErr})
+		return
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	err := devErrorPageTmpl.Execute(buf, map[string]any{"DevError": devErr, "Nonce": GetCSPNonce(r)})
+	if err != nil {
+		http.Error(w, devErr.Message, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write(buf.Bytes())
+}
+
+const devErrorStyles = `#hwy-dev-overlay{position:fixed;inset:0;z-index:2147483647;overflow:auto;padding:2rem;background:#1b1b1f;color:#eee;font:14px/1.5 ui-monospace,monospace}
+#hwy-dev-overlay h1{color:#ff6b6b;font-size:1.25rem;white-space:pre-wrap}
+#hwy-dev-overlay .meta{color:#aaa}
+#hwy-dev-overlay pre{background:#111;padding:1rem;overflow:auto}
+#hwy-dev-overlay .hl{background:#5c1f1f;display:block}`
+
+var devErrorPageTmpl = template.Must(template.New("deverror").Parse(`<!doctype html>
+<html>
+<head><meta charset="utf-8"><title>{{.DevError.Message}}</title><style{{if .Nonce}} nonce="{{.Nonce}}"{{end}}>` + devErrorStyles + `</style></head>
+<body>
+<div id="hwy-dev-overlay">{{with .DevError}}
+<h1>{{.Message}}</h1>
+<p class="meta">{{if .Phase}}{{.Phase}} error{{end}}{{if .Pattern}} in route {{.Pattern}}{{end}}</p>
+{{with .Source}}<p class="meta">{{.File}}:{{.Line}}</p>
+<pre>{{$line := .Line}}{{range .Lines}}<span{{if eq .Number $line}} class="hl"{{end}}>{{printf "%4d" .Number}} | {{.Text}}</span>
+{{end}}</pre>{{end}}
+{{if .Stack}}<pre>{{.Stack}}</pre>{{end}}
+{{end}}</div>
+</body>
+</html>
+`))
+
+// devErrorOverlayTmpl renders a DevError over a page that otherwise rendered
+// normally (e.g. with a loader error caught by an error boundary)
+var devErrorOverlayTmpl = template.Must(template.New("deverroroverlay").Parse(`<script{{if .Nonce}} nonce="{{.Nonce}}"{{end}}>
+	document.addEventListener("DOMContentLoaded", () => {
+		const e = {{.DevError}};
+		const el = (tag, text, className) => {
+			const node = document.createElement(tag);
+			node.textContent = text || "";
+			if (className) node.className = className;
+			return node;
+		};
+		const style = el("style", {{.Styles}});
+		const overlay = el("div");
+		overlay.id = "hwy-dev-overlay";
+		overlay.append(el("h1", e.message), el("p", (e.phase ? e.phase + " error" : "") + (e.pattern ? " in route " + e.pattern : ""), "meta"));
+		if (e.source) {
+			const pre = el("pre");
+			e.source.lines.forEach(l => pre.append(el("span", String(l.number).padStart(4) + " | " + l.text + "\n", l.number === e.source.line ? "hl" : "")));
+			overlay.append(el("p", e.source.file + ":" + e.source.line, "meta"), pre);
+		}
+		if (e.stack) overlay.append(el("pre", e.stack));
+		overlay.addEventListener("click", ev => { if (ev.target === overlay) overlay.remove(); });
+		document.body.append(style, overlay);
+	});
+</script>`))
+
+func getDevErrorOverlayHTML(devErr *DevError, nonce string) (template.HTML, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	err := devErrorOverlayTmpl.Execute(buf, map[string]any{"DevError": devErr, "Nonce": nonce, "Styles": devErrorStyles})
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// serveInternalError logs err and responds with a 500, detailing err when
+// IsDev is set
+func (h Hwy) serveInternalError(w http.ResponseWriter, r *http.Request, pattern string, msg string, err error) {
+	h.getLogger().Error(msg, "error", err)
+	if h.IsDev {
+		devErr := newDevError(err, pattern, "")
+		devErr.Message = msg + ": " + devErr.Message
+		h.serveDevError(w, r, devErr)
+		return
+	}
+	http.Error(w, msg, http.StatusInternalServerError)
+}
+
+// getRenderPlanDevError returns nil unless IsDev is set and a loader or
+// action failed
+func (h Hwy) getRenderPlanDevError(a *ActivePathData) *DevError {
+	plan := a.ErrorRenderPlan
+	if !h.IsDev || plan == nil {
+		return nil
+	}
+	return newDevError(plan.Err, (*a.MatchingPaths)[plan.ErrorIndex].Pattern, plan.Phase)
+}
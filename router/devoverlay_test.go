@@ -0,0 +1,110 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDevErrorForLoaderPanic(t *testing.T) {
+	setTestDataFuncs(t, "/tiger/$tiger_id", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			panic("dev overlay kaboom")
+		},
+	})
+
+	r := httptest.NewRequest("GET", "/tiger/dev-1", nil)
+	routeData, err := Hwy{IsDev: true}.GetRouteData(httptest.NewRecorder(), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	devErr := routeData.DevError
+	if devErr == nil {
+		t.Fatal("expected a dev error")
+	}
+	if devErr.Pattern != "/tiger/$tiger_id" || devErr.Phase != ErrorPhaseLoader || !strings.Contains(devErr.Message, "dev overlay kaboom") {
+		t.Errorf("unexpected dev error %+v", devErr)
+	}
+	if devErr.Source == nil || !strings.HasSuffix(devErr.Source.File, "devoverlay_test.go") {
+		t.Fatalf("expected source snippet from this file, got %+v", devErr.Source)
+	}
+	var highlighted string
+	for _, line := range devErr.Source.Lines {
+		if line.Number == devErr.Source.Line {
+			highlighted = line.Text
+		}
+	}
+	if !strings.Contains(highlighted, `panic("dev overlay kaboom")`) {
+		t.Errorf("expected the panicking line to be highlighted, got %q", highlighted)
+	}
+
+	ssrInnerHTML, err := GetSSRInnerHTML(routeData, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(*ssrInnerHTML), "hwy-dev-overlay") {
+		t.Error("expected dev error overlay in SSR inner HTML")
+	}
+
+	routeData, _ = Hwy{}.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/tiger/dev-2", nil))
+	if routeData.DevError != nil {
+		t.Error("expected no dev error outside of dev")
+	}
+}
+
+func TestDevErrorForRouteDataError(t *testing.T) {
+	setTestDataFuncs(t, "/tiger", &DataFuncs{
+		Guard: func(props *GuardProps) (*GuardOutcome, error) {
+			return nil, errors.New("guard exploded")
+		},
+	})
+
+	r := httptest.NewRequest("GET", "/tiger/dev-3?"+HwyPrefix+"json=1", nil)
+	w := httptest.NewRecorder()
+	Hwy{IsDev: true}.GetRootHandler().ServeHTTP(w, r)
+	var body struct{ DevError *DevError }
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusInternalServerError || body.DevError == nil || !strings.Contains(body.DevError.Message, "guard exploded") {
+		t.Errorf("expected JSON dev error, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	Hwy{}.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/tiger/dev-4", nil))
+	if strings.Contains(w.Body.String(), "guard exploded") {
+		t.Error("expected error details to stay hidden outside of dev")
+	}
+}
+
+func TestDevBuildErrorOverlay(t *testing.T) {
+	dir := t.TempDir()
+	buildErr := &BuildError{Message: `Could not resolve "nope"`, File: "pages/missing.ui.tsx", Line: 1, LineText: `import "nope";`}
+	if err := writeBuildErrorFile(dir, buildErr); err != nil {
+		t.Fatal(err)
+	}
+	buildErrBytes, err := os.ReadFile(filepath.Join(dir, BuildErrorFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := Hwy{IsDev: true, FS: fstest.MapFS{BuildErrorFileName: &fstest.MapFile{Data: buildErrBytes}}}
+	w := httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/tiger", nil))
+	for _, expected := range []string{"Could not resolve &#34;nope&#34;", "pages/missing.ui.tsx:1", "import &#34;nope&#34;;"} {
+		if !strings.Contains(w.Body.String(), expected) {
+			t.Errorf("expected %q in build error overlay:\n%s", expected, w.Body.String())
+		}
+	}
+
+	if err := writeBuildErrorFile(dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, BuildErrorFileName)); !os.IsNotExist(err) {
+		t.Error("expected a successful build to remove the build error file")
+	}
+}
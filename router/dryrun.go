@@ -0,0 +1,138 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrMissingBuildSource = errors.New("build source not found")
+	ErrAmbiguousRoutes    = errors.New("ambiguous routes")
+	ErrDeadRoute          = errors.New("dead route")
+)
+
+// BuildValidationError aggregates every problem found by a dry run build
+// (see BuildOptions.DryRun). Use errors.Is with the Err* sentinels to check
+// for specific ones.
+type BuildValidationError struct {
+	Problems []error
+}
+
+func (e *BuildValidationError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Error()
+	}
+	return fmt.Sprintf("hwy build validation failed with %d problem(s): %s", len(e.Problems), strings.Join(messages, "; "))
+}
+
+func (e *BuildValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+// validateBuild checks what build would read, without bundling or writing
+// anything (see BuildOptions.DryRun)
+func validateBuild(opts BuildOptions) error {
+	var problems []error
+	addMissingSource := func(srcPath string) {
+		if _, err := os.Stat(srcPath); err != nil {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrMissingBuildSource, srcPath))
+		}
+	}
+	addMissingSource(opts.PagesSrcDir)
+	addMissingSource(opts.ClientEntry)
+	for _, clientEntry := range opts.ClientEntries {
+		addMissingSource(clientEntry.SrcPath)
+	}
+	for _, tenant := range getSortedKeys(opts.TenantPagesDirs) {
+		addMissingSource(opts.TenantPagesDirs[tenant])
+	}
+	for _, pattern := range getSortedKeys(opts.DataFuncsMap) {
+		for _, srcPath := range opts.DataFuncsMap[pattern].Islands {
+			addMissingSource(srcPath)
+		}
+	}
+	if _, _, err := parseLegacyTargets(opts.LegacyTargets); err != nil {
+		problems = append(problems, err)
+	}
+
+	paths := walkPages(opts.PagesSrcDir)
+	tenantPaths := make(map[string][]JSONSafePath, len(opts.TenantPagesDirs))
+	for tenant, pagesDir := range opts.TenantPagesDirs {
+		tenantPaths[tenant] = walkPages(pagesDir)
+	}
+	for _, ambiguity := range findBuildAmbiguities(paths, tenantPaths) {
+		problems = append(problems, fmt.Errorf("%w: %s and %s both match %s%s",
+			ErrAmbiguousRoutes, ambiguity.Winner, ambiguity.Loser, ambiguity.URL, getTenantSuffix(ambiguity.Tenant)))
+	}
+	allPaths := [][]JSONSafePath{paths}
+	tenants := getSortedKeys(tenantPaths)
+	for _, tenant := range tenants {
+		allPaths = append(allPaths, tenantPaths[tenant])
+	}
+	dataFuncsMap, keyProblems := resolveDataFuncsMap(opts.DataFuncsMap, getSrcPathPatterns(allPaths...))
+	problems = append(problems, keyProblems...)
+	for i, paths := range allPaths {
+		tenant := ""
+		if i > 0 {
+			tenant = tenants[i-1]
+		}
+		for _, deadRoute := range FindDeadRoutes(paths) {
+			problems = append(problems, fmt.Errorf("%w: %s is shadowed by %s%s",
+				ErrDeadRoute, deadRoute.SrcPath, deadRoute.ShadowedBy, getTenantSuffix(tenant)))
+		}
+		if err := setPathClientEntries(paths, opts.ClientEntries, dataFuncsMap); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	for _, pattern := range getSortedKeys(dataFuncsMap) {
+		isBound := slices.ContainsFunc(allPaths, func(paths []JSONSafePath) bool {
+			return slices.ContainsFunc(paths, func(path JSONSafePath) bool {
+				return path.Pattern == pattern
+			})
+		})
+		if !isBound {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrUnboundDataFuncs, pattern))
+		}
+	}
+
+	if opts.WithTypeScript || opts.GeneratedTSOutDir != "" {
+		problems = append(problems, validateTypeScript(opts)...)
+	}
+	if len(problems) > 0 {
+		return &BuildValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validateTypeScript checks that GenerateTypeScript would succeed, and that
+// every action has the types it needs, generating into a temporary directory
+func validateTypeScript(opts BuildOptions) []error {
+	var problems []error
+	for _, pattern := range getSortedKeys(opts.DataFuncsMap) {
+		dataFuncs := opts.DataFuncsMap[pattern]
+		if dataFuncs.Action != nil && (dataFuncs.ActionInput == nil || dataFuncs.ActionOutput == nil) {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrMissingActionTypes, pattern))
+		}
+	}
+	tmpDir, err := os.MkdirTemp("", "hwy-dry-run-")
+	if err != nil {
+		return append(problems, err)
+	}
+	defer os.RemoveAll(tmpDir)
+	opts.GeneratedTSOutDir = tmpDir
+	if err := GenerateTypeScript(opts); err != nil {
+		problems = append(problems, err)
+	}
+	return problems
+}
+
+func getTenantSuffix(tenant string) string {
+	if tenant == "" {
+		return ""
+	}
+	return " (tenant " + tenant + ")"
+}
@@ -0,0 +1,65 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDryRunBuild(t *testing.T) {
+	dir := "../tmp/dryrun"
+	files := map[string]string{
+		"client.entry.ts":         `console.log("entry")`,
+		"pages/lion.ui.ts":        `export default "lion"`,
+		"pages/users/$id.ui.ts":   `export default "id"`,
+		"pages/users/$name.ui.ts": `export default "name"`,
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(dir, "out")
+	opts := BuildOptions{
+		PagesSrcDir:    filepath.Join(dir, "pages"),
+		HashedOutDir:   out,
+		UnhashedOutDir: out,
+		ClientEntryOut: out,
+		ClientEntry:    filepath.Join(dir, "client.entry.ts"),
+		IsDev:          true,
+		DryRun:         true,
+		Logger:         NewSlogLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		BeforeBuild: func(string) error {
+			t.Error("expected no hooks to run")
+			return nil
+		},
+	}
+
+	err := Build(opts)
+	var validationErr *BuildValidationError
+	// Routes of the same shape are both ambiguous and dead, as Build warns
+	if !errors.As(err, &validationErr) || len(validationErr.Problems) != 2 || !errors.Is(err, ErrDeadRoute) || !errors.Is(err, ErrAmbiguousRoutes) {
+		t.Fatalf("expected only the conflicting routes, got %v", err)
+	}
+
+	opts.ClientEntries = []SecondaryClientEntry{{Name: "marketing", SrcPath: filepath.Join(dir, "marketing.entry.ts")}}
+	opts.DataFuncsMap = DataFuncsMap{
+		"/lion":  {ClientEntry: "unknown"},
+		"/tiger": {},
+	}
+	opts.LegacyTargets = []string{"netscape4"}
+	err = Build(opts)
+	for _, sentinel := range []error{ErrDeadRoute, ErrMissingBuildSource, ErrUnknownClientEntry, ErrUnboundDataFuncs, ErrInvalidLegacyTarget} {
+		if !errors.Is(err, sentinel) {
+			t.Errorf("expected %v among the problems, got %v", sentinel, err)
+		}
+	}
+
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Error("expected a dry run not to write anything")
+	}
+}
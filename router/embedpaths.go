@@ -0,0 +1,34 @@
+package router
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Name of the constant BuildOptions.PathsGoFile declares, holding the paths
+// file for Hwy.EmbeddedPathsFile
+const EmbeddedPathsFileConst = "HwyPathsFile"
+
+// writePathsGoFile writes a Go source file declaring EmbeddedPathsFileConst
+// as pathsJSON, so the paths file is compiled into the binary
+func writePathsGoFile(goFile string, goPackage string, buildID string, pathsJSON []byte) error {
+	if goPackage == "" {
+		goPackage = "main"
+	}
+	source := fmt.Sprintf("// Code generated by hwy build. DO NOT EDIT.\n\n"+
+		"package %s\n\n"+
+		"// %s is the paths file of build %s, for Hwy.EmbeddedPathsFile\n"+
+		"const %s = %s\n",
+		goPackage, EmbeddedPathsFileConst, buildID, EmbeddedPathsFileConst, strconv.Quote(string(pathsJSON)))
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(goFile), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(goFile, formatted, 0644)
+}
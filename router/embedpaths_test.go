@@ -0,0 +1,45 @@
+package router
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWritePathsGoFile(t *testing.T) {
+	pathsJSON := []byte("{\"paths\":[],\"buildID\":\"`1`\"}")
+	goFile := filepath.Join(t.TempDir(), "gen", "hwy_paths_gen.go")
+	if err := writePathsGoFile(goFile, "app", "1", pathsJSON); err != nil {
+		t.Fatal(err)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), goFile, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Name.Name != "app" {
+		t.Errorf("expected package app, got %s", file.Name.Name)
+	}
+	spec := file.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
+	value, err := strconv.Unquote(spec.Values[0].(*ast.BasicLit).Value)
+	if spec.Names[0].Name != EmbeddedPathsFileConst || err != nil || value != string(pathsJSON) {
+		t.Errorf("expected %s to hold the paths file, got %s = %q", EmbeddedPathsFileConst, spec.Names[0].Name, value)
+	}
+}
+
+func TestEmbeddedPathsFile(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+
+	pathsFileBytes, _ := json.Marshal(PathsFile{Paths: GetPathsFromPageFiles("tiger.ui.tsx"), BuildID: "embedded"})
+	h := Hwy{FS: fstest.MapFS{}, EmbeddedPathsFile: string(pathsFileBytes)}
+	if err := h.Initialize(); err != nil {
+		t.Fatalf("expected no hwy_paths.json to be read, got %v", err)
+	}
+	if instanceBuildID != "embedded" {
+		t.Errorf("expected the embedded paths file, got build %q", instanceBuildID)
+	}
+}
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestEnabled(t *testing.T) {
+	var gotParams map[string]string
+	setTestDataFuncs(t, "/tiger/$tiger_id/$tiger_cub_id", &DataFuncs{
+		Enabled: func(props *DataProps) bool {
+			gotParams = *props.Params
+			return props.Request.Header.Get("X-Flags") == "cubs"
+		},
+	})
+	setTestDataFuncs(t, "/bear", &DataFuncs{
+		Enabled: func(props *DataProps) bool { return false },
+	})
+
+	patterns := func(path string, flags string) []string {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("X-Flags", flags)
+		item, _ := lookupMatchingPathItem(r, nil)
+		var patterns []string
+		for _, path := range *item.FullyDecoratedMatchingPaths {
+			patterns = append(patterns, path.Pattern)
+		}
+		return patterns
+	}
+
+	enabled := patterns("/tiger/enabled/cub", "cubs")
+	if want := []string{"/tiger", "/tiger/$tiger_id", "/tiger/$tiger_id/$tiger_cub_id"}; !reflect.DeepEqual(enabled, want) {
+		t.Errorf("expected %v, got %v", want, enabled)
+	}
+	if gotParams["tiger_id"] != "enabled" || gotParams["tiger_cub_id"] != "cub" {
+		t.Errorf("expected the route's params, got %v", gotParams)
+	}
+
+	// Falls through to the sibling splat, whether matched fresh or cached
+	for range 2 {
+		disabled := patterns("/tiger/enabled/cub", "")
+		if want := []string{"/tiger", "/tiger/$tiger_id", "/tiger/$tiger_id/$"}; !reflect.DeepEqual(disabled, want) {
+			t.Errorf("expected %v, got %v", want, disabled)
+		}
+	}
+	if enabled := patterns("/tiger/enabled/cub", "cubs"); enabled[len(enabled)-1] != "/tiger/$tiger_id/$tiger_cub_id" {
+		t.Errorf("expected the cached enabled match to be kept, got %v", enabled)
+	}
+
+	// A disabled layout takes its children with it, leaving the catch route
+	if disabled := patterns("/bear/enabled", ""); !reflect.DeepEqual(disabled, []string{"/$"}) {
+		t.Errorf("expected only the catch route, got %v", disabled)
+	}
+}
+
+func TestEnabledOnlyForWinners(t *testing.T) {
+	var calls int
+	setTestDataFuncs(t, "/tiger/$tiger_id/$", &DataFuncs{
+		Enabled: func(props *DataProps) bool {
+			calls++
+			return true
+		},
+	})
+
+	item, _ := lookupMatchingPathItem(httptest.NewRequest("GET", "/tiger/123/cub", nil), nil)
+	if last := (*item.MatchingPaths)[len(*item.MatchingPaths)-1]; last.Pattern != "/tiger/$tiger_id/$tiger_cub_id" {
+		t.Fatalf("expected the cub route to win, got %s", last.Pattern)
+	}
+	if calls != 0 {
+		t.Errorf("expected a losing route's predicate not to run, got %d calls", calls)
+	}
+}
+
+func TestEnabledReadsCurrentDataFuncs(t *testing.T) {
+	lastPattern := func() string {
+		item, _ := lookupMatchingPathItem(httptest.NewRequest("GET", "/bear/123", nil), nil)
+		return (*item.MatchingPaths)[len(*item.MatchingPaths)-1].Pattern
+	}
+	if pattern := lastPattern(); pattern != "/bear/$bear_id" {
+		t.Fatalf("expected /bear/$bear_id, got %s", pattern)
+	}
+
+	// Swapped in after the match above was cached
+	setTestDataFuncs(t, "/bear", &DataFuncs{
+		Enabled: func(props *DataProps) bool { return false },
+	})
+	if pattern := lastPattern(); pattern != "/$" {
+		t.Errorf("expected the swapped in predicate to disable /bear, got %s", pattern)
+	}
+}
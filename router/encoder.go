@@ -0,0 +1,39 @@
+package router
+
+import (
+	"html/template"
+	"strings"
+)
+
+// Encoder marshals route data to JSON, for both JSON navigation responses and
+// the data embedded by GetSSRInnerHTML. Set Hwy.Encoder to swap in a faster
+// library or a custom marshaler; it must honor encoding/json struct tags.
+type Encoder interface {
+	Marshal(v any) ([]byte, error)
+}
+
+// EncoderFunc adapts a marshal func (e.g. sonic.Marshal) to an Encoder
+type EncoderFunc func(v any) ([]byte, error)
+
+func (f EncoderFunc) Marshal(v any) ([]byte, error) {
+	return f(v)
+}
+
+var scriptUnsafeReplacer = strings.NewReplacer(
+	"<", `\u003c`,
+	">", `\u003e`,
+	"&", `\u0026`,
+	"\u2028", `\u2028`,
+	"\u2029", `\u2029`,
+)
+
+// encodeScriptJS encodes v for embedding in a <script>, escaping anything a
+// custom encoder may have left that could end the script early. These can
+// only occur inside JSON strings, where the escapes are equivalent.
+func encodeScriptJS(encoder Encoder, v any) (template.JS, error) {
+	jsonBytes, err := encoder.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(scriptUnsafeReplacer.Replace(string(jsonBytes))), nil
+}
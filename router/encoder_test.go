@@ -0,0 +1,54 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCustomEncoder(t *testing.T) {
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return "</script><script>alert(1)</script>", nil
+		},
+	})
+
+	var calls atomic.Int32
+	// Like many fast encoders, doesn't escape HTML
+	encoder := EncoderFunc(func(v any) ([]byte, error) {
+		calls.Add(1)
+		var buf bytes.Buffer
+		e := json.NewEncoder(&buf)
+		e.SetEscapeHTML(false)
+		err := e.Encode(v)
+		return bytes.TrimSpace(buf.Bytes()), err
+	})
+	h := Hwy{Encoder: encoder}
+
+	w := httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/lion/encoder-test?"+HwyPrefix+"json=1", nil))
+	if calls.Load() != 1 || !strings.Contains(w.Body.String(), `"</script><script>alert(1)</script>"`) {
+		t.Errorf("expected JSON response from custom encoder, got %d calls: %s", calls.Load(), w.Body.String())
+	}
+
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/encoder-test", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ssrInnerHTML, err := GetSSRInnerHTML(routeData, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 4 {
+		t.Errorf("expected SSR data from custom encoder, got %d calls", calls.Load())
+	}
+	if strings.Contains(string(*ssrInnerHTML), "</script><script>") {
+		t.Errorf("expected unsafe characters escaped: %s", *ssrInnerHTML)
+	}
+	if !strings.Contains(string(*ssrInnerHTML), `x.loadersData = [null,"\u003c/script\u003e`) {
+		t.Errorf("expected escaped loaders data: %s", *ssrInnerHTML)
+	}
+}
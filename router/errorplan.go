@@ -0,0 +1,67 @@
+package router
+
+// ErrorRenderPlan describes how a request whose loaders or action failed is
+// rendered: every matched path from the outermost failing one downward is
+// dropped, so the closest error boundary can render in its place.
+type ErrorRenderPlan struct {
+	// Index of the outermost failing matched path (an action error counts as
+	// a failure of the last path, unless a loader failed first)
+	ErrorIndex int
+	Phase      ErrorPhase
+	Err        error
+	// Sent to the client as OutermostErrorBoundaryIndex. -1 means nothing can
+	// catch the error.
+	BoundaryIndex int
+}
+
+// newErrorRenderPlan returns nil when there are no errors
+func newErrorRenderPlan(loaderErrors []error, actionError error, loadersData []any) *ErrorRenderPlan {
+	plan := &ErrorRenderPlan{ErrorIndex: -1}
+	if i := getOutermostErrorIndex(loaderErrors); i != -1 {
+		plan.ErrorIndex, plan.Phase, plan.Err = i, ErrorPhaseLoader, loaderErrors[i]
+	} else if actionError != nil {
+		plan.ErrorIndex, plan.Phase, plan.Err = len(loadersData)-1, ErrorPhaseAction, actionError
+	}
+	if plan.ErrorIndex < 0 {
+		return nil
+	}
+	plan.BoundaryIndex = findClosestParentErrorBoundaryIndex(loadersData, plan.ErrorIndex)
+	if plan.BoundaryIndex != -1 {
+		plan.BoundaryIndex = plan.ErrorIndex - plan.BoundaryIndex
+	}
+	return plan
+}
+
+// apply truncates every per-path slice of a to the failing path (inclusive),
+// so they all stay the same length. The failing path keeps its import URL
+// (for its error boundary) but gets no loader data, head, or (if the action
+// failed) action data.
+func (p *ErrorRenderPlan) apply(a *ActivePathData, item *gmpdItem) {
+	n := p.ErrorIndex + 1
+
+	matchingPaths := append([]*DecoratedPath(nil), (*a.MatchingPaths)[:n]...)
+	importURLs := append([]string(nil), (*a.ImportURLs)[:n]...)
+	loadersData := append([]any(nil), (*a.LoadersData)[:n]...)
+	actionData := append([]any(nil), (*a.ActionData)[:n]...)
+	activeHeads := append([]Head(nil), (*a.ActiveHeads)[:n]...)
+
+	loadersData[p.ErrorIndex] = nil
+	activeHeads[p.ErrorIndex] = nil
+	if p.Phase == ErrorPhaseAction {
+		actionData[p.ErrorIndex] = nil
+	}
+
+	a.MatchingPaths = &matchingPaths
+	a.ImportURLs = &importURLs
+	a.LoadersData = &loadersData
+	a.ActionData = &actionData
+	a.ActiveHeads = &activeHeads
+	a.OutermostErrorBoundaryIndex = p.BoundaryIndex
+	a.ErrorRenderPlan = p
+
+	if item.MatchingPaths != nil && len(*item.MatchingPaths) >= n {
+		keptPaths := (*item.MatchingPaths)[:n]
+		deps := GetDeps(&keptPaths)
+		a.Deps = &deps
+	}
+}
@@ -0,0 +1,115 @@
+package router
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestErrorItem returns three nested paths, with loaders failing at
+// failingLoaderIndex (-1 for none) and an action on the last path
+func newTestErrorItem(failingLoaderIndex int, action Action) *gmpdItem {
+	paths := make([]*DecoratedPath, 0, 3)
+	importURLs := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		dataFuncs := &DataFuncs{
+			Loader: func(props *LoaderProps) (any, error) {
+				if i == failingLoaderIndex {
+					return nil, errors.New("loader failed")
+				}
+				return i, nil
+			},
+		}
+		if i == 2 {
+			dataFuncs.Action = action
+		}
+		paths = append(paths, &DecoratedPath{DataFuncs: dataFuncs})
+		importURLs = append(importURLs, "/"+string(rune('a'+i)))
+	}
+	return &gmpdItem{
+		FullyDecoratedMatchingPaths: &paths,
+		ImportURLs:                  &importURLs,
+		Params:                      &map[string]string{},
+		SplatSegments:               &[]string{},
+		Deps:                        &[]string{},
+	}
+}
+
+func assertConsistentLengths(t *testing.T, a *ActivePathData, expected int) {
+	t.Helper()
+	lengths := []int{len(*a.MatchingPaths), len(*a.ImportURLs), len(*a.LoadersData), len(*a.ActionData), len(*a.ActiveHeads)}
+	for _, length := range lengths {
+		if length != expected {
+			t.Errorf("expected all per-path slices to have length %d, got %v", expected, lengths)
+			return
+		}
+	}
+}
+
+func TestErrorRenderPlanLoaderErrorAtEachDepth(t *testing.T) {
+	for depth := 0; depth < 3; depth++ {
+		r := httptest.NewRequest("GET", "/", nil)
+		a := Hwy{}.getMatchingPathData(httptest.NewRecorder(), r, newTestErrorItem(depth, nil), nil)
+
+		plan := a.ErrorRenderPlan
+		if plan == nil || plan.ErrorIndex != depth || plan.Phase != ErrorPhaseLoader {
+			t.Fatalf("depth %d: unexpected plan %+v", depth, plan)
+		}
+		assertConsistentLengths(t, a, depth+1)
+		if (*a.LoadersData)[depth] != nil {
+			t.Errorf("depth %d: expected no loader data for the failing path", depth)
+		}
+		for i := 0; i < depth; i++ {
+			if (*a.LoadersData)[i] != i {
+				t.Errorf("depth %d: expected parent loader data %d to be kept, got %v", depth, i, (*a.LoadersData)[i])
+			}
+		}
+		if a.OutermostErrorBoundaryIndex != plan.BoundaryIndex {
+			t.Errorf("depth %d: expected boundary index %d, got %d", depth, plan.BoundaryIndex, a.OutermostErrorBoundaryIndex)
+		}
+	}
+}
+
+func TestErrorRenderPlanActionErrors(t *testing.T) {
+	failingAction := func(props *ActionProps) (any, error) {
+		return nil, errors.New("action failed")
+	}
+	okAction := func(props *ActionProps) (any, error) {
+		return "ok", nil
+	}
+
+	tests := []struct {
+		name               string
+		failingLoaderIndex int
+		action             Action
+		expectedIndex      int
+		expectedPhase      ErrorPhase
+		expectedActionData any
+	}{
+		{"action error only", -1, failingAction, 2, ErrorPhaseAction, nil},
+		{"loader error before action error", 1, failingAction, 1, ErrorPhaseLoader, nil},
+		{"loader error on action path", 2, okAction, 2, ErrorPhaseLoader, "ok"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/", nil)
+		a := Hwy{}.getMatchingPathData(httptest.NewRecorder(), r, newTestErrorItem(tt.failingLoaderIndex, tt.action), nil)
+
+		plan := a.ErrorRenderPlan
+		if plan == nil || plan.ErrorIndex != tt.expectedIndex || plan.Phase != tt.expectedPhase {
+			t.Fatalf("%s: unexpected plan %+v", tt.name, plan)
+		}
+		assertConsistentLengths(t, a, tt.expectedIndex+1)
+		if got := (*a.ActionData)[tt.expectedIndex]; got != tt.expectedActionData {
+			t.Errorf("%s: expected action data %v, got %v", tt.name, tt.expectedActionData, got)
+		}
+	}
+}
+
+func TestErrorRenderPlanNoErrors(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	a := Hwy{}.getMatchingPathData(httptest.NewRecorder(), r, newTestErrorItem(-1, nil), nil)
+	if a.ErrorRenderPlan != nil || a.OutermostErrorBoundaryIndex != -2 {
+		t.Errorf("expected no error plan, got %+v (boundary %d)", a.ErrorRenderPlan, a.OutermostErrorBoundaryIndex)
+	}
+	assertConsistentLengths(t, a, 3)
+}
@@ -0,0 +1,71 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+type ErrorPhase string
+
+const (
+	ErrorPhaseGuard   ErrorPhase = "guard"
+	ErrorPhaseLoader  ErrorPhase = "loader"
+	ErrorPhaseAction  ErrorPhase = "action"
+	ErrorPhaseHead    ErrorPhase = "head"
+	ErrorPhaseHeaders ErrorPhase = "headers"
+
+	ErrorPhaseEventStream ErrorPhase = "event-stream"
+	ErrorPhaseWebSocket   ErrorPhase = "websocket"
+	ErrorPhaseJob         ErrorPhase = "job"
+)
+
+// ErrorReport is passed to Hwy.OnError
+type ErrorReport struct {
+	Request *http.Request // nil for ErrorPhaseJob
+	Pattern string        // the job name for ErrorPhaseJob
+	Phase   ErrorPhase
+	Err     error
+	// Only set for recovered panics
+	Stack []byte
+}
+
+// PanicError wraps a value recovered from a panicking data func, which is
+// then handled like any other error returned by that func
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// getErrorStatusCode returns the status of the first error in err's chain
+// with a StatusCode method (like *ParamError), or 0 if there is none
+func getErrorStatusCode(err error) int {
+	var statusErr interface{ StatusCode() int }
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode()
+	}
+	return 0
+}
+
+// callDataFunc calls fn, converting panics into a *PanicError and reporting
+// any error (other than NotFound, Gone, and PermanentRedirect) to Hwy.OnError
+func callDataFunc[T any](h Hwy, r *http.Request, pattern string, phase ErrorPhase, fn func() (T, error)) (result T, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = &PanicError{Value: recovered, Stack: debug.Stack()}
+		}
+		if err != nil && h.OnError != nil && !getIsContentLifecycleError(err) {
+			report := &ErrorReport{Request: r, Pattern: pattern, Phase: phase, Err: err}
+			if panicErr, ok := err.(*PanicError); ok {
+				report.Stack = panicErr.Stack
+			}
+			h.OnError(report)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,50 @@
+package router
+
+import (
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestOnErrorReportsErrorsAndPanics(t *testing.T) {
+	setTestDataFuncs(t, "/lion", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			panic("kaboom")
+		},
+	})
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return nil, errors.New("boom")
+		},
+	})
+
+	var mu sync.Mutex
+	var reports []*ErrorReport
+	h := Hwy{OnError: func(report *ErrorReport) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, report)
+	}}
+	r := httptest.NewRequest("GET", "/lion/on-error-test", nil)
+	if _, err := h.GetRouteData(httptest.NewRecorder(), r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(reports))
+	}
+	for _, report := range reports {
+		if report.Phase != ErrorPhaseLoader || report.Request != r {
+			t.Errorf("unexpected report: %+v", report)
+		}
+		var panicErr *PanicError
+		isPanic := errors.As(report.Err, &panicErr)
+		if isPanic != (report.Pattern == "/lion") {
+			t.Errorf("expected only /lion to panic, got %s: %v", report.Pattern, report.Err)
+		}
+		if isPanic && len(report.Stack) == 0 {
+			t.Errorf("expected a stack for the recovered panic")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package router
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEscapedPageFileSegments(t *testing.T) {
+	for pageFile, expected := range map[string]string{
+		"[$]index.ui.tsx":        "/%24index",
+		"docs/[_]index.ui.tsx":   "/docs/%5Findex",
+		"[__]drafts/post.ui.tsx": "/__drafts/post",
+		"__hidden/[$]pay.ui.tsx": "/%24pay",
+		"docs/[_]private.ui.tsx": "/docs/_private",
+	} {
+		path := GetPathsFromPageFiles(pageFile)[0]
+		if path.Pattern != expected || path.PathType != PathTypeStaticLayout {
+			t.Errorf("%s: expected static layout %s, got %s %s", pageFile, expected, path.PathType, path.Pattern)
+		}
+	}
+
+	withRouteTable(t, []string{
+		"_index.ui.tsx",
+		"$.ui.tsx",
+		"$id.ui.tsx",
+		"[$]index.ui.tsx",
+		"docs.ui.tsx",
+		"docs/_index.ui.tsx",
+		"docs/[_]index.ui.tsx",
+	})
+	for url, expected := range map[string]string{
+		"/$index":      "/%24index",
+		"/%24index":    "/%24index",
+		"/other":       "/$id",
+		"/docs":        "/docs/_index",
+		"/docs/_index": "/docs/%5Findex",
+	} {
+		item := getMatchingPathItem(httptest.NewRequest("GET", url, nil))
+		matchingPaths := *item.MatchingPaths
+		if got := matchingPaths[len(matchingPaths)-1].Pattern; got != expected {
+			t.Errorf("%s: expected %s, got %s", url, expected, got)
+		}
+	}
+
+	item := getMatchingPathItem(httptest.NewRequest("GET", "/a$b", nil))
+	if (*item.Params)["id"] != "a$b" {
+		t.Errorf("expected params to keep a literal $, got %v", *item.Params)
+	}
+}
@@ -0,0 +1,209 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventStream serves Server-Sent Events to requests for its route that
+// accept "text/event-stream". It should return once Context is done.
+type EventStream func(*EventStreamProps) error
+
+type EventStreamProps struct {
+	DataProps
+	Context context.Context
+	// From the Last-Event-ID header, sent by browsers when reconnecting, so
+	// the stream can resume after the last event the client received
+	LastEventID string
+	Events      *EventWriter
+}
+
+type Event struct {
+	ID    string
+	Event string // the event type; browsers default to "message"
+	// Strings and byte slices are sent as is, anything else as JSON
+	Data any
+	// Tells the browser how long to wait before reconnecting
+	Retry time.Duration
+}
+
+// EventWriter writes events (and keep-alive comments) to the client. It is
+// safe for concurrent use.
+type EventWriter struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (e *EventWriter) Send(event Event) error {
+	var sb strings.Builder
+	if event.ID != "" {
+		sb.WriteString("id: " + stripNewlines(event.ID) + "\n")
+	}
+	if event.Event != "" {
+		sb.WriteString("event: " + stripNewlines(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		sb.WriteString(fmt.Sprintf("retry: %d\n", event.Retry.Milliseconds()))
+	}
+	var data string
+	switch d := event.Data.(type) {
+	case nil:
+	case string:
+		data = d
+	case []byte:
+		data = string(d)
+	default:
+		dataBytes, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		data = string(dataBytes)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+	return e.write(sb.String())
+}
+
+func (e *EventWriter) write(s string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := e.w.Write([]byte(s)); err != nil {
+		return err
+	}
+	return e.rc.Flush()
+}
+
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\n", "", "\r", "").Replace(s)
+}
+
+// GetIsEventStreamRequest reports whether r accepts Server-Sent Events
+func GetIsEventStreamRequest(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func (h Hwy) getEventStreamKeepAlive() time.Duration {
+	if h.EventStreamKeepAlive > 0 {
+		return h.EventStreamKeepAlive
+	}
+	return 15 * time.Second
+}
+
+// streamRoute is the deepest matched route with a streaming handler
+type streamRoute struct {
+	r         *http.Request // after locale resolution and rewrites
+	path      *DecoratedPath
+	dataProps DataProps
+	served    bool // a guard outcome or error was already written
+}
+
+// matchStreamRoute matches r and runs its guards as usual, returning false
+// (having written nothing) if no matched route satisfies hasHandler
+func (h Hwy) matchStreamRoute(w http.ResponseWriter, r *http.Request, hasHandler func(*DataFuncs) bool) (*streamRoute, bool) {
+	// Leave redirects to the regular handler
+	if outcome, err := h.getRedirectRuleOutcome(r); outcome != nil || err != nil {
+		return nil, false
+	}
+	r, locale, localeOutcome := h.resolveLocale(w, r)
+	if localeOutcome != nil {
+		return nil, false
+	}
+	localeFreePath := r.URL.Path
+	r = h.applyRewriteRules(r)
+
+	item := getMatchingPathItem(r)
+	route := &streamRoute{r: r}
+	for _, path := range *item.FullyDecoratedMatchingPaths {
+		if path.DataFuncs != nil && hasHandler(path.DataFuncs) {
+			route.path = path
+		}
+	}
+	if route.path == nil {
+		return nil, false
+	}
+
+	scope, err := h.newRequestScope(r, locale, localeFreePath)
+	if err != nil {
+		h.getLogger().Error("error getting request services", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		route.served = true
+		return route, true
+	}
+	guardOutcome, err := h.runGuards(r, item, scope)
+	if err != nil {
+		h.getLogger().Error("guard error", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		route.served = true
+		return route, true
+	}
+	if guardOutcome != nil {
+		guardOutcome.serve(w, r)
+		route.served = true
+		return route, true
+	}
+	route.dataProps = scope.newDataProps(r, item.Params, item.SplatSegments)
+	return route, true
+}
+
+// serveEventStream streams events from the deepest matched route with an
+// EventStream. It returns false (having written nothing) if there is none.
+func (h Hwy) serveEventStream(w http.ResponseWriter, r *http.Request) (string, bool) {
+	route, ok := h.matchStreamRoute(w, r, func(d *DataFuncs) bool { return d.EventStream != nil })
+	if !ok {
+		return "", false
+	}
+	streamPath := route.path
+	if route.served {
+		return streamPath.Pattern, true
+	}
+	r = route.r
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	events := &EventWriter{w: w, rc: http.NewResponseController(w)}
+	if err := events.rc.Flush(); err != nil {
+		h.getLogger().Error("event stream flushing unsupported", "error", err)
+		return streamPath.Pattern, true
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		ticker := time.NewTicker(h.getEventStreamKeepAlive())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if events.write(": keep-alive\n\n") != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	_, err := callDataFunc(h, r, streamPath.Pattern, ErrorPhaseEventStream, func() (any, error) {
+		return nil, streamPath.DataFuncs.EventStream(&EventStreamProps{
+			DataProps:   route.dataProps,
+			Context:     ctx,
+			LastEventID: r.Header.Get("Last-Event-ID"),
+			Events:      events,
+		})
+	})
+	if err != nil && ctx.Err() == nil {
+		h.getLogger().Error("event stream error", "error", err)
+	}
+	return streamPath.Pattern, true
+}
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStream(t *testing.T) {
+	setTestDataFuncs(t, "/tiger/$tiger_id", &DataFuncs{
+		EventStream: func(props *EventStreamProps) error {
+			if err := props.Events.Send(Event{ID: "2", Data: "a\nb"}); err != nil {
+				return err
+			}
+			<-time.After(30 * time.Millisecond)
+			return props.Events.Send(Event{
+				Event: "update",
+				Data:  map[string]string{"id": (*props.Params)["tiger_id"], "after": props.LastEventID},
+			})
+		},
+	})
+
+	r := httptest.NewRequest("GET", "/tiger/events-1", nil)
+	r.Header.Set("Accept", "text/event-stream")
+	r.Header.Set("Last-Event-ID", "1")
+	w := httptest.NewRecorder()
+	Hwy{EventStreamKeepAlive: 10 * time.Millisecond}.GetRootHandler().ServeHTTP(w, r)
+
+	if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("expected content type text/event-stream, got %q", got)
+	}
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "id: 2\ndata: a\ndata: b\n\n") {
+		t.Errorf("expected first event with split data lines, got %q", body)
+	}
+	if !strings.Contains(body, ": keep-alive\n\n") {
+		t.Errorf("expected keep-alive comment, got %q", body)
+	}
+	if !strings.HasSuffix(body, "event: update\ndata: {\"after\":\"1\",\"id\":\"events-1\"}\n\n") {
+		t.Errorf("expected JSON event with last event ID, got %q", body)
+	}
+}
+
+func TestEventStreamFallsThroughWithoutStream(t *testing.T) {
+	r := httptest.NewRequest("GET", "/lion", nil)
+	r.Header.Set("Accept", "text/event-stream")
+	if _, served := (Hwy{}).serveEventStream(httptest.NewRecorder(), r); served {
+		t.Error("expected route without an event stream not to be served as one")
+	}
+}
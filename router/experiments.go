@@ -0,0 +1,253 @@
+package router
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Experiment is an A/B test, with each visitor assigned one variant
+type Experiment struct {
+	Name string
+	// e.g. []string{"control", "b"}
+	Variants []string
+	// Relative weights, parallel to Variants. Defaults to an even split.
+	Weights []int
+}
+
+// Experiments assigns every request a variant of each experiment, kept
+// sticky with a cookie per experiment. Assignments are available to data
+// funcs via DataProps.GetVariant and to the client via the "experiments"
+// AdHocData key. A route naming an experiment in DataFuncs.Experiment is
+// served from its variant page file (e.g. "checkout~b.ui.tsx" beside
+// "checkout.ui.tsx"), where one exists for the assigned variant.
+type Experiments struct {
+	Experiments []Experiment
+	// Optional, e.g. to bucket signed-in users by ID. Its assignments aren't
+	// stored in cookies. Returning "" (or an unknown variant) falls back to
+	// the cookie, then to a random assignment.
+	Assign func(r *http.Request, experiment *Experiment) string
+	// Defaults to "hwy_exp_", followed by the experiment's name
+	CookiePrefix string
+	// Defaults to 30 days
+	CookieMaxAge time.Duration
+}
+
+// ExperimentMetricsCollector is optionally implemented by a MetricsCollector
+// to count exposures: requests whose response depended on an experiment's
+// variant, via DataProps.GetVariant or a variant page file. Each experiment
+// is reported at most once per request.
+type ExperimentMetricsCollector interface {
+	ObserveExposure(experiment string, variant string)
+}
+
+func (c *PrometheusCollector) ObserveExposure(experiment string, variant string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.exposures[[2]string{experiment, variant}]++
+}
+
+// experimentAssignments holds a request's variants, keyed by experiment
+type experimentAssignments struct {
+	variants  map[string]string
+	collector ExperimentMetricsCollector
+
+	mu      sync.Mutex
+	exposed map[string]bool
+}
+
+// expose reports the experiment's exposure, once per request
+func (a *experimentAssignments) expose(experiment string) {
+	if a.collector == nil {
+		return
+	}
+	a.mu.Lock()
+	if a.exposed[experiment] {
+		a.mu.Unlock()
+		return
+	}
+	a.exposed[experiment] = true
+	a.mu.Unlock()
+	a.collector.ObserveExposure(experiment, a.variants[experiment])
+}
+
+// GetVariant returns the request's variant of the experiment (see
+// Hwy.Experiments), or "" if there is no such experiment. Calling it counts
+// as an exposure.
+func (p DataProps) GetVariant(experiment string) string {
+	if p.scope == nil || p.scope.experiments == nil {
+		return ""
+	}
+	variant, ok := p.scope.experiments.variants[experiment]
+	if ok {
+		p.scope.experiments.expose(experiment)
+	}
+	return variant
+}
+
+func (e *Experiments) cookieName(experiment string) string {
+	prefix := e.CookiePrefix
+	if prefix == "" {
+		prefix = "hwy_exp_"
+	}
+	return prefix + experiment
+}
+
+// assignExperiments assigns the request a variant of each experiment, setting
+// cookies (when w isn't nil) for any new assignments
+func (h Hwy) assignExperiments(w http.ResponseWriter, r *http.Request, scope *requestScope) {
+	if h.Experiments == nil || len(h.Experiments.Experiments) == 0 {
+		return
+	}
+	e := h.Experiments
+	assignments := &experimentAssignments{
+		variants: make(map[string]string, len(e.Experiments)),
+		exposed:  make(map[string]bool),
+	}
+	if collector, ok := h.Metrics.(ExperimentMetricsCollector); ok {
+		assignments.collector = collector
+	}
+	maxAge := e.CookieMaxAge
+	if maxAge == 0 {
+		maxAge = 30 * 24 * time.Hour
+	}
+	for i := range e.Experiments {
+		experiment := &e.Experiments[i]
+		if len(experiment.Variants) == 0 {
+			continue
+		}
+		cookieName := e.cookieName(experiment.Name)
+		var variant string
+		if e.Assign != nil {
+			variant = e.Assign(r, experiment)
+		}
+		if !slices.Contains(experiment.Variants, variant) {
+			variant = ""
+			if cookie, err := r.Cookie(cookieName); err == nil && slices.Contains(experiment.Variants, cookie.Value) {
+				variant = cookie.Value
+			}
+		}
+		if variant == "" {
+			variant = experiment.pickVariant()
+			if w != nil {
+				http.SetCookie(w, &http.Cookie{
+					Name:     cookieName,
+					Value:    variant,
+					Path:     "/",
+					MaxAge:   int(maxAge.Seconds()),
+					HttpOnly: true,
+					Secure:   r.TLS != nil,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+		}
+		assignments.variants[experiment.Name] = variant
+	}
+	scope.experiments = assignments
+	scope.adHocData["experiments"] = assignments.variants
+}
+
+// pickVariant picks a variant at random, by weight
+func (e *Experiment) pickVariant() string {
+	if len(e.Weights) != len(e.Variants) {
+		return e.Variants[rand.IntN(len(e.Variants))]
+	}
+	total := 0
+	for _, weight := range e.Weights {
+		total += max(weight, 0)
+	}
+	if total == 0 {
+		return e.Variants[0]
+	}
+	n := rand.IntN(total)
+	for i, weight := range e.Weights {
+		n -= max(weight, 0)
+		if n < 0 {
+			return e.Variants[i]
+		}
+	}
+	return e.Variants[len(e.Variants)-1]
+}
+
+// PathVariant is a route's page file for one variant of its experiment
+type PathVariant struct {
+	SrcPath     string    `json:"srcPath"`
+	OutPath     string    `json:"outPath"`
+	Deps        *[]string `json:"deps"`
+	CSSBundle   string    `json:"cssBundle,omitempty"`
+	CriticalCSS string    `json:"criticalCSS,omitempty"`
+}
+
+// variantDelimiter separates a page file's route from its variant, as in
+// "checkout~b.ui.tsx"
+const variantDelimiter = "~"
+
+// mergeVariantPaths folds variant page files into the Variants of the page
+// file for the same pattern. Variants without such a page file are dropped.
+func mergeVariantPaths(paths []JSONSafePath) []JSONSafePath {
+	merged := make([]JSONSafePath, 0, len(paths))
+	var variantPaths []JSONSafePath
+	for _, path := range paths {
+		if path.variant == "" {
+			merged = append(merged, path)
+		} else {
+			variantPaths = append(variantPaths, path)
+		}
+	}
+	for _, variantPath := range variantPaths {
+		i := slices.IndexFunc(merged, func(path JSONSafePath) bool { return path.Pattern == variantPath.Pattern })
+		if i == -1 {
+			continue
+		}
+		if merged[i].Variants == nil {
+			merged[i].Variants = make(map[string]*PathVariant)
+		}
+		merged[i].Variants[variantPath.variant] = &PathVariant{SrcPath: variantPath.SrcPath}
+	}
+	return merged
+}
+
+// cutVariant splits e.g. "/checkout~b" into "/checkout" and "b"
+func cutVariant(pattern string) (string, string) {
+	lastSlash := strings.LastIndex(pattern, "/")
+	i := strings.LastIndex(pattern[lastSlash+1:], variantDelimiter)
+	if i == -1 {
+		return pattern, ""
+	}
+	i += lastSlash + 1
+	return pattern[:i], pattern[i+len(variantDelimiter):]
+}
+
+// getVariantSelection returns the variants, by pattern, to serve the variant
+// paths with for the request's experiment assignments, counting them as
+// exposures
+func getVariantSelection(scope *requestScope, variantPaths []*MatchingPath) map[string]string {
+	if scope == nil || scope.experiments == nil {
+		return nil
+	}
+	var selection map[string]string
+	for _, path := range variantPaths {
+		variant := scope.experiments.variants[path.DataFuncs.Experiment]
+		if _, ok := path.Variants[variant]; !ok {
+			continue
+		}
+		if selection == nil {
+			selection = make(map[string]string)
+		}
+		selection[path.Pattern] = variant
+		scope.experiments.expose(path.DataFuncs.Experiment)
+	}
+	return selection
+}
+
+// applyVariant serves path from its variant's page file
+func (path *MatchingPath) applyVariant(variant string) {
+	v := path.Variants[variant]
+	path.OutPath = v.OutPath
+	path.Deps = v.Deps
+	path.CSSBundle = v.CSSBundle
+	path.CriticalCSS = v.CriticalCSS
+}
@@ -0,0 +1,137 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testExposureCollector struct {
+	MetricsCollector
+	exposures []string
+}
+
+func (c *testExposureCollector) ObserveExposure(experiment string, variant string) {
+	c.exposures = append(c.exposures, experiment+"="+variant)
+}
+
+func TestVariantPageFiles(t *testing.T) {
+	paths := GetPathsFromPageFiles("checkout.ui.tsx", "checkout~b.ui.tsx", "orphan~b.ui.tsx", "shop/_index~new.ui.tsx", "shop/_index.ui.tsx")
+	if len(paths) != 2 {
+		t.Fatalf("expected variant page files to be merged, got %+v", paths)
+	}
+	if paths[0].Pattern != "/checkout" || paths[0].Variants["b"] == nil || paths[0].Variants["b"].SrcPath != "/checkout~b.ui.tsx" {
+		t.Errorf("unexpected checkout path: %+v", paths[0])
+	}
+	if paths[1].Pattern != "/shop/_index" || paths[1].Variants["new"] == nil {
+		t.Errorf("unexpected shop index path: %+v", paths[1])
+	}
+}
+
+func TestExperimentAssignment(t *testing.T) {
+	h := Hwy{Experiments: &Experiments{
+		Experiments: []Experiment{
+			{Name: "hero", Variants: []string{"control", "b"}, Weights: []int{0, 1}},
+			{Name: "pricing", Variants: []string{"monthly", "annual"}},
+		},
+		Assign: func(r *http.Request, experiment *Experiment) string {
+			if experiment.Name == "pricing" {
+				return r.Header.Get("X-Pricing")
+			}
+			return ""
+		},
+	}}
+	assign := func(r *http.Request) (map[string]string, []*http.Cookie) {
+		w := httptest.NewRecorder()
+		scope := &requestScope{adHocData: make(map[string]any)}
+		h.assignExperiments(w, r, scope)
+		return scope.experiments.variants, w.Result().Cookies()
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Pricing", "annual")
+	variants, cookies := assign(r)
+	if variants["hero"] != "b" || variants["pricing"] != "annual" {
+		t.Errorf("unexpected assignments: %v", variants)
+	}
+	// Custom assignments aren't stored
+	if len(cookies) != 1 || cookies[0].Name != "hwy_exp_hero" || cookies[0].Value != "b" ||
+		cookies[0].MaxAge != int((30*24*time.Hour).Seconds()) {
+		t.Errorf("expected sticky cookies for new assignments, got %v", cookies)
+	}
+
+	// Cookies are kept, even if the weights would now pick differently
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "hwy_exp_hero", Value: "control"})
+	r.AddCookie(&http.Cookie{Name: "hwy_exp_pricing", Value: "unknown"})
+	variants, cookies = assign(r)
+	if variants["hero"] != "control" || !slices.Contains([]string{"monthly", "annual"}, variants["pricing"]) {
+		t.Errorf("unexpected assignments: %v", variants)
+	}
+	if len(cookies) != 1 || cookies[0].Name != "hwy_exp_pricing" {
+		t.Errorf("expected only the invalid cookie to be replaced, got %v", cookies)
+	}
+}
+
+func TestExperimentRouteData(t *testing.T) {
+	var variants []string
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Experiment: "lion-page",
+		Loader: func(props *LoaderProps) (any, error) {
+			variants = append(variants, props.GetVariant("hero"), props.GetVariant("hero"), props.GetVariant("missing"))
+			return nil, nil
+		},
+	})
+	i := slices.IndexFunc(*instancePaths, func(path Path) bool { return path.Pattern == "/lion/$" })
+	original := (*instancePaths)[i]
+	t.Cleanup(func() { (*instancePaths)[i] = original })
+	(*instancePaths)[i].Variants = map[string]*PathVariant{
+		"b": {OutPath: "hwy_entry__lion_b.js", Deps: &[]string{"hwy_entry__lion_b.js"}},
+	}
+
+	collector := &testExposureCollector{MetricsCollector: NewPrometheusCollector()}
+	h := Hwy{
+		Metrics: collector,
+		Experiments: &Experiments{Experiments: []Experiment{
+			{Name: "hero", Variants: []string{"a"}},
+			{Name: "lion-page", Variants: []string{"control", "b"}},
+		}},
+	}
+	get := func(lionPage string) *GetRouteDataOutput {
+		r := httptest.NewRequest("GET", "/lion/experiment", nil)
+		r.AddCookie(&http.Cookie{Name: "hwy_exp_lion-page", Value: lionPage})
+		routeData, err := h.GetRouteData(httptest.NewRecorder(), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return routeData
+	}
+
+	routeData := get("b")
+	if got := (*routeData.ImportURLs)[1]; got != "/hwy_entry__lion_b.js" {
+		t.Errorf("expected the variant's page file, got %q", got)
+	}
+	if !slices.Contains(*routeData.Deps, "hwy_entry__lion_b.js") || slices.Contains(*routeData.Deps, original.OutPath) {
+		t.Errorf("expected the variant's deps, got %v", *routeData.Deps)
+	}
+	if assignments, ok := (*(*routeData.AdHocData)["experiments"]).(map[string]string); !ok || assignments["lion-page"] != "b" {
+		t.Errorf("expected assignments in AdHocData, got %v", routeData.AdHocData)
+	}
+	if strings.Join(variants, ",") != "a,a," {
+		t.Errorf("unexpected variants from GetVariant: %v", variants)
+	}
+	if strings.Join(collector.exposures, ",") != "lion-page=b,hero=a" {
+		t.Errorf("expected one exposure per experiment, got %v", collector.exposures)
+	}
+
+	collector.exposures = nil
+	if got := (*get("control").ImportURLs)[1]; got != "/"+original.OutPath {
+		t.Errorf("expected the base page file for a variant without one, got %q", got)
+	}
+	if strings.Join(collector.exposures, ",") != "hero=a" {
+		t.Errorf("expected no page exposure without a variant page file, got %v", collector.exposures)
+	}
+}
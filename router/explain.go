@@ -0,0 +1,107 @@
+package router
+
+import (
+	"fmt"
+)
+
+// MatchExplanation describes how a path was matched, for debugging route
+// precedence. See Hwy.ExplainMatch.
+type MatchExplanation struct {
+	Path          string           `json:"path"`
+	Segments      []string         `json:"segments"`
+	Candidates    []MatchCandidate `json:"candidates"`
+	MatchedRoutes []string         `json:"matchedRoutes"`
+	SplatSegments []string         `json:"splatSegments"`
+}
+
+// MatchCandidate is one route considered by the matcher
+type MatchCandidate struct {
+	Pattern  string            `json:"pattern"`
+	PathType string            `json:"pathType"`
+	Segments []string          `json:"segments"`
+	Score    int               `json:"score"`
+	Params   map[string]string `json:"params,omitempty"`
+	Kept     bool              `json:"kept"`
+	// Why the candidate was eliminated (empty when kept)
+	Reason string `json:"reason,omitempty"`
+}
+
+type matchExplainer struct {
+	reasons map[string]string
+}
+
+// eliminate records the first reason pattern was eliminated (nil-safe)
+func (e *matchExplainer) eliminate(path *MatchingPath, reason string) {
+	if e == nil {
+		return
+	}
+	if _, exists := e.reasons[path.Pattern]; !exists {
+		e.reasons[path.Pattern] = reason
+	}
+}
+
+// ExplainMatch returns every route considered for path, with its score and
+// segments, and why it was kept or eliminated. It bypasses the match cache
+// and is meant for development, not for serving requests.
+func (h Hwy) ExplainMatch(path string) *MatchExplanation {
+	realPath := path
+	if realPath != "/" && len(realPath) > 0 && realPath[len(realPath)-1] == '/' {
+		realPath = realPath[:len(realPath)-1]
+	}
+	explanation := &MatchExplanation{
+		Path:          realPath,
+		Segments:      *getBaseSplatSegments(realPath),
+		Candidates:    []MatchCandidate{},
+		MatchedRoutes: []string{},
+		SplatSegments: []string{},
+	}
+	if instancePaths == nil {
+		return explanation
+	}
+
+	explainer := &matchExplainer{reasons: make(map[string]string)}
+	initialMatchingPaths := getInitialMatchingPaths(instancePaths, realPath)
+	splatSegments, matchingPaths := explainMatchingPaths(initialMatchingPaths, realPath, explainer)
+	if splatSegments != nil {
+		explanation.SplatSegments = *splatSegments
+	}
+
+	kept := make(map[string]bool, len(*matchingPaths))
+	for _, matchingPath := range *matchingPaths {
+		kept[matchingPath.Pattern] = true
+		explanation.MatchedRoutes = append(explanation.MatchedRoutes, matchingPath.Pattern)
+	}
+
+	initialByPattern := make(map[string]MatchingPath, len(*initialMatchingPaths))
+	for _, matchingPath := range *initialMatchingPaths {
+		initialByPattern[matchingPath.Pattern] = matchingPath
+	}
+
+	for _, p := range *instancePaths {
+		candidate := MatchCandidate{
+			Pattern:  p.Pattern,
+			PathType: p.PathType,
+			Segments: *p.Segments,
+		}
+		initial, matched := initialByPattern[p.Pattern]
+		switch {
+		case !matched:
+			candidate.Reason = fmt.Sprintf("pattern does not match %s", realPath)
+		case kept[p.Pattern]:
+			candidate.Kept = true
+		default:
+			candidate.Reason = explainer.reasons[p.Pattern]
+			if candidate.Reason == "" {
+				candidate.Reason = "eliminated by route precedence"
+			}
+		}
+		if matched {
+			candidate.Score = initial.Score
+			if initial.Params != nil {
+				candidate.Params = *initial.Params
+			}
+		}
+		explanation.Candidates = append(explanation.Candidates, candidate)
+	}
+	return explanation
+}
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExplainMatch(t *testing.T) {
+	explanation := Hwy{}.ExplainMatch("/tiger/123/456/789")
+
+	expectedRoutes := []string{"/tiger", "/tiger/$tiger_id", "/tiger/$tiger_id/$"}
+	if !slices.Equal(explanation.MatchedRoutes, expectedRoutes) {
+		t.Errorf("expected matched routes %v, got %v", expectedRoutes, explanation.MatchedRoutes)
+	}
+	if !slices.Equal(explanation.SplatSegments, []string{"456", "789"}) {
+		t.Errorf("expected splat segments [456 789], got %v", explanation.SplatSegments)
+	}
+
+	candidates := make(map[string]MatchCandidate)
+	for _, candidate := range explanation.Candidates {
+		candidates[candidate.Pattern] = candidate
+	}
+	if c := candidates["/tiger/$tiger_id"]; !c.Kept || c.Reason != "" || c.Params["tiger_id"] != "123" {
+		t.Errorf("expected /tiger/$tiger_id to be kept with params, got %+v", c)
+	}
+	if c := candidates["/tiger/$tiger_id/$tiger_cub_id"]; c.Kept || c.Reason == "" {
+		t.Errorf("expected /tiger/$tiger_id/$tiger_cub_id to be eliminated with a reason, got %+v", c)
+	}
+	if c := candidates["/lion"]; c.Kept || c.Reason == "" {
+		t.Errorf("expected /lion not to match, got %+v", c)
+	}
+}
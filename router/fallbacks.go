@@ -0,0 +1,48 @@
+package router
+
+// getIsUnmatched reports whether nothing but the ultimate catch route matched
+func getIsUnmatched(item *gmpdItem) bool {
+	paths := *item.FullyDecoratedMatchingPaths
+	return len(paths) == 0 || (len(paths) == 1 && paths[0].PathType == PathTypeUltimateCatch)
+}
+
+// getFallbackItem builds a single-route, uncached item for the route with
+// the given pattern, or returns nil if no such route exists
+func getFallbackItem(pattern string, status int, splatSegments *[]string) *gmpdItem {
+	if instancePaths == nil {
+		return nil
+	}
+	for _, path := range *instancePaths {
+		if path.Pattern != pattern {
+			continue
+		}
+		matchingPaths := []*MatchingPath{{
+			Pattern:     path.Pattern,
+			Segments:    path.Segments,
+			PathType:    path.PathType,
+			DataFuncs:   path.DataFuncs,
+			OutPath:     path.OutPath,
+			Params:      &map[string]string{},
+			Deps:        path.Deps,
+			Handle:      path.Handle,
+			Static:      path.Static,
+			Islands:     path.Islands,
+			CSSBundle:   path.CSSBundle,
+			CriticalCSS: path.CriticalCSS,
+			ClientEntry: path.ClientEntry,
+		}}
+		importURLs := []string{"/" + path.OutPath}
+		deps := GetDeps(&matchingPaths)
+		return &gmpdItem{
+			SplatSegments:               splatSegments,
+			Params:                      &map[string]string{},
+			MatchingPaths:               &matchingPaths,
+			FullyDecoratedMatchingPaths: decoratePaths(&matchingPaths),
+			ImportURLs:                  &importURLs,
+			Deps:                        &deps,
+			IsFallback:                  true,
+			Status:                      status,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNotFoundRouteReplacesUltimateCatch(t *testing.T) {
+	h := Hwy{NotFoundRoute: "/articles/_index"}
+	r := httptest.NewRequest("GET", "/no-such-page", nil)
+
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if routeData.Status != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", routeData.Status)
+	}
+	if len(*routeData.ImportURLs) != 1 {
+		t.Errorf("expected only the not found route to render, got %v", *routeData.ImportURLs)
+	}
+}
+
+func TestErrorRouteRendersOutsideBoundary(t *testing.T) {
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return nil, errors.New("boom")
+		},
+	})
+
+	h := Hwy{ErrorRoute: "/articles/_index"}
+	r := httptest.NewRequest("GET", "/lion/error-route-test", nil)
+
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if routeData.Status != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", routeData.Status)
+	}
+	if len(*routeData.ImportURLs) != 1 {
+		t.Errorf("expected only the error route to render, got %v", *routeData.ImportURLs)
+	}
+}
@@ -0,0 +1,142 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Headers forwarded by Fetcher by default: W3C trace context and baggage,
+// and request IDs
+var DefaultForwardHeaders = []string{"Traceparent", "Tracestate", "Baggage", "X-Request-Id"}
+
+// Fetcher configures DataProps.Fetch (see Hwy.Fetcher), for loaders that are
+// thin wrappers over upstream APIs
+type Fetcher struct {
+	// Shared by every request. Defaults to http.DefaultClient.
+	Client *http.Client
+	// Incoming request headers copied onto upstream requests (when not set
+	// by the caller). Defaults to DefaultForwardHeaders.
+	ForwardHeaders []string
+	// Adds headers to upstream requests, e.g. an OpenTelemetry propagator's
+	// Inject, given the context of the fetch's span (see SpanFetch)
+	InjectHeaders func(ctx context.Context, header http.Header)
+	// Caps each upstream request, within the incoming request's deadline.
+	// Zero means no cap beyond that deadline.
+	Timeout time.Duration
+}
+
+// FetchResponse is a fully read upstream response. With deduplication, it
+// may be shared by several callers, so it must not be modified.
+type FetchResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// DecodeJSON unmarshals the body into v, failing on non-2xx statuses
+func (resp *FetchResponse) DecodeJSON(v any) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("upstream responded %d", resp.StatusCode)
+	}
+	return json.Unmarshal(resp.Body, v)
+}
+
+// Fetch makes an upstream request with the Hwy.Fetcher client, bounded by
+// the incoming request's context (so it's canceled along with it), and
+// carrying its trace and request ID headers. Identical GETs (same URL and
+// headers) are made once per request, like Memo, so loaders run
+// concurrently can each fetch what they need.
+func (p DataProps) Fetch(method, url string, header http.Header, body io.Reader) (*FetchResponse, error) {
+	if method != http.MethodGet {
+		return p.fetch(method, url, header, body)
+	}
+	value, err := p.Memo(getFetchMemoKey(url, header), func() (any, error) {
+		return p.fetch(method, url, header, nil)
+	})
+	resp, _ := value.(*FetchResponse)
+	return resp, err
+}
+
+func (p DataProps) fetch(method, url string, header http.Header, body io.Reader) (*FetchResponse, error) {
+	fetcher := &Fetcher{}
+	var tracerProvider TracerProvider
+	if p.scope != nil {
+		tracerProvider = p.scope.tracerProvider
+		if p.scope.fetcher != nil {
+			fetcher = p.scope.fetcher
+		}
+	}
+	ctx := context.Background()
+	if p.Request != nil {
+		ctx = p.Request.Context()
+	}
+	if fetcher.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, fetcher.Timeout)
+		defer cancel()
+	}
+	ctx, span := startSpan(tracerProvider, ctx, SpanFetch,
+		SpanAttribute{Key: "http.request.method", Value: method},
+		SpanAttribute{Key: "url.full", Value: url},
+	)
+	resp, err := fetcher.do(ctx, p.Request, method, url, header, body)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (fetcher *Fetcher) do(ctx context.Context, incoming *http.Request, method, url string, header http.Header, body io.Reader) (*FetchResponse, error) {
+	r, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		r.Header[key] = slices.Clone(values)
+	}
+	if incoming != nil {
+		forwardHeaders := fetcher.ForwardHeaders
+		if forwardHeaders == nil {
+			forwardHeaders = DefaultForwardHeaders
+		}
+		for _, key := range forwardHeaders {
+			if value := incoming.Header.Get(key); value != "" && r.Header.Get(key) == "" {
+				r.Header.Set(key, value)
+			}
+		}
+	}
+	if fetcher.InjectHeaders != nil {
+		fetcher.InjectHeaders(ctx, r.Header)
+	}
+
+	client := fetcher.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &FetchResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}, nil
+}
+
+// getFetchMemoKey starts with a NUL, keeping it apart from the keys data
+// funcs pass to Memo
+func getFetchMemoKey(url string, header http.Header) string {
+	var b strings.Builder
+	b.WriteString("\x00hwy.fetch\x00")
+	b.WriteString(url)
+	for _, key := range getSortedKeys(header) {
+		b.WriteString("\x00" + http.CanonicalHeaderKey(key) + ": " + strings.Join(header[key], ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetch(t *testing.T) {
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"traceparent":"` + r.Header.Get("Traceparent") + `","injected":"` + r.Header.Get("X-Injected") + `","cookie":"` + r.Header.Get("Cookie") + `"}`))
+	}))
+	defer upstream.Close()
+
+	type upstreamData struct {
+		Traceparent string `json:"traceparent"`
+		Injected    string `json:"injected"`
+		Cookie      string `json:"cookie"`
+	}
+	fetchLoader := func(props *LoaderProps) (any, error) {
+		resp, err := props.Fetch("GET", upstream.URL+"/tiger", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var data upstreamData
+		return data, resp.DecodeJSON(&data)
+	}
+	setTestDataFuncs(t, "/lion", &DataFuncs{Loader: fetchLoader})
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{Loader: fetchLoader})
+
+	h := Hwy{Fetcher: &Fetcher{
+		Client:        upstream.Client(),
+		InjectHeaders: func(ctx context.Context, header http.Header) { header.Set("X-Injected", "1") },
+	}}
+	r := httptest.NewRequest("GET", "/lion/fetch-test", nil)
+	r.Header.Set("Traceparent", "00-trace-span-01")
+	r.Header.Set("Cookie", "session=secret")
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("expected identical GETs to be deduped within the request, got %d upstream hits", got)
+	}
+	want := upstreamData{Traceparent: "00-trace-span-01", Injected: "1"}
+	for i, data := range *routeData.LoadersData {
+		if data != want {
+			t.Errorf("expected loader %d to forward trace headers (and not cookies), got %+v", i, data)
+		}
+	}
+
+	// Not deduped across requests, or for other methods
+	props := DataProps{Request: httptest.NewRequest("GET", "/", nil)}
+	if _, err := props.Fetch("POST", upstream.URL+"/tiger", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := props.Fetch("POST", upstream.URL+"/tiger", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("expected POSTs not to be deduped, got %d upstream hits", got)
+	}
+
+	// Deadlines come from Fetcher.Timeout and the incoming request
+	h.Fetcher.Timeout = 10 * time.Millisecond
+	h.InvocationRecorder = &InvocationRecorder{}
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{Loader: func(props *LoaderProps) (any, error) {
+		return props.Fetch("GET", upstream.URL+"/slow", nil, nil)
+	}})
+	if _, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/fetch-timeout", nil)); err != nil {
+		t.Fatal(err)
+	}
+	for _, invocation := range h.InvocationRecorder.Invocations() {
+		if invocation.Pattern == "/lion/$" && !errors.Is(invocation.Err, context.DeadlineExceeded) {
+			t.Errorf("expected the slow fetch to time out per Fetcher.Timeout, got %v", invocation.Err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	props = DataProps{Request: httptest.NewRequest("GET", "/", nil).WithContext(ctx)}
+	if _, err := props.Fetch("GET", upstream.URL+"/slow", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the incoming request's deadline to apply, got %v", err)
+	}
+}
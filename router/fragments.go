@@ -0,0 +1,127 @@
+package router
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+)
+
+// FragmentFunc renders the HTML of its route for fragment (e.g. HTMX)
+// requests. Outlet holds the already-rendered HTML of its child route.
+type FragmentFunc func(*FragmentProps) (template.HTML, error)
+
+type FragmentProps struct {
+	DataProps
+	LoaderData any
+	ActionData any
+	Outlet     template.HTML
+}
+
+// Header set on fragment responses, naming the pattern of the outermost
+// route that was rendered (so clients can pick a swap target)
+const FragmentPatternHeader = "Hwy-Fragment-Pattern"
+
+func (h Hwy) getFragmentHeader() string {
+	if h.FragmentHeader != "" {
+		return h.FragmentHeader
+	}
+	return "HX-Request"
+}
+
+// GetIsFragmentRequest reports whether r asks for a fragment rather than a
+// full document or the JSON payload
+func (h Hwy) GetIsFragmentRequest(r *http.Request) bool {
+	return r.Header.Get(h.getFragmentHeader()) != "" && !GetIsJSONRequest(r)
+}
+
+// getChangedIndex returns the index of the outermost matched route that
+// differs (in pattern or in the params and splat segments it uses) from the
+// page the client is on, per the HX-Current-URL header. Without that header,
+// everything is considered changed.
+func getChangedIndex(r *http.Request, a *ActivePathData) int {
+	currentURL, err := url.Parse(r.Header.Get("HX-Current-URL"))
+	if err != nil || currentURL.Path == "" {
+		return 0
+	}
+	// Keeps the request's tenant, so the same route tree is matched
+	current := getMatchingPathItem((&http.Request{URL: &url.URL{Path: currentURL.Path}}).WithContext(r.Context()))
+
+	resolve := func(pattern string, params *map[string]string, splatSegments *[]string) string {
+		var p map[string]string
+		if params != nil {
+			p = *params
+		}
+		var s []string
+		if splatSegments != nil {
+			s = *splatSegments
+		}
+		return ResolvePattern(pattern, p, s)
+	}
+
+	for i, path := range *a.MatchingPaths {
+		if i >= len(*current.MatchingPaths) {
+			return i
+		}
+		currentPattern := (*current.MatchingPaths)[i].Pattern
+		if currentPattern != path.Pattern {
+			return i
+		}
+		if resolve(path.Pattern, current.Params, current.SplatSegments) != resolve(path.Pattern, a.Params, a.SplatSegments) {
+			return i
+		}
+	}
+	// Nothing changed (e.g. an action posting to the current page), so
+	// re-render the deepest route
+	return len(*a.MatchingPaths) - 1
+}
+
+// renderFragment renders the matched routes from the deepest up to the
+// outermost changed one, each wrapping its child as Outlet. Routes without a
+// Fragment func pass their child's HTML through. Returns false when no route
+// in that range has a Fragment func.
+func renderFragment(r *http.Request, a *ActivePathData) (template.HTML, string, bool, error) {
+	if a.MatchingPaths == nil || len(*a.MatchingPaths) == 0 {
+		return "", "", false, nil
+	}
+	from := getChangedIndex(r, a)
+	var outlet template.HTML
+	rendered := false
+	for i := len(*a.MatchingPaths) - 1; i >= from; i-- {
+		path := (*a.MatchingPaths)[i]
+		if path.DataFuncs == nil || path.DataFuncs.Fragment == nil {
+			continue
+		}
+		html, err := path.DataFuncs.Fragment(&FragmentProps{
+			DataProps:  a.scope.newDataProps(r, a.Params, a.SplatSegments),
+			LoaderData: (*a.LoadersData)[i],
+			ActionData: (*a.ActionData)[i],
+			Outlet:     outlet,
+		})
+		if err != nil {
+			return "", "", false, err
+		}
+		outlet = html
+		rendered = true
+	}
+	return outlet, (*a.MatchingPaths)[from].Pattern, rendered, nil
+}
+
+// serveFragment writes the fragment for routeData, returning false if there
+// is none to write (in which case the full document should be served)
+func (h Hwy) serveFragment(w http.ResponseWriter, r *http.Request, routeData *GetRouteDataOutput) (bool, error) {
+	if routeData.activePathData == nil {
+		return false, nil
+	}
+	html, pattern, ok, err := renderFragment(r, routeData.activePathData)
+	if err != nil || !ok {
+		return false, err
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set(FragmentPatternHeader, pattern)
+	w.Header().Add("Vary", h.getFragmentHeader())
+	if routeData.Status != 0 {
+		w.WriteHeader(routeData.Status)
+	}
+	w.Write([]byte(html))
+	return true, nil
+}
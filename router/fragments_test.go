@@ -0,0 +1,49 @@
+package router
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFragmentRendersOutermostChangedRoute(t *testing.T) {
+	setTestDataFuncs(t, "/tiger", &DataFuncs{
+		Fragment: func(props *FragmentProps) (template.HTML, error) {
+			return "<main>" + props.Outlet + "</main>", nil
+		},
+	})
+	setTestDataFuncs(t, "/tiger/$tiger_id", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return (*props.Params)["tiger_id"], nil
+		},
+		Fragment: func(props *FragmentProps) (template.HTML, error) {
+			return template.HTML("<p>" + props.LoaderData.(string) + "</p>"), nil
+		},
+	})
+
+	tests := []struct {
+		currentURL      string
+		expectedBody    string
+		expectedPattern string
+	}{
+		{"", "<main><p>fragment-2</p></main>", "/tiger"},
+		{"http://localhost/tiger/fragment-1", "<p>fragment-2</p>", "/tiger/$tiger_id"},
+		{"http://localhost/lion", "<main><p>fragment-2</p></main>", "/tiger"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/tiger/fragment-2", nil)
+		r.Header.Set("HX-Request", "true")
+		if tt.currentURL != "" {
+			r.Header.Set("HX-Current-URL", tt.currentURL)
+		}
+		w := httptest.NewRecorder()
+		Hwy{}.GetRootHandler().ServeHTTP(w, r)
+
+		if w.Body.String() != tt.expectedBody {
+			t.Errorf("current URL %q: expected body %q, got %q", tt.currentURL, tt.expectedBody, w.Body.String())
+		}
+		if got := w.Header().Get(FragmentPatternHeader); got != tt.expectedPattern {
+			t.Errorf("current URL %q: expected pattern header %q, got %q", tt.currentURL, tt.expectedPattern, got)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+)
+
+var errGone = errors.New("gone")
+
+// Gone is like NotFound, for content that existed but was deliberately
+// removed. The request is rendered the same way, but with a 410 status (and
+// so, by default, a noindex robots meta tag; see Hwy.Noindex).
+func Gone() error {
+	return errGone
+}
+
+func IsGone(err error) bool {
+	return errors.Is(err, errGone)
+}
+
+// PermanentRedirectError is returned by PermanentRedirect
+type PermanentRedirectError struct {
+	URL string
+}
+
+func (e *PermanentRedirectError) Error() string {
+	return "permanently moved to " + e.URL
+}
+
+// PermanentRedirect returns a sentinel error for loaders to return when
+// content has moved for good. The request is then redirected to url with a
+// 301, subject to the same host rules as Redirect.
+func PermanentRedirect(url string) error {
+	return &PermanentRedirectError{URL: url}
+}
+
+// getIsNotFoundOrGone reports whether err re-renders the request through the
+// nearest catch route
+func getIsNotFoundOrGone(err error) bool {
+	return IsNotFound(err) || IsGone(err)
+}
+
+// getIsContentLifecycleError reports whether err is one of the NotFound,
+// Gone, or PermanentRedirect sentinels rather than a failure
+func getIsContentLifecycleError(err error) bool {
+	var redirectErr *PermanentRedirectError
+	return getIsNotFoundOrGone(err) || errors.As(err, &redirectErr)
+}
+
+// getPermanentRedirectOutcome returns a 301 outcome if the outermost loader
+// error is a PermanentRedirect
+func getPermanentRedirectOutcome(errs []error) *GuardOutcome {
+	i := getOutermostErrorIndex(errs)
+	if i == -1 {
+		return nil
+	}
+	var redirectErr *PermanentRedirectError
+	if !errors.As(errs[i], &redirectErr) {
+		return nil
+	}
+	return &GuardOutcome{Status: http.StatusMovedPermanently, RedirectTo: redirectErr.URL}
+}
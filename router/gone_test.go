@@ -0,0 +1,72 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestLoaderGoneRendersNearestCatch(t *testing.T) {
+	var reported []error
+	setTestDataFuncs(t, "/dashboard/customers/$customer_id", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return nil, Gone()
+		},
+	})
+	h := Hwy{OnError: func(report *ErrorReport) { reported = append(reported, report.Err) }}
+
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/dashboard/customers/deleted", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routeData.Status != http.StatusGone {
+		t.Errorf("expected status 410, got %d", routeData.Status)
+	}
+	if routeData.Pattern == "/dashboard/customers/$customer_id" {
+		t.Error("expected the nearest catch route to render")
+	}
+	if !slices.ContainsFunc(*routeData.MetaHeadBlocks, func(block *HeadBlock) bool {
+		return block.Attributes["name"] == "robots" && block.Attributes["content"] == "noindex"
+	}) {
+		t.Error("expected a noindex robots meta tag")
+	}
+	if len(reported) > 0 {
+		t.Errorf("expected Gone not to be reported as an error, got %v", reported)
+	}
+}
+
+func TestLoaderPermanentRedirect(t *testing.T) {
+	var headRan bool
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			if (*props.SplatSegments)[0] == "away" {
+				return nil, PermanentRedirect("https://evil.example/")
+			}
+			return nil, PermanentRedirect("/tiger/renamed")
+		},
+		Head: func(props *HeadProps) (*[]HeadBlock, error) {
+			headRan = true
+			return &[]HeadBlock{}, nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	Hwy{}.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/lion/moved", nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/tiger/renamed" {
+		t.Errorf("expected a 301 to /tiger/renamed, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if headRan {
+		t.Error("expected heads to be skipped")
+	}
+
+	routeData, err := Hwy{}.GetRouteData(nil, httptest.NewRequest("GET", "/lion/moved?"+HwyPrefix+"json=1", nil))
+	if err != nil || routeData.GuardOutcome == nil || routeData.GuardOutcome.Status != http.StatusMovedPermanently {
+		t.Errorf("expected a 301 guard outcome for JSON requests, got %+v %v", routeData, err)
+	}
+
+	if _, err := (Hwy{}).GetRouteData(nil, httptest.NewRequest("GET", "/lion/away", nil)); !errors.Is(err, ErrUnsafeRedirect) {
+		t.Errorf("expected external redirects to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Guard runs before any loaders or actions for its route and all of its
+// children. Returning a non-nil *GuardOutcome short-circuits the request.
+type Guard func(*GuardProps) (*GuardOutcome, error)
+
+type GuardProps struct {
+	DataProps
+}
+
+type GuardOutcome struct {
+	// Defaults to 302 when RedirectTo is set, otherwise 403
+	Status     int    `json:"status"`
+	RedirectTo string `json:"redirectTo,omitempty"`
+
+	allowExternal bool
+}
+
+// Redirect returns a GuardOutcome that redirects to the given URL with a 302.
+// Absolute URLs must point at the request's host or one of Hwy.AllowedHosts,
+// otherwise the request fails with ErrUnsafeRedirect.
+func Redirect(to string) *GuardOutcome {
+	return &GuardOutcome{Status: http.StatusFound, RedirectTo: to}
+}
+
+// RedirectExternal is like Redirect, but allows any host. Never pass it a URL
+// built from user input.
+func RedirectExternal(to string) *GuardOutcome {
+	return &GuardOutcome{Status: http.StatusFound, RedirectTo: to, allowExternal: true}
+}
+
+// Deny returns a GuardOutcome that ends the request with the given status
+func Deny(status int) *GuardOutcome {
+	return &GuardOutcome{Status: status}
+}
+
+func (o *GuardOutcome) status() int {
+	if o.Status != 0 {
+		return o.Status
+	}
+	if o.RedirectTo != "" {
+		return http.StatusFound
+	}
+	return http.StatusForbidden
+}
+
+func (o *GuardOutcome) serve(w http.ResponseWriter, r *http.Request) {
+	if o.RedirectTo != "" {
+		http.Redirect(w, r, o.RedirectTo, o.status())
+		return
+	}
+	http.Error(w, http.StatusText(o.status()), o.status())
+}
+
+// runGuards runs the guards of all matched paths, outermost first, stopping
+// at the first guard that short-circuits or errors.
+func (h Hwy) runGuards(r *http.Request, item *gmpdItem, scope *requestScope) (*GuardOutcome, error) {
+	for _, path := range *item.FullyDecoratedMatchingPaths {
+		if path.DataFuncs == nil || path.DataFuncs.Guard == nil {
+			continue
+		}
+		_, span := h.startSpan(r.Context(), SpanGuard, getPathSpanAttributes(path)...)
+		startTime := time.Now()
+		props := &GuardProps{DataProps: scope.newDataProps(r, item.Params, item.SplatSegments)}
+		outcome, err := callDataFunc(h, r, path.Pattern, ErrorPhaseGuard, func() (*GuardOutcome, error) {
+			return path.DataFuncs.Guard(props)
+		})
+		endSpan(span, err)
+		h.recordInvocation(ErrorPhaseGuard, path.Pattern, &props.DataProps, startTime, outcome, err)
+		if err != nil {
+			return nil, err
+		}
+		if outcome != nil {
+			if outcome.RedirectTo != "" && !outcome.allowExternal && !h.getIsSafeRedirect(r, outcome.RedirectTo) {
+				return nil, fmt.Errorf("%w: %q", ErrUnsafeRedirect, outcome.RedirectTo)
+			}
+			outcome.Status = outcome.status()
+			return outcome, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,83 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestGuardItem(guards ...Guard) *gmpdItem {
+	paths := make([]*DecoratedPath, 0, len(guards))
+	for _, guard := range guards {
+		paths = append(paths, &DecoratedPath{DataFuncs: &DataFuncs{Guard: guard}})
+	}
+	return &gmpdItem{
+		FullyDecoratedMatchingPaths: &paths,
+		Params:                      &map[string]string{},
+		SplatSegments:               &[]string{},
+	}
+}
+
+func TestRunGuardsOutermostWins(t *testing.T) {
+	childRan := false
+	item := newTestGuardItem(
+		func(props *GuardProps) (*GuardOutcome, error) {
+			return Redirect("/login"), nil
+		},
+		func(props *GuardProps) (*GuardOutcome, error) {
+			childRan = true
+			return Deny(http.StatusForbidden), nil
+		},
+	)
+
+	outcome, err := Hwy{}.runGuards(&http.Request{}, item, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outcome == nil || outcome.RedirectTo != "/login" || outcome.Status != http.StatusFound {
+		t.Errorf("expected 302 redirect to /login, got %+v", outcome)
+	}
+	if childRan {
+		t.Errorf("expected child guard not to run after parent short-circuited")
+	}
+}
+
+func TestRunGuardsDefaultStatus(t *testing.T) {
+	item := newTestGuardItem(
+		nil,
+		func(props *GuardProps) (*GuardOutcome, error) {
+			return &GuardOutcome{}, nil
+		},
+	)
+
+	outcome, _ := Hwy{}.runGuards(&http.Request{}, item, nil)
+	if outcome == nil || outcome.Status != http.StatusForbidden {
+		t.Errorf("expected default 403, got %+v", outcome)
+	}
+}
+
+func TestGuardOutcomeJSONStatus(t *testing.T) {
+	var outcome *GuardOutcome
+	setTestDataFuncs(t, "/lion", &DataFuncs{
+		Guard: func(props *GuardProps) (*GuardOutcome, error) { return outcome, nil },
+	})
+
+	for _, c := range []struct {
+		outcome        *GuardOutcome
+		expectedStatus int
+		expectedBody   string
+	}{
+		{outcome: Deny(http.StatusForbidden), expectedStatus: http.StatusForbidden, expectedBody: `"status":403`},
+		{outcome: Deny(http.StatusTooManyRequests), expectedStatus: http.StatusTooManyRequests, expectedBody: `"status":429`},
+		// Redirects are followed by the client from the JSON
+		{outcome: Redirect("/login"), expectedStatus: http.StatusOK, expectedBody: `"redirectTo":"/login"`},
+	} {
+		outcome = c.outcome
+		w := httptest.NewRecorder()
+		Hwy{}.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/lion/guarded?"+HwyPrefix+"json=1", nil))
+		if w.Code != c.expectedStatus || !strings.Contains(w.Body.String(), c.expectedBody) {
+			t.Errorf("%+v: expected %d with %s, got %d %s", c.outcome, c.expectedStatus, c.expectedBody, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHandlesFromPathsFileAndDataFuncs(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+
+	pathsFile := PathsFile{Paths: GetPathsFromPageFiles("tiger.ui.tsx", "tiger/$tiger_id.ui.tsx")}
+	pathsFile.Paths[0].Handle = map[string]any{"breadcrumb": "Tigers"}
+	pathsFileBytes, err := json.Marshal(pathsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := Hwy{
+		FS: fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}},
+		DataFuncsMap: DataFuncsMap{
+			"/tiger/$tiger_id": {
+				Handle: map[string]any{"breadcrumb": "Tiger"},
+				Loader: func(props *LoaderProps) (any, error) {
+					return props.Handle["breadcrumb"], nil
+				},
+			},
+		},
+	}
+	if err := h.Initialize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/tiger/123", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handles := *routeData.Handles
+	if len(handles) != 2 || handles[0]["breadcrumb"] != "Tigers" || handles[1]["breadcrumb"] != "Tiger" {
+		t.Errorf("expected handles from the paths file and data funcs, got %v", handles)
+	}
+	if (*routeData.LoadersData)[1] != "Tiger" {
+		t.Errorf("expected loader to see its own handle, got %v", (*routeData.LoadersData)[1])
+	}
+}
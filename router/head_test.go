@@ -0,0 +1,98 @@
+package router
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDedupeHeadBlocks(t *testing.T) {
+	blocks := []HeadBlock{
+		{Title: "Parent"},
+		{Tag: "meta", Attributes: map[string]string{"name": "description", "content": "parent"}},
+		{Tag: "meta", Attributes: map[string]string{"property": "og:image", "content": "parent.png"}},
+		{Tag: "link", Attributes: map[string]string{"rel": "stylesheet", "href": "/a.css"}},
+		{Tag: "link", Attributes: map[string]string{"rel": "canonical", "href": "/parent"}},
+		{Title: "Child"},
+		{Tag: "meta", Attributes: map[string]string{"name": "description", "content": "child"}},
+		{Tag: "meta", Attributes: map[string]string{"property": "og:image", "content": "child.png"}},
+		{Tag: "link", Attributes: map[string]string{"rel": "stylesheet", "href": "/a.css"}},
+		{Tag: "link", Attributes: map[string]string{"rel": "stylesheet", "href": "/b.css"}},
+		{Tag: "link", Attributes: map[string]string{"rel": "canonical", "href": "/child"}},
+	}
+
+	deduped := *dedupeHeadBlocks(&blocks, DefaultHeadDedupeKeys)
+
+	if len(deduped) != 6 {
+		t.Fatalf("expected 6 blocks, got %d", len(deduped))
+	}
+	if deduped[0].Title != "Child" {
+		t.Errorf("expected child title to win, got %s", deduped[0].Title)
+	}
+	if deduped[1].Attributes["content"] != "child" {
+		t.Errorf("expected child description to win")
+	}
+	if deduped[2].Attributes["content"] != "child.png" {
+		t.Errorf("expected child og:image to replace parent's")
+	}
+	if deduped[4].Attributes["href"] != "/child" {
+		t.Errorf("expected child canonical to replace parent's")
+	}
+}
+
+func TestDedupeHeadBlocksCustomKeys(t *testing.T) {
+	blocks := []HeadBlock{
+		{Tag: "meta", Attributes: map[string]string{"property": "og:image", "content": "parent.png"}},
+		{Tag: "meta", Attributes: map[string]string{"property": "og:image", "content": "child.png"}},
+	}
+
+	deduped := *dedupeHeadBlocks(&blocks, []HeadDedupeKey{})
+
+	if len(deduped) != 2 {
+		t.Errorf("expected no keyed dedupe without keys, got %d blocks", len(deduped))
+	}
+}
+
+func TestHTMLAndBodyAttributes(t *testing.T) {
+	blocks := []*HeadBlock{
+		{Tag: "html", Attributes: map[string]string{"lang": "en", "dir": "ltr"}},
+		{Tag: "body", Attributes: map[string]string{"class": "parent"}},
+		{Tag: "html", Attributes: map[string]string{"lang": "de"}},
+		{Tag: "body", Attributes: map[string]string{"class": `child" onload="x`}},
+		{Tag: "iframe", Attributes: map[string]string{"src": "/x"}},
+		{Tag: "template", Attributes: map[string]string{"id": "y"}},
+	}
+
+	sorted := sortHeadBlocks(filterHeadBlocks(&blocks, []string{"template"}))
+
+	if html := GetAttributesHTML(sorted.htmlAttributes); html != `dir="ltr" lang="de"` {
+		t.Errorf("unexpected html attributes: %s", html)
+	}
+	if body := GetAttributesHTML(sorted.bodyAttributes); body != `class="child&#34; onload=&#34;x"` {
+		t.Errorf("unexpected body attributes: %s", body)
+	}
+	if len(*sorted.restHeadBlocks) != 1 || (*sorted.restHeadBlocks)[0].Tag != "template" {
+		t.Errorf("expected only the extra permitted tag to survive filtering")
+	}
+}
+
+func TestGetHeadElementsReusesBuffers(t *testing.T) {
+	newRouteData := func(title string) *GetRouteDataOutput {
+		return &GetRouteDataOutput{
+			Title:          title,
+			MetaHeadBlocks: &[]*HeadBlock{{Tag: "meta", Attributes: map[string]string{"name": "description", "content": title}}},
+			RestHeadBlocks: &[]*HeadBlock{},
+		}
+	}
+
+	first, err := GetHeadElements(newRouteData("first"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	firstCopy := *first
+	if _, err := GetHeadElements(newRouteData("second")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *first != firstCopy || !strings.Contains(string(*first), "<title>first</title>") {
+		t.Errorf("expected earlier output to be unaffected by buffer reuse, got %s", *first)
+	}
+}
@@ -0,0 +1,57 @@
+package router
+
+import (
+	"net/http"
+)
+
+// HeadersFunc returns response headers for its route. Headers functions
+// run parent→child, each seeing what its parents returned, and each child's
+// headers replace its parents' key by key, so a child can deterministically
+// tighten (or loosen) e.g. a parent's Cache-Control.
+type HeadersFunc func(*HeadersProps) (http.Header, error)
+
+type HeadersProps struct {
+	DataProps
+	LoaderData any
+	// The data returned by each ancestor loader, outermost first
+	ParentLoadersData []any
+	// The composed headers of all ancestor routes (don't modify)
+	ParentHeaders http.Header
+}
+
+// composeHeaders runs the matched routes' Headers functions, stopping at the
+// first route whose loader failed. A failing Headers function is reported
+// and skipped, leaving its parents' headers in place.
+func (h Hwy) composeHeaders(r *http.Request, item *gmpdItem, loadersData []any, errs []error, scope *requestScope) http.Header {
+	var composed http.Header
+	for i, path := range *item.FullyDecoratedMatchingPaths {
+		if errs[i] != nil {
+			break
+		}
+		if path.DataFuncs == nil || path.DataFuncs.Headers == nil {
+			continue
+		}
+		headers, err := callDataFunc(h, r, path.Pattern, ErrorPhaseHeaders, func() (http.Header, error) {
+			return path.DataFuncs.Headers(&HeadersProps{
+				DataProps:         scope.newDataProps(r, item.Params, item.SplatSegments),
+				LoaderData:        loadersData[i],
+				ParentLoadersData: loadersData[:i:i],
+				ParentHeaders:     composed.Clone(),
+			})
+		})
+		if err != nil {
+			h.getLogger().Error("headers error", "pattern", path.Pattern, "error", err)
+			continue
+		}
+		if len(headers) == 0 {
+			continue
+		}
+		if composed == nil {
+			composed = make(http.Header, len(headers))
+		}
+		for key, values := range headers {
+			composed[http.CanonicalHeaderKey(key)] = values
+		}
+	}
+	return composed
+}
@@ -0,0 +1,57 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHeadersComposition(t *testing.T) {
+	setTestDataFuncs(t, "/lion", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) { return "lion", nil },
+		Headers: func(props *HeadersProps) (http.Header, error) {
+			return http.Header{"Cache-Control": {"public, max-age=600"}, "X-Section": {"lion"}}, nil
+		},
+	})
+	var childProps *HeadersProps
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) { return "cub", nil },
+		Headers: func(props *HeadersProps) (http.Header, error) {
+			childProps = props
+			if (*props.SplatSegments)[0] == "fail" {
+				return nil, errors.New("headers failed")
+			}
+			return http.Header{"cache-control": {"private, max-age=60"}}, nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	if _, err := (Hwy{}).GetRouteData(w, httptest.NewRequest("GET", "/lion/headers", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "private, max-age=60" {
+		t.Errorf("expected the child to tighten Cache-Control, got %q", got)
+	}
+	if got := w.Header().Get("X-Section"); got != "lion" {
+		t.Errorf("expected the parent's other headers to be kept, got %q", got)
+	}
+	if childProps.LoaderData != "cub" || !reflect.DeepEqual(childProps.ParentLoadersData, []any{"lion"}) ||
+		childProps.ParentHeaders.Get("Cache-Control") != "public, max-age=600" {
+		t.Errorf("unexpected child props: %+v", childProps)
+	}
+
+	var report *ErrorReport
+	h := Hwy{OnError: func(r *ErrorReport) { report = r }}
+	w = httptest.NewRecorder()
+	if _, err := h.GetRouteData(w, httptest.NewRequest("GET", "/lion/fail", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=600" {
+		t.Errorf("expected the parent's headers when the child's fail, got %q", got)
+	}
+	if report == nil || report.Phase != ErrorPhaseHeaders || report.Pattern != "/lion/$" {
+		t.Errorf("expected a headers error report, got %+v", report)
+	}
+}
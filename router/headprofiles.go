@@ -0,0 +1,33 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// getDefaultHeadBlocks returns DefaultHeadBlocks followed by those of the
+// request's head profile (see Hwy.HeadProfiles), if any
+func (h Hwy) getDefaultHeadBlocks(r *http.Request) []HeadBlock {
+	profile := h.HeadProfile
+	if h.SelectHeadProfile != nil {
+		if selected := h.SelectHeadProfile(r); selected != "" {
+			profile = selected
+		}
+	}
+	profileBlocks, ok := h.HeadProfiles[profile]
+	if !ok || len(profileBlocks) == 0 {
+		return h.DefaultHeadBlocks
+	}
+	return append(slices.Clone(h.DefaultHeadBlocks), profileBlocks...)
+}
+
+func (h Hwy) validateHeadProfile() []error {
+	if h.HeadProfile == "" {
+		return nil
+	}
+	if _, ok := h.HeadProfiles[h.HeadProfile]; !ok {
+		return []error{fmt.Errorf("%w: %s", ErrUnknownHeadProfile, h.HeadProfile)}
+	}
+	return nil
+}
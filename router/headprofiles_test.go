@@ -0,0 +1,66 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHeadProfiles(t *testing.T) {
+	h := Hwy{
+		DefaultHeadBlocks: []HeadBlock{{Title: "Site"}},
+		HeadProfiles: map[string][]HeadBlock{
+			"production": {{Tag: "meta", Attributes: map[string]string{"name": "google-site-verification", "content": "abc"}}},
+			"preview":    {{Tag: "meta", Attributes: map[string]string{"name": "robots", "content": "noindex"}}},
+		},
+		HeadProfile: "production",
+		SelectHeadProfile: func(r *http.Request) string {
+			if r.Host == "preview.example.com" {
+				return "preview"
+			}
+			return ""
+		},
+	}
+	getMetaNames := func(host string) []string {
+		r := httptest.NewRequest("GET", "/lion/head-profile-test", nil)
+		r.Host = host
+		routeData, err := h.GetRouteData(httptest.NewRecorder(), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if routeData.Title != "Site" {
+			t.Errorf("expected DefaultHeadBlocks kept, got title %q", routeData.Title)
+		}
+		var names []string
+		for _, block := range *routeData.MetaHeadBlocks {
+			if name := block.Attributes["name"]; name == "google-site-verification" || name == "robots" {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+
+	if names := getMetaNames("example.com"); len(names) != 1 || names[0] != "google-site-verification" {
+		t.Errorf("expected the production profile, got %v", names)
+	}
+	if names := getMetaNames("preview.example.com"); len(names) != 1 || names[0] != "robots" {
+		t.Errorf("expected the preview profile, got %v", names)
+	}
+}
+
+func TestUnknownHeadProfile(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+
+	pathsFileBytes, _ := json.Marshal(PathsFile{Paths: GetPathsFromPageFiles("tiger.ui.tsx")})
+	h := Hwy{
+		FS:           fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}},
+		HeadProfiles: map[string][]HeadBlock{"production": nil},
+		HeadProfile:  "staging",
+	}
+	if err := h.Initialize(); !errors.Is(err, ErrUnknownHeadProfile) {
+		t.Errorf("expected ErrUnknownHeadProfile, got %v", err)
+	}
+}
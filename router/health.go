@@ -0,0 +1,81 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNotInitialized     = errors.New("hwy not initialized")
+	ErrEmptyManifest      = errors.New("paths file has no paths")
+	ErrDuplicatePattern   = errors.New("duplicate route pattern")
+	ErrUnboundDataFuncs   = errors.New("data funcs pattern matches no route")
+	ErrStaleBuild         = errors.New("paths file build ID doesn't match the expected build ID")
+	ErrMissingFallback    = errors.New("fallback route not found")
+	ErrUnknownHeadProfile = errors.New("head profile not found in HeadProfiles")
+	ErrMissingAsset       = errors.New("built asset not found in AssetsFS")
+	ErrAssetIntegrity     = errors.New("built asset doesn't match its integrity hash")
+
+	// Only reported with Hwy.Strict
+	ErrMissingLoader      = errors.New("layout route has no loader")
+	ErrMissingActionTypes = errors.New("action has no ActionInput or ActionOutput for TypeScript generation")
+	ErrDisallowedHeadTag  = errors.New("head returns a tag that isn't permitted")
+)
+
+// InitializeError aggregates every problem found by Initialize. Use
+// errors.Is with the Err* sentinels above to check for specific ones.
+type InitializeError struct {
+	Problems []error
+}
+
+func (e *InitializeError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Error()
+	}
+	return fmt.Sprintf("hwy initialization failed with %d problem(s): %s", len(e.Problems), strings.Join(messages, "; "))
+}
+
+func (e *InitializeError) Unwrap() []error {
+	return e.Problems
+}
+
+// Result of the last Initialize call (ErrNotInitialized until then)
+var instanceInitErr = ErrNotInitialized
+
+// Healthy is a readiness check, returning nil once Initialize has succeeded
+// (and the error it returned otherwise)
+func (h Hwy) Healthy() error {
+	return instanceInitErr
+}
+
+// validatePaths checks the freshly loaded instancePaths
+func (h Hwy) validatePaths(pathsFile *PathsFile) []error {
+	var problems []error
+	if len(pathsFile.Paths) == 0 {
+		problems = append(problems, ErrEmptyManifest)
+	}
+	if h.ExpectedBuildID != "" && pathsFile.BuildID != h.ExpectedBuildID {
+		problems = append(problems, fmt.Errorf("%w: got %q, expected %q", ErrStaleBuild, pathsFile.BuildID, h.ExpectedBuildID))
+	}
+
+	seen := make(map[string]bool, len(pathsFile.Paths))
+	for _, path := range pathsFile.Paths {
+		if seen[path.Pattern] {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrDuplicatePattern, path.Pattern))
+		}
+		seen[path.Pattern] = true
+	}
+	for _, pattern := range getSortedKeys(h.DataFuncsMap) {
+		if !seen[pattern] {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrUnboundDataFuncs, pattern))
+		}
+	}
+	for _, pattern := range []string{h.NotFoundRoute, h.ErrorRoute} {
+		if pattern != "" && getFallbackItem(pattern, 0, nil) == nil {
+			problems = append(problems, fmt.Errorf("%w: no route found with pattern %s", ErrMissingFallback, pattern))
+		}
+	}
+	return problems
+}
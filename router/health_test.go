@@ -0,0 +1,47 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestInitializeProblems(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+
+	paths := GetPathsFromPageFiles("tiger.ui.tsx", "tiger.ui.jsx")
+	pathsFileBytes, _ := json.Marshal(PathsFile{Paths: paths, BuildID: "1"})
+	h := Hwy{
+		FS:              fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}},
+		DataFuncsMap:    DataFuncsMap{"/lion": {}},
+		ExpectedBuildID: "2",
+		NotFoundRoute:   "/missing",
+	}
+	err := h.Initialize()
+
+	var initErr *InitializeError
+	if !errors.As(err, &initErr) || len(initErr.Problems) != 4 {
+		t.Fatalf("expected an InitializeError with 4 problems, got %v", err)
+	}
+	for _, sentinel := range []error{ErrStaleBuild, ErrDuplicatePattern, ErrUnboundDataFuncs, ErrMissingFallback} {
+		if !errors.Is(err, sentinel) {
+			t.Errorf("expected %v among the problems", sentinel)
+		}
+	}
+	if h.Healthy() != err {
+		t.Errorf("expected Healthy to report the initialization error, got %v", h.Healthy())
+	}
+
+	pathsFileBytes, _ = json.Marshal(PathsFile{})
+	h = Hwy{FS: fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}}}
+	if err := h.Initialize(); !errors.Is(err, ErrEmptyManifest) {
+		t.Errorf("expected ErrEmptyManifest, got %v", err)
+	}
+
+	pathsFileBytes, _ = json.Marshal(PathsFile{Paths: paths[:1], BuildID: "2"})
+	h = Hwy{FS: fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}}, ExpectedBuildID: "2"}
+	if err := h.Initialize(); err != nil || h.Healthy() != nil {
+		t.Errorf("expected a healthy initialization, got %v, %v", err, h.Healthy())
+	}
+}
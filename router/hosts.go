@@ -0,0 +1,76 @@
+package router
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrUnsafeRedirect is returned when a guard redirects to a foreign host
+// without using RedirectExternal
+var ErrUnsafeRedirect = errors.New("unsafe redirect to foreign host")
+
+// GetIsHostAllowed reports whether r's Host is in Hwy.AllowedHosts (always
+// true when AllowedHosts is empty)
+func (h Hwy) GetIsHostAllowed(r *http.Request) bool {
+	if len(h.AllowedHosts) == 0 {
+		return true
+	}
+	return getIsHostInList(r.Host, h.AllowedHosts, true)
+}
+
+// Entries match exactly, ignoring the port unless the entry has one. A leading
+// dot (".example.com") also matches subdomains, and "*" matches anything
+// when allowWildcard is set.
+func getIsHostInList(host string, list []string, allowWildcard bool) bool {
+	hostWithoutPort := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostWithoutPort = h
+	}
+	for _, allowed := range list {
+		switch {
+		case allowed == "*":
+			if allowWildcard {
+				return true
+			}
+		case strings.HasPrefix(allowed, "."):
+			if strings.EqualFold(hostWithoutPort, allowed[1:]) ||
+				strings.HasSuffix(strings.ToLower(hostWithoutPort), strings.ToLower(allowed)) {
+				return true
+			}
+		case strings.Contains(allowed, ":"):
+			if strings.EqualFold(host, allowed) {
+				return true
+			}
+		default:
+			if strings.EqualFold(hostWithoutPort, allowed) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getIsSafeRedirect allows relative redirects, and absolute ones to the
+// request's own host or one of Hwy.AllowedHosts
+func (h Hwy) getIsSafeRedirect(r *http.Request, to string) bool {
+	// Browsers treat backslashes like slashes, so "/\evil.com" is scheme-relative
+	to = strings.ReplaceAll(to, "\\", "/")
+	u, err := url.Parse(to)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return !strings.HasPrefix(to, "//")
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	// A wildcard host allowance shouldn't open up redirects to anywhere
+	return getIsHostInList(u.Host, h.AllowedHosts, false)
+}
@@ -0,0 +1,73 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowedHosts(t *testing.T) {
+	h := Hwy{AllowedHosts: []string{"example.com", ".example.org", "localhost:8080"}}
+	tests := []struct {
+		host     string
+		expected bool
+	}{
+		{"example.com", true},
+		{"EXAMPLE.com:443", true},
+		{"sub.example.com", false},
+		{"example.org", true},
+		{"a.b.example.org", true},
+		{"badexample.org", false},
+		{"localhost:8080", true},
+		{"localhost:9090", false},
+		{"evil.com", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Host = tt.host
+		if got := h.GetIsHostAllowed(r); got != tt.expected {
+			t.Errorf("host %q: expected %v, got %v", tt.host, tt.expected, got)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/lion", nil)
+	r.Host = "evil.com"
+	w := httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for disallowed host, got %d", w.Code)
+	}
+}
+
+func TestUnsafeGuardRedirects(t *testing.T) {
+	h := Hwy{AllowedHosts: []string{"example.com", "*"}}
+	tests := []struct {
+		outcome *GuardOutcome
+		safe    bool
+	}{
+		{Redirect("/login?next=/tiger"), true},
+		{Redirect("login"), true},
+		{Redirect("https://app.test/login"), true},
+		{Redirect("https://example.com/login"), true},
+		{Redirect("https://evil.com/login"), false},
+		{Redirect("//evil.com"), false},
+		{Redirect("/\\evil.com"), false},
+		{Redirect("javascript:alert(1)"), false},
+		{RedirectExternal("https://evil.com/login"), true},
+	}
+	for _, tt := range tests {
+		item := newTestGuardItem(func(props *GuardProps) (*GuardOutcome, error) {
+			return tt.outcome, nil
+		})
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Host = "app.test"
+		_, err := h.runGuards(r, item, nil)
+		if tt.safe && err != nil {
+			t.Errorf("redirect to %q: unexpected error: %v", tt.outcome.RedirectTo, err)
+		}
+		if !tt.safe && !errors.Is(err, ErrUnsafeRedirect) {
+			t.Errorf("redirect to %q: expected ErrUnsafeRedirect, got %v", tt.outcome.RedirectTo, err)
+		}
+	}
+}
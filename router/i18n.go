@@ -0,0 +1,185 @@
+package router
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type I18nConfig struct {
+	// e.g. []string{"en", "de", "fr-CA"}
+	Locales       []string
+	DefaultLocale string
+	// When false (default), the default locale is served without a prefix
+	// (e.g. "/about" instead of "/en/about")
+	PrefixDefaultLocale bool
+	// Cookie consulted (before Accept-Language) when negotiating a locale for
+	// unprefixed requests. Defaults to "hwy_locale".
+	CookieName string
+	// When false (default), unprefixed requests are redirected to the prefixed
+	// URL of the negotiated locale, if it differs from the default locale
+	DisableLocaleDetection bool
+	// Optional directory in Hwy.FS holding a JSON message catalog per locale
+	// (e.g. "messages/de.json"), loaded by Initialize for DataProps.T
+	MessagesDir string
+	// Messages by locale then key, merged over those from MessagesDir
+	Messages map[string]map[string]string
+}
+
+func (c *I18nConfig) cookieName() string {
+	if c.CookieName != "" {
+		return c.CookieName
+	}
+	return "hwy_locale"
+}
+
+func (c *I18nConfig) findLocale(candidate string) (string, bool) {
+	for _, locale := range c.Locales {
+		if strings.EqualFold(locale, candidate) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// getLocalePrefix returns the URL prefix for locale, e.g. "/de" (or "" for an
+// unprefixed default locale)
+func (c *I18nConfig) getLocalePrefix(locale string) string {
+	if locale == c.DefaultLocale && !c.PrefixDefaultLocale {
+		return ""
+	}
+	return "/" + locale
+}
+
+// resolveLocale strips any locale prefix from the request path and returns
+// the request to match against, the resolved locale, and a redirect outcome
+// when the client should be sent to a differently-prefixed URL. Unprefixed
+// responses depend on the negotiated locale, so they vary by its headers
+// when w is non-nil.
+func (h Hwy) resolveLocale(w http.ResponseWriter, r *http.Request) (*http.Request, string, *GuardOutcome) {
+	c := h.I18n
+	if c == nil {
+		return r, "", nil
+	}
+
+	segments := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if locale, ok := c.findLocale(segments[0]); ok {
+		stripped := r.Clone(r.Context())
+		stripped.URL.Path = "/"
+		if len(segments) > 1 {
+			stripped.URL.Path += segments[1]
+		}
+		stripped.URL.RawPath = ""
+		// Keep any encoded slashes past the locale
+		if rawSegments := strings.SplitN(strings.TrimPrefix(r.URL.RawPath, "/"), "/", 2); len(rawSegments) > 1 {
+			stripped.URL.RawPath = "/" + rawSegments[1]
+		}
+		return stripped, locale, nil
+	}
+
+	if w != nil && !c.DisableLocaleDetection {
+		w.Header().Add("Vary", "Accept-Language, Cookie")
+	}
+	locale := c.negotiateLocale(r)
+	if prefix := c.getLocalePrefix(locale); prefix != "" {
+		to := prefix + strings.TrimSuffix(r.URL.EscapedPath(), "/")
+		if r.URL.RawQuery != "" {
+			to += "?" + r.URL.RawQuery
+		}
+		if !h.getIsSafeRedirect(r, to) {
+			return r, locale, Deny(http.StatusBadRequest)
+		}
+		return r, locale, &GuardOutcome{Status: http.StatusFound, RedirectTo: to}
+	}
+
+	return r, locale, nil
+}
+
+func (c *I18nConfig) negotiateLocale(r *http.Request) string {
+	if c.DisableLocaleDetection {
+		return c.DefaultLocale
+	}
+	if cookie, err := r.Cookie(c.cookieName()); err == nil {
+		if locale, ok := c.findLocale(cookie.Value); ok {
+			return locale
+		}
+	}
+	for _, tag := range parseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if locale, ok := c.findLocale(tag); ok {
+			return locale
+		}
+		base, _, _ := strings.Cut(tag, "-")
+		if locale, ok := c.findLocale(base); ok {
+			return locale
+		}
+	}
+	return c.DefaultLocale
+}
+
+// parseAcceptLanguage returns the language tags in header, highest q first
+func parseAcceptLanguage(header string) []string {
+	type weightedTag struct {
+		tag string
+		q   float64
+	}
+	var weighted []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if qStr, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(qStr, 64); err == nil {
+				q = parsed
+			}
+		}
+		weighted = append(weighted, weightedTag{tag, q})
+	}
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].q > weighted[j].q
+	})
+	tags := make([]string, 0, len(weighted))
+	for _, w := range weighted {
+		tags = append(tags, w.tag)
+	}
+	return tags
+}
+
+// getHreflangHeadBlocks returns alternate links to localeFreePath in every
+// configured locale, plus an x-default pointing to the default locale
+func getHreflangHeadBlocks(c *I18nConfig, siteOrigin string, localeFreePath string) []HeadBlock {
+	if c == nil {
+		return nil
+	}
+	blocks := make([]HeadBlock, 0, len(c.Locales)+1)
+	for _, locale := range c.Locales {
+		blocks = append(blocks, newAlternateHeadBlock(locale, getLocalizedURL(c, siteOrigin, locale, localeFreePath)))
+	}
+	defaultURL := getLocalizedURL(c, siteOrigin, c.DefaultLocale, localeFreePath)
+	blocks = append(blocks, newAlternateHeadBlock("x-default", defaultURL))
+	return blocks
+}
+
+func getLocalizedURL(c *I18nConfig, siteOrigin string, locale string, localeFreePath string) string {
+	path := strings.TrimSuffix(localeFreePath, "/")
+	if c != nil {
+		path = c.getLocalePrefix(locale) + path
+	}
+	if path == "" {
+		path = "/"
+	}
+	return siteOrigin + path
+}
+
+func newAlternateHeadBlock(hreflang string, href string) HeadBlock {
+	return HeadBlock{
+		Tag: "link",
+		Attributes: map[string]string{
+			"rel":      "alternate",
+			"hreflang": hreflang,
+			"href":     href,
+		},
+	}
+}
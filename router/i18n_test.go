@@ -0,0 +1,121 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveLocale(t *testing.T) {
+	h := Hwy{I18n: &I18nConfig{Locales: []string{"en", "de", "fr-CA"}, DefaultLocale: "en"}}
+
+	cases := []struct {
+		path           string
+		acceptLanguage string
+		cookie         string
+		expectedPath   string
+		expectedLocale string
+		redirectTo     string
+	}{
+		{path: "/de/tiger/123", expectedPath: "/tiger/123", expectedLocale: "de"},
+		{path: "/fr-ca", expectedPath: "/", expectedLocale: "fr-CA"},
+		{path: "/tiger", expectedPath: "/tiger", expectedLocale: "en"},
+		{path: "/tiger", acceptLanguage: "de-DE,de;q=0.9,en;q=0.8", expectedLocale: "de", redirectTo: "/de/tiger"},
+		{path: "/tiger", acceptLanguage: "en;q=0.5,de;q=0.9", cookie: "en", expectedPath: "/tiger", expectedLocale: "en"},
+		{path: "/", acceptLanguage: "fr-CA", expectedLocale: "fr-CA", redirectTo: "/fr-CA"},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", c.path, nil)
+		if c.acceptLanguage != "" {
+			r.Header.Set("Accept-Language", c.acceptLanguage)
+		}
+		if c.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "hwy_locale", Value: c.cookie})
+		}
+		resolved, locale, outcome := h.resolveLocale(nil, r)
+		if locale != c.expectedLocale {
+			t.Errorf("%s: expected locale %s, got %s", c.path, c.expectedLocale, locale)
+		}
+		if c.redirectTo != "" {
+			if outcome == nil || outcome.RedirectTo != c.redirectTo {
+				t.Errorf("%s: expected redirect to %s, got %+v", c.path, c.redirectTo, outcome)
+			}
+			continue
+		}
+		if outcome != nil {
+			t.Errorf("%s: expected no redirect, got %+v", c.path, outcome)
+		}
+		if resolved.URL.Path != c.expectedPath {
+			t.Errorf("%s: expected path %s, got %s", c.path, c.expectedPath, resolved.URL.Path)
+		}
+	}
+}
+
+func TestResolveLocaleEscapesRedirect(t *testing.T) {
+	h := Hwy{I18n: &I18nConfig{Locales: []string{"en", "de"}, DefaultLocale: "en"}}
+
+	for path, expected := range map[string]string{
+		"/a%3Fb":          "/de/a%3Fb",
+		"/a%23b":          "/de/a%23b",
+		"/a%2Fb":          "/de/a%2Fb",
+		"/caf%C3%A9":      "/de/caf%C3%A9",
+		"/tiger?x=1":      "/de/tiger?x=1",
+		"//evil.com/":     "/de//evil.com",
+		"/%2F%2Fevil.com": "/de/%2F%2Fevil.com",
+	} {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("Accept-Language", "de")
+		w := httptest.NewRecorder()
+		_, _, outcome := h.resolveLocale(w, r)
+		if outcome == nil || outcome.RedirectTo != expected {
+			t.Errorf("%s: expected redirect to %s, got %+v", path, expected, outcome)
+		}
+	}
+}
+
+func TestResolveLocaleVary(t *testing.T) {
+	h := Hwy{I18n: &I18nConfig{Locales: []string{"en", "de"}, DefaultLocale: "en"}}
+
+	for _, c := range []struct {
+		path, acceptLanguage, expectedVary string
+	}{
+		{path: "/tiger", acceptLanguage: "de", expectedVary: "Accept-Language, Cookie"},
+		{path: "/tiger", acceptLanguage: "en", expectedVary: "Accept-Language, Cookie"},
+		{path: "/de/tiger", acceptLanguage: "en", expectedVary: ""},
+	} {
+		r := httptest.NewRequest("GET", c.path, nil)
+		r.Header.Set("Accept-Language", c.acceptLanguage)
+		w := httptest.NewRecorder()
+		h.resolveLocale(w, r)
+		if vary := w.Header().Get("Vary"); vary != c.expectedVary {
+			t.Errorf("%s (%s): expected Vary %q, got %q", c.path, c.acceptLanguage, c.expectedVary, vary)
+		}
+	}
+
+	h.I18n.DisableLocaleDetection = true
+	w := httptest.NewRecorder()
+	h.resolveLocale(w, httptest.NewRequest("GET", "/tiger", nil))
+	if vary := w.Header().Get("Vary"); vary != "" {
+		t.Errorf("expected no Vary without locale detection, got %q", vary)
+	}
+}
+
+func TestHreflangHeadBlocks(t *testing.T) {
+	c := &I18nConfig{Locales: []string{"en", "de"}, DefaultLocale: "en"}
+
+	blocks := getHreflangHeadBlocks(c, "https://example.com", "/tiger")
+	expected := map[string]string{
+		"en":        "https://example.com/tiger",
+		"de":        "https://example.com/de/tiger",
+		"x-default": "https://example.com/tiger",
+	}
+	if len(blocks) != len(expected) {
+		t.Fatalf("expected %d blocks, got %d", len(expected), len(blocks))
+	}
+	for _, block := range blocks {
+		if href := expected[block.Attributes["hreflang"]]; href != block.Attributes["href"] {
+			t.Errorf("expected %s href to be %s, got %s", block.Attributes["hreflang"], href, block.Attributes["href"])
+		}
+	}
+}
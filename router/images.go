@@ -0,0 +1,410 @@
+package router
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrImageWidthNotAllowed  = errors.New("image width not allowed")
+	ErrImageFormatNotAllowed = errors.New("image format not supported")
+)
+
+// ImageEncoder is an extra output format for Images, e.g. WebP or AVIF from
+// a third-party package. JPEG and PNG are always available.
+type ImageEncoder struct {
+	Format      string // used in URLs, e.g. "webp"
+	ContentType string // e.g. "image/webp"
+	Encode      func(w io.Writer, img image.Image, quality int) error
+}
+
+// Images serves resized and re-encoded variants of the images in FS, at
+// URLs keyed by each source's content hash so they can be cached forever.
+// Mount Handler at Prefix, and build URLs with URL and SrcSet (or, on the
+// client, with the module written by WriteTypeScript). Variants are made on
+// first request and kept in memory.
+type Images struct {
+	FS fs.FS
+	// Where Handler is mounted. Defaults to "/_hwy/images/".
+	Prefix string
+	// The only widths served, so arbitrary sizes can't be requested.
+	// Defaults to DefaultImageWidths. Images are never upscaled.
+	Widths []int
+	// 1 to 100, defaults to 80. Passed to JPEG and any Encoders.
+	Quality int
+	// Extra output formats, alongside "jpeg" and "png"
+	Encoders []ImageEncoder
+	// How many encoded variants to keep in memory. Defaults to 128.
+	CacheSize int
+	Logger    Logger // defaults to DefaultLogger
+
+	once     sync.Once
+	variants *cache
+	infos    sync.Map // source path -> *imageInfo
+}
+
+var DefaultImageWidths = []int{320, 640, 960, 1280, 1920}
+
+const (
+	defaultImagesPrefix    = "/_hwy/images/"
+	defaultImageQuality    = 80
+	defaultImageCacheSize  = 128
+	imageHashLength        = 16
+	imageCacheControl      = "public, max-age=31536000, immutable"
+	imagesTypeScriptOutput = "hwy_images.ts"
+)
+
+type imageInfo struct {
+	modTime time.Time
+	hash    string
+	width   int
+	height  int
+}
+
+type imageVariant struct {
+	contentType string
+	content     []byte
+}
+
+func (i *Images) getPrefix() string {
+	if i.Prefix == "" {
+		return defaultImagesPrefix
+	}
+	if !strings.HasSuffix(i.Prefix, "/") {
+		return i.Prefix + "/"
+	}
+	return i.Prefix
+}
+
+func (i *Images) getWidths() []int {
+	if len(i.Widths) == 0 {
+		return DefaultImageWidths
+	}
+	return i.Widths
+}
+
+func (i *Images) getQuality() int {
+	if i.Quality <= 0 || i.Quality > 100 {
+		return defaultImageQuality
+	}
+	return i.Quality
+}
+
+func (i *Images) getLogger() Logger {
+	if i.Logger == nil {
+		return DefaultLogger
+	}
+	return i.Logger
+}
+
+func (i *Images) getVariants() *cache {
+	i.once.Do(func() {
+		size := i.CacheSize
+		if size <= 0 {
+			size = defaultImageCacheSize
+		}
+		i.variants = NewLRUCache(size)
+	})
+	return i.variants
+}
+
+// getInfo hashes src and reads its dimensions, reusing the last result
+// until the file's modification time changes
+func (i *Images) getInfo(src string) (*imageInfo, error) {
+	if !fs.ValidPath(src) {
+		return nil, fs.ErrNotExist
+	}
+	stat, err := fs.Stat(i.FS, src)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := i.infos.Load(src); ok && cached.(*imageInfo).modTime.Equal(stat.ModTime()) {
+		return cached.(*imageInfo), nil
+	}
+	content, err := fs.ReadFile(i.FS, src)
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image %s: %w", src, err)
+	}
+	sum := sha256.Sum256(content)
+	info := &imageInfo{
+		modTime: stat.ModTime(),
+		hash:    hex.EncodeToString(sum[:])[:imageHashLength],
+		width:   config.Width,
+		height:  config.Height,
+	}
+	i.infos.Store(src, info)
+	return info, nil
+}
+
+func (i *Images) getEncoder(format string) *ImageEncoder {
+	quality := i.getQuality()
+	switch format {
+	case "jpeg":
+		return &ImageEncoder{Format: format, ContentType: "image/jpeg", Encode: func(w io.Writer, img image.Image, _ int) error {
+			return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+		}}
+	case "png":
+		return &ImageEncoder{Format: format, ContentType: "image/png", Encode: func(w io.Writer, img image.Image, _ int) error {
+			return png.Encode(w, img)
+		}}
+	}
+	for j := range i.Encoders {
+		if i.Encoders[j].Format == format {
+			return &i.Encoders[j]
+		}
+	}
+	return nil
+}
+
+// URL returns the URL of src (a path within FS, e.g. "photos/hero.jpg")
+// resized to width (one of Widths) and encoded as format
+func (i *Images) URL(src string, width int, format string) (string, error) {
+	if !slices.Contains(i.getWidths(), width) {
+		return "", fmt.Errorf("%w: %d", ErrImageWidthNotAllowed, width)
+	}
+	if i.getEncoder(format) == nil {
+		return "", fmt.Errorf("%w: %q", ErrImageFormatNotAllowed, format)
+	}
+	info, err := i.getInfo(src)
+	if err != nil {
+		return "", err
+	}
+	return i.getURL(info.hash, width, format, src), nil
+}
+
+func (i *Images) getURL(hash string, width int, format, src string) string {
+	return i.getPrefix() + hash + "/" + strconv.Itoa(width) + "/" + format + "/" + src
+}
+
+// SrcSet returns a srcset attribute value for src encoded as format, with
+// every allowed width up to the image's own (or just the smallest allowed
+// width, for images smaller than all of them)
+func (i *Images) SrcSet(src string, format string) (string, error) {
+	if i.getEncoder(format) == nil {
+		return "", fmt.Errorf("%w: %q", ErrImageFormatNotAllowed, format)
+	}
+	info, err := i.getInfo(src)
+	if err != nil {
+		return "", err
+	}
+	return getImageSrcSet(i.getWidths(), info.width, func(width int) string {
+		return i.getURL(info.hash, width, format, src)
+	}), nil
+}
+
+func getImageSrcSet(widths []int, imageWidth int, getURL func(width int) string) string {
+	widths = getSortedImageWidths(widths)
+	var candidates []string
+	for _, width := range widths {
+		if width <= imageWidth {
+			candidates = append(candidates, getURL(width)+" "+strconv.Itoa(width)+"w")
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = append(candidates, getURL(widths[0])+" "+strconv.Itoa(imageWidth)+"w")
+	}
+	return strings.Join(candidates, ", ")
+}
+
+func getSortedImageWidths(widths []int) []int {
+	sorted := slices.Clone(widths)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// Handler serves the URLs made by URL and SrcSet. URLs whose hash doesn't
+// match the current file (e.g. from before a deploy) are not found.
+func (i *Images) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, i.getPrefix())
+		parts := strings.SplitN(rest, "/", 4)
+		if !ok || len(parts) != 4 || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			http.NotFound(w, r)
+			return
+		}
+		hash, widthStr, format, src := parts[0], parts[1], parts[2], parts[3]
+		width, err := strconv.Atoi(widthStr)
+		encoder := i.getEncoder(format)
+		if err != nil || !slices.Contains(i.getWidths(), width) || encoder == nil {
+			http.NotFound(w, r)
+			return
+		}
+		info, err := i.getInfo(src)
+		if err != nil || info.hash != hash {
+			http.NotFound(w, r)
+			return
+		}
+
+		key := hash + "/" + widthStr + "/" + format
+		var variant *imageVariant
+		if cached, ok := i.getVariants().Get(key); ok {
+			variant = cached.(*imageVariant)
+		} else {
+			if variant, err = i.newVariant(src, width, encoder); err != nil {
+				i.getLogger().Error("image transform error", "src", src, "error", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			i.getVariants().Set(key, variant, false)
+		}
+
+		w.Header().Set("Content-Type", variant.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(variant.content)))
+		w.Header().Set("Cache-Control", imageCacheControl)
+		w.Write(variant.content)
+	})
+}
+
+func (i *Images) newVariant(src string, width int, encoder *ImageEncoder) (*imageVariant, error) {
+	file, err := i.FS.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := encoder.Encode(buf, resizeImage(img, width), i.getQuality()); err != nil {
+		return nil, err
+	}
+	return &imageVariant{contentType: encoder.ContentType, content: buf.Bytes()}, nil
+}
+
+// resizeImage scales img down to width, keeping its aspect ratio, by
+// averaging the source pixels under each destination pixel
+func resizeImage(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	if width >= srcWidth {
+		return img
+	}
+	height := max(1, int(math.Round(float64(srcHeight)*float64(width)/float64(srcWidth))))
+
+	// Premultiplied, so transparent pixels don't bleed their color
+	srcRGBA := image.NewRGBA(image.Rect(0, 0, srcWidth, srcHeight))
+	draw.Draw(srcRGBA, srcRGBA.Bounds(), img, bounds.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		y0 := y * srcHeight / height
+		y1 := max(y0+1, (y+1)*srcHeight/height)
+		for x := range width {
+			x0 := x * srcWidth / width
+			x1 := max(x0+1, (x+1)*srcWidth/width)
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := srcRGBA.Pix[sy*srcRGBA.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += int(pixel[0])
+					g += int(pixel[1])
+					b += int(pixel[2])
+					a += int(pixel[3])
+					n++
+				}
+			}
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// WriteTypeScript writes hwy_images.ts to outDir, with imageURL and
+// imageSrcSet functions matching URL and SrcSet, for every decodable image
+// in FS. Rerun it when the images change.
+func (i *Images) WriteTypeScript(outDir string) error {
+	images := make(map[string][2]any)
+	err := fs.WalkDir(i.FS, ".", func(src string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := i.getInfo(src)
+		if err != nil {
+			return nil // not an image
+		}
+		images[src] = [2]any{info.hash, info.width}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	formats := []string{"jpeg", "png"}
+	for _, encoder := range i.Encoders {
+		formats = append(formats, encoder.Format)
+	}
+	config, err := json.Marshal(map[string]any{
+		"prefix":  i.getPrefix(),
+		"widths":  getSortedImageWidths(i.getWidths()),
+		"formats": formats,
+		"images":  images,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outDir, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, imagesTypeScriptOutput), []byte(fmt.Sprintf(imagesTypeScript, config)), os.ModePerm)
+}
+
+const imagesTypeScript = `// Generated by Hwy. Do not edit.
+
+const config: {
+	prefix: string;
+	widths: number[];
+	formats: string[];
+	images: Record<string, [hash: string, width: number]>;
+} = %s;
+
+export type ImageSrc = keyof typeof config.images;
+
+function getImage(src: string): [string, number] {
+	const image = config.images[src];
+	if (!image) {
+		throw new Error("unknown image: " + src);
+	}
+	return image;
+}
+
+export function imageURL(src: string, width: number, format: string): string {
+	const [hash] = getImage(src);
+	return config.prefix + hash + "/" + width + "/" + format + "/" + src;
+}
+
+export function imageSrcSet(src: string, format: string): string {
+	const [hash, imageWidth] = getImage(src);
+	const url = (width: number) => config.prefix + hash + "/" + width + "/" + format + "/" + src;
+	const candidates = config.widths.filter((width) => width <= imageWidth).map((width) => url(width) + " " + width + "w");
+	return candidates.length > 0 ? candidates.join(", ") : url(config.widths[0]) + " " + imageWidth + "w";
+}
+`
@@ -0,0 +1,148 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestImages(t *testing.T) *Images {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for y := range 50 {
+		for x := range 100 {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return &Images{
+		FS:     fstest.MapFS{"photos/red.png": {Data: buf.Bytes()}, "notes.txt": {Data: []byte("hi")}},
+		Widths: []int{50, 25, 200},
+		Encoders: []ImageEncoder{{
+			Format:      "fake",
+			ContentType: "image/fake",
+			Encode: func(w io.Writer, img image.Image, quality int) error {
+				_, err := w.Write([]byte("fake " + img.Bounds().Size().String()))
+				return err
+			},
+		}},
+	}
+}
+
+func TestImagesURLs(t *testing.T) {
+	images := newTestImages(t)
+
+	url, err := images.URL("photos/red.png", 25, "png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(strings.TrimPrefix(url, defaultImagesPrefix), "/")
+	if len(parts) != 5 || len(parts[0]) != imageHashLength || parts[1] != "25" || parts[2] != "png" {
+		t.Errorf("unexpected URL: %s", url)
+	}
+
+	if _, err := images.URL("photos/red.png", 30, "png"); !errors.Is(err, ErrImageWidthNotAllowed) {
+		t.Errorf("expected ErrImageWidthNotAllowed, got %v", err)
+	}
+	if _, err := images.URL("photos/red.png", 25, "tiff"); !errors.Is(err, ErrImageFormatNotAllowed) {
+		t.Errorf("expected ErrImageFormatNotAllowed, got %v", err)
+	}
+
+	srcSet, err := images.SrcSet("photos/red.png", "fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := parts[0]
+	want := defaultImagesPrefix + hash + "/25/fake/photos/red.png 25w, " + defaultImagesPrefix + hash + "/50/fake/photos/red.png 50w"
+	if srcSet != want {
+		t.Errorf("got %s, want %s", srcSet, want)
+	}
+
+	small := getImageSrcSet([]int{320, 640}, 100, func(width int) string { return "x" })
+	if small != "x 100w" {
+		t.Errorf("expected the smallest width at the image's own size, got %s", small)
+	}
+}
+
+func TestImagesHandler(t *testing.T) {
+	images := newTestImages(t)
+	handler := images.Handler()
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		return w
+	}
+
+	url, _ := images.URL("photos/red.png", 25, "png")
+	w := get(url)
+	if w.Code != 200 || w.Header().Get("Content-Type") != "image/png" || w.Header().Get("Cache-Control") != imageCacheControl {
+		t.Fatalf("unexpected response: %d %v", w.Code, w.Header())
+	}
+	img, err := png.Decode(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size != image.Pt(25, 13) {
+		t.Errorf("expected a 25x13 image, got %v", size)
+	}
+	if r, g, b, _ := img.At(10, 5).RGBA(); r>>8 != 255 || g != 0 || b != 0 {
+		t.Errorf("expected red pixels to stay red, got %d %d %d", r>>8, g>>8, b>>8)
+	}
+
+	// Never upscaled
+	url, _ = images.URL("photos/red.png", 200, "fake")
+	if body := get(url).Body.String(); body != "fake (100,50)" {
+		t.Errorf("expected the original size, got %s", body)
+	}
+
+	notFound := []string{
+		strings.Replace(url, "/200/", "/30/", 1),
+		strings.Replace(url, "/fake/", "/tiff/", 1),
+		defaultImagesPrefix + "0000000000000000/200/fake/photos/red.png",
+		defaultImagesPrefix + "0000000000000000/200/fake/notes.txt",
+		defaultImagesPrefix + "0000000000000000/200/fake/../secret.png",
+		"/elsewhere/photos/red.png",
+	}
+	for _, url := range notFound {
+		if code := get(url).Code; code != 404 {
+			t.Errorf("expected 404 for %s, got %d", url, code)
+		}
+	}
+}
+
+func TestImagesWriteTypeScript(t *testing.T) {
+	images := newTestImages(t)
+	outDir := t.TempDir()
+	if err := images.WriteTypeScript(outDir); err != nil {
+		t.Fatal(err)
+	}
+	ts, err := os.ReadFile(filepath.Join(outDir, imagesTypeScriptOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _ := images.getInfo("photos/red.png")
+	for _, want := range []string{
+		`"photos/red.png":["` + info.hash + `",100]`,
+		`"widths":[25,50,200]`,
+		`"formats":["jpeg","png","fake"]`,
+		"export function imageSrcSet(",
+	} {
+		if !bytes.Contains(ts, []byte(want)) {
+			t.Errorf("expected generated TypeScript to contain %s", want)
+		}
+	}
+	if bytes.Contains(ts, []byte("notes.txt")) {
+		t.Error("expected non-images to be skipped")
+	}
+}
@@ -0,0 +1,64 @@
+package router
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+)
+
+// ClientEntryFileName is the client entry's key in PathsFile.Integrity
+const ClientEntryFileName = "hwy_client_entry.js"
+
+var instanceIntegrity map[string]string
+
+// GetIntegrity returns the Subresource Integrity hash (e.g. "sha384-...") of
+// a built file, keyed by its base name, or "" when BuildOptions.Integrity was
+// off. Use it for the client entry's script tag (see ClientEntryFileName).
+func GetIntegrity(fileName string) string {
+	return instanceIntegrity[fileName]
+}
+
+func getSRIHash(content []byte) string {
+	sum := sha512.Sum384(content)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// getDirIntegrity hashes the scripts, stylesheets, and wasm modules in dir
+func getDirIntegrity(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	integrity := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".js" && ext != ".css" && ext != ".wasm") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		integrity[entry.Name()] = getSRIHash(content)
+	}
+	return integrity, nil
+}
+
+// getDepsIntegrity returns nil when there is nothing to send
+func getDepsIntegrity(deps *[]string, cssBundles []string) map[string]string {
+	if len(instanceIntegrity) == 0 || (deps == nil && len(cssBundles) == 0) {
+		return nil
+	}
+	var files []string
+	if deps != nil {
+		files = *deps
+	}
+	integrity := make(map[string]string, len(files)+len(cssBundles))
+	for _, file := range append(files[:len(files):len(files)], cssBundles...) {
+		if hash, ok := instanceIntegrity[file]; ok {
+			integrity[file] = hash
+		}
+	}
+	return integrity
+}
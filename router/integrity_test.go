@@ -0,0 +1,44 @@
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIntegrity(t *testing.T) {
+	if !strings.HasPrefix(GetIntegrity(ClientEntryFileName), "sha384-") {
+		t.Fatalf("expected client entry SRI hash, got %q", GetIntegrity(ClientEntryFileName))
+	}
+
+	r := httptest.NewRequest("GET", "/tiger/integrity-1", nil)
+	routeData, err := Hwy{}.GetRouteData(httptest.NewRecorder(), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*routeData.Deps) == 0 {
+		t.Fatal("expected deps")
+	}
+	for _, dep := range *routeData.Deps {
+		if !strings.HasPrefix(routeData.Integrity[dep], "sha384-") {
+			t.Errorf("expected SRI hash for dep %q, got %q", dep, routeData.Integrity[dep])
+		}
+	}
+
+	ssrInnerHTML, err := GetSSRInnerHTML(routeData, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(*ssrInnerHTML), "link.integrity = integrity[module]") ||
+		!strings.Contains(string(*ssrInnerHTML), routeData.Integrity[(*routeData.Deps)[0]]) {
+		t.Error("expected SSR script to apply integrity hashes to modulepreload links")
+	}
+}
+
+func TestGetSRIHash(t *testing.T) {
+	// echo -n "alert('Hello, world.');" | openssl dgst -sha384 -binary | openssl base64 -A
+	expected := "sha384-H8BRh8j48O9oYatfu5AZzq6A9RINhZO5H16dQZngK7T62em8MUt1FLm52t+eX6xO"
+	if got := getSRIHash([]byte("alert('Hello, world.');")); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,82 @@
+package router
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Island is an interactive component on a route, built as its own entry
+// point so it can be hydrated without the rest of the route's JS (see
+// DataFuncs.Islands). Server-rendered markup marks its boundary, e.g. with
+// a data-hwy-island="Name" attribute, for the client to hydrate it into.
+type Island struct {
+	Name    string    `json:"name"` // the source file's base name, without extension
+	SrcPath string    `json:"srcPath"`
+	OutPath string    `json:"outPath"`
+	Deps    *[]string `json:"deps"`
+}
+
+func getIslandName(srcPath string) string {
+	base := filepath.Base(srcPath)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// getClientImportURLs blanks the import URLs of static routes, keeping the
+// slice parallel to the other per-route data so the client can skip them.
+// The unblanked URLs are still used server-side, e.g. by
+// Hwy.ComponentRenderer.
+func (a *ActivePathData) getClientImportURLs() *[]string {
+	if a.ImportURLs == nil || a.MatchingPaths == nil {
+		return a.ImportURLs
+	}
+	var importURLs []string
+	for i, path := range *a.MatchingPaths {
+		if !path.Static || i >= len(*a.ImportURLs) {
+			continue
+		}
+		if importURLs == nil {
+			importURLs = append([]string(nil), *a.ImportURLs...)
+		}
+		importURLs[i] = ""
+	}
+	if importURLs == nil {
+		return a.ImportURLs
+	}
+	return &importURLs
+}
+
+// getIslandURLs maps the name of each island on the matched routes to its
+// import URL (of its legacy build if isLegacy), or returns nil if there are
+// none
+func (h Hwy) getIslandURLs(paths *[]*DecoratedPath, isLegacy bool) map[string]string {
+	if paths == nil {
+		return nil
+	}
+	var islandURLs map[string]string
+	for _, path := range *paths {
+		for _, island := range path.Islands {
+			if islandURLs == nil {
+				islandURLs = make(map[string]string)
+			}
+			outPath := island.OutPath
+			if isLegacy {
+				outPath = getLegacyFileName(outPath)
+			}
+			islandURLs[island.Name] = (*h.withAssetBasePrefix(&[]string{"/" + outPath}))[0]
+		}
+	}
+	return islandURLs
+}
+
+func (a *ActivePathData) getIslandOutPaths() []string {
+	if a.MatchingPaths == nil {
+		return nil
+	}
+	var outPaths []string
+	for _, path := range *a.MatchingPaths {
+		for _, island := range path.Islands {
+			outPaths = append(outPaths, island.OutPath)
+		}
+	}
+	return outPaths
+}
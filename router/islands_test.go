@@ -0,0 +1,111 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBuildIslands(t *testing.T) {
+	// Relative, like the other fixtures, so paths match esbuild's metafile
+	dir := "../tmp/islands"
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	files := map[string]string{
+		"client.entry.tsx":       `console.log("entry");`,
+		"pages/_index.ui.tsx":    `export default function Home() { return "home"; }`,
+		"pages/about.ui.tsx":     `export default function About() { return "about"; }`,
+		"components/Counter.tsx": `export default function Counter() { return 0; }`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counterSrc := filepath.Join(dir, "components/Counter.tsx")
+	err := Build(BuildOptions{
+		PagesSrcDir:    filepath.Join(dir, "pages"),
+		HashedOutDir:   filepath.Join(dir, "out"),
+		UnhashedOutDir: filepath.Join(dir, "out"),
+		ClientEntryOut: filepath.Join(dir, "out"),
+		ClientEntry:    filepath.Join(dir, "client.entry.tsx"),
+		DataFuncsMap: DataFuncsMap{
+			"/about": {Static: true, Islands: []string{counterSrc}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pathsFileBytes, err := os.ReadFile(filepath.Join(dir, "out/hwy_paths.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pathsFile PathsFile
+	if err := json.Unmarshal(pathsFileBytes, &pathsFile); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range pathsFile.Paths {
+		if path.Pattern != "/about" {
+			if path.Static || len(path.Islands) != 0 {
+				t.Errorf("expected %s to be interactive without islands, got %+v", path.Pattern, path)
+			}
+			continue
+		}
+		if !path.Static || len(path.Islands) != 1 {
+			t.Fatalf("expected a static route with one island, got %+v", path)
+		}
+		island := path.Islands[0]
+		if island.Name != "Counter" || island.SrcPath != counterSrc || island.OutPath == "" {
+			t.Errorf("unexpected island: %+v", island)
+		}
+		if island.Deps == nil || !slices.Contains(*island.Deps, island.OutPath) {
+			t.Errorf("expected island deps to include its own chunk, got %v", island.Deps)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "out", island.OutPath)); err != nil {
+			t.Errorf("expected island chunk to be written: %v", err)
+		}
+	}
+}
+
+func TestStaticRouteRouteData(t *testing.T) {
+	i := slices.IndexFunc(*instancePaths, func(path Path) bool { return path.Pattern == "/lion/$" })
+	original := (*instancePaths)[i]
+	t.Cleanup(func() { (*instancePaths)[i] = original })
+	(*instancePaths)[i].Static = true
+	(*instancePaths)[i].Islands = []Island{{
+		Name:    "Counter",
+		OutPath: "hwy_entry__counter.js",
+		Deps:    &[]string{"hwy_entry__counter.js", "hwy_chunk__shared.js"},
+	}}
+
+	routeData, err := Hwy{}.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/static-island", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	importURLs := *routeData.ImportURLs
+	if len(importURLs) != 2 || importURLs[0] == "" || importURLs[1] != "" {
+		t.Errorf("expected only the static route's import URL to be blanked, got %v", importURLs)
+	}
+	if slices.Contains(*routeData.Deps, original.OutPath) {
+		t.Errorf("expected static route's chunk to be left out of deps, got %v", *routeData.Deps)
+	}
+	if !slices.Contains(*routeData.Deps, "hwy_chunk__shared.js") {
+		t.Errorf("expected island deps to be included, got %v", *routeData.Deps)
+	}
+	if routeData.Islands["Counter"] != "/hwy_entry__counter.js" {
+		t.Errorf("expected island import URL, got %v", routeData.Islands)
+	}
+	// Server-side rendering still needs the static route's module
+	if (*routeData.activePathData.ImportURLs)[1] != "/"+original.OutPath {
+		t.Errorf("expected unblanked import URLs server-side, got %v", *routeData.activePathData.ImportURLs)
+	}
+}
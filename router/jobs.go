@@ -0,0 +1,91 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Job is a periodic background task, such as a warmer that re-renders pages
+// or refreshes a data cache before it goes stale
+type Job struct {
+	Name     string // used in logs, metrics, and error reports
+	Interval time.Duration
+	// Each run is delayed by a random duration of up to Jitter, so instances
+	// don't all hit the same upstreams at once
+	Jitter time.Duration
+	// By default, the first run happens after one interval
+	RunImmediately bool
+	Run            func(ctx context.Context) error
+}
+
+// JobMetricsCollector is optionally implemented by a MetricsCollector to
+// measure scheduled job runs
+type JobMetricsCollector interface {
+	ObserveJob(name string, duration time.Duration, err error)
+}
+
+var ErrInvalidJob = errors.New("invalid job")
+
+// Schedule runs job every Interval (plus jitter), as timed by Hwy.Clock if
+// it's a TimerClock, until Shutdown, which waits for a run in progress and
+// cancels its context if the deadline passes. Errors and recovered panics
+// are logged and reported to OnError, and don't stop the job. It returns
+// ErrShuttingDown once shutting down.
+func (h Hwy) Schedule(job Job) error {
+	if job.Run == nil || job.Interval <= 0 {
+		return fmt.Errorf("%w %q: Run and a positive Interval are required", ErrInvalidJob, job.Name)
+	}
+	l := instanceLifecycle
+	if !l.begin() {
+		return ErrShuttingDown
+	}
+	go func() {
+		defer l.done()
+		if job.RunImmediately {
+			h.runJob(l.ctx, job)
+		}
+		for {
+			next, stop := getAfter(instanceClock, job.getNextDelay())
+			select {
+			case <-l.stopping:
+				stop()
+				return
+			case <-next:
+			}
+			h.runJob(l.ctx, job)
+		}
+	}()
+	return nil
+}
+
+func (job Job) getNextDelay() time.Duration {
+	if job.Jitter <= 0 {
+		return job.Interval
+	}
+	return job.Interval + rand.N(job.Jitter)
+}
+
+func (h Hwy) runJob(ctx context.Context, job Job) {
+	startTime := time.Now()
+	_, err := callDataFunc(h, nil, job.Name, ErrorPhaseJob, func() (any, error) {
+		return nil, job.Run(ctx)
+	})
+	if err != nil {
+		h.getLogger().Error("job error", "job", job.Name, "error", err)
+	}
+	if collector, ok := h.Metrics.(JobMetricsCollector); ok {
+		collector.ObserveJob(job.Name, time.Since(startTime), err)
+	}
+}
+
+func (c *PrometheusCollector) ObserveJob(name string, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observe(c.jobDurations, name, duration)
+	if err != nil {
+		c.jobErrors[name]++
+	}
+}
@@ -0,0 +1,99 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduleRunsJobsUntilShutdown(t *testing.T) {
+	withTestLifecycle(t)
+	collector := NewPrometheusCollector()
+	reports := make(chan *ErrorReport, 10)
+	h := Hwy{Metrics: collector, OnError: func(report *ErrorReport) { reports <- report }}
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	prevClock := instanceClock
+	instanceClock = clock
+	t.Cleanup(func() { instanceClock = prevClock })
+
+	var runs atomic.Int32
+	err := h.Schedule(Job{
+		Name:           "warm",
+		Interval:       time.Minute,
+		Jitter:         time.Second,
+		RunImmediately: true,
+		Run: func(ctx context.Context) error {
+			if runs.Add(1) == 2 {
+				panic("kaboom")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Once a job waits for its next run, its previous run has finished
+	clock.WaitForTimers(1)
+	if runs.Load() != 1 {
+		t.Fatalf("expected 1 immediate run, got %d", runs.Load())
+	}
+	// Jitter only ever delays a run
+	clock.Advance(time.Minute - time.Nanosecond)
+	if runs.Load() != 1 {
+		t.Fatalf("expected no run before the interval, got %d", runs.Load())
+	}
+	clock.Advance(time.Second + time.Nanosecond)
+	clock.WaitForTimers(1)
+	if runs.Load() != 2 {
+		t.Fatalf("expected 2 runs once the interval and jitter passed, got %d", runs.Load())
+	}
+	clock.Advance(time.Minute + time.Second)
+	clock.WaitForTimers(1)
+	if runs.Load() != 3 {
+		t.Fatalf("expected 3 runs, got %d", runs.Load())
+	}
+	if err := h.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Shutdown waited for the job to stop
+	clock.Advance(time.Hour)
+	if runs.Load() != 3 {
+		t.Errorf("expected no runs after shutdown, got %d", runs.Load())
+	}
+
+	report := <-reports
+	var panicErr *PanicError
+	if report.Phase != ErrorPhaseJob || report.Pattern != "warm" || !errors.As(report.Err, &panicErr) {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, expected := range []string{`hwy_job_errors_total{job="warm"} 1`, `hwy_job_duration_seconds_count{job="warm"}`} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("expected %q in metrics:\n%s", expected, body)
+		}
+	}
+
+	if err := h.Schedule(Job{Name: "late", Interval: time.Second, Run: func(context.Context) error { return nil }}); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown, got %v", err)
+	}
+}
+
+func TestScheduleValidatesJobs(t *testing.T) {
+	withTestLifecycle(t)
+	h := Hwy{}
+	if err := h.Schedule(Job{Name: "no-run", Interval: time.Second}); !errors.Is(err, ErrInvalidJob) {
+		t.Errorf("expected ErrInvalidJob, got %v", err)
+	}
+	if err := h.Schedule(Job{Name: "no-interval", Run: func(context.Context) error { return nil }}); !errors.Is(err, ErrInvalidJob) {
+		t.Errorf("expected ErrInvalidJob, got %v", err)
+	}
+}
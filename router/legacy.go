@@ -0,0 +1,197 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Directory of HashedOutDir the legacy build (see BuildOptions.LegacyTargets)
+// is written to
+const LegacyOutDirName = "legacy"
+
+// LegacyOutput is the legacy build of an entry point, by the file name of its
+// modern build (or its client entry file name) in PathsFile.Legacy
+type LegacyOutput struct {
+	OutPath string   `json:"outPath"` // e.g. "legacy/hwy_entry__[hash].js"
+	Deps    []string `json:"deps"`
+}
+
+var ErrInvalidLegacyTarget = errors.New("invalid legacy target")
+
+var instanceLegacy map[string]LegacyOutput
+
+var legacyTargetRe = regexp.MustCompile(`^([a-z]+)(\d[\d.]*)$`)
+
+var legacyTargetVersions = map[string]api.Target{
+	"es2015": api.ES2015,
+	"es2016": api.ES2016,
+	"es2017": api.ES2017,
+	"es2018": api.ES2018,
+	"es2019": api.ES2019,
+	"es2020": api.ES2020,
+	"es2021": api.ES2021,
+	"es2022": api.ES2022,
+}
+
+var legacyTargetEngines = map[string]api.EngineName{
+	"chrome":  api.EngineChrome,
+	"edge":    api.EngineEdge,
+	"firefox": api.EngineFirefox,
+	"ios":     api.EngineIOS,
+	"opera":   api.EngineOpera,
+	"safari":  api.EngineSafari,
+}
+
+// parseLegacyTargets parses esbuild target strings, e.g. "es2017" or
+// "safari11.1"
+func parseLegacyTargets(targets []string) (api.Target, []api.Engine, error) {
+	target := api.DefaultTarget
+	var engines []api.Engine
+	for _, legacyTarget := range targets {
+		if version, ok := legacyTargetVersions[legacyTarget]; ok {
+			target = version
+			continue
+		}
+		match := legacyTargetRe.FindStringSubmatch(legacyTarget)
+		if match == nil {
+			return 0, nil, fmt.Errorf("%w: %s", ErrInvalidLegacyTarget, legacyTarget)
+		}
+		engine, ok := legacyTargetEngines[match[1]]
+		if !ok {
+			return 0, nil, fmt.Errorf("%w: %s", ErrInvalidLegacyTarget, legacyTarget)
+		}
+		engines = append(engines, api.Engine{Name: engine, Version: match[2]})
+	}
+	return target, engines, nil
+}
+
+// getLegacyOutputs maps the modern name of each entry point (by entryNames,
+// keyed by source path) to its output in metafile, a legacy build's
+func getLegacyOutputs(metafile *MetafileJSON, entryNames map[string]string, workers *workerBuilds) (map[string]LegacyOutput, error) {
+	legacyOutputs := make(map[string]LegacyOutput, len(entryNames))
+	for key, output := range metafile.Outputs {
+		name, ok := entryNames[output.EntryPoint]
+		if !ok || !strings.HasSuffix(key, ".js") {
+			continue
+		}
+		deps, err := findAllDependencies(metafile, key)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, workers.getWorkerDeps(metafile, deps)...)
+		outPath := path.Join(LegacyOutDirName, path.Base(key))
+		legacyDeps := make([]string, 0, len(deps))
+		for _, dep := range deps {
+			legacyDep := path.Join(LegacyOutDirName, dep)
+			// Like ClientEntryDeps, a client entry's deps leave out the entry
+			if legacyDep != outPath || !getIsClientEntryFileName(name) {
+				legacyDeps = append(legacyDeps, legacyDep)
+			}
+		}
+		legacyOutputs[name] = LegacyOutput{OutPath: outPath, Deps: legacyDeps}
+	}
+	return legacyOutputs, nil
+}
+
+// legacyBuild is the output of buildLegacy
+type legacyBuild struct {
+	metafile MetafileJSON
+	workers  *workerBuilds
+}
+
+// buildLegacy builds esbuildOpts' entry points again for opts.LegacyTargets,
+// into LegacyOutDirName in opts.HashedOutDir
+func buildLegacy(esbuildOpts api.BuildOptions, opts BuildOptions) (*legacyBuild, error) {
+	target, engines, err := parseLegacyTargets(opts.LegacyTargets)
+	if err != nil {
+		return nil, err
+	}
+	esbuildOpts.Target = target
+	esbuildOpts.Engines = engines
+	// Route modules are loaded with import(), so browsers without it can't
+	// be served anyway, and lowering it would leave require() calls behind
+	esbuildOpts.Supported = map[string]bool{"dynamic-import": true}
+	legacy := &legacyBuild{workers: &workerBuilds{}}
+	esbuildOpts.Outdir = filepath.Join(opts.HashedOutDir, LegacyOutDirName)
+	var cacheHit bool
+	legacy.metafile, cacheHit, err = bundle(esbuildOpts, legacy.workers, opts.getBuildCacheDir(LegacyOutDirName))
+	if err != nil {
+		return nil, err
+	}
+	if cacheHit {
+		opts.getLogger().Info("build cache hit: inputs unchanged, bundle restored", "stage", "legacy bundle")
+	}
+	return legacy, nil
+}
+
+// getOutputs matches the entry points of the legacy build to those of the
+// modern one, by metafile
+func (l *legacyBuild) getOutputs(opts BuildOptions, metafile *MetafileJSON) (map[string]LegacyOutput, error) {
+	// Client entries go by their file names, as their modern builds are
+	// moved out of HashedOutDir
+	entryNames := make(map[string]string, len(metafile.Outputs))
+	for key, output := range metafile.Outputs {
+		if output.EntryPoint != "" && strings.HasSuffix(key, ".js") {
+			entryNames[output.EntryPoint] = filepath.Base(key)
+		}
+	}
+	entryNames[opts.ClientEntry] = ClientEntryFileName
+	for _, clientEntry := range opts.ClientEntries {
+		entryNames[clientEntry.SrcPath] = GetClientEntryFileName(clientEntry.Name)
+	}
+	return getLegacyOutputs(&l.metafile, entryNames, l.workers)
+}
+
+func (h Hwy) getIsLegacyBrowser(r *http.Request) bool {
+	return len(instanceLegacy) > 0 && h.IsLegacyBrowser != nil && h.IsLegacyBrowser(r)
+}
+
+// getLegacyFileName returns the legacy build of a built file, or fileName if
+// it has none
+func getLegacyFileName(fileName string) string {
+	if legacyOutput, ok := instanceLegacy[fileName]; ok {
+		return legacyOutput.OutPath
+	}
+	return fileName
+}
+
+// getLegacyAssets returns the legacy builds of importURLs, and the deps of
+// those, of islands, and of the client entry
+func getLegacyAssets(importURLs *[]string, islands []string, clientEntryFileName string) (*[]string, *[]string) {
+	var legacyImportURLs *[]string
+	if importURLs != nil {
+		mapped := make([]string, len(*importURLs))
+		for i, importURL := range *importURLs {
+			mapped[i] = importURL
+			if importURL != "" && importURL != "/" {
+				mapped[i] = "/" + getLegacyFileName(strings.TrimPrefix(importURL, "/"))
+			}
+		}
+		legacyImportURLs = &mapped
+	}
+	var names []string
+	if importURLs != nil {
+		names = append(names, *importURLs...)
+	}
+	names = append(append(names, islands...), clientEntryFileName)
+	var deps []string
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		for _, dep := range instanceLegacy[strings.TrimPrefix(name, "/")].Deps {
+			if !slices.Contains(deps, dep) {
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return legacyImportURLs, &deps
+}
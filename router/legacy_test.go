@@ -0,0 +1,97 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestParseLegacyTargets(t *testing.T) {
+	target, engines, err := parseLegacyTargets([]string{"es2017", "chrome64", "safari11.1"})
+	if err != nil || target != api.ES2017 || len(engines) != 2 || engines[1] != (api.Engine{Name: api.EngineSafari, Version: "11.1"}) {
+		t.Errorf("unexpected targets %v %v (%v)", target, engines, err)
+	}
+	for _, legacyTarget := range []string{"ie11", "es5", "safari"} {
+		if _, _, err := parseLegacyTargets([]string{legacyTarget}); !errors.Is(err, ErrInvalidLegacyTarget) {
+			t.Errorf("%s: expected ErrInvalidLegacyTarget, got %v", legacyTarget, err)
+		}
+	}
+}
+
+func TestGetLegacyOutputs(t *testing.T) {
+	var metafile MetafileJSON
+	json.Unmarshal([]byte(`{"outputs":{
+		"out/legacy/hwy_entry__A.js":{"entryPoint":"client.entry.tsx","imports":[{"path":"out/legacy/hwy_chunk__C.js"}]},
+		"out/legacy/hwy_entry__B.js":{"entryPoint":"pages/tiger.ui.tsx","imports":[{"path":"out/legacy/hwy_chunk__C.js"}]},
+		"out/legacy/hwy_entry__B.js.map":{"entryPoint":"pages/tiger.ui.tsx"},
+		"out/legacy/hwy_chunk__C.js":{}
+	}}`), &metafile)
+	legacyOutputs, err := getLegacyOutputs(&metafile, map[string]string{
+		"client.entry.tsx":   ClientEntryFileName,
+		"pages/tiger.ui.tsx": "hwy_entry__modern.js",
+	}, &workerBuilds{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientEntry := legacyOutputs[ClientEntryFileName]
+	if clientEntry.OutPath != "legacy/hwy_entry__A.js" || !slices.Equal(clientEntry.Deps, []string{"legacy/hwy_chunk__C.js"}) {
+		t.Errorf("expected the client entry's deps to leave it out, got %+v", clientEntry)
+	}
+	tiger := legacyOutputs["hwy_entry__modern.js"]
+	if tiger.OutPath != "legacy/hwy_entry__B.js" || !slices.Equal(tiger.Deps, []string{"legacy/hwy_entry__B.js", "legacy/hwy_chunk__C.js"}) {
+		t.Errorf("unexpected route output %+v", tiger)
+	}
+}
+
+func TestLegacyBrowser(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+
+	paths := GetPathsFromPageFiles("tiger.ui.tsx")
+	paths[0].OutPath, paths[0].Deps = "tiger.js", &[]string{"tiger.js", "shared.js"}
+	pathsFileBytes, _ := json.Marshal(PathsFile{
+		Paths:           paths,
+		ClientEntryDeps: []string{"shared.js"},
+		Legacy: map[string]LegacyOutput{
+			"tiger.js":          {OutPath: "legacy/tiger.js", Deps: []string{"legacy/tiger.js", "legacy/shared.js", "legacy/helpers.js"}},
+			ClientEntryFileName: {OutPath: "legacy/entry.js", Deps: []string{"legacy/shared.js", "legacy/helpers.js"}},
+		},
+	})
+	h := Hwy{
+		FS: fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}},
+		IsLegacyBrowser: func(r *http.Request) bool {
+			return strings.Contains(r.UserAgent(), "OldWebView")
+		},
+	}
+	if err := h.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	getRouteData := func(userAgent string) *GetRouteDataOutput {
+		r := httptest.NewRequest("GET", "/tiger?legacy-browser="+userAgent, nil)
+		r.Header.Set("User-Agent", userAgent)
+		routeData, err := h.GetRouteData(httptest.NewRecorder(), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return routeData
+	}
+
+	routeData := getRouteData("OldWebView/1.0")
+	if !slices.Equal(*routeData.ImportURLs, []string{"/legacy/tiger.js"}) || !slices.Equal(*routeData.Deps, []string{"legacy/tiger.js", "legacy/shared.js", "legacy/helpers.js"}) {
+		t.Errorf("expected the legacy build, got %v %v", *routeData.ImportURLs, *routeData.Deps)
+	}
+	if !routeData.isLegacy || getLegacyFileName(GetClientEntryFileName(routeData.ClientEntry)) != "legacy/entry.js" {
+		t.Error("expected the legacy client entry")
+	}
+
+	routeData = getRouteData("Modern/1.0")
+	if !slices.Equal(*routeData.ImportURLs, []string{"/tiger.js"}) || !slices.Equal(*routeData.Deps, []string{"tiger.js", "shared.js"}) {
+		t.Errorf("expected the modern build, got %v %v", *routeData.ImportURLs, *routeData.Deps)
+	}
+}
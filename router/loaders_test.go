@@ -0,0 +1,159 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestLoaderItem(loaders ...Loader) *gmpdItem {
+	paths := make([]*DecoratedPath, 0, len(loaders))
+	for _, loader := range loaders {
+		paths = append(paths, &DecoratedPath{DataFuncs: &DataFuncs{Loader: loader}})
+	}
+	return &gmpdItem{
+		FullyDecoratedMatchingPaths: &paths,
+		Params:                      &map[string]string{},
+		SplatSegments:               &[]string{},
+	}
+}
+
+func TestRunLoadersSequentialPassesParentData(t *testing.T) {
+	item := newTestLoaderItem(
+		func(props *LoaderProps) (any, error) {
+			return "root", nil
+		},
+		func(props *LoaderProps) (any, error) {
+			return (*props.ParentLoadersData)[0].(string) + "/child", nil
+		},
+		func(props *LoaderProps) (any, error) {
+			return (*props.ParentLoadersData)[1].(string) + "/grandchild", nil
+		},
+	)
+
+	h := Hwy{LoaderStrategy: LoaderStrategySequential}
+	loadersData, errs := h.runLoaders(&http.Request{}, item, nil)
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error at index %d: %v", i, err)
+		}
+	}
+	if loadersData[2] != "root/child/grandchild" {
+		t.Errorf("expected root/child/grandchild, got %v", loadersData[2])
+	}
+}
+
+func TestRunLoadersSequentialStopsAfterError(t *testing.T) {
+	childRan := false
+	item := newTestLoaderItem(
+		func(props *LoaderProps) (any, error) {
+			return nil, errors.New("boom")
+		},
+		func(props *LoaderProps) (any, error) {
+			childRan = true
+			return nil, nil
+		},
+	)
+
+	h := Hwy{LoaderStrategy: LoaderStrategySequential}
+	_, errs := h.runLoaders(&http.Request{}, item, nil)
+
+	if errs[0] == nil {
+		t.Errorf("expected parent error")
+	}
+	if childRan {
+		t.Errorf("expected child loader to be skipped after parent error")
+	}
+}
+
+func TestRunLoadersBoundedRespectsLimit(t *testing.T) {
+	var running, maxRunning int32
+	started, release := make(chan struct{}, 5), make(chan struct{})
+	loader := func(props *LoaderProps) (any, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		started <- struct{}{}
+		<-release
+		atomic.AddInt32(&running, -1)
+		return nil, nil
+	}
+	item := newTestLoaderItem(loader, loader, loader, loader, loader)
+
+	h := Hwy{LoaderStrategy: LoaderStrategyBounded, MaxConcurrentLoaders: 2}
+	done := make(chan struct{})
+	go func() {
+		h.runLoaders(&http.Request{}, item, nil)
+		close(done)
+	}()
+	// Loaders block until released, so all the bound allows are running
+	// at once before the first release
+	<-started
+	<-started
+	for range 5 {
+		release <- struct{}{}
+	}
+	<-done
+
+	if maxRunning != 2 {
+		t.Errorf("expected exactly 2 concurrent loaders, got %d", maxRunning)
+	}
+}
+
+func setTestDataFuncs(t *testing.T, pattern string, dataFuncs *DataFuncs) {
+	for i, path := range *instancePaths {
+		if path.Pattern == pattern {
+			(*instancePaths)[i].DataFuncs = dataFuncs
+			t.Cleanup(func() {
+				(*instancePaths)[i].DataFuncs = nil
+				gmpdCache = NewLRUCache(gmpdCacheSize)
+			})
+			return
+		}
+	}
+	t.Fatalf("no path with pattern %s", pattern)
+}
+
+func TestLoaderNotFoundRendersNearestCatch(t *testing.T) {
+	setTestDataFuncs(t, "/dashboard", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return "dashboard", nil
+		},
+	})
+	setTestDataFuncs(t, "/dashboard/customers/$customer_id", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return nil, NotFound()
+		},
+	})
+
+	activePathData := testGetMatchingPathData("/dashboard/customers/not-a-customer")
+
+	if activePathData.Status != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", activePathData.Status)
+	}
+	expectedTypes := []string{PathTypeStaticLayout, PathTypeNonUltimateSplat}
+	if len(*activePathData.MatchingPaths) != len(expectedTypes) {
+		t.Fatalf("expected %d matching paths, got %d", len(expectedTypes), len(*activePathData.MatchingPaths))
+	}
+	for i, path := range *activePathData.MatchingPaths {
+		if path.PathType != expectedTypes[i] {
+			t.Errorf("expected matching path %d to be %s, got %s", i, expectedTypes[i], path.PathType)
+		}
+	}
+	if (*activePathData.LoadersData)[0] != "dashboard" {
+		t.Errorf("expected parent loader data to be retained")
+	}
+	splatSegments := *activePathData.SplatSegments
+	if len(splatSegments) != 2 || splatSegments[0] != "customers" || splatSegments[1] != "not-a-customer" {
+		t.Errorf("expected splat segments [customers not-a-customer], got %v", splatSegments)
+	}
+	if activePathData.OutermostErrorBoundaryIndex != -2 {
+		t.Errorf("expected no error boundary, got %d", activePathData.OutermostErrorBoundaryIndex)
+	}
+}
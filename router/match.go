@@ -0,0 +1,221 @@
+package router
+
+import (
+	"slices"
+	"strings"
+)
+
+// CompiledPattern is a route pattern split into segments once, so it can be
+// matched against many paths
+type CompiledPattern struct {
+	pattern        string // without leading slash or trailing "/_index"
+	segments       []string
+	truthySegments []string
+	isCatch        bool
+	// Index of a non-terminal "$" segment, or -1
+	midSplatIndex int
+	// Parallel to segments, or nil if none are compound
+	compound []*compoundSegment
+}
+
+// MatchResult is the outcome of matching a single pattern against a path.
+// Matching is the first step of routing: every matching pattern then
+// competes on Score (see Hwy.ExplainMatch).
+type MatchResult struct {
+	Matches            bool
+	Params             map[string]string
+	Score              int
+	RealSegmentsLength int
+
+	midSplat *midSplat
+}
+
+// midSplat locates the segments covered by a non-terminal "$" within the
+// path's non-empty segments
+type midSplat struct {
+	start, end int
+	// Path segments covered by the whole pattern
+	consumed int
+}
+
+func CompilePattern(pattern string) *CompiledPattern {
+	pattern = strings.TrimSuffix(pattern, "/_index") // needs to be first
+	pattern = strings.TrimPrefix(pattern, "/")       // needs to be second
+	segments := strings.Split(pattern, "/")
+	midSplatIndex := slices.Index(segments[:len(segments)-1], "$")
+	return &CompiledPattern{
+		pattern:        pattern,
+		segments:       segments,
+		truthySegments: getTruthySegments(segments),
+		isCatch:        segments[len(segments)-1] == "$",
+		midSplatIndex:  midSplatIndex,
+		compound:       getCompoundSegments(segments),
+	}
+}
+
+// Match reports whether pattern (e.g. "/tiger/$tiger_id") matches path
+func Match(pattern string, path string) MatchResult {
+	return CompilePattern(pattern).Match(path)
+}
+
+func (c *CompiledPattern) Match(path string) MatchResult {
+	return c.matchSplitPath(splitPath(path))
+}
+
+// splitPath is done once per request, rather than once per candidate route
+type splitPathResult struct {
+	path           string // without leading slash
+	segments       []string
+	truthySegments []string
+}
+
+func splitPath(path string) splitPathResult {
+	path = strings.TrimPrefix(path, "/")
+	segments := strings.Split(path, "/")
+	return splitPathResult{
+		path:           path,
+		segments:       segments,
+		truthySegments: getTruthySegments(segments),
+	}
+}
+
+func getTruthySegments(segments []string) []string {
+	truthySegments := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if len(segment) > 0 {
+			truthySegments = append(truthySegments, segment)
+		}
+	}
+	return truthySegments
+}
+
+// matchSplitPath does not allocate unless the pattern matches
+func (c *CompiledPattern) matchSplitPath(split splitPathResult) MatchResult {
+	if c.midSplatIndex != -1 {
+		return c.matchMidSplit(split)
+	}
+	pathSegments := split.segments
+	adjPatternSegmentsLength := len(c.segments)
+	pathSegmentsLength := len(pathSegments)
+	if c.isCatch {
+		adjPatternSegmentsLength--
+	}
+	if adjPatternSegmentsLength > pathSegmentsLength {
+		return MatchResult{}
+	}
+	matches := false
+	if c.pattern == split.path {
+		matches = true
+	} else {
+		for i, patternSegment := range c.segments {
+			if i < pathSegmentsLength && patternSegment == pathSegments[i] {
+				matches = true
+				continue
+			}
+			if c.compound != nil && c.compound[i] != nil {
+				matches = i < pathSegmentsLength && c.compound[i].matches(pathSegments[i])
+				if matches {
+					continue
+				}
+				break
+			}
+			if patternSegment == "$" || (strings.HasPrefix(patternSegment, "$") && i < pathSegmentsLength) {
+				matches = true
+				continue
+			}
+			matches = false
+			break
+		}
+	}
+	if !matches {
+		return MatchResult{}
+	}
+	params := make(map[string]string)
+	for i, patternSegment := range c.segments {
+		if i >= pathSegmentsLength || patternSegment == pathSegments[i] {
+			continue
+		}
+		if c.compound != nil && c.compound[i] != nil {
+			c.compound[i].setParams(params, pathSegments[i])
+		} else if len(patternSegment) > 1 && patternSegment[0] == '$' {
+			params[patternSegment[1:]] = decodeSegment(pathSegments[i])
+		}
+	}
+	strength := getMatchStrength(c.truthySegments, split.truthySegments, c.compound)
+	return MatchResult{
+		Matches:            matches,
+		Params:             params,
+		Score:              strength.Score,
+		RealSegmentsLength: strength.RealSegmentsLength,
+	}
+}
+
+// matchMidSplit matches patterns with a non-terminal "$" (e.g.
+// "files/$/preview"), which covers one or more segments: as many as it can
+// while the rest of the pattern still matches
+func (c *CompiledPattern) matchMidSplit(split splitPathResult) MatchResult {
+	pathSegments := split.truthySegments
+	prefix := c.segments[:c.midSplatIndex]
+	suffix := c.segments[c.midSplatIndex+1:]
+	if len(pathSegments) < len(prefix)+1+len(suffix) {
+		return MatchResult{}
+	}
+	prefixScore, ok := c.matchSegments(0, prefix, pathSegments)
+	if !ok {
+		return MatchResult{}
+	}
+	suffixStart := c.midSplatIndex + 1
+	for end := len(pathSegments) - len(suffix); end > len(prefix); end-- {
+		suffixScore, ok := c.matchSegments(suffixStart, suffix, pathSegments[end:])
+		if !ok {
+			continue
+		}
+		params := make(map[string]string)
+		c.setParams(params, 0, prefix, pathSegments)
+		c.setParams(params, suffixStart, suffix, pathSegments[end:])
+		return MatchResult{
+			Matches:            true,
+			Params:             params,
+			Score:              prefixScore + scoreSplat + suffixScore,
+			RealSegmentsLength: len(pathSegments),
+			midSplat:           &midSplat{start: len(prefix), end: end, consumed: end + len(suffix)},
+		}
+	}
+	return MatchResult{}
+}
+
+// matchSegments matches patternSegments (starting at index offset of the
+// pattern's segments) against the start of pathSegments, scoring as
+// getMatchStrength does
+func (c *CompiledPattern) matchSegments(offset int, patternSegments []string, pathSegments []string) (int, bool) {
+	score := 0
+	for i, patternSegment := range patternSegments {
+		switch {
+		case patternSegment == pathSegments[i]:
+			score += scoreStatic
+		case c.compound != nil && c.compound[offset+i] != nil:
+			if !c.compound[offset+i].matches(pathSegments[i]) {
+				return 0, false
+			}
+			score += scoreCompound
+		case strings.HasPrefix(patternSegment, "$") && pathSegments[i] != "":
+			score += scoreDynamic
+		default:
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+// setParams sets the params of patternSegments (starting at index offset of
+// the pattern's segments) from pathSegments
+func (c *CompiledPattern) setParams(params map[string]string, offset int, patternSegments []string, pathSegments []string) {
+	for i, patternSegment := range patternSegments {
+		switch {
+		case c.compound != nil && c.compound[offset+i] != nil:
+			c.compound[offset+i].setParams(params, pathSegments[i])
+		case len(patternSegment) > 1 && patternSegment[0] == '$':
+			params[patternSegment[1:]] = decodeSegment(pathSegments[i])
+		}
+	}
+}
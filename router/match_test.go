@@ -0,0 +1,67 @@
+package router
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	result := Match("/tiger/$tiger_id/$", "/tiger/123/456/789")
+	if !result.Matches || result.Params["tiger_id"] != "123" {
+		t.Errorf("expected match with tiger_id 123, got %+v", result)
+	}
+	if Match("/tiger/$tiger_id/$tiger_cub_id", "/tiger/123").Matches {
+		t.Errorf("expected pattern longer than path not to match")
+	}
+	if Match("/lion", "/tiger").Matches {
+		t.Errorf("expected static mismatch not to match")
+	}
+	if !Match("/_index", "/").Matches {
+		t.Errorf("expected root index to match /")
+	}
+}
+
+func TestMatchMidSplat(t *testing.T) {
+	result := Match("/files/$/preview/$size", "/files/a/b/preview/large")
+	if !result.Matches || result.Params["size"] != "large" || result.midSplat.start != 1 || result.midSplat.end != 3 {
+		t.Errorf("expected a match covering a/b, got %+v", result)
+	}
+	if result := Match("/files/$/preview", "/files/a/preview/b/preview/edit"); !result.Matches || result.midSplat.end != 4 {
+		t.Errorf("expected the splat to cover as many segments as it can, got %+v", result)
+	}
+	if Match("/files/$/preview", "/files/preview").Matches {
+		t.Errorf("expected the splat to need at least one segment")
+	}
+	if Match("/files/$/preview", "/files/a/b").Matches {
+		t.Errorf("expected the rest of the pattern to still need matching")
+	}
+}
+
+// Fuzz workers each run the fixture build in init, so use -parallel 1
+func FuzzMatch(f *testing.F) {
+	for _, path := range filesToMock {
+		if after, ok := strings.CutPrefix(path, "pages/"); ok {
+			pattern := GetPathsFromPageFiles(after)[0].Pattern
+			f.Add(pattern, "/tiger/123/456")
+			f.Add(pattern, "/"+strings.ReplaceAll(strings.TrimSuffix(pattern, "/_index"), "$", "x"))
+		}
+	}
+	f.Add("", "")
+	f.Add("/$a/$", "/")
+
+	f.Fuzz(func(t *testing.T, pattern string, path string) {
+		result := Match(pattern, path)
+		if !result.Matches {
+			return
+		}
+		for key, value := range result.Params {
+			if !strings.Contains(pattern, "$"+key) {
+				t.Errorf("param %q not in pattern %q", key, pattern)
+			}
+			// an encoded slash decodes to one within its segment
+			if strings.Contains(value, "/") && !strings.Contains(strings.ToUpper(path), "%2F") {
+				t.Errorf("param %q value %q spans segments", key, value)
+			}
+		}
+	})
+}
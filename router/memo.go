@@ -0,0 +1,39 @@
+package router
+
+type memoEntry struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// Memo returns the result of fn for key, calling it at most once per request.
+// The cache is shared by every loader, action, and head in the request, so
+// concurrent loaders needing the same upstream data (e.g. the current user)
+// fetch it only once; later callers wait for the first. Errors are cached too.
+func (p DataProps) Memo(key string, fn func() (any, error)) (any, error) {
+	if p.scope == nil {
+		return fn()
+	}
+	s := p.scope
+	s.memoMu.Lock()
+	if entry, ok := s.memo[key]; ok {
+		s.memoMu.Unlock()
+		<-entry.done
+		return entry.value, entry.err
+	}
+	entry := &memoEntry{done: make(chan struct{})}
+	s.memo[key] = entry
+	s.memoMu.Unlock()
+
+	// If fn panics, waiting callers get an error rather than blocking forever
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			entry.err = &PanicError{Value: recovered}
+			close(entry.done)
+			panic(recovered)
+		}
+	}()
+	entry.value, entry.err = fn()
+	close(entry.done)
+	return entry.value, entry.err
+}
@@ -0,0 +1,40 @@
+package router
+
+import (
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMemoSharedAcrossLoaders(t *testing.T) {
+	var fetches atomic.Int32
+	getUser := func(props *LoaderProps) (any, error) {
+		return props.Memo("user", func() (any, error) {
+			fetches.Add(1)
+			return "bob", nil
+		})
+	}
+	setTestDataFuncs(t, "/lion", &DataFuncs{Loader: getUser})
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{Loader: getUser})
+
+	h := Hwy{}
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/memo-test", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("expected 1 fetch, got %d", got)
+	}
+	for i, data := range *routeData.LoadersData {
+		if data != "bob" {
+			t.Errorf("expected loader %d to get memoized user, got %v", i, data)
+		}
+	}
+
+	if _, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/memo-test-2", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("expected memo not to outlive its request, got %d fetches", got)
+	}
+}
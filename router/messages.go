@@ -0,0 +1,121 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"strings"
+)
+
+var ErrMissingMessages = errors.New("message catalog not found")
+
+// Locale to flattened message key to message, loaded by Initialize
+var instanceMessages map[string]map[string]string
+var instanceDefaultLocale string
+
+// loadMessages loads a catalog for each of I18n.Locales from I18n.MessagesDir
+// (e.g. "messages/de.json"), then merges in I18n.Messages. Catalogs may nest
+// objects, flattened to dotted keys ({"nav": {"home": "Home"}} is "nav.home").
+func (h Hwy) loadMessages() []error {
+	instanceMessages = nil
+	instanceDefaultLocale = ""
+	c := h.I18n
+	if c == nil {
+		return nil
+	}
+	instanceDefaultLocale = c.DefaultLocale
+	var problems []error
+	messages := make(map[string]map[string]string, len(c.Locales))
+	if c.MessagesDir != "" {
+		for _, locale := range c.Locales {
+			file := path.Join(c.MessagesDir, locale+".json")
+			catalogBytes, err := fs.ReadFile(h.FS, file)
+			if errors.Is(err, fs.ErrNotExist) {
+				problems = append(problems, fmt.Errorf("%w: %s", ErrMissingMessages, file))
+				continue
+			}
+			if err != nil {
+				problems = append(problems, err)
+				continue
+			}
+			var catalog map[string]any
+			if err := json.Unmarshal(catalogBytes, &catalog); err != nil {
+				problems = append(problems, fmt.Errorf("error parsing %s: %w", file, err))
+				continue
+			}
+			messages[locale] = make(map[string]string)
+			flattenMessages(messages[locale], "", catalog)
+		}
+	}
+	for locale, catalog := range c.Messages {
+		if messages[locale] == nil {
+			messages[locale] = make(map[string]string, len(catalog))
+		}
+		maps.Copy(messages[locale], catalog)
+	}
+	instanceMessages = messages
+	return problems
+}
+
+func flattenMessages(messages map[string]string, prefix string, catalog map[string]any) {
+	for key, value := range catalog {
+		switch value := value.(type) {
+		case string:
+			messages[prefix+key] = value
+		case map[string]any:
+			flattenMessages(messages, prefix+key+".", value)
+		}
+	}
+}
+
+// Translate returns the message for key in locale (falling back to
+// I18n.DefaultLocale, then to key itself), with "{name}" placeholders
+// replaced by args, given as name-value pairs. When a "count" arg is given,
+// the "key.one" message is used if count is 1, and "key.other" otherwise,
+// where they exist.
+func Translate(locale string, key string, args ...any) string {
+	if locale == "" {
+		locale = instanceDefaultLocale
+	}
+	lookupKey := key
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] == "count" {
+			lookupKey = key + ".other"
+			if fmt.Sprint(args[i+1]) == "1" {
+				lookupKey = key + ".one"
+			}
+			break
+		}
+	}
+	message, ok := lookupMessage(locale, lookupKey)
+	if !ok && lookupKey != key {
+		message, ok = lookupMessage(locale, key)
+	}
+	if !ok {
+		message = key
+	}
+	if len(args) < 2 || !strings.Contains(message, "{") {
+		return message
+	}
+	replacements := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		replacements = append(replacements, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+	return strings.NewReplacer(replacements...).Replace(message)
+}
+
+func lookupMessage(locale string, key string) (string, bool) {
+	if message, ok := instanceMessages[locale][key]; ok {
+		return message, true
+	}
+	message, ok := instanceMessages[instanceDefaultLocale][key]
+	return message, ok
+}
+
+// T translates key into the request's locale (see Translate)
+func (p DataProps) T(key string, args ...any) string {
+	return Translate(p.Locale, key, args...)
+}
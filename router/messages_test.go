@@ -0,0 +1,74 @@
+package router
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func setTestMessages(t *testing.T, h Hwy) []error {
+	t.Cleanup(func() { instanceMessages, instanceDefaultLocale = nil, "" })
+	return h.loadMessages()
+}
+
+func TestTranslate(t *testing.T) {
+	problems := setTestMessages(t, Hwy{
+		FS: fstest.MapFS{
+			"messages/en.json": {Data: []byte(`{"greeting": "Hello, {name}!", "cubs": {"one": "{count} cub", "other": "{count} cubs"}, "nav": {"home": "Home"}}`)},
+			"messages/de.json": {Data: []byte(`{"greeting": "Hallo, {name}!", "cubs": {"one": "{count} Junges", "other": "{count} Junge"}}`)},
+		},
+		I18n: &I18nConfig{
+			Locales:       []string{"en", "de", "fr"},
+			DefaultLocale: "en",
+			MessagesDir:   "messages",
+			Messages:      map[string]map[string]string{"de": {"nav.home": "Startseite"}},
+		},
+	})
+	if len(problems) != 1 || !errors.Is(problems[0], ErrMissingMessages) {
+		t.Errorf("expected a missing catalog problem for fr, got %v", problems)
+	}
+
+	for _, c := range []struct {
+		locale, key string
+		args        []any
+		expected    string
+	}{
+		{"de", "greeting", []any{"name", "Simba"}, "Hallo, Simba!"},
+		{"", "greeting", []any{"name", "Simba"}, "Hello, Simba!"},
+		{"de", "cubs", []any{"count", 1}, "1 Junges"},
+		{"de", "cubs", []any{"count", 3}, "3 Junge"},
+		{"de", "nav.home", nil, "Startseite"},
+		{"fr", "nav.home", nil, "Home"},
+		{"de", "missing.key", nil, "missing.key"},
+	} {
+		if got := Translate(c.locale, c.key, c.args...); got != c.expected {
+			t.Errorf("%s %s: expected %q, got %q", c.locale, c.key, c.expected, got)
+		}
+	}
+}
+
+func TestTranslateInHead(t *testing.T) {
+	h := Hwy{I18n: &I18nConfig{
+		Locales:       []string{"en", "de"},
+		DefaultLocale: "en",
+		Messages: map[string]map[string]string{
+			"en": {"lion.title": "Lion {name}"},
+			"de": {"lion.title": "Löwe {name}"},
+		},
+	}}
+	setTestMessages(t, h)
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Head: func(props *HeadProps) (*[]HeadBlock, error) {
+			return &[]HeadBlock{{Title: props.T("lion.title", "name", (*props.SplatSegments)[0])}}, nil
+		},
+	})
+
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/de/lion/translated", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routeData.Title != "Löwe translated" {
+		t.Errorf("expected the title in the routing locale, got %q", routeData.Title)
+	}
+}
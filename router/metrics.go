@@ -0,0 +1,240 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsCollector receives request pipeline measurements. Set it as
+// Hwy.Metrics (and BuildOptions.Metrics for build durations). Patterns are
+// route patterns, not raw paths, so label cardinality stays bounded.
+type MetricsCollector interface {
+	ObserveRequest(pattern string, status int)
+	ObserveLoader(pattern string, duration time.Duration, err error)
+	ObserveAction(pattern string, duration time.Duration, err error)
+	ObserveMatchCache(hit bool)
+	ObserveBuild(duration time.Duration)
+}
+
+// PrometheusCollector is a dependency-free MetricsCollector that serves its
+// metrics in the Prometheus text exposition format (mount it at /metrics)
+type PrometheusCollector struct {
+	mu              sync.Mutex
+	buckets         []float64
+	requests        map[[2]string]uint64
+	prefetches      map[[2]string]uint64
+	loaderDurations map[string]*histogram
+	loaderErrors    map[string]uint64
+	actionDurations map[string]*histogram
+	actionErrors    map[string]uint64
+	jobDurations    map[string]*histogram
+	jobErrors       map[string]uint64
+	exposures       map[[2]string]uint64
+	overBudget      map[string]uint64
+	matchCacheHits  uint64
+	matchCacheMiss  uint64
+	lastBuildTime   float64
+	builds          uint64
+}
+
+// Default histogram buckets, in seconds
+var DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// NewPrometheusCollector uses DefaultMetricsBuckets when buckets is empty
+func NewPrometheusCollector(buckets ...float64) *PrometheusCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultMetricsBuckets
+	}
+	return &PrometheusCollector{
+		buckets:         buckets,
+		requests:        make(map[[2]string]uint64),
+		prefetches:      make(map[[2]string]uint64),
+		loaderDurations: make(map[string]*histogram),
+		loaderErrors:    make(map[string]uint64),
+		actionDurations: make(map[string]*histogram),
+		actionErrors:    make(map[string]uint64),
+		jobDurations:    make(map[string]*histogram),
+		jobErrors:       make(map[string]uint64),
+		exposures:       make(map[[2]string]uint64),
+		overBudget:      make(map[string]uint64),
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (c *PrometheusCollector) observe(m map[string]*histogram, key string, d time.Duration) {
+	hist, ok := m[key]
+	if !ok {
+		hist = &histogram{counts: make([]uint64, len(c.buckets))}
+		m[key] = hist
+	}
+	seconds := d.Seconds()
+	for i, bound := range c.buckets {
+		if seconds <= bound {
+			hist.counts[i]++
+		}
+	}
+	hist.sum += seconds
+	hist.count++
+}
+
+func (c *PrometheusCollector) ObserveRequest(pattern string, status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests[[2]string{pattern, strconv.Itoa(status)}]++
+}
+
+func (c *PrometheusCollector) ObserveLoader(pattern string, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observe(c.loaderDurations, pattern, duration)
+	if err != nil {
+		c.loaderErrors[pattern]++
+	}
+}
+
+func (c *PrometheusCollector) ObserveAction(pattern string, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observe(c.actionDurations, pattern, duration)
+	if err != nil {
+		c.actionErrors[pattern]++
+	}
+}
+
+func (c *PrometheusCollector) ObserveMatchCache(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hit {
+		c.matchCacheHits++
+	} else {
+		c.matchCacheMiss++
+	}
+}
+
+func (c *PrometheusCollector) ObserveBuild(duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastBuildTime = duration.Seconds()
+	c.builds++
+}
+
+func (c *PrometheusCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var sb strings.Builder
+
+	writeStatusCounters(&sb, "hwy_requests_total", "Requests by route pattern and status", c.requests)
+	writeStatusCounters(&sb, "hwy_prefetch_requests_total", "Prefetch requests by route pattern and status", c.prefetches)
+
+	c.writeHistograms(&sb, "hwy_loader_duration_seconds", "Loader duration by route pattern", "pattern", c.loaderDurations)
+	writeCounters(&sb, "hwy_loader_errors_total", "Loader errors by route pattern", "pattern", c.loaderErrors)
+	c.writeHistograms(&sb, "hwy_action_duration_seconds", "Action duration by route pattern", "pattern", c.actionDurations)
+	writeCounters(&sb, "hwy_action_errors_total", "Action errors by route pattern", "pattern", c.actionErrors)
+	c.writeHistograms(&sb, "hwy_job_duration_seconds", "Scheduled job duration by job name", "job", c.jobDurations)
+	writeCounters(&sb, "hwy_job_errors_total", "Scheduled job errors by job name", "job", c.jobErrors)
+	writeLabelPairCounters(&sb, "hwy_experiment_exposures_total", "Experiment exposures by experiment and variant", [2]string{"experiment", "variant"}, c.exposures)
+	writeCounters(&sb, "hwy_payload_over_budget_total", "Loader data over its payload budget by route pattern", "pattern", c.overBudget)
+
+	writeHeader(&sb, "hwy_match_cache_hits_total", "counter", "Route matcher cache hits")
+	fmt.Fprintf(&sb, "hwy_match_cache_hits_total %d\n", c.matchCacheHits)
+	writeHeader(&sb, "hwy_match_cache_misses_total", "counter", "Route matcher cache misses")
+	fmt.Fprintf(&sb, "hwy_match_cache_misses_total %d\n", c.matchCacheMiss)
+
+	writeHeader(&sb, "hwy_builds_total", "counter", "Completed builds")
+	fmt.Fprintf(&sb, "hwy_builds_total %d\n", c.builds)
+	writeHeader(&sb, "hwy_build_duration_seconds", "gauge", "Duration of the last build")
+	fmt.Fprintf(&sb, "hwy_build_duration_seconds %s\n", formatFloat(c.lastBuildTime))
+
+	w.Write([]byte(sb.String()))
+}
+
+func (c *PrometheusCollector) writeHistograms(sb *strings.Builder, name, help, label string, m map[string]*histogram) {
+	writeHeader(sb, name, "histogram", help)
+	for _, key := range getSortedKeys(m) {
+		hist := m[key]
+		for i, bound := range c.buckets {
+			fmt.Fprintf(sb, "%s_bucket{%s=%q,le=%q} %d\n", name, label, key, formatFloat(bound), hist.counts[i])
+		}
+		fmt.Fprintf(sb, "%s_bucket{%s=%q,le=\"+Inf\"} %d\n", name, label, key, hist.count)
+		fmt.Fprintf(sb, "%s_sum{%s=%q} %s\n", name, label, key, formatFloat(hist.sum))
+		fmt.Fprintf(sb, "%s_count{%s=%q} %d\n", name, label, key, hist.count)
+	}
+}
+
+func writeStatusCounters(sb *strings.Builder, name, help string, m map[[2]string]uint64) {
+	writeLabelPairCounters(sb, name, help, [2]string{"pattern", "status"}, m)
+}
+
+func writeLabelPairCounters(sb *strings.Builder, name, help string, labels [2]string, m map[[2]string]uint64) {
+	writeHeader(sb, name, "counter", help)
+	keys := make([][2]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, key := range keys {
+		fmt.Fprintf(sb, "%s{%s=%q,%s=%q} %d\n", name, labels[0], key[0], labels[1], key[1], m[key])
+	}
+}
+
+func writeCounters(sb *strings.Builder, name, help, label string, m map[string]uint64) {
+	writeHeader(sb, name, "counter", help)
+	for _, key := range getSortedKeys(m) {
+		fmt.Fprintf(sb, "%s{%s=%q} %d\n", name, label, key, m[key])
+	}
+}
+
+func writeHeader(sb *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func getSortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (h Hwy) observeRequest(r *http.Request, routeData *GetRouteDataOutput, err error) {
+	observe := h.Metrics.ObserveRequest
+	if GetIsPrefetchRequest(r) {
+		prefetchCollector, ok := h.Metrics.(PrefetchMetricsCollector)
+		if !ok {
+			return
+		}
+		observe = prefetchCollector.ObservePrefetch
+	}
+	switch {
+	case err != nil:
+		observe("", http.StatusInternalServerError)
+	case routeData.GuardOutcome != nil:
+		observe(routeData.Pattern, routeData.GuardOutcome.Status)
+	case routeData.Status != 0:
+		observe(routeData.Pattern, routeData.Status)
+	default:
+		observe(routeData.Pattern, http.StatusOK)
+	}
+}
@@ -0,0 +1,42 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrometheusCollector(t *testing.T) {
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return nil, errors.New("boom")
+		},
+	})
+
+	collector := NewPrometheusCollector()
+	h := Hwy{Metrics: collector}
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest("GET", "/lion/metrics-test", nil)
+		if _, err := h.GetRouteData(httptest.NewRecorder(), r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	for _, expected := range []string{
+		`hwy_requests_total{pattern="/lion/$",status="200"} 2`,
+		`hwy_loader_duration_seconds_count{pattern="/lion/$"} 2`,
+		`hwy_loader_errors_total{pattern="/lion/$"} 2`,
+		`hwy_match_cache_hits_total 1`,
+		`hwy_match_cache_misses_total 1`,
+	} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("expected %q in metrics output:\n%s", expected, body)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMidPathSplatRouting(t *testing.T) {
+	withRouteTable(t, []string{
+		"_index.ui.tsx",
+		"$.ui.tsx",
+		"files.ui.tsx",
+		"files/$/preview.ui.tsx",
+		"files/$/preview/$size.ui.tsx",
+		"files/$id/preview.ui.tsx",
+	})
+	paths := GetPathsFromPageFiles("files/$/preview.ui.tsx")
+	if paths[0].Pattern != "/files/$/preview" || paths[0].PathType != PathTypeStaticLayout {
+		t.Fatalf("unexpected path %+v", paths[0])
+	}
+
+	for _, c := range []struct {
+		path          string
+		patterns      []string
+		splatSegments []string
+	}{
+		{"/files/docs/2024/preview", []string{"/files", "/files/$/preview"}, []string{"docs", "2024"}},
+		{"/files/docs/2024/preview/large", []string{"/files", "/files/$/preview", "/files/$/preview/$size"}, []string{"docs", "2024"}},
+		{"/files/docs/preview", []string{"/files", "/files/$id/preview"}, nil},
+	} {
+		item := getMatchingPathItem(httptest.NewRequest("GET", c.path, nil))
+		var patterns []string
+		for _, path := range *item.MatchingPaths {
+			patterns = append(patterns, path.Pattern)
+		}
+		if !reflect.DeepEqual(patterns, c.patterns) {
+			t.Errorf("%s: expected %v, got %v", c.path, c.patterns, patterns)
+		}
+		var splatSegments []string
+		if item.SplatSegments != nil {
+			splatSegments = *item.SplatSegments
+		}
+		if len(splatSegments) == 0 {
+			splatSegments = nil
+		}
+		if !reflect.DeepEqual(splatSegments, c.splatSegments) {
+			t.Errorf("%s: expected splat segments %v, got %v", c.path, c.splatSegments, splatSegments)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package router
+
+import "time"
+
+const defaultNegativeCacheSize = 10_000
+
+// NegativeCachePolicy controls caching of match results for paths matching
+// no route (e.g. bots probing random URLs). Such results are kept in their
+// own cache, so they can't evict those of legitimate paths.
+type NegativeCachePolicy struct {
+	// Defaults to 10,000. A negative value disables negative caching.
+	MaxItems int
+	// How long results stay cached. Zero means until evicted.
+	TTL time.Duration
+	// Optional, deciding whether a path's result is cached at all
+	ShouldCache func(realPath string) bool
+}
+
+type negativeCacheEntry struct {
+	item      *gmpdItem
+	expiresAt time.Time
+}
+
+var instanceNegativeCachePolicy *NegativeCachePolicy
+var negativeCache *cache
+
+// initNegativeCache resets the negative cache per policy, which may be nil to
+// keep unmatched results in the matcher cache (where they're never moved to
+// the front)
+func initNegativeCache(policy *NegativeCachePolicy) {
+	instanceNegativeCachePolicy = policy
+	negativeCache = nil
+	if policy == nil {
+		return
+	}
+	size := policy.MaxItems
+	if size == 0 {
+		size = defaultNegativeCacheSize
+	}
+	if size > 0 {
+		negativeCache = NewLRUCache(size)
+	}
+}
+
+func getNegativeCacheItem(cacheKey string) (*gmpdItem, bool) {
+	if negativeCache == nil {
+		return nil, false
+	}
+	cached, ok := negativeCache.Get(cacheKey)
+	if !ok {
+		return nil, false
+	}
+	entry := cached.(*negativeCacheEntry)
+	if !entry.expiresAt.IsZero() && instanceClock.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.item, true
+}
+
+// setMatchedItem caches item, per the negative cache policy if it matched
+// nothing but (at most) the ultimate catch route
+func setMatchedItem(cacheKey string, realPath string, item *gmpdItem) {
+	isSpam := getIsUnmatched(item)
+	policy := instanceNegativeCachePolicy
+	if !isSpam || policy == nil {
+		gmpdCache.Set(cacheKey, item, isSpam)
+		return
+	}
+	if negativeCache == nil || (policy.ShouldCache != nil && !policy.ShouldCache(realPath)) {
+		return
+	}
+	entry := &negativeCacheEntry{item: item}
+	if policy.TTL > 0 {
+		entry.expiresAt = instanceClock.Now().Add(policy.TTL)
+	}
+	negativeCache.Set(cacheKey, entry, false)
+}
@@ -0,0 +1,97 @@
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withNegativeCache(t *testing.T, policy *NegativeCachePolicy) {
+	prevCache := gmpdCache
+	gmpdCache = NewLRUCache(gmpdCacheSize)
+	initNegativeCache(policy)
+	t.Cleanup(func() {
+		gmpdCache = prevCache
+		initNegativeCache(nil)
+	})
+}
+
+func lookupTestPath(url string) bool {
+	_, cached := lookupMatchingPathItem(httptest.NewRequest("GET", url, nil), nil)
+	return cached
+}
+
+func TestNegativeCacheKeepsUnmatchedPathsApart(t *testing.T) {
+	withRouteTable(t, []string{"_index.ui.tsx", "lion.ui.tsx"})
+	withNegativeCache(t, &NegativeCachePolicy{
+		MaxItems:    1,
+		ShouldCache: func(realPath string) bool { return !strings.HasSuffix(realPath, ".php") },
+	})
+
+	lookupTestPath("/lion")
+	for _, url := range []string{"/wp-login.php", "/random-1", "/random-2", "/random-3"} {
+		lookupTestPath(url)
+	}
+	if _, ok := gmpdCache.Get("/lion"); !ok {
+		t.Error("expected matched paths to stay in the matcher cache")
+	}
+	if _, ok := gmpdCache.Get("/random-3"); ok {
+		t.Error("expected unmatched paths to stay out of the matcher cache")
+	}
+	if !lookupTestPath("/random-3") {
+		t.Error("expected the latest unmatched path to be negative cached")
+	}
+	if lookupTestPath("/random-1") {
+		t.Error("expected older unmatched paths to be evicted past MaxItems")
+	}
+	if lookupTestPath("/wp-login.php") {
+		t.Error("expected ShouldCache to keep the path uncached")
+	}
+}
+
+func TestNegativeCacheWithUltimateCatch(t *testing.T) {
+	withRouteTable(t, []string{"_index.ui.tsx", "lion.ui.tsx", "$.ui.tsx"})
+	withNegativeCache(t, &NegativeCachePolicy{})
+
+	lookupTestPath("/probe")
+	if _, ok := gmpdCache.Get("/probe"); ok {
+		t.Error("expected a path matching only the catch route to stay out of the matcher cache")
+	}
+	if !lookupTestPath("/probe") {
+		t.Error("expected a path matching only the catch route to be negative cached")
+	}
+	lookupTestPath("/lion")
+	if _, ok := gmpdCache.Get("/lion"); !ok {
+		t.Error("expected matched paths to stay in the matcher cache")
+	}
+}
+
+func TestNegativeCacheTTL(t *testing.T) {
+	withRouteTable(t, []string{"_index.ui.tsx"})
+	withNegativeCache(t, &NegativeCachePolicy{TTL: time.Minute})
+	clock := NewFakeClock(time.Unix(0, 0))
+	prevClock := instanceClock
+	instanceClock = clock
+	t.Cleanup(func() { instanceClock = prevClock })
+
+	lookupTestPath("/expiring")
+	clock.Advance(time.Minute)
+	if !lookupTestPath("/expiring") {
+		t.Fatal("expected the unmatched path to be negative cached")
+	}
+	clock.Advance(time.Second)
+	if lookupTestPath("/expiring") {
+		t.Error("expected the entry to expire after TTL")
+	}
+}
+
+func TestNegativeCacheDisabled(t *testing.T) {
+	withRouteTable(t, []string{"_index.ui.tsx"})
+	withNegativeCache(t, &NegativeCachePolicy{MaxItems: -1})
+
+	lookupTestPath("/uncached")
+	if lookupTestPath("/uncached") {
+		t.Error("expected no negative caching")
+	}
+}
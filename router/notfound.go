@@ -0,0 +1,173 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+)
+
+var errNotFound = errors.New("not found")
+
+// NotFound returns a sentinel error for loaders to return when the requested
+// entity does not exist. The request is then re-rendered through the nearest
+// splat or catch route (or Hwy.NotFoundRoute, in place of the ultimate catch
+// route), with a 404 status.
+func NotFound() error {
+	return errNotFound
+}
+
+func IsNotFound(err error) bool {
+	return errors.Is(err, errNotFound)
+}
+
+func getOutermostErrorIndex(errs []error) int {
+	for i, err := range errs {
+		if err != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// getNotFoundData swaps everything from the not-found route downward for the
+// nearest catch route that could have rendered in its place. Loader data for
+// the retained parent layouts is reused rather than re-fetched.
+func (h Hwy) getNotFoundData(r *http.Request, item *gmpdItem, notFoundIndex int, loadersData []any, scope *requestScope) (*gmpdItem, []any, []error) {
+	realPath := getRealPath(r)
+	notFoundPath := (*item.MatchingPaths)[notFoundIndex]
+
+	maxPrefixLength := 0
+	for _, segment := range *notFoundPath.Segments {
+		if segment != "$" {
+			maxPrefixLength++
+		}
+	}
+
+	catchPath, catchParams := findNearestCatchPath(realPath, maxPrefixLength)
+	if catchPath == nil || catchPath.PathType == PathTypeUltimateCatch {
+		if h.NotFoundRoute != "" {
+			if notFoundItem := getFallbackItem(h.NotFoundRoute, http.StatusNotFound, getBaseSplatSegments(realPath)); notFoundItem != nil {
+				return h.runFallbackLoader(r, notFoundItem, 0, []any{nil}, scope)
+			}
+		}
+	}
+	if catchPath == nil {
+		return getNotFoundItemWithoutCatch(), []any{}, []error{}
+	}
+	catchPrefixLength := len(*catchPath.Segments) - 1
+
+	// Keep the parent layouts the catch route would have rendered inside of
+	keep := 0
+	for keep < notFoundIndex {
+		parent := (*item.MatchingPaths)[keep]
+		isLayout := parent.PathType == PathTypeStaticLayout || parent.PathType == PathTypeDynamicLayout
+		if !isLayout || len(*parent.Segments) > catchPrefixLength {
+			break
+		}
+		keep++
+	}
+
+	matchingPaths := make([]*MatchingPath, 0, keep+1)
+	matchingPaths = append(matchingPaths, (*item.MatchingPaths)[:keep]...)
+	catchMatchingPath := &MatchingPath{
+		Pattern:     catchPath.Pattern,
+		Segments:    catchPath.Segments,
+		PathType:    catchPath.PathType,
+		DataFuncs:   catchPath.DataFuncs,
+		OutPath:     catchPath.OutPath,
+		Params:      catchParams,
+		Deps:        catchPath.Deps,
+		Handle:      catchPath.Handle,
+		Static:      catchPath.Static,
+		Islands:     catchPath.Islands,
+		CSSBundle:   catchPath.CSSBundle,
+		CriticalCSS: catchPath.CriticalCSS,
+		ClientEntry: catchPath.ClientEntry,
+	}
+	matchingPaths = append(matchingPaths, catchMatchingPath)
+
+	importURLs := make([]string, 0, len(matchingPaths))
+	for _, path := range matchingPaths {
+		importURLs = append(importURLs, "/"+path.OutPath)
+	}
+	deps := GetDeps(&matchingPaths)
+
+	notFoundItem := &gmpdItem{
+		SplatSegments:               getSplatSegmentsFromWinningPath(catchMatchingPath, realPath),
+		Params:                      catchParams,
+		MatchingPaths:               &matchingPaths,
+		FullyDecoratedMatchingPaths: decoratePaths(&matchingPaths),
+		ImportURLs:                  &importURLs,
+		Deps:                        &deps,
+		IsFallback:                  true,
+		Status:                      http.StatusNotFound,
+	}
+
+	return h.runFallbackLoader(r, notFoundItem, keep, loadersData, scope)
+}
+
+// runFallbackLoader runs only the last loader of a fallback item, reusing
+// the first keep entries of loadersData for its parents
+func (h Hwy) runFallbackLoader(r *http.Request, item *gmpdItem, keep int, loadersData []any, scope *requestScope) (*gmpdItem, []any, []error) {
+	paths := *item.FullyDecoratedMatchingPaths
+	newLoadersData := make([]any, len(paths))
+	copy(newLoadersData, loadersData[:keep])
+	newErrors := make([]error, len(paths))
+
+	last := len(paths) - 1
+	dataFuncs := paths[last].DataFuncs
+	if dataFuncs != nil && dataFuncs.Loader != nil {
+		var parentLoadersData *[]any
+		if h.LoaderStrategy == LoaderStrategySequential {
+			parents := newLoadersData[:last:last]
+			parentLoadersData = &parents
+		}
+		newLoadersData[last], newErrors[last] = dataFuncs.Loader(&LoaderProps{
+			DataProps:         scope.newDataProps(r, item.Params, item.SplatSegments),
+			ParentLoadersData: parentLoadersData,
+		})
+		// Don't loop -- if the fallback route itself is not found, just render it
+		if getIsNotFoundOrGone(newErrors[last]) {
+			newErrors[last] = nil
+		}
+	}
+
+	return item, newLoadersData, newErrors
+}
+
+// findNearestCatchPath returns the deepest splat or catch route matching
+// realPath whose non-splat prefix is shorter than maxPrefixLength
+func findNearestCatchPath(realPath string, maxPrefixLength int) (*Path, *map[string]string) {
+	var nearest *Path
+	var nearestParams *map[string]string
+	nearestPrefixLength := -1
+	for i, path := range *instancePaths {
+		if path.PathType != PathTypeNonUltimateSplat && path.PathType != PathTypeUltimateCatch {
+			continue
+		}
+		prefixLength := len(*path.Segments) - 1
+		if prefixLength >= maxPrefixLength || prefixLength <= nearestPrefixLength {
+			continue
+		}
+		result := Match(path.Pattern, realPath)
+		if !result.Matches {
+			continue
+		}
+		nearest = &(*instancePaths)[i]
+		nearestParams = &result.Params
+		nearestPrefixLength = prefixLength
+	}
+	return nearest, nearestParams
+}
+
+func getNotFoundItemWithoutCatch() *gmpdItem {
+	return &gmpdItem{
+		SplatSegments:               &[]string{},
+		Params:                      &map[string]string{},
+		MatchingPaths:               &[]*MatchingPath{},
+		FullyDecoratedMatchingPaths: &[]*DecoratedPath{},
+		ImportURLs:                  &[]string{},
+		Deps:                        &[]string{},
+		IsFallback:                  true,
+		Status:                      http.StatusNotFound,
+	}
+}
@@ -0,0 +1,82 @@
+package router
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMissingParam = errors.New("missing param")
+	ErrInvalidUUID  = errors.New("invalid UUID")
+)
+
+// ParamError is returned by the typed param accessors when a param is
+// missing or can't be converted. A loader returning one renders its error
+// boundary with its StatusCode (400).
+type ParamError struct {
+	Name  string
+	Value string
+	Type  string // the requested type, e.g. "int" or "uuid"
+	Err   error
+}
+
+func (e *ParamError) Error() string {
+	if errors.Is(e.Err, ErrMissingParam) {
+		return fmt.Sprintf("missing param %q", e.Name)
+	}
+	return fmt.Sprintf("param %q: %q is not a valid %s", e.Name, e.Value, e.Type)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ParamError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// Param returns the named param, or a *ParamError if the route has none
+func (p DataProps) Param(name string) (string, error) {
+	if p.Params != nil {
+		if value, ok := (*p.Params)[name]; ok {
+			return value, nil
+		}
+	}
+	return "", &ParamError{Name: name, Type: "string", Err: ErrMissingParam}
+}
+
+func (p DataProps) ParamInt(name string) (int, error) {
+	value, err := p.Param(name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &ParamError{Name: name, Value: value, Type: "int", Err: err}
+	}
+	return n, nil
+}
+
+// ParamUUID returns the named param as a lowercase, hyphenated UUID
+func (p DataProps) ParamUUID(name string) (string, error) {
+	value, err := p.Param(name)
+	if err != nil {
+		return "", err
+	}
+	if !getIsUUID(value) {
+		return "", &ParamError{Name: name, Value: value, Type: "uuid", Err: ErrInvalidUUID}
+	}
+	return strings.ToLower(value), nil
+}
+
+func getIsUUID(s string) bool {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return false
+	}
+	_, err := hex.DecodeString(s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36])
+	return err == nil
+}
@@ -0,0 +1,62 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTypedParams(t *testing.T) {
+	props := DataProps{Params: &map[string]string{
+		"id":   "42",
+		"uuid": "0B1D3C4E-5F60-4718-9A2B-3C4D5E6F7A8B",
+		"bad":  "4x2",
+	}}
+
+	if id, err := props.ParamInt("id"); err != nil || id != 42 {
+		t.Errorf("expected 42, got %d (%v)", id, err)
+	}
+	if uuid, err := props.ParamUUID("uuid"); err != nil || uuid != "0b1d3c4e-5f60-4718-9a2b-3c4d5e6f7a8b" {
+		t.Errorf("expected lowercase UUID, got %q (%v)", uuid, err)
+	}
+
+	var paramErr *ParamError
+	if _, err := props.ParamInt("bad"); !errors.As(err, &paramErr) || paramErr.Name != "bad" || paramErr.Type != "int" {
+		t.Errorf("expected int ParamError, got %v", err)
+	}
+	if _, err := props.ParamUUID("bad"); !errors.Is(err, ErrInvalidUUID) {
+		t.Errorf("expected ErrInvalidUUID, got %v", err)
+	}
+	if _, err := props.Param("nope"); !errors.Is(err, ErrMissingParam) {
+		t.Errorf("expected ErrMissingParam, got %v", err)
+	}
+	if _, err := (DataProps{}).ParamInt("id"); !errors.Is(err, ErrMissingParam) {
+		t.Errorf("expected ErrMissingParam without params, got %v", err)
+	}
+}
+
+func TestParamErrorRendersWithBadRequest(t *testing.T) {
+	setTestDataFuncs(t, "/dashboard/customers/$customer_id", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return props.ParamInt("customer_id")
+		},
+	})
+
+	h := Hwy{}
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/dashboard/customers/not-a-number", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routeData.Status != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", routeData.Status)
+	}
+
+	routeData, err = h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/dashboard/customers/7", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routeData.Status != 0 || (*routeData.LoadersData)[2] != 7 {
+		t.Errorf("expected customer 7, got status %d, data %v", routeData.Status, *routeData.LoadersData)
+	}
+}
@@ -0,0 +1,111 @@
+package router
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Paths are matched with each segment decoded, except for "%" and "/", which
+// stay escaped (as "%25" and "%2F") so an encoded slash can't split a segment,
+// and for "$" and a segment of "_index", which stay escaped (as "%24" and
+// "%5Findex") to match escaped page file segments (see escapeLiteralSegment).
+// Params and splat segments are decoded fully once matched.
+
+var segmentEscaper = strings.NewReplacer("%", "%25", "/", "%2F", "$", "%24")
+var segmentUnescaper = strings.NewReplacer("%25", "%", "%2F", "/", "%24", "$", "%5F", "_")
+
+// getMatchPath returns the path of u to match against routes
+func getMatchPath(u *url.URL) string {
+	if u.RawPath == "" {
+		if !strings.ContainsAny(u.Path, "%$") && !strings.Contains(u.Path, "/_index") {
+			return u.Path
+		}
+		segments := strings.Split(u.Path, "/")
+		for i, segment := range segments {
+			segments[i] = escapeMatchSegment(segment)
+		}
+		return strings.Join(segments, "/")
+	}
+	segments := strings.Split(u.EscapedPath(), "/")
+	for i, segment := range segments {
+		if decoded, err := url.PathUnescape(segment); err == nil {
+			segments[i] = escapeMatchSegment(decoded)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// escapeMatchSegment escapes a decoded segment for a match path
+func escapeMatchSegment(segment string) string {
+	if segment == "_index" {
+		return "%5Findex"
+	}
+	return segmentEscaper.Replace(segment)
+}
+
+// escapeLiteralSegment escapes a page file segment meant literally (see
+// getPathFromPageFile) to match its match path segment
+func escapeLiteralSegment(segment string) string {
+	if segment == "_index" {
+		return "%5Findex"
+	}
+	return strings.ReplaceAll(segment, "$", "%24")
+}
+
+// decodeSegment decodes a segment of a match path
+func decodeSegment(segment string) string {
+	if !strings.Contains(segment, "%") {
+		return segment
+	}
+	return segmentUnescaper.Replace(segment)
+}
+
+// getRawSegments maps the request's match path segments to its segments as
+// sent, still percent-encoded
+func (p DataProps) getRawSegments() map[string]string {
+	matchSegments := getTruthySegments(strings.Split(getRealPath(p.Request), "/"))
+	rawSegments := getTruthySegments(strings.Split(p.Request.URL.EscapedPath(), "/"))
+	segments := make(map[string]string, len(matchSegments))
+	for i, segment := range matchSegments {
+		if i < len(rawSegments) {
+			segments[segment] = rawSegments[i]
+		}
+	}
+	return segments
+}
+
+// RawParam returns the named param as sent, still percent-encoded, where
+// Params holds it decoded (e.g. "caf%C3%A9" rather than "café")
+func (p DataProps) RawParam(name string) string {
+	if p.Params == nil {
+		return ""
+	}
+	value, ok := (*p.Params)[name]
+	if !ok || p.Request == nil {
+		return url.PathEscape(value)
+	}
+	if raw, ok := p.getRawSegments()[escapeMatchSegment(value)]; ok {
+		return raw
+	}
+	return url.PathEscape(value)
+}
+
+// RawSplatSegments returns SplatSegments as sent, still percent-encoded
+func (p DataProps) RawSplatSegments() []string {
+	if p.SplatSegments == nil {
+		return nil
+	}
+	var rawSegments map[string]string
+	if p.Request != nil {
+		rawSegments = p.getRawSegments()
+	}
+	segments := make([]string, 0, len(*p.SplatSegments))
+	for _, segment := range *p.SplatSegments {
+		raw, ok := rawSegments[escapeMatchSegment(segment)]
+		if !ok {
+			raw = url.PathEscape(segment)
+		}
+		segments = append(segments, raw)
+	}
+	return segments
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetMatchPath(t *testing.T) {
+	for _, c := range []struct {
+		url      string
+		expected string
+	}{
+		{"/lion/simba", "/lion/simba"},
+		{"/caf%C3%A9", "/café"},
+		{"/files/a%2Fb/c", "/files/a%2Fb/c"},
+		{"/files/a%2fb", "/files/a%2Fb"},
+		{"/discount/100%25", "/discount/100%25"},
+		{"/discount/100%252F", "/discount/100%252F"},
+	} {
+		if got := getMatchPath(httptest.NewRequest("GET", c.url, nil).URL); got != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.url, c.expected, got)
+		}
+	}
+}
+
+func TestDecodedParams(t *testing.T) {
+	var props *DataProps
+	setTestDataFuncs(t, "/tiger/$tiger_id/$tiger_cub_id", &DataFuncs{
+		Loader: func(p *LoaderProps) (any, error) {
+			props = &p.DataProps
+			return nil, nil
+		},
+	})
+
+	routeData, err := Hwy{}.GetRouteData(nil, httptest.NewRequest("GET", "/tiger/caf%C3%A9/a%2Fb", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routeData.Pattern != "/tiger/$tiger_id/$tiger_cub_id" {
+		t.Fatalf("expected an encoded slash not to split its segment, matched %s", routeData.Pattern)
+	}
+	expected := map[string]string{"tiger_id": "café", "tiger_cub_id": "a/b"}
+	if !reflect.DeepEqual(*props.Params, expected) {
+		t.Errorf("expected decoded params %v, got %v", expected, *props.Params)
+	}
+	if raw := props.RawParam("tiger_id"); raw != "caf%C3%A9" {
+		t.Errorf("expected the raw param as sent, got %q", raw)
+	}
+	if raw := props.RawParam("tiger_cub_id"); raw != "a%2Fb" {
+		t.Errorf("expected the raw param as sent, got %q", raw)
+	}
+}
+
+func TestDecodedSplatSegments(t *testing.T) {
+	var props *DataProps
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(p *LoaderProps) (any, error) {
+			props = &p.DataProps
+			return nil, nil
+		},
+	})
+
+	if _, err := (Hwy{}).GetRouteData(nil, httptest.NewRequest("GET", "/lion/%E6%97%A5%E6%9C%AC/50%25/x%2Fy", nil)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"日本", "50%", "x/y"}
+	if !reflect.DeepEqual(*props.SplatSegments, expected) {
+		t.Errorf("expected decoded splat segments %q, got %q", expected, *props.SplatSegments)
+	}
+	expectedRaw := []string{"%E6%97%A5%E6%9C%AC", "50%25", "x%2Fy"}
+	if raw := props.RawSplatSegments(); !reflect.DeepEqual(raw, expectedRaw) {
+		t.Errorf("expected raw splat segments %q, got %q", expectedRaw, raw)
+	}
+}
@@ -0,0 +1,56 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PathsFileSchemaVersion is the schema version of the paths files Build
+// writes. Older paths files are migrated as they're read, and newer ones are
+// rejected with ErrUnsupportedSchema, rather than being misread.
+const PathsFileSchemaVersion = 2
+
+var ErrUnsupportedSchema = errors.New("unsupported paths file schema version")
+
+// pathsFileMigrations[i] converts the fields of a paths file from schema
+// version i+1 to i+2
+var pathsFileMigrations = []func(fields map[string]json.RawMessage) error{
+	// Version 1 paths files predate schemaVersion, and are otherwise the same
+	func(fields map[string]json.RawMessage) error { return nil },
+}
+
+// DecodePathsFile reads a paths file (hwy_paths.json) of any schema version
+// up to PathsFileSchemaVersion, migrating it to the current one
+func DecodePathsFile(data []byte) (*PathsFile, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	version := 1 // files without schemaVersion
+	if raw, ok := fields["schemaVersion"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, err
+		}
+	}
+	if version < 1 || version > PathsFileSchemaVersion {
+		return nil, fmt.Errorf("%w: %d (this hwy-go reads up to %d; rebuild or upgrade)", ErrUnsupportedSchema, version, PathsFileSchemaVersion)
+	}
+	if version < PathsFileSchemaVersion {
+		for _, migrate := range pathsFileMigrations[version-1:] {
+			if err := migrate(fields); err != nil {
+				return nil, err
+			}
+		}
+		fields["schemaVersion"] = json.RawMessage(fmt.Sprint(PathsFileSchemaVersion))
+		var err error
+		if data, err = json.Marshal(fields); err != nil {
+			return nil, err
+		}
+	}
+	pathsFile := PathsFile{}
+	if err := json.Unmarshal(data, &pathsFile); err != nil {
+		return nil, err
+	}
+	return &pathsFile, nil
+}
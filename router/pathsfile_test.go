@@ -0,0 +1,28 @@
+package router
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodePathsFile(t *testing.T) {
+	// Version 1 files predate schemaVersion
+	pathsFile, err := DecodePathsFile([]byte(`{"paths":[{"pattern":"/tiger","pathType":"static-layout"}],"buildID":"1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pathsFile.SchemaVersion != PathsFileSchemaVersion || len(pathsFile.Paths) != 1 || pathsFile.BuildID != "1" {
+		t.Errorf("expected a migrated paths file, got %+v", pathsFile)
+	}
+
+	pathsFile, err = DecodePathsFile([]byte(`{"schemaVersion":2,"paths":[],"buildID":"2"}`))
+	if err != nil || pathsFile.BuildID != "2" {
+		t.Errorf("expected a current paths file read as is, got %+v (%v)", pathsFile, err)
+	}
+
+	for _, data := range []string{`{"schemaVersion":3,"paths":[]}`, `{"schemaVersion":0,"paths":[]}`} {
+		if _, err := DecodePathsFile([]byte(data)); !errors.Is(err, ErrUnsupportedSchema) {
+			t.Errorf("%s: expected ErrUnsupportedSchema, got %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PayloadMetricsCollector is implemented by MetricsCollectors that also count
+// loader data over its payload budget (see DataFuncs.PayloadBudget)
+type PayloadMetricsCollector interface {
+	ObservePayloadOverBudget(pattern string, size int)
+}
+
+func (c *PrometheusCollector) ObservePayloadOverBudget(pattern string, size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.overBudget[pattern]++
+}
+
+// How many of an over-budget payload's largest top-level keys are logged
+const payloadBudgetTopKeys = 5
+
+func (h Hwy) getPayloadBudget(dataFuncs *DataFuncs) int {
+	if dataFuncs != nil && dataFuncs.PayloadBudget != 0 {
+		return dataFuncs.PayloadBudget
+	}
+	return h.PayloadBudget
+}
+
+// checkPayloadBudgets warns about each matched route whose serialized loader
+// data exceeds its payload budget
+func (h Hwy) checkPayloadBudgets(activePathData *ActivePathData) {
+	if activePathData.LoadersData == nil {
+		return
+	}
+	for i, path := range *activePathData.MatchingPaths {
+		budget := h.getPayloadBudget(path.DataFuncs)
+		if budget <= 0 || i >= len(*activePathData.LoadersData) {
+			continue
+		}
+		data := (*activePathData.LoadersData)[i]
+		if data == nil {
+			continue
+		}
+		jsonBytes, err := h.marshalJSON(data)
+		if err != nil || len(jsonBytes) <= budget {
+			continue
+		}
+		h.getLogger().Warn("loader data over payload budget",
+			"pattern", path.Pattern, "bytes", len(jsonBytes), "budget", budget, "largestKeys", getLargestKeys(jsonBytes))
+		if collector, ok := h.Metrics.(PayloadMetricsCollector); ok {
+			collector.ObservePayloadOverBudget(path.Pattern, len(jsonBytes))
+		}
+	}
+}
+
+func (h Hwy) marshalJSON(v any) ([]byte, error) {
+	if h.Encoder != nil {
+		return h.Encoder.Marshal(v)
+	}
+	return json.Marshal(v)
+}
+
+// getLargestKeys lists the largest top-level keys of a JSON object with their
+// sizes, e.g. "items (48213 bytes), user (212 bytes)", or "" for non-objects
+func getLargestKeys(jsonBytes []byte) string {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(jsonBytes, &fields) != nil {
+		return ""
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if c := len(fields[b]) - len(fields[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	described := make([]string, 0, payloadBudgetTopKeys)
+	for _, key := range keys[:min(len(keys), payloadBudgetTopKeys)] {
+		described = append(described, fmt.Sprintf("%s (%d bytes)", key, len(fields[key])))
+	}
+	return strings.Join(described, ", ")
+}
@@ -0,0 +1,56 @@
+package router
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPayloadBudget(t *testing.T) {
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return map[string]any{"items": strings.Repeat("x", 2000), "user": "lion"}, nil
+		},
+		PayloadBudget: 1024,
+	})
+
+	var buf bytes.Buffer
+	collector := NewPrometheusCollector()
+	h := Hwy{Logger: NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil))), Metrics: collector, PayloadBudget: -1}
+	if _, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/payload-test", nil)); err != nil {
+		t.Fatal(err)
+	}
+	logged := buf.String()
+	for _, expected := range []string{"loader data over payload budget", "pattern=/lion/$", "budget=1024", `largestKeys="items (2002 bytes), user (6 bytes)"`} {
+		if !strings.Contains(logged, expected) {
+			t.Errorf("expected %q in log:\n%s", expected, logged)
+		}
+	}
+	if strings.Contains(logged, "pattern=/lion ") {
+		t.Error("expected the parent layout's budget to be disabled")
+	}
+
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), `hwy_payload_over_budget_total{pattern="/lion/$"} 1`) {
+		t.Errorf("expected over-budget payload counted:\n%s", body)
+	}
+
+	buf.Reset()
+	h.PayloadBudget = 0
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return strings.Repeat("x", 2000), nil
+		},
+	})
+	if _, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/payload-test-unbudgeted", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("expected no warning without a budget, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,26 @@
+package router
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Buffers that grew larger than this are left for the GC rather than pooled,
+// so one huge page doesn't pin its memory forever
+const maxPooledBufferSize = 1 << 16
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GetIsPrefetchRequest reports whether r is a speculative prefetch (per the
+// Sec-Purpose header, or the legacy Purpose header), which the user may never
+// actually navigate to
+func GetIsPrefetchRequest(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Sec-Purpose"), "prefetch") ||
+		r.Header.Get("Purpose") == "prefetch"
+}
+
+// PrefetchMetricsCollector is optionally implemented by a MetricsCollector to
+// count prefetch requests separately. Prefetches are never reported through
+// ObserveRequest or ObserveLoader, so they don't distort real traffic.
+type PrefetchMetricsCollector interface {
+	ObservePrefetch(pattern string, status int)
+}
+
+func (c *PrometheusCollector) ObservePrefetch(pattern string, status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prefetches[[2]string{pattern, strconv.Itoa(status)}]++
+}
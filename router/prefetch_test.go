@@ -0,0 +1,51 @@
+package router
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrefetchRequests(t *testing.T) {
+	var sawPrefetch bool
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			sawPrefetch = props.IsPrefetch
+			return nil, nil
+		},
+	})
+
+	collector := NewPrometheusCollector()
+	h := Hwy{Metrics: collector, PrefetchCacheControl: "private, max-age=10"}
+	r := httptest.NewRequest("GET", "/lion/prefetch-test?"+HwyPrefix+"json=1", nil)
+	r.Header.Set("Sec-Purpose", "prefetch")
+	w := httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w, r)
+
+	if !sawPrefetch {
+		t.Error("expected loader to see IsPrefetch")
+	}
+	if got := w.Header().Get("Cache-Control"); got != "private, max-age=10" {
+		t.Errorf("expected prefetch cache control, got %q", got)
+	}
+
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), `hwy_prefetch_requests_total{pattern="/lion/$",status="200"} 1`) {
+		t.Errorf("expected prefetch counted separately:\n%s", body)
+	}
+	for _, unexpected := range []string{`hwy_requests_total{`, `hwy_loader_duration_seconds_count{`} {
+		if strings.Contains(string(body), unexpected) {
+			t.Errorf("expected prefetch not to be reported as %q:\n%s", unexpected, body)
+		}
+	}
+
+	r = httptest.NewRequest("GET", "/lion/prefetch-test?"+HwyPrefix+"json=1", nil)
+	w = httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w, r)
+	if sawPrefetch || w.Header().Get("Cache-Control") != "" {
+		t.Error("expected regular navigation not to be treated as a prefetch")
+	}
+}
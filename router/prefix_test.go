@@ -0,0 +1,47 @@
+package router
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrefix(t *testing.T) {
+	t.Cleanup(func() { instancePrefix = HwyPrefix })
+	instancePrefix = "__acme__"
+
+	if !GetIsJSONRequest(httptest.NewRequest("GET", "/lion?__acme__json=1", nil)) {
+		t.Error("expected the configured prefix's query param to mark JSON requests")
+	}
+	if GetIsJSONRequest(httptest.NewRequest("GET", "/lion?"+HwyPrefix+"json=1", nil)) {
+		t.Error("expected the default prefix's query param to be ignored")
+	}
+
+	routeData, err := Hwy{}.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/prefix", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ssrInnerHTML, err := GetSSRInnerHTML(routeData, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(*ssrInnerHTML), `Symbol.for("__acme__")`) || strings.Contains(string(*ssrInnerHTML), HwyPrefix) {
+		t.Errorf("expected globals under the configured prefix, got %s", *ssrInnerHTML)
+	}
+}
+
+func TestWritePrefixTypeScript(t *testing.T) {
+	dir := t.TempDir()
+	if err := writePrefixTypeScript(dir, "__acme__"); err != nil {
+		t.Fatal(err)
+	}
+	ts, err := os.ReadFile(filepath.Join(dir, "hwy_prefix.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(ts), `export const HWY_PREFIX = "__acme__";`) {
+		t.Errorf("unexpected TypeScript: %s", ts)
+	}
+}
@@ -0,0 +1,67 @@
+package router
+
+// Preload is a resource (e.g. a font or hero image) for the browser to fetch
+// before it discovers it, rendered as a <link rel="preload"> head block.
+// Preload head blocks come before all other link, style, and script blocks,
+// and so before the route's modulepreloads.
+type Preload struct {
+	Href string
+	As   string // e.g. "font", "image", "style", "fetch"
+	Type string // e.g. "font/woff2"
+	// Defaults to "anonymous" for fonts, which are always fetched in CORS mode
+	CrossOrigin   string
+	Media         string
+	FetchPriority string // "high", "low", or "auto"
+	// For responsive images (see Images.SrcSet); Href may then be empty
+	ImageSrcSet string
+	ImageSizes  string
+}
+
+func (p *Preload) toHeadBlock() HeadBlock {
+	attributes := map[string]string{"rel": "preload", "as": p.As}
+	crossOrigin := p.CrossOrigin
+	if crossOrigin == "" && p.As == "font" {
+		crossOrigin = "anonymous"
+	}
+	for key, value := range map[string]string{
+		"href":          p.Href,
+		"type":          p.Type,
+		"crossorigin":   crossOrigin,
+		"media":         p.Media,
+		"fetchpriority": p.FetchPriority,
+		"imagesrcset":   p.ImageSrcSet,
+		"imagesizes":    p.ImageSizes,
+	} {
+		if value != "" {
+			attributes[key] = value
+		}
+	}
+	return HeadBlock{Tag: "link", Attributes: attributes}
+}
+
+// getPreloadHeadBlocks returns Hwy.Preloads, then the matched routes'
+// preloads (outermost first), keeping only the first preload of each href
+func (h Hwy) getPreloadHeadBlocks(activePathData *ActivePathData) []HeadBlock {
+	preloads := h.Preloads
+	if activePathData.MatchingPaths != nil {
+		for _, path := range *activePathData.MatchingPaths {
+			if path.DataFuncs != nil && len(path.DataFuncs.Preloads) > 0 {
+				preloads = append(preloads[:len(preloads):len(preloads)], path.DataFuncs.Preloads...)
+			}
+		}
+	}
+	if len(preloads) == 0 {
+		return nil
+	}
+	headBlocks := make([]HeadBlock, 0, len(preloads))
+	seen := make(map[string]bool, len(preloads))
+	for i := range preloads {
+		key := preloads[i].Href + " " + preloads[i].ImageSrcSet
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		headBlocks = append(headBlocks, preloads[i].toHeadBlock())
+	}
+	return headBlocks
+}
@@ -0,0 +1,39 @@
+package router
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestPreloads(t *testing.T) {
+	font := Preload{Href: "/public/inter.woff2", As: "font", Type: "font/woff2"}
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Preloads: []Preload{
+			{Href: "/public/hero.jpg", As: "image", FetchPriority: "high", ImageSrcSet: "/a 320w, /b 640w"},
+			font,
+		},
+		Head: func(props *HeadProps) (*[]HeadBlock, error) {
+			return &[]HeadBlock{{Tag: "link", Attributes: map[string]string{"rel": "stylesheet", "href": "/lion.css"}}}, nil
+		},
+	})
+
+	h := Hwy{Preloads: []Preload{font}}
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/preload", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []map[string]string{
+		{"rel": "preload", "as": "font", "href": "/public/inter.woff2", "type": "font/woff2", "crossorigin": "anonymous"},
+		{"rel": "preload", "as": "image", "href": "/public/hero.jpg", "fetchpriority": "high", "imagesrcset": "/a 320w, /b 640w"},
+		{"rel": "stylesheet", "href": "/lion.css"},
+	}
+	var got []map[string]string
+	for _, block := range *routeData.RestHeadBlocks {
+		got = append(got, block.Attributes)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
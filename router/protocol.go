@@ -0,0 +1,48 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Header in which clients send the version of the JSON protocol (the shape
+// of GetRouteDataOutput) they speak, and in which JSON responses name the
+// version they were answered in. Clients that don't send it are taken to
+// speak version 1.
+const ProtocolHeader = "Hwy-Protocol"
+
+// The newest JSON protocol version this server answers. Version 2 adds the
+// "protocol" field to responses.
+const ProtocolVersion = 2
+
+// getProtocolVersion returns the version the client asked for, or false if
+// it isn't one this server answers (see Hwy.MinProtocolVersion)
+func (h Hwy) getProtocolVersion(r *http.Request) (int, bool) {
+	header := r.Header.Get(ProtocolHeader)
+	if header == "" {
+		return 1, h.MinProtocolVersion <= 1
+	}
+	version, err := strconv.Atoi(header)
+	if err != nil {
+		return 0, false
+	}
+	return version, version >= max(h.MinProtocolVersion, 1) && version <= ProtocolVersion
+}
+
+// serveUnsupportedProtocol answers 400, naming the newest version this server
+// answers, so mismatched clients can reload rather than misparse the response
+func serveUnsupportedProtocol(w http.ResponseWriter) {
+	w.Header().Add("Vary", ProtocolHeader)
+	w.Header().Set(ProtocolHeader, strconv.Itoa(ProtocolVersion))
+	http.Error(w, "Unsupported "+ProtocolHeader+" version", http.StatusBadRequest)
+}
+
+// setProtocolVersion shapes routeData for clients of version. As JSON
+// responses differ by version, shared caches must key them by ProtocolHeader.
+func setProtocolVersion(w http.ResponseWriter, routeData *GetRouteDataOutput, version int) {
+	w.Header().Add("Vary", ProtocolHeader)
+	w.Header().Set(ProtocolHeader, strconv.Itoa(version))
+	if version >= 2 {
+		routeData.Protocol = version
+	}
+}
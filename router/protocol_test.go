@@ -0,0 +1,68 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestProtocolVersions(t *testing.T) {
+	get := func(h Hwy, header string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/lion/protocol-test?"+HwyPrefix+"json=1", nil)
+		if header != "" {
+			r.Header.Set(ProtocolHeader, header)
+		}
+		w := httptest.NewRecorder()
+		h.GetRootHandler().ServeHTTP(w, r)
+		return w
+	}
+	getProtocolField := func(w *httptest.ResponseRecorder) (any, bool) {
+		var body map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		protocol, ok := body["protocol"]
+		return protocol, ok
+	}
+
+	// Clients from before versioning get the version 1 shape
+	w := get(Hwy{}, "")
+	if w.Code != http.StatusOK || w.Header().Get(ProtocolHeader) != "1" {
+		t.Errorf("expected a version 1 response, got %d with %q", w.Code, w.Header().Get(ProtocolHeader))
+	}
+	if _, ok := getProtocolField(w); ok {
+		t.Error("expected no protocol field for version 1 clients")
+	}
+	if !slices.Contains(w.Header().Values("Vary"), ProtocolHeader) {
+		t.Errorf("expected Vary to include %s, got %v", ProtocolHeader, w.Header().Values("Vary"))
+	}
+
+	w = get(Hwy{}, "2")
+	if w.Header().Get(ProtocolHeader) != "2" {
+		t.Errorf("expected a version 2 response, got %q", w.Header().Get(ProtocolHeader))
+	}
+	if protocol, _ := getProtocolField(w); protocol != float64(2) {
+		t.Errorf("expected protocol field 2, got %v", protocol)
+	}
+
+	for _, tc := range []struct {
+		h      Hwy
+		header string
+	}{
+		{Hwy{}, "3"},
+		{Hwy{}, "0"},
+		{Hwy{}, "two"},
+		{Hwy{MinProtocolVersion: 2}, "1"},
+		{Hwy{MinProtocolVersion: 2}, ""},
+	} {
+		w := get(tc.h, tc.header)
+		if w.Code != http.StatusBadRequest || w.Header().Get(ProtocolHeader) != "2" {
+			t.Errorf("%q (min %d): expected 400 naming version 2, got %d with %q", tc.header, tc.h.MinProtocolVersion, w.Code, w.Header().Get(ProtocolHeader))
+		}
+		if !slices.Contains(w.Header().Values("Vary"), ProtocolHeader) {
+			t.Errorf("%q: expected Vary to include %s", tc.header, ProtocolHeader)
+		}
+	}
+}
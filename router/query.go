@@ -0,0 +1,252 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMissingQueryParam = errors.New("missing query param")
+	ErrQueryOutOfRange   = errors.New("query param out of range")
+	ErrQueryNotAllowed   = errors.New("query param not allowed")
+)
+
+// QueryError is returned by BindQuery when a query param is missing, can't be
+// converted, or fails validation. A loader returning one renders its error
+// boundary with its StatusCode (400).
+type QueryError struct {
+	Key   string
+	Value string
+	Err   error
+}
+
+func (e *QueryError) Error() string {
+	if errors.Is(e.Err, ErrMissingQueryParam) {
+		return fmt.Sprintf("missing query param %q", e.Key)
+	}
+	return fmt.Sprintf("query param %q=%q: %v", e.Key, e.Value, e.Err)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+func (e *QueryError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// BindQuery fills the struct pointed to by dst from the request's query
+// string. Fields are bound by their `query` tag (fields without one are
+// skipped) and may be strings, bools, ints, uints, floats, or slices of
+// those (from repeated keys). Supported tags:
+//
+//	query:"page"       the query key
+//	default:"1"        used when the key is absent or empty
+//	validate:"required,min=1,max=100,oneof=asc|desc"
+//
+// min and max bound numbers, or the length of strings and slices.
+func (p DataProps) BindQuery(dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("BindQuery: dst must be a pointer to a struct, got %T", dst)
+	}
+	var query url.Values
+	if p.Request != nil {
+		query = p.Request.URL.Query()
+	}
+	return bindQuery(v.Elem(), query)
+}
+
+func bindQuery(v reflect.Value, query url.Values) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		key := field.Tag.Get("query")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+		values := getNonEmptyValues(query[key])
+		if len(values) == 0 {
+			if defaultValue, ok := field.Tag.Lookup("default"); ok {
+				values = []string{defaultValue}
+			}
+		}
+		rules := parseQueryRules(field.Tag.Get("validate"))
+		if len(values) == 0 {
+			if _, required := rules["required"]; required {
+				return &QueryError{Key: key, Err: ErrMissingQueryParam}
+			}
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		if fieldValue.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(fieldValue.Type(), len(values), len(values))
+			for j, value := range values {
+				if err := setQueryValue(slice.Index(j), value); err != nil {
+					return &QueryError{Key: key, Value: value, Err: err}
+				}
+				if err := validateQueryValue(slice.Index(j), value, rules, false); err != nil {
+					return &QueryError{Key: key, Value: value, Err: err}
+				}
+			}
+			if err := validateQueryLength(len(values), rules); err != nil {
+				return &QueryError{Key: key, Value: strings.Join(values, ","), Err: err}
+			}
+			fieldValue.Set(slice)
+			continue
+		}
+
+		value := values[0]
+		if err := setQueryValue(fieldValue, value); err != nil {
+			return &QueryError{Key: key, Value: value, Err: err}
+		}
+		if err := validateQueryValue(fieldValue, value, rules, true); err != nil {
+			return &QueryError{Key: key, Value: value, Err: err}
+		}
+	}
+	return nil
+}
+
+func getNonEmptyValues(values []string) []string {
+	nonEmpty := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+	return nonEmpty
+}
+
+func parseQueryRules(tag string) map[string]string {
+	rules := make(map[string]string)
+	for _, rule := range strings.Split(tag, ",") {
+		if rule == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(rule, "=")
+		rules[name] = arg
+	}
+	return rules
+}
+
+func setQueryValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// validateQueryValue checks min and max against numbers, or (if checkLength
+// is set) against the length of strings
+func validateQueryValue(v reflect.Value, value string, rules map[string]string, checkLength bool) error {
+	if allowed, ok := rules["oneof"]; ok {
+		found := false
+		for _, option := range strings.Split(allowed, "|") {
+			if option == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: must be one of %s", ErrQueryNotAllowed, allowed)
+		}
+	}
+	var n float64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	case reflect.String:
+		if !checkLength {
+			return nil
+		}
+		return validateQueryLength(len(value), rules)
+	default:
+		return nil
+	}
+	return validateQueryBounds(n, rules)
+}
+
+func validateQueryLength(length int, rules map[string]string) error {
+	return validateQueryBounds(float64(length), rules)
+}
+
+func validateQueryBounds(n float64, rules map[string]string) error {
+	if arg, ok := rules["min"]; ok {
+		if minValue, err := strconv.ParseFloat(arg, 64); err == nil && n < minValue {
+			return fmt.Errorf("%w: must be at least %s", ErrQueryOutOfRange, arg)
+		}
+	}
+	if arg, ok := rules["max"]; ok {
+		if maxValue, err := strconv.ParseFloat(arg, 64); err == nil && n > maxValue {
+			return fmt.Errorf("%w: must be at most %s", ErrQueryOutOfRange, arg)
+		}
+	}
+	return nil
+}
+
+// CacheKey returns the request path plus the given query keys, canonicalized
+// (keys and values sorted, empty values dropped), for keying loader caches.
+// Only the listed keys are included, so tracking params like utm_source don't
+// fragment the cache.
+func (p DataProps) CacheKey(queryKeys ...string) string {
+	if p.Request == nil {
+		return ""
+	}
+	path := p.Request.URL.Path
+	if len(queryKeys) == 0 {
+		return path
+	}
+	query := p.Request.URL.Query()
+	canonical := make(url.Values, len(queryKeys))
+	for _, key := range queryKeys {
+		values := getNonEmptyValues(query[key])
+		if len(values) == 0 {
+			continue
+		}
+		sort.Strings(values)
+		canonical[key] = values
+	}
+	if len(canonical) == 0 {
+		return path
+	}
+	// Encode sorts by key
+	return path + "?" + canonical.Encode()
+}
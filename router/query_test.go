@@ -0,0 +1,64 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testSearchParams struct {
+	Page     int      `query:"page" default:"1" validate:"min=1"`
+	PerPage  uint     `query:"per_page" default:"20" validate:"max=100"`
+	Sort     string   `query:"sort" default:"asc" validate:"oneof=asc|desc"`
+	Q        string   `query:"q" validate:"required,max=10"`
+	Tags     []string `query:"tag" validate:"max=2"`
+	Archived bool     `query:"archived"`
+}
+
+func TestBindQuery(t *testing.T) {
+	bind := func(target string) (testSearchParams, error) {
+		var params testSearchParams
+		err := DataProps{Request: httptest.NewRequest("GET", target, nil)}.BindQuery(&params)
+		return params, err
+	}
+
+	params, err := bind("/search?q=shoes&page=3&tag=a&tag=b&archived=true&per_page=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Page != 3 || params.PerPage != 20 || params.Sort != "asc" || params.Q != "shoes" ||
+		len(params.Tags) != 2 || !params.Archived {
+		t.Errorf("unexpected params: %+v", params)
+	}
+
+	for target, expected := range map[string]error{
+		"/search":                       ErrMissingQueryParam,
+		"/search?q=x&page=0":            ErrQueryOutOfRange,
+		"/search?q=x&sort=sideways":     ErrQueryNotAllowed,
+		"/search?q=much-too-long":       ErrQueryOutOfRange,
+		"/search?q=x&tag=a&tag=b&tag=c": ErrQueryOutOfRange,
+	} {
+		_, err := bind(target)
+		var queryErr *QueryError
+		if !errors.Is(err, expected) || !errors.As(err, &queryErr) || queryErr.StatusCode() != http.StatusBadRequest {
+			t.Errorf("%s: expected %v, got %v", target, expected, err)
+		}
+	}
+	if _, err := bind("/search?q=x&page=two"); err == nil {
+		t.Error("expected conversion error")
+	}
+	if err := (DataProps{}).BindQuery(testSearchParams{}); err == nil {
+		t.Error("expected error for non-pointer dst")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	props := DataProps{Request: httptest.NewRequest("GET", "/products?utm_source=x&sort=price&tag=b&tag=a&page=", nil)}
+	if got := props.CacheKey(); got != "/products" {
+		t.Errorf("expected path only, got %q", got)
+	}
+	if got := props.CacheKey("tag", "sort", "page"); got != "/products?sort=price&tag=a&tag=b" {
+		t.Errorf("unexpected cache key %q", got)
+	}
+}
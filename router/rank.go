@@ -0,0 +1,61 @@
+package router
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Route precedence, when several routes match a path:
+//
+//  1. The ultimate catch route ("/$") only wins when nothing else matches.
+//  2. Every matching static layout (a route whose last segment is static)
+//     renders, as a layout of the deeper routes.
+//  3. The other routes compete in groups by segment count, where one must
+//     outscore any static layout of its length to stay in. Each group's best
+//     route by RankRoutes wins, except that an index route deeper than the
+//     path's own segments beats the rest of its group.
+//  4. A dynamic index route loses to a higher-scoring static layout of the
+//     same depth that doesn't share its dynamic segment.
+//  5. If the deepest winner doesn't cover the whole path, the best-scoring
+//     splat route covers it instead, falling back to the ultimate catch route.
+//
+// Hwy.ExplainMatch shows which of these eliminated each candidate.
+
+// RankRoutes returns candidates (routes matching the same path, with their
+// Score from matching) sorted best first: by Score (6 per static segment, 5
+// per compound segment like "$slug-$id", 4 per dynamic segment, and 2 for a
+// splat), then by the count of static characters in their patterns, then by
+// pattern. Ties are broken the same way whatever the order of the route
+// table.
+func RankRoutes(candidates []*MatchingPath) []*MatchingPath {
+	ranked := slices.Clone(candidates)
+	slices.SortStableFunc(ranked, compareRoutes)
+	return ranked
+}
+
+// compareRoutes is negative when a outranks b
+func compareRoutes(a, b *MatchingPath) int {
+	if c := cmp.Compare(b.Score, a.Score); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(getStaticCharCount(b.Pattern), getStaticCharCount(a.Pattern)); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Pattern, b.Pattern)
+}
+
+// getStaticCharCount counts the characters of pattern outside of params and
+// splats
+func getStaticCharCount(pattern string) int {
+	count := 0
+	for _, segment := range strings.Split(strings.TrimSuffix(pattern, "/_index"), "/") {
+		switch {
+		case getIsCompoundSegment(segment):
+			count += len(compoundParamRe.ReplaceAllString(segment, ""))
+		case !strings.HasPrefix(segment, "$"):
+			count += len(segment)
+		}
+	}
+	return count
+}
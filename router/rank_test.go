@@ -0,0 +1,102 @@
+package router
+
+import (
+	"math/rand/v2"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRankRoutes(t *testing.T) {
+	candidates := []*MatchingPath{
+		{Pattern: "/blog/$slug", Score: 10},
+		{Pattern: "/blog/latest-$id", Score: 11},
+		{Pattern: "/blog/$slug-$id", Score: 11},
+		{Pattern: "/blog/$", Score: 8},
+		{Pattern: "/blog/$a-$b", Score: 11},
+	}
+	var patterns []string
+	for _, path := range RankRoutes(candidates) {
+		patterns = append(patterns, path.Pattern)
+	}
+	expected := []string{"/blog/latest-$id", "/blog/$a-$b", "/blog/$slug-$id", "/blog/$slug", "/blog/$"}
+	if !reflect.DeepEqual(patterns, expected) {
+		t.Errorf("expected %v, got %v", expected, patterns)
+	}
+	if candidates[0].Pattern != "/blog/$slug" {
+		t.Error("expected the candidates to be left unsorted")
+	}
+}
+
+// Precedence must not depend on the order of the route table
+func TestRankRoutesProperties(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	words := []string{"tiger", "lion", "bear", "dashboard", "customers", "orders", "articles", "test", "index", "dynamic-index", "123", "456"}
+	urls := []string{"/"}
+	for range 300 {
+		segments := make([]string, 1+rng.IntN(5))
+		for i := range segments {
+			segments[i] = words[rng.IntN(len(words))]
+		}
+		urls = append(urls, "/"+strings.Join(segments, "/"))
+	}
+
+	matchAll := func() [][]string {
+		gmpdCache = NewLRUCache(gmpdCacheSize)
+		results := make([][]string, 0, len(urls))
+		for _, url := range urls {
+			item := getMatchingPathItem(httptest.NewRequest("GET", url, nil))
+			var result []string
+			for _, path := range *item.MatchingPaths {
+				result = append(result, path.Pattern)
+			}
+			if item.SplatSegments != nil {
+				result = append(result, "splat:"+strings.Join(*item.SplatSegments, "/"))
+			}
+			results = append(results, result)
+		}
+		return results
+	}
+
+	prevPaths, prevCache := instancePaths, gmpdCache
+	t.Cleanup(func() { instancePaths, gmpdCache = prevPaths, prevCache })
+	expected := matchAll()
+	for range 20 {
+		shuffled := slices.Clone(*prevPaths)
+		rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		instancePaths = &shuffled
+		results := matchAll()
+		for i, url := range urls {
+			if !reflect.DeepEqual(results[i], expected[i]) {
+				t.Fatalf("%s: expected %v regardless of route order, got %v", url, expected[i], results[i])
+			}
+		}
+	}
+
+	// Ranking is a total order: any order of candidates ranks the same
+	for range 50 {
+		candidates := make([]*MatchingPath, 0, 6)
+		for range 1 + rng.IntN(6) {
+			candidates = append(candidates, &MatchingPath{
+				Pattern: "/" + words[rng.IntN(len(words))] + "/$" + words[rng.IntN(3)],
+				Score:   rng.IntN(3),
+			})
+		}
+		ranked := RankRoutes(candidates)
+		reversed := slices.Clone(candidates)
+		slices.Reverse(reversed)
+		rerankedPatterns, rankedPatterns := []string{}, []string{}
+		for i, path := range RankRoutes(reversed) {
+			rerankedPatterns = append(rerankedPatterns, path.Pattern)
+			rankedPatterns = append(rankedPatterns, ranked[i].Pattern)
+			if i > 0 && compareRoutes(ranked[i], ranked[i-1]) < 0 {
+				t.Fatalf("expected %s not to outrank %s", ranked[i].Pattern, ranked[i-1].Pattern)
+			}
+		}
+		if !reflect.DeepEqual(rankedPatterns, rerankedPatterns) {
+			t.Fatalf("expected the same ranking in any order, got %v and %v", rankedPatterns, rerankedPatterns)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package router
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter decides whether a request to a matched route may proceed,
+// before any guards, loaders, or actions run
+type RateLimiter interface {
+	// Returns false, with how long the client should wait before retrying,
+	// to reject the request
+	Allow(pattern string, method string, key string) (bool, time.Duration)
+}
+
+type RateLimit struct {
+	Limiter RateLimiter
+	// Identifies the client. Defaults to the IP of r.RemoteAddr (so behind a
+	// proxy, set this to read the forwarded client IP).
+	Key func(r *http.Request) string
+}
+
+func (l *RateLimit) getKey(r *http.Request) string {
+	if l.Key != nil {
+		return l.Key(r)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// checkRateLimit returns a 429 outcome if the limiter rejects the request to
+// item's route, setting Retry-After (when w isn't nil)
+func (h Hwy) checkRateLimit(w http.ResponseWriter, r *http.Request, item *gmpdItem) *GuardOutcome {
+	if h.RateLimit == nil || h.RateLimit.Limiter == nil || getIsUnmatched(item) {
+		return nil
+	}
+	matchingPaths := *item.MatchingPaths
+	pattern := matchingPaths[len(matchingPaths)-1].Pattern
+	allowed, retryAfter := h.RateLimit.Limiter.Allow(pattern, r.Method, h.RateLimit.getKey(r))
+	if allowed {
+		return nil
+	}
+	if w != nil {
+		seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	return Deny(http.StatusTooManyRequests)
+}
+
+// TokenBucketLimiter is an in-memory RateLimiter with a token bucket per
+// route, method, and client. Buckets are per process, so with several
+// instances each enforces its own limit.
+type TokenBucketLimiter struct {
+	// Tokens added per second
+	Rate float64
+	// Bucket capacity, i.e. how many requests may be made at once
+	Burst int
+	// Limited methods. Defaults to those that run actions (POST, PUT, PATCH,
+	// and DELETE).
+	Methods []string
+	// Past this many buckets, the least recently used are dropped (so rotating
+	// keys can't grow memory without bound). Defaults to 100,000.
+	MaxBuckets int
+
+	mu      sync.Mutex
+	buckets *cache
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+const defaultMaxTokenBuckets = 100_000
+
+func NewTokenBucketLimiter(rate float64, burst int) *TokenBucketLimiter {
+	return &TokenBucketLimiter{Rate: rate, Burst: burst}
+}
+
+func (l *TokenBucketLimiter) Allow(pattern string, method string, key string) (bool, time.Duration) {
+	if l.Methods == nil {
+		if _, ok := acceptedMethods[method]; !ok {
+			return true, 0
+		}
+	} else if !slices.Contains(l.Methods, method) {
+		return true, 0
+	}
+	now := instanceClock.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buckets == nil {
+		maxBuckets := l.MaxBuckets
+		if maxBuckets <= 0 {
+			maxBuckets = defaultMaxTokenBuckets
+		}
+		l.buckets = NewLRUCache(maxBuckets)
+	}
+	bucketKey := pattern + "\x00" + method + "\x00" + key
+	var bucket *tokenBucket
+	if cached, ok := l.buckets.Get(bucketKey); ok {
+		bucket = cached.(*tokenBucket)
+	} else {
+		bucket = &tokenBucket{tokens: float64(l.Burst), updated: now}
+		l.buckets.Set(bucketKey, bucket, false)
+	}
+	bucket.refill(now, l.Rate, l.Burst)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	if l.Rate <= 0 {
+		return false, time.Minute
+	}
+	return false, time.Duration((1 - bucket.tokens) / l.Rate * float64(time.Second))
+}
+
+func (b *tokenBucket) refill(now time.Time, rate float64, burst int) {
+	b.tokens = min(b.tokens+now.Sub(b.updated).Seconds()*rate, float64(burst))
+	b.updated = now
+}
@@ -0,0 +1,107 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketLimiter(t *testing.T) {
+	l := NewTokenBucketLimiter(1, 2)
+	for i := range 2 {
+		if ok, _ := l.Allow("/lion", "POST", "1.2.3.4"); !ok {
+			t.Fatalf("expected request %d to be within the burst", i+1)
+		}
+	}
+	ok, retryAfter := l.Allow("/lion", "POST", "1.2.3.4")
+	if ok || retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("expected a rejection retrying within a second, got %v %v", ok, retryAfter)
+	}
+	if ok, _ := l.Allow("/lion", "POST", "5.6.7.8"); !ok {
+		t.Error("expected other clients to have their own bucket")
+	}
+	if ok, _ := l.Allow("/tiger", "POST", "1.2.3.4"); !ok {
+		t.Error("expected other routes to have their own bucket")
+	}
+	if ok, _ := l.Allow("/lion", "GET", "1.2.3.4"); !ok {
+		t.Error("expected non-action methods not to be limited by default")
+	}
+
+	l.Methods = []string{"GET"}
+	l.Allow("/bear", "GET", "1.2.3.4")
+	l.Allow("/bear", "GET", "1.2.3.4")
+	if ok, _ := l.Allow("/bear", "GET", "1.2.3.4"); ok {
+		t.Error("expected Methods to be limited")
+	}
+}
+
+func TestTokenBucketLimiterRefill(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	prevClock := instanceClock
+	instanceClock = clock
+	t.Cleanup(func() { instanceClock = prevClock })
+
+	l := NewTokenBucketLimiter(1, 1)
+	l.Allow("/lion", "POST", "1.2.3.4")
+	if ok, _ := l.Allow("/lion", "POST", "1.2.3.4"); ok {
+		t.Fatal("expected the bucket to be empty")
+	}
+	clock.Advance(time.Second)
+	if ok, _ := l.Allow("/lion", "POST", "1.2.3.4"); !ok {
+		t.Error("expected the bucket to refill per the clock")
+	}
+}
+
+func TestTokenBucketLimiterMaxBuckets(t *testing.T) {
+	l := NewTokenBucketLimiter(0, 1)
+	l.MaxBuckets = 10
+	l.Allow("/lion", "POST", "attacker")
+	for i := range 100 {
+		l.Allow("/lion", "POST", strconv.Itoa(i))
+	}
+	if n := l.buckets.order.Len(); n > l.MaxBuckets+1 {
+		t.Errorf("expected at most %d buckets, got %d", l.MaxBuckets+1, n)
+	}
+	if ok, _ := l.Allow("/lion", "POST", "99"); ok {
+		t.Error("expected recently used buckets to be kept")
+	}
+}
+
+func TestRateLimitedAction(t *testing.T) {
+	var actionRuns int
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Action: func(props *ActionProps) (any, error) {
+			actionRuns++
+			return nil, nil
+		},
+	})
+	var gotPattern, gotKey string
+	h := Hwy{RateLimit: &RateLimit{
+		Limiter: rateLimiterFunc(func(pattern string, method string, key string) (bool, time.Duration) {
+			gotPattern, gotKey = pattern, key
+			return false, 1500 * time.Millisecond
+		}),
+	}}
+
+	r := httptest.NewRequest("POST", "/lion/rate-limited", nil)
+	r.RemoteAddr = "1.2.3.4:5678"
+	w := httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w, r)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "2" {
+		t.Errorf("expected a 429 retrying after 2s, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if actionRuns != 0 {
+		t.Error("expected the action not to run")
+	}
+	if gotPattern != "/lion/$" || gotKey != "1.2.3.4" {
+		t.Errorf("unexpected limiter input %q %q", gotPattern, gotKey)
+	}
+}
+
+type rateLimiterFunc func(pattern string, method string, key string) (bool, time.Duration)
+
+func (f rateLimiterFunc) Allow(pattern string, method string, key string) (bool, time.Duration) {
+	return f(pattern, method, key)
+}
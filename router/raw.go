@@ -0,0 +1,71 @@
+package router
+
+import (
+	"io"
+	"mime"
+	"net/http"
+)
+
+// RawResponse, when returned by a loader, is written as is in place of the
+// page or route data JSON, e.g. for CSV exports, PDFs, or proxied files that
+// still want route matching, params, and guards. The outermost loader
+// returning one wins. Headers from DataFuncs.Headers still apply.
+type RawResponse struct {
+	Status  int // defaults to 200
+	Headers http.Header
+	Body    io.Reader // closed once written, if it's an io.Closer
+}
+
+// NewDownload returns a RawResponse the browser saves as filename
+func NewDownload(filename, contentType string, body io.Reader) *RawResponse {
+	return &RawResponse{
+		Headers: http.Header{
+			"Content-Type":        {contentType},
+			"Content-Disposition": {mime.FormatMediaType("attachment", map[string]string{"filename": filename})},
+		},
+		Body: body,
+	}
+}
+
+func getRawResponse(data any) *RawResponse {
+	switch raw := data.(type) {
+	case *RawResponse:
+		return raw
+	case RawResponse:
+		return &raw
+	}
+	return nil
+}
+
+// getOutermostRawResponse returns nil if no loader returned a RawResponse
+func getOutermostRawResponse(loadersData []any, errs []error) *RawResponse {
+	for i, data := range loadersData {
+		if errs[i] != nil {
+			return nil
+		}
+		if raw := getRawResponse(data); raw != nil {
+			return raw
+		}
+	}
+	return nil
+}
+
+func (h Hwy) serveRawResponse(w http.ResponseWriter, raw *RawResponse) {
+	if closer, ok := raw.Body.(io.Closer); ok {
+		defer closer.Close()
+	}
+	for key, values := range raw.Headers {
+		w.Header()[http.CanonicalHeaderKey(key)] = values
+	}
+	status := raw.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	if raw.Body == nil {
+		return
+	}
+	if _, err := io.Copy(w, raw.Body); err != nil {
+		h.getLogger().Error("error writing raw response", "error", err)
+	}
+}
@@ -0,0 +1,69 @@
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testRawBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *testRawBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRawResponse(t *testing.T) {
+	body := &testRawBody{Reader: strings.NewReader("id,name\n1,Simba\n")}
+	headRan := false
+	setTestDataFuncs(t, "/lion", &DataFuncs{
+		Headers: func(props *HeadersProps) (http.Header, error) {
+			return http.Header{"Cache-Control": {"private"}}, nil
+		},
+	})
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			if (*props.SplatSegments)[0] == "accepted" {
+				return RawResponse{Status: http.StatusAccepted}, nil
+			}
+			return NewDownload("lions.csv", "text/csv", body), nil
+		},
+		Head: func(props *HeadProps) (*[]HeadBlock, error) {
+			headRan = true
+			return nil, nil
+		},
+	})
+
+	// A document request, which would otherwise need a root template
+	w := httptest.NewRecorder()
+	Hwy{}.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/lion/raw-export", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "id,name\n1,Simba\n" {
+		t.Errorf("unexpected response: %d %s", w.Code, w.Body)
+	}
+	for key, want := range map[string]string{
+		"Content-Type":        "text/csv",
+		"Content-Disposition": `attachment; filename=lions.csv`,
+		"Cache-Control":       "private",
+	} {
+		if got := w.Header().Get(key); got != want {
+			t.Errorf("expected %s %q, got %q", key, want, got)
+		}
+	}
+	if !body.closed {
+		t.Error("expected the body to be closed")
+	}
+	if headRan {
+		t.Error("expected head functions to be skipped")
+	}
+
+	w = httptest.NewRecorder()
+	Hwy{}.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/lion/accepted?"+HwyPrefix+"json=1", nil))
+	if w.Code != http.StatusAccepted || w.Body.Len() != 0 {
+		t.Errorf("expected an empty 202 for JSON requests too, got %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,87 @@
+package router
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Invocation is a data func call recorded by an InvocationRecorder
+type Invocation struct {
+	Phase   ErrorPhase // ErrorPhaseGuard, ErrorPhaseLoader, ErrorPhaseAction, or ErrorPhaseHead
+	Pattern string
+	Method  string
+	URL     string // the request's path and query
+	// Copies of the props the data func was called with
+	Params        map[string]string
+	SplatSegments []string
+	Locale        string
+	IsPrefetch    bool
+	Result        any
+	Err           error
+	Duration      time.Duration
+}
+
+// InvocationRecorder records the guard, loader, action, and head calls of the
+// Hwy it's set on (see Hwy.InvocationRecorder), e.g. for integration tests to
+// assert that navigating to a URL ran exactly the expected loaders, with the
+// expected params. It's safe for concurrent use.
+type InvocationRecorder struct {
+	mu          sync.Mutex
+	invocations []Invocation
+}
+
+// Invocations returns the calls recorded since the last Reset, in the order
+// they returned (which for loaders run concurrently isn't the route order)
+func (rec *InvocationRecorder) Invocations() []Invocation {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return slices.Clone(rec.invocations)
+}
+
+// Patterns returns the patterns of the recorded calls in phase, sorted
+func (rec *InvocationRecorder) Patterns(phase ErrorPhase) []string {
+	var patterns []string
+	for _, invocation := range rec.Invocations() {
+		if invocation.Phase == phase {
+			patterns = append(patterns, invocation.Pattern)
+		}
+	}
+	slices.Sort(patterns)
+	return patterns
+}
+
+func (rec *InvocationRecorder) Reset() {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.invocations = nil
+}
+
+func (h Hwy) recordInvocation(phase ErrorPhase, pattern string, props *DataProps, startTime time.Time, result any, err error) {
+	if h.InvocationRecorder == nil {
+		return
+	}
+	invocation := Invocation{
+		Phase:      phase,
+		Pattern:    pattern,
+		Locale:     props.Locale,
+		IsPrefetch: props.IsPrefetch,
+		Result:     result,
+		Err:        err,
+		Duration:   time.Since(startTime),
+	}
+	if props.Request != nil {
+		invocation.Method = props.Request.Method
+		invocation.URL = props.Request.URL.RequestURI()
+	}
+	if props.Params != nil {
+		invocation.Params = maps.Clone(*props.Params)
+	}
+	if props.SplatSegments != nil {
+		invocation.SplatSegments = slices.Clone(*props.SplatSegments)
+	}
+	h.InvocationRecorder.mu.Lock()
+	defer h.InvocationRecorder.mu.Unlock()
+	h.InvocationRecorder.invocations = append(h.InvocationRecorder.invocations, invocation)
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestInvocationRecorder(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+
+	paths := GetPathsFromPageFiles("recorder/tiger.ui.tsx", "recorder/tiger/$tiger_id.ui.tsx")
+	pathsFileBytes, _ := json.Marshal(PathsFile{Paths: paths, BuildID: "1"})
+	errCub := errors.New("no cub")
+	h := &Hwy{
+		FS: fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}},
+		DataFuncsMap: DataFuncsMap{
+			"/recorder/tiger": {
+				Guard:  func(*GuardProps) (*GuardOutcome, error) { return nil, nil },
+				Loader: func(*LoaderProps) (any, error) { return "tiger", nil },
+			},
+			"/recorder/tiger/$tiger_id": {
+				Loader: func(props *LoaderProps) (any, error) {
+					if (*props.Params)["tiger_id"] == "cub" {
+						return nil, errCub
+					}
+					return "tiger " + (*props.Params)["tiger_id"], nil
+				},
+			},
+		},
+	}
+	if err := h.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	rec := &InvocationRecorder{}
+	recorded := h.With(WithInvocationRecorder(rec))
+
+	if _, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/recorder/tiger/123", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Invocations()) != 0 {
+		t.Fatal("expected no calls recorded without a recorder")
+	}
+
+	if _, err := recorded.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/recorder/tiger/123?q=1", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if loaders := rec.Patterns(ErrorPhaseLoader); !slices.Equal(loaders, []string{"/recorder/tiger", "/recorder/tiger/$tiger_id"}) {
+		t.Errorf("expected both loaders to be recorded, got %v", loaders)
+	}
+	if guards := rec.Patterns(ErrorPhaseGuard); !slices.Equal(guards, []string{"/recorder/tiger"}) {
+		t.Errorf("expected the guard to be recorded, got %v", guards)
+	}
+	for _, invocation := range rec.Invocations() {
+		if invocation.Pattern != "/recorder/tiger/$tiger_id" || invocation.Phase != ErrorPhaseLoader {
+			continue
+		}
+		if invocation.Params["tiger_id"] != "123" || invocation.Result != "tiger 123" || invocation.Err != nil {
+			t.Errorf("expected the loader's params and result, got %+v", invocation)
+		}
+		if invocation.Method != "GET" || invocation.URL != "/recorder/tiger/123?q=1" {
+			t.Errorf("expected the request's method and URL, got %s %s", invocation.Method, invocation.URL)
+		}
+	}
+
+	rec.Reset()
+	if _, err := recorded.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/recorder/tiger/cub", nil)); err != nil {
+		t.Fatal(err)
+	}
+	isErrRecorded := slices.ContainsFunc(rec.Invocations(), func(invocation Invocation) bool {
+		return invocation.Params["tiger_id"] == "cub" && errors.Is(invocation.Err, errCub)
+	})
+	if !isErrRecorded || len(rec.Patterns(ErrorPhaseLoader)) != 2 {
+		t.Errorf("expected only this request's calls, with the loader's error, got %+v", rec.Invocations())
+	}
+}
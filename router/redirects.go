@@ -0,0 +1,133 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RedirectRule redirects requests matching From to To before any route
+// matching happens. Both use route pattern syntax, so "/old/$id" -> "/new/$id"
+// carries the id param over, and a trailing "$" carries over the splat segments.
+type RedirectRule struct {
+	From string
+	To   string
+	// Defaults to 301
+	Status int
+}
+
+// RewriteRule serves requests matching From as if they were for To, without
+// the client ever seeing To. Pattern syntax is the same as RedirectRule.
+type RewriteRule struct {
+	From string
+	To   string
+}
+
+// matchRule matches a whole path (not just a prefix, as with layout routes)
+// against a rule pattern
+func matchRule(pattern string, path string) (*map[string]string, []string, bool) {
+	patternSegments := *getBaseSplatSegments(pattern)
+	pathSegments := *getBaseSplatSegments(path)
+	isSplat := len(patternSegments) > 0 && patternSegments[len(patternSegments)-1] == "$"
+
+	if isSplat {
+		if len(pathSegments) < len(patternSegments)-1 {
+			return nil, nil, false
+		}
+	} else if len(pathSegments) != len(patternSegments) {
+		return nil, nil, false
+	}
+
+	params := make(map[string]string)
+	for i, patternSegment := range patternSegments {
+		if patternSegment == "$" {
+			return &params, pathSegments[i:], true
+		}
+		if strings.HasPrefix(patternSegment, "$") {
+			params[patternSegment[1:]] = pathSegments[i]
+			continue
+		}
+		if patternSegment != pathSegments[i] {
+			return nil, nil, false
+		}
+	}
+	return &params, nil, true
+}
+
+// interpolateRuleTarget fills target's params and splat from the matched
+// (decoded) segments. Redirect targets go to the client as URLs, so there
+// each substituted segment is percent-escaped, "/" and "\" included, or a
+// splat like "%5Cevil.com" would turn "/$" into the scheme-relative
+// "/\evil.com".
+func interpolateRuleTarget(target string, params *map[string]string, splatSegments []string, shouldEscape bool) string {
+	escape := func(segment string) string {
+		if shouldEscape {
+			return url.PathEscape(segment)
+		}
+		return segment
+	}
+	targetSegments := strings.Split(target, "/")
+	for i, segment := range targetSegments {
+		if segment == "$" {
+			escaped := make([]string, len(splatSegments))
+			for j, splatSegment := range splatSegments {
+				escaped[j] = escape(splatSegment)
+			}
+			targetSegments[i] = strings.Join(escaped, "/")
+			continue
+		}
+		if strings.HasPrefix(segment, "$") {
+			targetSegments[i] = escape((*params)[segment[1:]])
+		}
+	}
+	return strings.Join(targetSegments, "/")
+}
+
+// getRedirectRuleOutcome returns the outcome of the first redirect rule
+// matching r, if any. Unless the rule's own target is absolute, the
+// interpolated target must pass getIsSafeRedirect like any guard redirect.
+func (h Hwy) getRedirectRuleOutcome(r *http.Request) (*GuardOutcome, error) {
+	for _, rule := range h.Redirects {
+		params, splatSegments, ok := matchRule(rule.From, r.URL.Path)
+		if !ok {
+			continue
+		}
+		to := interpolateRuleTarget(rule.To, params, splatSegments, true)
+		if !getIsAbsoluteURL(rule.To) && !h.getIsSafeRedirect(r, to) {
+			return nil, fmt.Errorf("%w: %q", ErrUnsafeRedirect, to)
+		}
+		if r.URL.RawQuery != "" && !strings.Contains(to, "?") {
+			to += "?" + r.URL.RawQuery
+		}
+		status := rule.Status
+		if status == 0 {
+			status = http.StatusMovedPermanently
+		}
+		return &GuardOutcome{Status: status, RedirectTo: to}, nil
+	}
+	return nil, nil
+}
+
+// applyRewriteRules returns a shallow copy of r with its path rewritten by
+// the first matching rule, or r itself if no rule matches
+func (h Hwy) applyRewriteRules(r *http.Request) *http.Request {
+	for _, rule := range h.Rewrites {
+		params, splatSegments, ok := matchRule(rule.From, r.URL.Path)
+		if !ok {
+			continue
+		}
+		rewritten := r.Clone(r.Context())
+		rewritten.URL.Path = interpolateRuleTarget(rule.To, params, splatSegments, false)
+		rewritten.URL.RawPath = ""
+		return rewritten
+	}
+	return r
+}
+
+// getIsAbsoluteURL reports whether a rule target names its own scheme and
+// host, in which case the app chose the destination deliberately
+func getIsAbsoluteURL(target string) bool {
+	u, err := url.Parse(target)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
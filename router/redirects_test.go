@@ -0,0 +1,80 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectRules(t *testing.T) {
+	h := Hwy{
+		Redirects: []RedirectRule{
+			{From: "/old-blog/$", To: "/blog/$"},
+			{From: "/users/$user_id/profile", To: "/profiles/$user_id", Status: http.StatusFound},
+			{From: "/old/$", To: "/$"},
+			{From: "/moved/$", To: "https://new.example.com/$"},
+		},
+	}
+
+	cases := []struct {
+		path     string
+		expected string
+		status   int
+	}{
+		{"/old-blog/2024/hello?ref=x", "/blog/2024/hello?ref=x", http.StatusMovedPermanently},
+		{"/users/42/profile", "/profiles/42", http.StatusFound},
+		{"/users/42/profile/extra", "", 0},
+		{"/lion", "", 0},
+		{"/old/a%20b", "/a%20b", http.StatusMovedPermanently},
+		{"/moved/lion", "https://new.example.com/lion", http.StatusMovedPermanently},
+		// Substituted segments are escaped, so these can't become scheme-relative
+		{"/old/%5Cevil.com", "/%5Cevil.com", http.StatusMovedPermanently},
+		{"/old/%252F%252Fevil.com", "/%2F%2Fevil.com", http.StatusMovedPermanently},
+	}
+
+	for _, c := range cases {
+		outcome, err := h.getRedirectRuleOutcome(httptest.NewRequest("GET", c.path, nil))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.path, err)
+			continue
+		}
+		if c.expected == "" {
+			if outcome != nil {
+				t.Errorf("%s: expected no redirect, got %+v", c.path, outcome)
+			}
+			continue
+		}
+		if outcome == nil || outcome.RedirectTo != c.expected || outcome.Status != c.status {
+			t.Errorf("%s: expected %d to %s, got %+v", c.path, c.status, c.expected, outcome)
+		}
+	}
+}
+
+func TestRedirectRulesUnsafeTarget(t *testing.T) {
+	// Escaping can't save a target that's scheme-relative to begin with
+	h := Hwy{Redirects: []RedirectRule{{From: "/legacy/$", To: "//$"}}}
+
+	for _, path := range []string{"/legacy/evil.com", "/legacy/%5Cevil.com"} {
+		outcome, err := h.getRedirectRuleOutcome(httptest.NewRequest("GET", path, nil))
+		if !errors.Is(err, ErrUnsafeRedirect) {
+			t.Errorf("%s: expected ErrUnsafeRedirect, got %+v, %v", path, outcome, err)
+		}
+		if _, err := h.GetRouteData(nil, httptest.NewRequest("GET", path, nil)); !errors.Is(err, ErrUnsafeRedirect) {
+			t.Errorf("%s: expected GetRouteData to fail with ErrUnsafeRedirect, got %v", path, err)
+		}
+	}
+}
+
+func TestRewriteRules(t *testing.T) {
+	h := Hwy{Rewrites: []RewriteRule{{From: "/u/$user_id", To: "/tiger/$user_id"}}}
+
+	r := httptest.NewRequest("GET", "/u/123", nil)
+	rewritten := h.applyRewriteRules(r)
+	if rewritten.URL.Path != "/tiger/123" {
+		t.Errorf("expected /tiger/123, got %s", rewritten.URL.Path)
+	}
+	if r.URL.Path != "/u/123" {
+		t.Errorf("expected original request to be left untouched")
+	}
+}
@@ -0,0 +1,182 @@
+package router
+
+import (
+	"context"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+)
+
+// RootRenderProps holds everything needed to render a full HTML document
+// around a route
+type RootRenderProps struct {
+	Request        *http.Request
+	RouteData      *GetRouteDataOutput
+	HeadElements   template.HTML // title, meta, and other head blocks
+	SSRInnerHTML   template.HTML // the route data hydration script
+	HTMLAttributes template.HTMLAttr
+	BodyAttributes template.HTMLAttr
+	CSPNonce       string
+	ClientEntryURL string
+	// Empty unless BuildOptions.Integrity was set
+	ClientEntryIntegrity string
+	// A module script loading ClientEntryURL, with its integrity and nonce
+	ClientEntryScript template.HTML
+	// Server-rendered markup to hydrate (see DataFuncs.SSRComponents), if any
+	ComponentsHTML template.HTML
+	// A style block of the matched routes' critical CSS (see
+	// DataFuncs.CriticalCSS), if any
+	CriticalCSS template.HTML
+	// Stylesheet links for the head. When there is critical CSS, these only
+	// preload, and DeferredStylesheets (for the end of the body) apply them.
+	Stylesheets         template.HTML
+	DeferredStylesheets template.HTML
+	// Registers the service worker, when Hwy.ServiceWorker is set
+	ServiceWorkerScript template.HTML
+	// Hwy.RootTemplateData
+	Data map[string]any
+}
+
+// RootRenderer renders the document for non-JSON requests. Set it as
+// Hwy.RootRenderer; when nil, the html/template at Hwy.RootTemplateLocation
+// is used.
+type RootRenderer interface {
+	RenderRoot(w io.Writer, props *RootRenderProps) error
+}
+
+// getTemplateData returns the data root html/templates are executed with:
+// Data's entries, plus each slot by field name (e.g. {{.SSRInnerHTML}})
+func (p *RootRenderProps) getTemplateData() map[string]any {
+	tmplData := make(map[string]any, len(p.Data)+13)
+	tmplData["HeadElements"] = p.HeadElements
+	tmplData["SSRInnerHTML"] = p.SSRInnerHTML
+	tmplData["HTMLAttributes"] = p.HTMLAttributes
+	tmplData["BodyAttributes"] = p.BodyAttributes
+	tmplData["CSPNonce"] = p.CSPNonce
+	tmplData["ClientEntryURL"] = p.ClientEntryURL
+	tmplData["ClientEntryIntegrity"] = p.ClientEntryIntegrity
+	tmplData["ClientEntryScript"] = p.ClientEntryScript
+	tmplData["ComponentsHTML"] = p.ComponentsHTML
+	tmplData["CriticalCSS"] = p.CriticalCSS
+	tmplData["Stylesheets"] = p.Stylesheets
+	tmplData["DeferredStylesheets"] = p.DeferredStylesheets
+	tmplData["ServiceWorkerScript"] = p.ServiceWorkerScript
+	for key, value := range p.Data {
+		tmplData[key] = value
+	}
+	return tmplData
+}
+
+// HTMLTemplateRenderer executes a parsed html/template (or the named
+// template within it, if Name is set) with the props' template data
+type HTMLTemplateRenderer struct {
+	Template *template.Template
+	Name     string
+}
+
+func (t HTMLTemplateRenderer) RenderRoot(w io.Writer, props *RootRenderProps) error {
+	if t.Name != "" {
+		return t.Template.ExecuteTemplate(w, t.Name, props.getTemplateData())
+	}
+	return t.Template.Execute(w, props.getTemplateData())
+}
+
+// fsTemplateRenderer re-parses the template on every render, so edits to it
+// show up without a restart
+type fsTemplateRenderer struct {
+	fsys     fs.FS
+	location string
+}
+
+func (t fsTemplateRenderer) RenderRoot(w io.Writer, props *RootRenderProps) error {
+	tmpl, err := template.ParseFS(t.fsys, t.location)
+	if err != nil {
+		return err
+	}
+	return HTMLTemplateRenderer{Template: tmpl}.RenderRoot(w, props)
+}
+
+// TemplComponent matches templ.Component, so templ layouts work with
+// TemplRenderer without Hwy depending on templ
+type TemplComponent interface {
+	Render(ctx context.Context, w io.Writer) error
+}
+
+// TemplRenderer renders the component it returns, e.g.
+//
+//	hwy.TemplRenderer(func(props *hwy.RootRenderProps) hwy.TemplComponent {
+//		return layouts.Root(props)
+//	})
+type TemplRenderer func(props *RootRenderProps) TemplComponent
+
+func (f TemplRenderer) RenderRoot(w io.Writer, props *RootRenderProps) error {
+	ctx := context.Background()
+	if props.Request != nil {
+		ctx = props.Request.Context()
+	}
+	return f(props).Render(ctx, w)
+}
+
+var clientEntryScriptTmpl = template.Must(template.New("cliententry").Parse(
+	`<script type="module" src="{{.URL}}"{{if .Integrity}} integrity="{{.Integrity}}" crossorigin="anonymous"{{end}}{{if .Nonce}} nonce="{{.Nonce}}"{{end}}></script>`,
+))
+
+func (h Hwy) newRootRenderProps(r *http.Request, routeData *GetRouteDataOutput) (*RootRenderProps, error) {
+	headElements, err := GetHeadElements(routeData)
+	if err != nil {
+		return nil, err
+	}
+	ssrInnerHTML, err := GetSSRInnerHTML(routeData, h.IsDev)
+	if err != nil {
+		return nil, err
+	}
+	criticalCSS, stylesheets, deferredStylesheets, err := h.getStylesheetsHTML(routeData)
+	if err != nil {
+		return nil, err
+	}
+	serviceWorkerScript, err := h.getServiceWorkerScript(routeData.nonce)
+	if err != nil {
+		return nil, err
+	}
+	clientEntryFileName := GetClientEntryFileName(routeData.ClientEntry)
+	if routeData.isLegacy {
+		clientEntryFileName = getLegacyFileName(clientEntryFileName)
+	}
+	props := &RootRenderProps{
+		Request:              r,
+		RouteData:            routeData,
+		HeadElements:         *headElements,
+		SSRInnerHTML:         *ssrInnerHTML,
+		HTMLAttributes:       GetAttributesHTML(routeData.HTMLAttributes),
+		BodyAttributes:       GetAttributesHTML(routeData.BodyAttributes),
+		CSPNonce:             routeData.nonce,
+		ClientEntryURL:       h.GetAssetURL(clientEntryFileName),
+		ClientEntryIntegrity: GetIntegrity(clientEntryFileName),
+		ComponentsHTML:       h.renderComponents(r, routeData),
+		CriticalCSS:          criticalCSS,
+		Stylesheets:          stylesheets,
+		DeferredStylesheets:  deferredStylesheets,
+		ServiceWorkerScript:  serviceWorkerScript,
+		Data:                 h.RootTemplateData,
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	err = clientEntryScriptTmpl.Execute(buf, map[string]string{
+		"URL":       props.ClientEntryURL,
+		"Integrity": props.ClientEntryIntegrity,
+		"Nonce":     props.CSPNonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+	props.ClientEntryScript = template.HTML(buf.String())
+	return props, nil
+}
+
+func (h Hwy) getRootRenderer() RootRenderer {
+	if h.RootRenderer != nil {
+		return h.RootRenderer
+	}
+	return fsTemplateRenderer{fsys: h.FS, location: h.RootTemplateLocation}
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"context"
+	"html/template"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testTemplComponent struct{ props *RootRenderProps }
+
+func (c testTemplComponent) Render(ctx context.Context, w io.Writer) error {
+	_, err := io.WriteString(w, "<html><head>"+string(c.props.HeadElements)+string(c.props.SSRInnerHTML)+
+		string(c.props.ClientEntryScript)+"</head><body "+string(c.props.BodyAttributes)+"></body></html>")
+	return err
+}
+
+func TestRootRenderers(t *testing.T) {
+	tmpl := template.Must(template.New("root").Parse(
+		`<html {{.HTMLAttributes}}><head>{{.HeadElements}}{{.SSRInnerHTML}}{{.ClientEntryScript}}</head><body>{{.AppName}}</body></html>`,
+	))
+	for name, renderer := range map[string]RootRenderer{
+		"html/template": HTMLTemplateRenderer{Template: tmpl},
+		"templ": TemplRenderer(func(props *RootRenderProps) TemplComponent {
+			return testTemplComponent{props}
+		}),
+	} {
+		h := Hwy{RootRenderer: renderer, RootTemplateData: map[string]any{"AppName": "Zoo"}}
+		w := httptest.NewRecorder()
+		h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/lion/render-test", nil))
+		body := w.Body.String()
+		if w.Code != 200 {
+			t.Fatalf("%s: expected 200, got %d: %s", name, w.Code, body)
+		}
+		for _, expected := range []string{`x.pattern = "/lion/$"`, `<script type="module" src="/public/hwy_client_entry.js"`} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("%s: expected %q in:\n%s", name, expected, body)
+			}
+		}
+		if name == "html/template" && !strings.Contains(body, "<body>Zoo</body>") {
+			t.Errorf("expected RootTemplateData in template: %s", body)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ResolveOptions configures ResolveRouteData
+type ResolveOptions struct {
+	// Matches the tenant's route tree (see Hwy.Tenants), rather than the one
+	// Tenants resolves from the URL
+	Tenant string
+	// Sent with the request, e.g. the Accept-Language or cookies of a
+	// representative anonymous visitor
+	Header http.Header
+	// Makes loaders see the request as a prefetch (see DataProps.IsPrefetch),
+	// e.g. to skip side effects like view counts
+	AsPrefetch bool
+	// Skips rendering ResolvedRouteData.HTML
+	SkipHTML bool
+}
+
+// ResolvedRouteData is a route's data resolved outside an HTTP request (see
+// ResolveRouteData), along with the responses GetRootHandler would send
+type ResolvedRouteData struct {
+	RouteData *GetRouteDataOutput
+	// The status of page loads, e.g. 404 when a loader returned NotFound, or
+	// 302 when a guard redirected (navigations get 200s, with the outcome)
+	Status int
+	// Headers set by data funcs (e.g. by DataFuncs.HandlerFunc)
+	Header http.Header
+	// The response to the client router's navigations, shaped for the
+	// ProtocolHeader of ResolveOptions.Header (as GetRootHandler would)
+	JSON []byte
+	// The response to page loads. Nil with ResolveOptions.SkipHTML, or when a
+	// guard or redirect rule short-circuited, or a loader returned a
+	// RawResponse.
+	HTML []byte
+}
+
+// ResolveRouteData runs a GET of url (a path, or an absolute URL for
+// Hwy.Tenants to resolve by host) through the route's guards, loaders, and
+// heads, without an HTTP request, e.g. for background jobs (see Schedule) to
+// pre-render hot URLs and push them to a CDN, or to prime loader caches. ctx
+// is the data funcs' request context.
+func (h Hwy) ResolveRouteData(ctx context.Context, url string, opts ResolveOptions) (*ResolvedRouteData, error) {
+	if opts.Tenant != "" {
+		ctx = context.WithValue(ctx, tenantContextKey{}, opts.Tenant)
+	}
+	r, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range opts.Header {
+		r.Header[key] = append([]string(nil), values...)
+	}
+	if opts.AsPrefetch {
+		r.Header.Set("Sec-Purpose", "prefetch")
+	}
+	r = h.withTenant(nil, r)
+
+	w := &resolveResponseWriter{header: make(http.Header)}
+	routeData, err := h.GetRouteData(w, r)
+	if err != nil {
+		return nil, err
+	}
+	resolved := &ResolvedRouteData{
+		RouteData: routeData,
+		Status:    http.StatusOK,
+		Header:    w.header,
+	}
+	if routeData.Status != 0 {
+		resolved.Status = routeData.Status
+	}
+	if routeData.GuardOutcome != nil {
+		resolved.Status = routeData.GuardOutcome.status()
+	}
+
+	protocolVersion, ok := h.getProtocolVersion(r)
+	if !ok {
+		return nil, fmt.Errorf("unsupported %s version %q", ProtocolHeader, r.Header.Get(ProtocolHeader))
+	}
+	jsonRouteData := *routeData
+	if protocolVersion >= 2 {
+		jsonRouteData.Protocol = protocolVersion
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := h.encodeRouteDataJSON(r, buf, &jsonRouteData); err != nil {
+		return nil, err
+	}
+	resolved.JSON = append([]byte(nil), buf.Bytes()...)
+
+	if opts.SkipHTML || routeData.GuardOutcome != nil || routeData.RawResponse != nil {
+		return resolved, nil
+	}
+	renderProps, err := h.newRootRenderProps(r, routeData)
+	if err != nil {
+		return nil, err
+	}
+	buf.Reset()
+	_, span := h.startSpan(ctx, SpanSerialize, SpanAttribute{Key: "hwy.format", Value: "html"})
+	err = h.getRootRenderer().RenderRoot(buf, renderProps)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	resolved.HTML = append([]byte(nil), buf.Bytes()...)
+	return resolved, nil
+}
+
+// resolveResponseWriter collects the headers data funcs set, discarding
+// anything they write
+type resolveResponseWriter struct {
+	header http.Header
+}
+
+func (w *resolveResponseWriter) Header() http.Header         { return w.header }
+func (w *resolveResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *resolveResponseWriter) WriteHeader(int)             {}
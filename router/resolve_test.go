@@ -0,0 +1,94 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestResolveRouteData(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+
+	paths := GetPathsFromPageFiles("resolve/$tiger_id.ui.tsx", "resolve/private.ui.tsx", "resolve/$.ui.tsx")
+	pathsFileBytes, _ := json.Marshal(PathsFile{Paths: paths, BuildID: "1"})
+	type ctxKey struct{}
+	h := &Hwy{
+		FS: fstest.MapFS{
+			"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes},
+			"index.go.html":  &fstest.MapFile{Data: []byte(`<html><head>{{.HeadElements}}</head></html>`)},
+		},
+		RootTemplateLocation: "index.go.html",
+		DataFuncsMap: DataFuncsMap{
+			"/resolve/$tiger_id": {
+				Loader: func(props *LoaderProps) (any, error) {
+					if (*props.Params)["tiger_id"] == "missing" {
+						return nil, NotFound()
+					}
+					return map[string]any{
+						"lang":       props.Request.Header.Get("Accept-Language"),
+						"isPrefetch": props.IsPrefetch,
+						"fromCtx":    props.Request.Context().Value(ctxKey{}),
+					}, nil
+				},
+				Head: func(props *HeadProps) (*[]HeadBlock, error) {
+					return &[]HeadBlock{{Title: "Tiger " + (*props.Params)["tiger_id"]}}, nil
+				},
+				HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Cache-Control", "public, max-age=60")
+				},
+			},
+			"/resolve/private": {
+				Guard: func(*GuardProps) (*GuardOutcome, error) { return Redirect("/login"), nil },
+			},
+		},
+	}
+	if err := h.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "job")
+	resolved, err := h.ResolveRouteData(ctx, "/resolve/123", ResolveOptions{
+		Header:     http.Header{"Accept-Language": {"fr"}},
+		AsPrefetch: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaderData := (*resolved.RouteData.LoadersData)[0].(map[string]any)
+	if loaderData["lang"] != "fr" || loaderData["isPrefetch"] != true || loaderData["fromCtx"] != "job" {
+		t.Errorf("expected the loader to see the options and context, got %v", loaderData)
+	}
+	if resolved.Status != http.StatusOK || resolved.Header.Get("Cache-Control") != "public, max-age=60" {
+		t.Errorf("expected a 200 with the handler's headers, got %d %v", resolved.Status, resolved.Header)
+	}
+	if !strings.Contains(string(resolved.HTML), "<title>Tiger 123</title>") {
+		t.Errorf("expected the rendered document, got %s", resolved.HTML)
+	}
+	var jsonRouteData GetRouteDataOutput
+	if err := json.Unmarshal(resolved.JSON, &jsonRouteData); err != nil || jsonRouteData.Title != "Tiger 123" {
+		t.Errorf("expected the navigation's JSON, got %s (%v)", resolved.JSON, err)
+	}
+
+	resolved, err = h.ResolveRouteData(context.Background(), "/resolve/missing", ResolveOptions{SkipHTML: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Status != http.StatusNotFound || resolved.HTML != nil || resolved.RouteData.Pattern != "/resolve/$" {
+		t.Errorf("expected the catch route's 404 without HTML, got %d %s", resolved.Status, resolved.RouteData.Pattern)
+	}
+
+	resolved, err = h.ResolveRouteData(context.Background(), "/resolve/private", ResolveOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Status != http.StatusFound || resolved.HTML != nil || resolved.RouteData.GuardOutcome.RedirectTo != "/login" {
+		t.Errorf("expected the guard's redirect, got %d %+v", resolved.Status, resolved.RouteData.GuardOutcome)
+	}
+
+	if _, err := h.ResolveRouteData(context.Background(), "/resolve/1", ResolveOptions{Header: http.Header{ProtocolHeader: {"999"}}}); err == nil {
+		t.Error("expected an unsupported protocol version to fail")
+	}
+}
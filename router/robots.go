@@ -0,0 +1,47 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Noindex picks the pages that get a robots meta tag keeping them out of
+// search indexes, e.g. error pages and staging environments
+type Noindex struct {
+	// Defaults to DefaultNoindexStatuses. Pages whose loader errors were
+	// rendered in an error boundary count as 500s. Empty (but not nil) adds
+	// the tag for no status.
+	Statuses []int
+	// Reports whether r is served by a preview or staging environment, whose
+	// pages all get the tag
+	IsPreview func(*http.Request) bool
+	// Defaults to "noindex"
+	Content string
+}
+
+// Used when Noindex.Statuses is nil
+var DefaultNoindexStatuses = []int{http.StatusNotFound, http.StatusGone, http.StatusInternalServerError}
+
+func (h Hwy) getIsNoindex(r *http.Request, activePathData *ActivePathData) bool {
+	n := h.Noindex
+	if n != nil && n.IsPreview != nil && n.IsPreview(r) {
+		return true
+	}
+	statuses := DefaultNoindexStatuses
+	if n != nil && n.Statuses != nil {
+		statuses = n.Statuses
+	}
+	status := activePathData.Status
+	if status == 0 && activePathData.ErrorRenderPlan != nil {
+		status = http.StatusInternalServerError
+	}
+	return slices.Contains(statuses, status)
+}
+
+func (n *Noindex) getHeadBlock() HeadBlock {
+	content := "noindex"
+	if n != nil && n.Content != "" {
+		content = n.Content
+	}
+	return HeadBlock{Tag: "meta", Attributes: map[string]string{"name": "robots", "content": content}}
+}
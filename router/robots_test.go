@@ -0,0 +1,66 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNoindex(t *testing.T) {
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			switch (*props.SplatSegments)[0] {
+			case "missing":
+				return nil, NotFound()
+			case "broken":
+				return nil, errors.New("boom")
+			}
+			return nil, nil
+		},
+	})
+	getRobots := func(h Hwy, path string) string {
+		routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, block := range *routeData.MetaHeadBlocks {
+			if block.Attributes["name"] == "robots" {
+				return block.Attributes["content"]
+			}
+		}
+		return ""
+	}
+
+	for path, expected := range map[string]string{
+		"/lion/fine":    "",
+		"/lion/missing": "noindex",
+		"/lion/broken":  "noindex",
+	} {
+		if got := getRobots(Hwy{}, path); got != expected {
+			t.Errorf("%s: expected robots %q, got %q", path, expected, got)
+		}
+	}
+
+	preview := Hwy{Noindex: &Noindex{
+		Statuses:  []int{},
+		IsPreview: func(r *http.Request) bool { return r.Host == "preview.example.com" },
+		Content:   "noindex, nofollow",
+	}}
+	if got := getRobots(preview, "/lion/missing"); got != "" {
+		t.Errorf("expected no robots tag with no statuses, got %q", got)
+	}
+	r := httptest.NewRequest("GET", "/lion/fine", nil)
+	r.Host = "preview.example.com"
+	routeData, err := preview.GetRouteData(httptest.NewRecorder(), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, block := range *routeData.MetaHeadBlocks {
+		found = found || (block.Attributes["name"] == "robots" && block.Attributes["content"] == "noindex, nofollow")
+	}
+	if !found {
+		t.Error("expected preview pages to get the configured robots tag")
+	}
+}
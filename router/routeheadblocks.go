@@ -0,0 +1,44 @@
+package router
+
+import "net/http"
+
+// getRouteHeadBlocks returns the Hwy.RouteHeadBlocks of every matched route,
+// outermost first. Stylesheet links get the request's CSP nonce (scripts get
+// theirs from GetHeadElements), and blocks with an integrity attribute
+// default to crossorigin="anonymous", as SRI requires.
+func (h Hwy) getRouteHeadBlocks(r *http.Request, activePathData *ActivePathData) []HeadBlock {
+	if len(h.RouteHeadBlocks) == 0 || activePathData.MatchingPaths == nil {
+		return nil
+	}
+	nonce := GetCSPNonce(r)
+	var headBlocks []HeadBlock
+	for _, path := range *activePathData.MatchingPaths {
+		for _, block := range h.RouteHeadBlocks[path.Pattern] {
+			headBlocks = append(headBlocks, getRouteHeadBlock(block, nonce))
+		}
+	}
+	return headBlocks
+}
+
+func getRouteHeadBlock(block HeadBlock, nonce string) HeadBlock {
+	isStylesheet := block.Tag == "link" && block.Attributes["rel"] == "stylesheet"
+	_, hasIntegrity := block.Attributes["integrity"]
+	_, hasCrossOrigin := block.Attributes["crossorigin"]
+	addNonce := isStylesheet && nonce != ""
+	addCrossOrigin := hasIntegrity && !hasCrossOrigin
+	if !addNonce && !addCrossOrigin {
+		return block
+	}
+	attributes := make(map[string]string, len(block.Attributes)+2)
+	for key, value := range block.Attributes {
+		attributes[key] = value
+	}
+	if addNonce {
+		attributes["nonce"] = nonce
+	}
+	if addCrossOrigin {
+		attributes["crossorigin"] = "anonymous"
+	}
+	block.Attributes = attributes
+	return block
+}
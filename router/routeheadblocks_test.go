@@ -0,0 +1,45 @@
+package router
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouteHeadBlocks(t *testing.T) {
+	h := Hwy{RouteHeadBlocks: map[string][]HeadBlock{
+		"/dashboard": {
+			{Tag: "script", Attributes: map[string]string{"src": "https://widget.example/w.js", "integrity": "sha384-abc"}},
+			{Tag: "link", Attributes: map[string]string{"rel": "stylesheet", "href": "https://widget.example/w.css"}},
+		},
+	}}
+	getBlocks := func(path string) []*HeadBlock {
+		r := httptest.NewRequest("GET", path, nil)
+		r = r.WithContext(context.WithValue(r.Context(), cspNonceKey{}, "n0nce"))
+		routeData, err := h.GetRouteData(httptest.NewRecorder(), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var blocks []*HeadBlock
+		for _, block := range *routeData.RestHeadBlocks {
+			if block.Tag == "script" || block.Tag == "link" {
+				blocks = append(blocks, block)
+			}
+		}
+		return blocks
+	}
+
+	blocks := getBlocks("/dashboard/customers")
+	if len(blocks) != 2 {
+		t.Fatalf("expected the dashboard's blocks on its child route, got %v", blocks)
+	}
+	if blocks[0].Attributes["crossorigin"] != "anonymous" || blocks[0].Attributes["nonce"] != "" {
+		t.Errorf("expected the script to get crossorigin (and its nonce only when rendered), got %v", blocks[0].Attributes)
+	}
+	if blocks[1].Attributes["nonce"] != "n0nce" {
+		t.Errorf("expected the stylesheet to get the nonce, got %v", blocks[1].Attributes)
+	}
+	if blocks := getBlocks("/lion"); len(blocks) != 0 {
+		t.Errorf("expected no blocks on other routes, got %v", blocks)
+	}
+}
@@ -1,8 +1,10 @@
 package router
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"html/template"
 	"io/fs"
 	"net/http"
@@ -10,6 +12,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 type SegmentObj struct {
@@ -24,13 +27,26 @@ var PathTypeDynamicLayout = "dynamic-layout"
 var PathTypeNonUltimateSplat = "non-ultimate-splat"
 
 type Path struct {
-	Pattern   string     `json:"pattern"`
-	Segments  *[]string  `json:"segments"`
-	PathType  string     `json:"pathType"`
-	OutPath   string     `json:"outPath"`
-	SrcPath   string     `json:"srcPath"`
-	Deps      *[]string  `json:"deps"`
-	DataFuncs *DataFuncs `json:",omitempty"`
+	Pattern   string         `json:"pattern"`
+	Segments  *[]string      `json:"segments"`
+	PathType  string         `json:"pathType"`
+	OutPath   string         `json:"outPath"`
+	SrcPath   string         `json:"srcPath"`
+	Deps      *[]string      `json:"deps"`
+	DataFuncs *DataFuncs     `json:",omitempty"`
+	Handle    map[string]any `json:"handle,omitempty"`
+	Static    bool           `json:"static,omitempty"`
+	Islands   []Island       `json:"islands,omitempty"`
+	// The stylesheet esbuild emitted for this route's imports, if any
+	CSSBundle   string `json:"cssBundle,omitempty"`
+	CriticalCSS string `json:"criticalCSS,omitempty"`
+	// Page files for variants of DataFuncs.Experiment, by variant
+	Variants map[string]*PathVariant `json:"variants,omitempty"`
+	// Name of the secondary client entry (see BuildOptions.ClientEntries)
+	// this route loads, or "" for BuildOptions.ClientEntry
+	ClientEntry string `json:"clientEntry,omitempty"`
+
+	compiled *CompiledPattern
 }
 
 type JSONSafePath struct {
@@ -40,6 +56,20 @@ type JSONSafePath struct {
 	OutPath  string    `json:"outPath"`
 	SrcPath  string    `json:"srcPath"`
 	Deps     *[]string `json:"deps"`
+
+	Handle  map[string]any `json:"handle,omitempty"`
+	Static  bool           `json:"static,omitempty"`
+	Islands []Island       `json:"islands,omitempty"`
+
+	CSSBundle   string `json:"cssBundle,omitempty"`
+	CriticalCSS string `json:"criticalCSS,omitempty"`
+
+	Variants map[string]*PathVariant `json:"variants,omitempty"`
+
+	ClientEntry string `json:"clientEntry,omitempty"`
+
+	// Set for variant page files until they're merged into their route's
+	variant string
 }
 
 type HeadBlock struct {
@@ -53,24 +83,27 @@ type Action func(*ActionProps) (any, error)
 type Head func(*HeadProps) (*[]HeadBlock, error)
 
 type LoaderProps struct {
-	Request       *http.Request
-	Params        *map[string]string
-	SplatSegments *[]string
+	DataProps
+
+	// Only populated when using LoaderStrategySequential.
+	// Contains the data returned by each ancestor loader, outermost first.
+	ParentLoadersData *[]any
+
+	// The Handle of the loader's own route
+	Handle map[string]any
 }
 
 type ActionProps struct {
-	Request        *http.Request
-	Params         *map[string]string
-	SplatSegments  *[]string
+	DataProps
 	ResponseWriter http.ResponseWriter
 }
 
 type HeadProps struct {
-	Request       *http.Request
-	Params        *map[string]string
-	SplatSegments *[]string
-	LoaderData    any
-	ActionData    any
+	DataProps
+	LoaderData any
+	ActionData any
+
+	canonicalPath string
 }
 
 type DataFuncs struct {
@@ -78,6 +111,58 @@ type DataFuncs struct {
 	Action      Action
 	Head        Head
 	HandlerFunc http.HandlerFunc
+	Guard       Guard // also applies to all child routes
+	// Response headers, composed with the parent routes' (see HeadersFunc)
+	Headers HeadersFunc
+
+	// Static route metadata (e.g. a breadcrumb label or nav section), written
+	// to the paths file at build time and sent to the client per matched route
+	Handle map[string]any
+	// Takes precedence over a "breadcrumb" label in Handle
+	Breadcrumb BreadcrumbFunc
+	// Renders this route's HTML for fragment (e.g. HTMX) requests
+	Fragment FragmentFunc
+	// Serves Server-Sent Events to requests accepting "text/event-stream"
+	EventStream EventStream
+	// Serves WebSocket upgrade requests
+	WebSocket WebSocketHandler
+	// Server-renders this route's components (nested in its parents') to HTML
+	// on document requests, via Hwy.ComponentRenderer
+	SSRComponents bool
+	// Marks this route as static: its module and chunks aren't sent to the
+	// client (its import URL is ""), so its markup must come from
+	// SSRComponents. Read at build time.
+	Static bool
+	// Selectors whose rules, from this route's CSS, are inlined in a style
+	// block on document requests, with the full stylesheets then deferred
+	// (see extractCriticalCSS for matching). Read at build time.
+	CriticalCSS []string
+	// Source files of interactive components on this route, each built as
+	// its own entry point and hydrated independently. Paths take the same form
+	// as BuildOptions.ClientEntry. Read at build time.
+	Islands []string
+	// Fonts, hero images, etc. to preload whenever this route matches
+	Preloads []Preload
+	// Evaluated per request whenever this route would match. Returning false
+	// (e.g. for a dark-launched page behind a feature flag) removes the route
+	// from matching, so the request falls through to its siblings or catch
+	// routes as if it (and its child routes) didn't exist.
+	Enabled func(*DataProps) bool
+	// Name of the experiment (see Hwy.Experiments) whose assigned variant
+	// picks this route's page file, e.g. "checkout~b.ui.tsx" for variant "b"
+	Experiment string
+	// Optional, for loaders of flaky upstreams, serving fallback data while
+	// they fail. Share it between routes to trip them together.
+	CircuitBreaker *CircuitBreaker
+	// Optional, serving the loader's last successful data when it fails
+	StaleOnError *StaleOnError
+	// Bytes of serialized loader data above which a warning is logged (see
+	// Hwy.PayloadBudget). Negative disables the budget for this route.
+	PayloadBudget int
+	// Name of the secondary client entry (see BuildOptions.ClientEntries)
+	// this route loads, overriding the one picked by subtree. Read at build
+	// time.
+	ClientEntry string
 
 	// Used in TypeScript generation
 	LoaderOutput any
@@ -95,16 +180,21 @@ type ActivePathData struct {
 	SplatSegments               *[]string
 	Params                      *map[string]string
 	Deps                        *[]string
-}
+	Status                      int
+	ErrorRenderPlan             *ErrorRenderPlan // nil unless a loader or action failed
+	RawResponse                 *RawResponse     // set when a loader returned one, leaving the rest unset
 
-type matcherOutput struct {
-	matches            bool
-	params             *map[string]string
-	score              int
-	realSegmentsLength int
+	// Set when a loader returned PermanentRedirect, leaving the rest unset
+	redirectOutcome *GuardOutcome
+
+	scope *requestScope
 }
 
 type GroupedBySegmentLength map[int]*[]*MatchingPath
+
+// DataFuncsMap binds data funcs to routes by pattern (e.g. "/tiger/$id"),
+// which is preferred, or by page file, relative to the pages directory (e.g.
+// "tiger/$id.ui.tsx"), for keys not starting with "/"
 type DataFuncsMap = map[string]DataFuncs
 
 type MatchStrength struct {
@@ -113,6 +203,7 @@ type MatchStrength struct {
 }
 
 type MatchingPath struct {
+	Pattern            string
 	Score              int
 	RealSegmentsLength int
 	Segments           *[]string
@@ -121,19 +212,45 @@ type MatchingPath struct {
 	OutPath            string
 	Params             *map[string]string
 	Deps               *[]string
+	Handle             map[string]any
+	Static             bool
+	Islands            []Island
+	CSSBundle          string
+	CriticalCSS        string
+	Variants           map[string]*PathVariant
+	ClientEntry        string
+
+	midSplat  *midSplat
+	pathIndex int // into the paths it was matched from
 }
 
 type DecoratedPath struct {
-	DataFuncs *DataFuncs
-	PathType  string // technically only needed for testing
+	DataFuncs   *DataFuncs
+	PathType    string // technically only needed for testing
+	Pattern     string
+	Handle      map[string]any
+	Static      bool
+	Islands     []Island
+	CSSBundle   string
+	CriticalCSS string
+	ClientEntry string
 }
 
 type gmpdItem struct {
 	SplatSegments               *[]string
 	Params                      *map[string]string
+	MatchingPaths               *[]*MatchingPath
 	FullyDecoratedMatchingPaths *[]*DecoratedPath
 	ImportURLs                  *[]string
 	Deps                        *[]string
+
+	// Fallback items (not found, error) are built per request, are never
+	// cached, and never run actions
+	IsFallback bool
+	Status     int
+
+	// Matched routes with variant page files for DataFuncs.Experiment
+	variantPaths []*MatchingPath
 }
 
 type GetRouteDataOutput struct {
@@ -149,24 +266,283 @@ type GetRouteDataOutput struct {
 	AdHocData                   *map[string]*any   `json:"adHocData"`
 	BuildID                     string             `json:"buildID"`
 	Deps                        *[]string          `json:"deps"`
+	GuardOutcome                *GuardOutcome      `json:"guardOutcome,omitempty"` // set when a guard or redirect rule short-circuits
+	RawResponse                 *RawResponse       `json:"-"`                      // set when a loader returned one
+	Status                      int                `json:"-"`                      // 0 means 200
+	HTMLAttributes              map[string]string  `json:"htmlAttributes,omitempty"`
+	BodyAttributes              map[string]string  `json:"bodyAttributes,omitempty"`
+	Pattern                     string             `json:"pattern"`           // of the deepest matched route
+	Patterns                    *[]string          `json:"patterns"`          // parallel to ImportURLs
+	PathTypes                   *[]string          `json:"pathTypes"`         // parallel to ImportURLs
+	Handles                     *[]map[string]any  `json:"handles"`           // parallel to ImportURLs
+	Islands                     map[string]string  `json:"islands,omitempty"` // island name to import URL
+	Breadcrumbs                 []Breadcrumb       `json:"-"`                 // also sent to the client in AdHocData
+	CSSBundles                  []string           `json:"cssBundles,omitempty"`
+	Integrity                   map[string]string  `json:"integrity,omitempty"` // SRI hashes of Deps and CSSBundles
+	AssetBasePrefix             string             `json:"assetBasePrefix,omitempty"`
+	DevError                    *DevError          `json:"devError,omitempty"` // only when Hwy.IsDev
+	Protocol                    int                `json:"protocol,omitempty"` // see ProtocolHeader
+	// Set when a loader failed and its last successful data was served in
+	// its place (see DataFuncs.StaleOnError), e.g. to show a warning
+	IsStale       bool     `json:"isStale,omitempty"`
+	StalePatterns []string `json:"stalePatterns,omitempty"` // the routes served stale data
+	// Name of the secondary client entry the matched route loads, if any.
+	// Clients should do a full page load when navigating to a route with a
+	// different one.
+	ClientEntry string `json:"clientEntry,omitempty"`
+
+	permittedHeadTags []string
+	activePathData    *ActivePathData
+	isLegacy          bool // see Hwy.IsLegacyBrowser
+	nonce             string
+	encoder           Encoder // nil means GetSSRInnerHTML uses html/template's own encoding
 }
 
 var instancePaths *[]Path
 var instanceClientEntryDeps *[]string
 var instanceBuildID string
 
+type LoaderStrategy string
+
+const (
+	// Runs all matched loaders concurrently (default)
+	LoaderStrategyParallel LoaderStrategy = "parallel"
+	// Runs matched loaders one at a time, parent to child, passing
+	// parent loader results into each child's LoaderProps
+	LoaderStrategySequential LoaderStrategy = "sequential"
+	// Runs matched loaders concurrently, but never more than
+	// Hwy.MaxConcurrentLoaders at once
+	LoaderStrategyBounded LoaderStrategy = "bounded"
+)
+
 type Hwy struct {
 	DefaultHeadBlocks    []HeadBlock
 	FS                   fs.FS
 	DataFuncsMap         DataFuncsMap
 	RootTemplateLocation string
 	RootTemplateData     map[string]any
+	// Renders the document in place of the template at RootTemplateLocation
+	RootRenderer RootRenderer
+	// Renders component markup for routes with DataFuncs.SSRComponents
+	ComponentRenderer ComponentRenderer
+	// Serves the service worker written by BuildOptions.ServiceWorker at
+	// ServiceWorkerPath, and registers it on every page
+	ServiceWorker bool
+	// Preloaded on every page (e.g. fonts from the public dir), ahead of the
+	// matched routes' DataFuncs.Preloads
+	Preloads []Preload
+
+	// Replaces HwyPrefix in the client's globalThis Symbol key and the JSON
+	// request query param (e.g. so two apps can share a document). Must
+	// match BuildOptions.Prefix. Process-wide, like Clock.
+	Prefix string
+
+	// Makes paths matching no route unless lowercased (e.g. "/About") 308
+	// redirect to their canonical case, taken from the matched pattern's
+	// static segments (so patterns should be lowercase). Param and splat
+	// values keep their original case.
+	CaseInsensitive bool
+
+	// Optional, checked after matching and before guards, rejecting requests
+	// with a 429 (as a GuardOutcome)
+	RateLimit *RateLimit
+
+	// Optional, for caching match results of paths matching no route apart
+	// from the rest
+	NegativeCache *NegativeCachePolicy
+
+	// Defaults to the system clock (see Clock). Like everything set up by
+	// Initialize, it's shared by the whole process (see With).
+	Clock Clock
+	// Overrides the paths file's build ID (sent to clients, and in asset
+	// URLs), e.g. with a constant for snapshot tests of SSR output.
+	// ExpectedBuildID is still checked against the paths file's.
+	BuildIDFunc func() string
+
+	// Shows errors (with stack traces and source snippets) in responses
+	IsDev bool
+	// Makes Initialize populate the route matcher cache for every route whose
+	// pattern has no params or splats (for each tenant too), so the first
+	// requests after a deploy skip matching
+	WarmMatcherCache bool
+	// Makes Initialize also run those routes' loaders once, as prefetches
+	// (see DataProps.IsPrefetch), warming any caches the loaders use.
+	// Errors are logged.
+	WarmLoaders bool
+
+	// Makes Initialize also report incomplete data funcs: layout routes
+	// without a loader (ErrMissingLoader), actions without ActionInput and
+	// ActionOutput samples (ErrMissingActionTypes), and heads returning tags
+	// that aren't permitted (ErrDisallowedHeadTag). Heads are called with
+	// placeholder props, so those needing real loader data aren't checked.
+	Strict bool
+	// When set, Initialize reports ErrStaleBuild if the paths file is from a
+	// different build (e.g. a build ID embedded in the binary at build time)
+	ExpectedBuildID string
+
+	// Defaults to LoaderStrategyParallel
+	LoaderStrategy LoaderStrategy
+	// Only used with LoaderStrategyBounded (minimum 1)
+	MaxConcurrentLoaders int
+	// Marshals JSON responses and SSR route data. Defaults to encoding/json.
+	Encoder Encoder
+	// Shapes the keys of JSON responses. Nil sends every field as is.
+	Serialization *Serialization
+	// Default DataFuncs.PayloadBudget, in bytes. Zero sets no budget.
+	PayloadBudget int
+	// Returns the AES key (16, 24, or 32 bytes, e.g. one held by the
+	// session) with which loader data fields tagged `hwy:"encrypted"` are
+	// sent to the client (see clientPayloadFields)
+	PayloadKey func(*http.Request) ([]byte, error)
+
+	// Run in order over each successful loader's data before it's sent to
+	// the client (e.g. RedactServerOnly)
+	LoaderDataTransforms []LoaderDataTransform
+
+	// Defaults to DefaultLogger
+	Logger Logger
+
+	// When set, spans are emitted for matching, guards, loaders, actions,
+	// heads, serialization, and DataProps.Fetch
+	TracerProvider TracerProvider
+	// Configures DataProps.Fetch. Nil uses http.DefaultClient.
+	Fetcher *Fetcher
+
+	// When set, request, loader, action, and matcher cache metrics are
+	// reported to it
+	Metrics MetricsCollector
+
+	// Called for every guard, loader, action, and head error (NotFound
+	// aside), including recovered panics
+	OnError func(*ErrorReport)
+
+	// When set, GetRootHandler logs every request to it (method, matched route
+	// pattern, status, duration, bytes written, and build ID)
+	AccessLogger Logger
+
+	// Requests carrying this header get only the HTML of the outermost changed
+	// route (see DataFuncs.Fragment). Defaults to "HX-Request".
+	FragmentHeader string
+
+	// How often idle event streams get a keep-alive comment. Defaults to 15s.
+	EventStreamKeepAlive time.Duration
+
+	// Decides whether to accept a WebSocket upgrade. Defaults to allowing
+	// requests without an Origin header or from the request's own host.
+	WebSocketCheckOrigin func(*http.Request) bool
+
+	// Cache-Control for successful JSON responses to prefetch requests (e.g.
+	// "private, max-age=10"), so the following navigation can reuse them
+	PrefetchCacheControl string
+
+	// Oldest JSON protocol version (see ProtocolHeader) still answered, so
+	// SPAs deployed before an upgrade keep working during rollouts. Requests
+	// for older or newer versions get a 400. Defaults to 1.
+	MinProtocolVersion int
+
+	// Hosts the root handler serves, answering 400 to anything else. Entries
+	// ignore the port unless they include one, and a leading dot (".example.com")
+	// also matches subdomains. Empty allows any host. Absolute guard redirects
+	// may also target these hosts.
+	AllowedHosts []string
+
+	// Security headers (HSTS, CSP with a per-request nonce, etc.) for the
+	// root handler. Nil sets none.
+	SecurityHeaders *SecurityHeaders
+
+	// Compresses (gzip, plus any extra Compressors) HTML and JSON responses.
+	// Nil compresses nothing.
+	Compression *Compression
+
+	// Serves ImportURLs and deps (including modulepreload links) from
+	// elsewhere, e.g. "https://cdn.example.com/builds/{buildID}/". Chunks
+	// import each other relatively, so lazily loaded chunks follow along.
+	AssetBasePrefix string
+	// The built assets (BuildOptions.HashedOutDir), for Initialize to check
+	// that every route module, dep, and stylesheet in the paths file exists
+	// (and matches its hash, with BuildOptions.Integrity), reporting
+	// ErrMissingAsset and ErrAssetIntegrity rather than failing chunk loads
+	// at runtime. Nil skips the check.
+	AssetsFS fs.FS
+	// Paths file contents read in place of hwy_paths.json from FS, e.g. the
+	// EmbeddedPathsFileConst written by BuildOptions.PathsGoFile, so the
+	// binary can't drift from the build it was compiled with
+	EmbeddedPathsFile string
+	// Picks out requests (e.g. by User-Agent, for old WebViews) served the
+	// legacy build (see BuildOptions.LegacyTargets), if there is one.
+	// Responses vary by whatever it reads, so set Vary accordingly.
+	IsLegacyBrowser func(*http.Request) bool
+
+	// App-wide services, shared by every request
+	Services *Services
+	// Optional, adds request-scoped services to a per-request copy of Services
+	ServicesHook ServicesHook
+	// Optional, its user is available to data funcs as DataProps.User and to
+	// the client as the "user" AdHocData, so return only what the client may
+	// see (or a struct with the rest in fields tagged json:"-")
+	ResolveUser UserResolver
+	// Where RequireUser redirects signed-out requests. Defaults to "/login".
+	LoginRoute string
+
+	// Optional per-tenant route overrides, selected per request
+	Tenants *Tenants
+	// Optional A/B experiments, assigned per request
+	Experiments *Experiments
+	// Optional client hints and device class detection, in DataProps
+	ClientHints *ClientHintsConfig
+
+	// Optional locale-prefixed routing (e.g. "/de/about" matches "/about")
+	I18n *I18nConfig
+	// Used to build absolute canonical and hreflang URLs (e.g. "https://example.com")
+	SiteOrigin string
+	// Defaults to DefaultHeadDedupeKeys
+	HeadDedupeKeys []HeadDedupeKey
+	// Named sets of head blocks added after DefaultHeadBlocks (e.g.
+	// "production" with analytics scripts, "staging" with a noindex meta), by
+	// HeadProfile or per request by SelectHeadProfile
+	HeadProfiles map[string][]HeadBlock
+	// The profile used unless SelectHeadProfile picks another. Initialize
+	// reports ErrUnknownHeadProfile if it isn't in HeadProfiles.
+	HeadProfile string
+	// Returns the profile for r, or "" for HeadProfile
+	SelectHeadProfile func(r *http.Request) string
+	// Head blocks (e.g. a third-party widget's script or stylesheet) added
+	// whenever the route with the keyed pattern matches, including as a
+	// parent. They go through deduping ahead of the routes' Head funcs.
+	RouteHeadBlocks map[string][]HeadBlock
+	// Added to the built-in list of tags Head functions may emit
+	// ("meta", "base", "link", "style", "script", "noscript")
+	ExtraPermittedHeadTags []string
+	// Which pages get a noindex robots meta tag. Nil adds it to pages with
+	// DefaultNoindexStatuses.
+	Noindex *Noindex
+
+	// Evaluated in order, before any route matching (redirects first)
+	Redirects []RedirectRule
+	Rewrites  []RewriteRule
+
+	// Optional pattern of the route to render (with a 404) when nothing but the
+	// ultimate catch route matches, or when a loader returns NotFound() and no
+	// closer splat route exists
+	NotFoundRoute string
+	// Optional pattern of the route to render (with a 500) when an error occurs
+	// outside of any error boundary
+	ErrorRoute string
+
+	// Records data func calls, e.g. for integration tests
+	InvocationRecorder *InvocationRecorder
+
+	// Set on clones (see With), by pattern
+	dataFuncsOverrides DataFuncsMap
+	isClone            bool
 }
 
 type SortHeadBlocksOutput struct {
 	title          string
 	metaHeadBlocks *[]*HeadBlock
 	restHeadBlocks *[]*HeadBlock
+	htmlAttributes map[string]string
+	bodyAttributes map[string]string
 }
 
 type SSRInnerHTMLInput struct {
@@ -181,22 +557,49 @@ type SSRInnerHTMLInput struct {
 	ActionData                  *[]any
 	AdHocData                   any
 	Deps                        *[]string
+	Pattern                     string
+	Patterns                    *[]string
+	PathTypes                   *[]string
+	Handles                     *[]map[string]any
+	Nonce                       string
+	Integrity                   map[string]string
+	AssetBasePrefix             string
+	// Pre-encoded by a custom Encoder, used in place of the fields above
+	LoadersDataJS template.JS
+	ActionDataJS  template.JS
+	AdHocDataJS   template.JS
 }
 
-func getInitialMatchingPaths(pathToUse string) *[]MatchingPath {
+func getInitialMatchingPaths(paths *[]Path, pathToUse string) *[]MatchingPath {
 	var initialMatchingPaths []MatchingPath
-	for _, path := range *instancePaths {
-		matcherOutput := matcher(path.Pattern, pathToUse)
-		if matcherOutput.matches {
+	split := splitPath(pathToUse)
+	for i, path := range *paths {
+		compiled := path.compiled
+		if compiled == nil {
+			compiled = CompilePattern(path.Pattern)
+		}
+		result := compiled.matchSplitPath(split)
+		if result.Matches {
+			params := result.Params // so only matches escape to the heap
 			initialMatchingPaths = append(initialMatchingPaths, MatchingPath{
-				Score:              matcherOutput.score,
-				RealSegmentsLength: matcherOutput.realSegmentsLength,
+				Pattern:            path.Pattern,
+				Score:              result.Score,
+				RealSegmentsLength: result.RealSegmentsLength,
 				PathType:           path.PathType,
 				OutPath:            path.OutPath,
 				Segments:           path.Segments,
 				DataFuncs:          path.DataFuncs,
-				Params:             matcherOutput.params,
+				Params:             &params,
 				Deps:               path.Deps,
+				Handle:             path.Handle,
+				Static:             path.Static,
+				Islands:            path.Islands,
+				CSSBundle:          path.CSSBundle,
+				CriticalCSS:        path.CriticalCSS,
+				Variants:           path.Variants,
+				ClientEntry:        path.ClientEntry,
+				midSplat:           result.midSplat,
+				pathIndex:          i,
 			})
 		}
 	}
@@ -207,38 +610,51 @@ func decoratePaths(paths *[]*MatchingPath) *[]*DecoratedPath {
 	decoratedPaths := make([]*DecoratedPath, 0, len(*paths))
 	for _, path := range *paths {
 		decoratedPaths = append(decoratedPaths, &DecoratedPath{
-			DataFuncs: path.DataFuncs,
-			PathType:  path.PathType,
+			DataFuncs:   path.DataFuncs,
+			PathType:    path.PathType,
+			Pattern:     path.Pattern,
+			Handle:      path.Handle,
+			Static:      path.Static,
+			Islands:     path.Islands,
+			CSSBundle:   path.CSSBundle,
+			CriticalCSS: path.CriticalCSS,
+			ClientEntry: path.ClientEntry,
 		})
 	}
 	return &decoratedPaths
 }
 
-func getMatchStrength(pattern string, path string) MatchStrength {
-	var patternSegments []string
-	for _, segment := range strings.Split(pattern, "/") {
-		if len(segment) > 0 {
-			patternSegments = append(patternSegments, segment)
-		}
-	}
-	var realSegments []string
-	for _, segment := range strings.Split(path, "/") {
-		if len(segment) > 0 {
-			realSegments = append(realSegments, segment)
-		}
-	}
+// Per-segment match scores. A compound segment (e.g. "$slug-$id") is more
+// specific than a plain dynamic one, but less than a static one.
+const (
+	scoreStatic   = 6
+	scoreCompound = 5
+	scoreDynamic  = 4
+	scoreSplat    = 2
+)
+
+// getMatchStrength takes the non-empty segments of the pattern and path, and
+// the pattern's compound segments (see getCompoundSegments)
+func getMatchStrength(patternSegments []string, realSegments []string, compound []*compoundSegment) MatchStrength {
 	score := 0
 	for i := 0; i < len(patternSegments); i++ {
 		if len(realSegments) >= len(patternSegments) && patternSegments[i] == realSegments[i] {
-			score += 3
+			score += scoreStatic
 			continue
 		}
+		if compound != nil && compound[i] != nil {
+			if i < len(realSegments) && compound[i].matches(realSegments[i]) {
+				score += scoreCompound
+				continue
+			}
+			break
+		}
 		if patternSegments[i] == "$" {
-			score += 1
+			score += scoreSplat
 			continue
 		}
 		if strings.HasPrefix(patternSegments[i], "$") {
-			score += 2
+			score += scoreDynamic
 			continue
 		}
 		break
@@ -247,7 +663,14 @@ func getMatchStrength(pattern string, path string) MatchStrength {
 }
 
 func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]string, *[]*MatchingPath) {
+	return explainMatchingPaths(pathsArg, realPath, nil)
+}
+
+// explainMatchingPaths is getMatchingPathsInternal, optionally recording why
+// each eliminated candidate was eliminated (explainer may be nil)
+func explainMatchingPaths(pathsArg *[]MatchingPath, realPath string, explainer *matchExplainer) (*[]string, *[]*MatchingPath) {
 	var paths []*MatchingPath
+	pathSegments := splitPath(realPath).truthySegments
 	for _, x := range *pathsArg {
 		// if it's dash route (home), no need to compare segments length
 		if x.RealSegmentsLength == 0 {
@@ -265,6 +688,7 @@ func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]str
 		// make sure any remaining matches are not longer than the path itself
 		shouldMoveOn := len(*x.Segments) <= indexAdjustedRealSegmentsLength
 		if !shouldMoveOn {
+			explainer.eliminate(&x, "route has more segments than the path")
 			continue
 		}
 
@@ -275,20 +699,10 @@ func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]str
 			continue
 		}
 
-		var truthySegments []string
-		for _, segment := range *x.Segments {
-			if len(segment) > 0 {
-				truthySegments = append(truthySegments, segment)
-			}
-		}
-		var pathSegments []string
-		for _, segment := range strings.Split(realPath, "/") {
-			if len(segment) > 0 {
-				pathSegments = append(pathSegments, segment)
-			}
-		}
-		if len(truthySegments) == len(pathSegments) {
+		if len(getTruthySegments(*x.Segments)) == len(pathSegments) {
 			paths = append(paths, &x)
+		} else {
+			explainer.eliminate(&x, "index route segment count differs from the path's")
 		}
 	}
 
@@ -298,6 +712,8 @@ func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]str
 		for _, x := range paths {
 			if x.PathType != PathTypeUltimateCatch {
 				nonUltimateCatchPaths = append(nonUltimateCatchPaths, x)
+			} else {
+				explainer.eliminate(x, "ultimate catch-all is superseded by other matches")
 			}
 		}
 		paths = nonUltimateCatchPaths
@@ -310,6 +726,9 @@ func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]str
 		if (paths)[0].PathType == PathTypeUltimateCatch {
 			splatSegments = getBaseSplatSegments(realPath)
 		}
+		if paths[0].midSplat != nil {
+			splatSegments = getSplatSegmentsFromWinningPath(paths[0], realPath)
+		}
 		return splatSegments, &paths
 	}
 
@@ -322,6 +741,21 @@ func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]str
 			definiteMatches = append(definiteMatches, x)
 		}
 	}
+	// a mid-path splat layout only stands if no other static layout of its
+	// length outscores it
+	candidates := slices.Clone(definiteMatches)
+	definiteMatches = slices.DeleteFunc(definiteMatches, func(x *MatchingPath) bool {
+		if x.midSplat == nil {
+			return false
+		}
+		for _, other := range candidates {
+			if other != x && len(*other.Segments) == len(*x.Segments) && other.Score > x.Score {
+				explainer.eliminate(x, fmt.Sprintf("mid-path splat is outscored by %s", other.Pattern))
+				return true
+			}
+		}
+		return false
+	})
 
 	highestScoresBySegmentLengthOfDefiniteMatches := getHighestScoresBySegmentLength(&definiteMatches)
 
@@ -341,6 +775,8 @@ func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]str
 					groupedBySegmentLength[segmentLength] = &[]*MatchingPath{}
 				}
 				*groupedBySegmentLength[segmentLength] = append(*groupedBySegmentLength[segmentLength], x)
+			} else {
+				explainer.eliminate(x, fmt.Sprintf("outscored by a static layout with %d segments", segmentLength))
 			}
 		}
 	}
@@ -350,32 +786,25 @@ func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]str
 	var xformedMaybes []*MatchingPath
 	var wildcardSplat *MatchingPath = nil
 	for _, paths := range *sortedGroupedBySegmentLength {
-		winner := (*paths)[0]
-		highestScore := winner.Score
-		var indexCandidate *MatchingPath = nil
+		ranked := RankRoutes(*paths)
+		winner := ranked[0]
 
-		for _, path := range *paths {
+		// an index route deeper than the path's own segments wins its group
+		for _, path := range ranked {
 			if path.PathType == PathTypeIndex && path.RealSegmentsLength < len(*path.Segments) {
-				if indexCandidate == nil {
-					indexCandidate = path
-				} else {
-					if path.Score > indexCandidate.Score {
-						indexCandidate = path
-					}
-				}
-			}
-			if path.Score > highestScore {
-				highestScore = path.Score
 				winner = path
+				break
 			}
 		}
 
-		if indexCandidate != nil {
-			winner = indexCandidate
+		for _, path := range *paths {
+			if path != winner {
+				explainer.eliminate(path, fmt.Sprintf("lost to %s among candidates with %d segments", winner.Pattern, len(*winner.Segments)))
+			}
 		}
 
 		// find non ultimate splat
-		splat := findNonUltimateSplat(paths)
+		splat := findNonUltimateSplat(&ranked)
 
 		if splat != nil {
 			if wildcardSplat == nil || splat.Score > wildcardSplat.Score {
@@ -413,6 +842,8 @@ func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]str
 
 		if !definiteMatchesShouldOverride {
 			xformedMaybes = append(xformedMaybes, winner)
+		} else {
+			explainer.eliminate(winner, "dynamic index is overridden by a higher-scoring static layout")
 		}
 	}
 
@@ -428,6 +859,9 @@ func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]str
 		} else {
 			lastPathSegmentsLengthConstructive = len(*lastPath.Segments)
 		}
+		if lastPath.midSplat != nil {
+			lastPathSegmentsLengthConstructive = lastPath.midSplat.consumed
+		}
 
 		splatIsTooFarOut := lastPathSegmentsLengthConstructive > lastPath.RealSegmentsLength
 		splatIsNeeded := lastPathSegmentsLengthConstructive < lastPath.RealSegmentsLength
@@ -436,10 +870,14 @@ func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]str
 
 		if weNeedADifferentSplat {
 			if wildcardSplat != nil {
+				explainer.eliminate(lastPath, fmt.Sprintf("replaced by splat %s, as its segments do not cover the path", wildcardSplat.Pattern))
 				(*maybeFinalPaths)[len(*maybeFinalPaths)-1] = wildcardSplat
 				splatSegments = getSplatSegmentsFromWinningPath(wildcardSplat, realPath)
 			} else {
 				splatSegments = getBaseSplatSegments(realPath)
+				for _, x := range *maybeFinalPaths {
+					explainer.eliminate(x, "no candidate covers the path, so the ultimate catch-all wins")
+				}
 				var filteredPaths []*MatchingPath
 				for _, x := range *pathsArg {
 					if x.PathType == PathTypeUltimateCatch {
@@ -466,11 +904,16 @@ func getMatchingPathsInternal(pathsArg *[]MatchingPath, realPath string) (*[]str
 			currentDynamicSegment := (*current.Segments)[len(*current.Segments)-1]
 			nextDynamicSegment := (*next.Segments)[len(*next.Segments)-2]
 			if currentDynamicSegment != nextDynamicSegment {
+				explainer.eliminate(current, "dynamic layout does not share the dynamic segment of the index after it")
 				*maybeFinalPaths = append((*maybeFinalPaths)[:i], (*maybeFinalPaths)[i+1:]...)
 			}
 		}
 	}
 
+	if n := len(*maybeFinalPaths); n > 0 && (*maybeFinalPaths)[n-1].midSplat != nil {
+		splatSegments = getSplatSegmentsFromWinningPath((*maybeFinalPaths)[n-1], realPath)
+	}
+
 	return splatSegments, maybeFinalPaths
 }
 
@@ -514,6 +957,15 @@ func getHighestScoresBySegmentLength(matches *[]*MatchingPath) map[int]int {
 func getSplatSegmentsFromWinningPath(winner *MatchingPath, realPath string) *[]string {
 	data := strings.Split(realPath, "/")
 
+	if winner.midSplat != nil {
+		middle := getTruthySegments(data)[winner.midSplat.start:winner.midSplat.end]
+		splatSegments := make([]string, 0, len(middle))
+		for _, segment := range middle {
+			splatSegments = append(splatSegments, decodeSegment(segment))
+		}
+		return &splatSegments
+	}
+
 	filteredData := []string{}
 	for _, segment := range data {
 		if segment != "" {
@@ -531,6 +983,9 @@ func getSplatSegmentsFromWinningPath(winner *MatchingPath, realPath string) *[]s
 	numOfSplatSegments := len(filteredData) - numOfNonSplatSegments
 	if numOfSplatSegments > 0 {
 		final := filteredData[len(filteredData)-numOfSplatSegments:]
+		for i, segment := range final {
+			final[i] = decodeSegment(segment)
+		}
 		return &final
 	} else {
 		return &[]string{}
@@ -541,7 +996,7 @@ func getWinnerIsDynamicIndex(winner *MatchingPath) bool {
 	segmentsLength := len(*winner.Segments)
 	if winner.PathType == PathTypeIndex && segmentsLength >= 2 {
 		secondToLastSegment := (*winner.Segments)[segmentsLength-2]
-		return strings.HasPrefix(secondToLastSegment, "$")
+		return strings.HasPrefix(secondToLastSegment, "$") || getIsCompoundSegment(secondToLastSegment)
 	}
 	return false
 }
@@ -558,44 +1013,161 @@ func getBaseSplatSegments(realPath string) *[]string {
 	var splatSegments []string
 	for _, segment := range strings.Split(realPath, "/") {
 		if len(segment) > 0 {
-			splatSegments = append(splatSegments, segment)
+			splatSegments = append(splatSegments, decodeSegment(segment))
 		}
 	}
 	return &splatSegments
 }
 
-var gmpdCache = NewLRUCache(500_000)
+const gmpdCacheSize = 500_000
+
+var gmpdCache = NewLRUCache(gmpdCacheSize)
 
-func getMatchingPathData(w http.ResponseWriter, r *http.Request) *ActivePathData {
-	realPath := r.URL.Path
+func getRealPath(r *http.Request) string {
+	realPath := getMatchPath(r.URL)
 	if realPath != "/" && realPath[len(realPath)-1] == '/' {
 		realPath = realPath[:len(realPath)-1]
 	}
+	return realPath
+}
+
+func getMatchingPathItem(r *http.Request) *gmpdItem {
+	item, _ := lookupMatchingPathItem(r, nil)
+	return item
+}
+
+// lookupMatchingPathItem also reports whether the item was served from cache.
+// When winning routes have DataFuncs.Enabled, their predicates are evaluated
+// (with scope, if not nil), and any disabled one is left out of matching
+// until the winners are all enabled. Routes with variant page files are
+// served from those assigned (per scope).
+func lookupMatchingPathItem(r *http.Request, scope *requestScope) (*gmpdItem, bool) {
+	realPath := getRealPath(r)
+	paths, tenant := getTenantPaths(r)
+	cacheKey := realPath
+	if tenant != "" {
+		cacheKey = tenant + "\x00" + realPath
+	}
+
+	item, ok := getOrMatchItem(paths, realPath, cacheKey, nil)
+	overrides := &matchOverrides{variants: getVariantSelection(scope, item.variantPaths)}
+	// Each pass leaves out a winner (and its children), so this terminates.
+	// Overridden items are cached per combination of overrides.
+	for {
+		pattern, isDisabled := getDisabledPattern(r, scope, paths, realPath, item)
+		if !isDisabled {
+			break
+		}
+		overrides.disabled = append(overrides.disabled, pattern)
+		item, ok = getOrMatchItem(paths, realPath, cacheKey+overrides.getCacheKeySuffix(), overrides)
+	}
+	if len(overrides.disabled) == 0 && len(overrides.variants) > 0 {
+		item, ok = getOrMatchItem(paths, realPath, cacheKey+overrides.getCacheKeySuffix(), overrides)
+	}
+	return item, ok
+}
+
+// matchOverrides adjust matching for a single request
+type matchOverrides struct {
+	// Patterns of routes left out of matching, along with their children
+	disabled []string
+	// Pattern to the variant whose page file to serve it from
+	variants map[string]string
+}
+
+func (o *matchOverrides) getCacheKeySuffix() string {
+	var b strings.Builder
+	for _, pattern := range o.disabled {
+		b.WriteString("\x00-" + pattern)
+	}
+	for _, pattern := range getSortedKeys(o.variants) {
+		b.WriteString("\x00~" + pattern + variantDelimiter + o.variants[pattern])
+	}
+	return b.String()
+}
 
-	cached, ok := gmpdCache.Get(realPath)
+// getOrMatchItem matches realPath against paths (as adjusted by overrides, if
+// not nil), unless cacheKey is cached
+func getOrMatchItem(paths *[]Path, realPath string, cacheKey string, overrides *matchOverrides) (*gmpdItem, bool) {
+	if cached, ok := gmpdCache.Get(cacheKey); ok {
+		return cached.(*gmpdItem), true
+	}
+	if cached, ok := getNegativeCacheItem(cacheKey); ok {
+		return cached, true
+	}
 	item := &gmpdItem{}
-	if ok {
-		item = cached.(*gmpdItem)
-	} else {
-		initialMatchingPaths := getInitialMatchingPaths(realPath)
-		splatSegments, matchingPaths := getMatchingPathsInternal(initialMatchingPaths, realPath)
-		importURLs := make([]string, 0, len(*matchingPaths))
-		item.ImportURLs = &importURLs
+	initialMatchingPaths := getInitialMatchingPaths(paths, realPath)
+	if overrides != nil {
+		// A disabled layout takes its child routes with it
+		*initialMatchingPaths = slices.DeleteFunc(*initialMatchingPaths, func(path MatchingPath) bool {
+			return slices.ContainsFunc(overrides.disabled, func(pattern string) bool {
+				return path.Pattern == pattern || strings.HasPrefix(path.Pattern, pattern+"/")
+			})
+		})
+		for i, path := range *initialMatchingPaths {
+			if variant, ok := overrides.variants[path.Pattern]; ok {
+				(*initialMatchingPaths)[i].applyVariant(variant)
+			}
+		}
+	}
+	splatSegments, matchingPaths := getMatchingPathsInternal(initialMatchingPaths, realPath)
+	if overrides == nil {
 		for _, path := range *matchingPaths {
-			importURLs = append(importURLs, "/"+path.OutPath)
+			if path.DataFuncs != nil && path.DataFuncs.Experiment != "" && len(path.Variants) > 0 {
+				item.variantPaths = append(item.variantPaths, path)
+			}
+		}
+	}
+	importURLs := make([]string, 0, len(*matchingPaths))
+	item.ImportURLs = &importURLs
+	for _, path := range *matchingPaths {
+		importURLs = append(importURLs, "/"+path.OutPath)
+	}
+	var lastPath = &MatchingPath{}
+	if len(*matchingPaths) > 0 {
+		lastPath = (*matchingPaths)[len(*matchingPaths)-1]
+	}
+	item.MatchingPaths = matchingPaths
+	item.FullyDecoratedMatchingPaths = decoratePaths(matchingPaths)
+	item.SplatSegments = splatSegments
+	item.Params = lastPath.Params
+	deps := GetDeps(matchingPaths)
+	item.Deps = &deps
+	setMatchedItem(cacheKey, realPath, item)
+	return item, false
+}
+
+// getDisabledPattern returns the pattern of the outermost of item's winning
+// routes whose Enabled predicate returns false for r. Data funcs are read
+// from paths rather than from the (possibly cached) item, so swapping a
+// route's DataFuncs takes effect immediately.
+func getDisabledPattern(r *http.Request, scope *requestScope, paths *[]Path, realPath string, item *gmpdItem) (string, bool) {
+	for _, path := range *item.MatchingPaths {
+		dataFuncs := getCurrentDataFuncs(paths, path)
+		if dataFuncs == nil || dataFuncs.Enabled == nil {
+			continue
+		}
+		props := scope.newDataProps(r, path.Params, getSplatSegmentsFromWinningPath(path, realPath))
+		if !dataFuncs.Enabled(&props) {
+			return path.Pattern, true
 		}
-		var lastPath = &MatchingPath{}
-		if len(*matchingPaths) > 0 {
-			lastPath = (*matchingPaths)[len(*matchingPaths)-1]
+	}
+	return "", false
+}
+
+func getCurrentDataFuncs(paths *[]Path, path *MatchingPath) *DataFuncs {
+	if path.pathIndex < len(*paths) && (*paths)[path.pathIndex].Pattern == path.Pattern {
+		return (*paths)[path.pathIndex].DataFuncs
+	}
+	for _, p := range *paths {
+		if p.Pattern == path.Pattern {
+			return p.DataFuncs
 		}
-		item.FullyDecoratedMatchingPaths = decoratePaths(matchingPaths)
-		item.SplatSegments = splatSegments
-		item.Params = lastPath.Params
-		deps := GetDeps(matchingPaths)
-		item.Deps = &deps
-		isSpam := len(*matchingPaths) == 0
-		gmpdCache.Set(realPath, item, isSpam)
 	}
+	return nil
+}
+
+func (h Hwy) getMatchingPathData(w http.ResponseWriter, r *http.Request, item *gmpdItem, scope *requestScope) *ActivePathData {
 
 	var lastPath = &DecoratedPath{}
 	if len(*item.FullyDecoratedMatchingPaths) > 0 {
@@ -604,38 +1176,50 @@ func getMatchingPathData(w http.ResponseWriter, r *http.Request) *ActivePathData
 
 	var actionData any
 	var actionDataError error
-	actionExists := lastPath.DataFuncs != nil && lastPath.DataFuncs.Action != nil
+	actionExists := lastPath.DataFuncs != nil && lastPath.DataFuncs.Action != nil && !item.IsFallback
 	_, shouldRunAction := acceptedMethods[r.Method]
 	if actionExists && shouldRunAction {
-		actionData, actionDataError = getActionData(
-			&lastPath.DataFuncs.Action,
-			&ActionProps{
-				Request:        r,
-				Params:         item.Params,
-				SplatSegments:  item.SplatSegments,
-				ResponseWriter: w,
-			},
-		)
-	}
-	loadersData := make([]any, len(*item.FullyDecoratedMatchingPaths))
-	errors := make([]error, len(*item.FullyDecoratedMatchingPaths))
-	var wg sync.WaitGroup
-	for i, path := range *item.FullyDecoratedMatchingPaths {
-		wg.Add(1)
-		go func(i int, dataFuncs *DataFuncs) {
-			defer wg.Done()
-			if dataFuncs == nil || dataFuncs.Loader == nil {
-				loadersData[i], errors[i] = nil, nil
-				return
-			}
-			loadersData[i], errors[i] = (dataFuncs.Loader)(&LoaderProps{
-				Request:       r,
-				Params:        item.Params,
-				SplatSegments: item.SplatSegments,
-			})
-		}(i, path.DataFuncs)
+		_, span := h.startSpan(r.Context(), SpanAction, getPathSpanAttributes(lastPath)...)
+		startTime := time.Now()
+		actionProps := &ActionProps{
+			DataProps:      scope.newDataProps(r, item.Params, item.SplatSegments),
+			ResponseWriter: w,
+		}
+		actionData, actionDataError = callDataFunc(h, r, lastPath.Pattern, ErrorPhaseAction, func() (any, error) {
+			return getActionData(&lastPath.DataFuncs.Action, actionProps)
+		})
+		endSpan(span, actionDataError)
+		h.recordInvocation(ErrorPhaseAction, lastPath.Pattern, &actionProps.DataProps, startTime, actionData, actionDataError)
+		if h.Metrics != nil {
+			h.Metrics.ObserveAction(lastPath.Pattern, time.Since(startTime), actionDataError)
+		}
+	}
+	loadersData, errors := h.runLoaders(r, item, scope)
+
+	if outcome := getPermanentRedirectOutcome(errors); outcome != nil && !item.IsFallback {
+		return &ActivePathData{
+			MatchingPaths:   item.FullyDecoratedMatchingPaths,
+			Params:          item.Params,
+			SplatSegments:   item.SplatSegments,
+			redirectOutcome: outcome,
+			scope:           scope,
+		}
+	}
+
+	var isGone bool
+	if i := getOutermostErrorIndex(errors); i != -1 && getIsNotFoundOrGone(errors[i]) {
+		isGone = IsGone(errors[i])
+		if item.IsFallback {
+			errors[i] = nil // don't loop
+		} else {
+			item, loadersData, errors = h.getNotFoundData(r, item, i, loadersData, scope)
+		}
+	}
+	h.transformLoadersData(r, item, loadersData, errors)
+	status := item.Status
+	if isGone {
+		status = http.StatusGone
 	}
-	wg.Wait()
 
 	// Response mutation needs to be in sync, with the last path being the most important
 	for _, path := range *item.FullyDecoratedMatchingPaths {
@@ -644,37 +1228,44 @@ func getMatchingPathData(w http.ResponseWriter, r *http.Request) *ActivePathData
 		}
 	}
 
-	var thereAreErrors bool
-	outermostErrorIndex := -1
-	for i, err := range errors {
+	for _, err := range errors {
 		if err != nil {
-			Log.Errorf("ERROR: %v", err)
-			thereAreErrors = true
-			outermostErrorIndex = i
+			h.getLogger().Error("loader error", "error", err)
 			break
 		}
 	}
-
 	if actionDataError != nil {
-		Log.Errorf("ERROR: %v", actionDataError)
-		thereAreErrors = true // __TODO -- test this
-		actionDataErrorIndex := len(loadersData) - 1
-		if actionDataErrorIndex < outermostErrorIndex || outermostErrorIndex < 0 {
-			outermostErrorIndex = actionDataErrorIndex
-		}
+		h.getLogger().Error("action error", "error", actionDataError)
 	}
+	errorRenderPlan := newErrorRenderPlan(errors, actionDataError, loadersData)
 
-	closestParentErrorBoundaryIndex := -2
+	// Nothing can catch this error client-side, so render the configured error route instead
+	if errorRenderPlan != nil && errorRenderPlan.BoundaryIndex == -1 && h.ErrorRoute != "" && !item.IsFallback {
+		if errorItem := getFallbackItem(h.ErrorRoute, http.StatusInternalServerError, &[]string{}); errorItem != nil {
+			return h.getMatchingPathData(w, r, errorItem, scope)
+		}
+	}
 
-	if thereAreErrors && outermostErrorIndex != -1 {
-		closestParentErrorBoundaryIndex = findClosestParentErrorBoundaryIndex(loadersData, outermostErrorIndex)
+	if errorRenderPlan != nil && status == 0 {
+		status = getErrorStatusCode(errorRenderPlan.Err)
+	}
 
-		if closestParentErrorBoundaryIndex != -1 {
-			closestParentErrorBoundaryIndex = outermostErrorIndex - closestParentErrorBoundaryIndex
+	if w != nil {
+		for key, values := range h.composeHeaders(r, item, loadersData, errors, scope) {
+			w.Header()[key] = values
+		}
+	}
+	if raw := getOutermostRawResponse(loadersData, errors); raw != nil {
+		return &ActivePathData{
+			MatchingPaths: item.FullyDecoratedMatchingPaths,
+			Params:        item.Params,
+			SplatSegments: item.SplatSegments,
+			RawResponse:   raw,
+			scope:         scope,
 		}
 	}
 
-	var activeHeads []Head
+	activeHeads := make([]Head, 0, len(*item.FullyDecoratedMatchingPaths))
 	for _, path := range *item.FullyDecoratedMatchingPaths {
 		if path.DataFuncs == nil || path.DataFuncs.Head == nil {
 			activeHeads = append(activeHeads, nil)
@@ -683,30 +1274,12 @@ func getMatchingPathData(w http.ResponseWriter, r *http.Request) *ActivePathData
 		}
 	}
 
-	// __TODO -- this is a bit of a mess, also should dedupe
-	if thereAreErrors {
-		var activePathData ActivePathData = ActivePathData{}
-		locMatchingPaths := (*item.FullyDecoratedMatchingPaths)[:outermostErrorIndex+1]
-		activePathData.MatchingPaths = &locMatchingPaths
-		locActiveHeads := activeHeads[:outermostErrorIndex]
-		activePathData.ActiveHeads = &locActiveHeads
-		locLoadersData := loadersData[:outermostErrorIndex]
-		activePathData.LoadersData = &locLoadersData
-		locImportURLs := (*item.ImportURLs)[:outermostErrorIndex+1]
-		activePathData.ImportURLs = &locImportURLs
-		activePathData.OutermostErrorBoundaryIndex = closestParentErrorBoundaryIndex
-		locActionData := make([]any, len(*activePathData.ImportURLs))
-		activePathData.ActionData = &locActionData
-		activePathData.SplatSegments = item.SplatSegments
-		activePathData.Params = item.Params
-		return &activePathData
-	}
 	var activePathData ActivePathData = ActivePathData{}
 	activePathData.MatchingPaths = item.FullyDecoratedMatchingPaths
 	activePathData.ActiveHeads = &activeHeads
 	activePathData.LoadersData = &loadersData
 	activePathData.ImportURLs = item.ImportURLs
-	activePathData.OutermostErrorBoundaryIndex = closestParentErrorBoundaryIndex
+	activePathData.OutermostErrorBoundaryIndex = -2
 	locActionData := make([]any, len(*activePathData.ImportURLs))
 	if len(locActionData) > 0 {
 		locActionData[len(locActionData)-1] = actionData
@@ -715,9 +1288,96 @@ func getMatchingPathData(w http.ResponseWriter, r *http.Request) *ActivePathData
 	activePathData.SplatSegments = item.SplatSegments
 	activePathData.Params = item.Params
 	activePathData.Deps = item.Deps
+	activePathData.Status = status
+	activePathData.scope = scope
+	if errorRenderPlan != nil {
+		errorRenderPlan.apply(&activePathData, item)
+	}
 	return &activePathData
 }
 
+func (h Hwy) runLoaders(r *http.Request, item *gmpdItem, scope *requestScope) ([]any, []error) {
+	paths := *item.FullyDecoratedMatchingPaths
+	loadersData := make([]any, len(paths))
+	errors := make([]error, len(paths))
+
+	runLoader := func(i int, parentLoadersData *[]any) {
+		dataFuncs := paths[i].DataFuncs
+		if dataFuncs == nil || dataFuncs.Loader == nil {
+			return
+		}
+		_, span := h.startSpan(r.Context(), SpanLoader, getPathSpanAttributes(paths[i])...)
+		startTime := time.Now()
+		props := &LoaderProps{
+			DataProps:         scope.newDataProps(r, item.Params, item.SplatSegments),
+			ParentLoadersData: parentLoadersData,
+			Handle:            paths[i].Handle,
+		}
+		var isFallback bool
+		loadersData[i], errors[i] = callDataFunc(h, r, paths[i].Pattern, ErrorPhaseLoader, func() (data any, err error) {
+			if dataFuncs.CircuitBreaker != nil {
+				data, isFallback, err = dataFuncs.CircuitBreaker.call(h, paths[i].Pattern, props, dataFuncs.Loader)
+				return data, err
+			}
+			return (dataFuncs.Loader)(props)
+		})
+		endSpan(span, errors[i])
+		h.recordInvocation(ErrorPhaseLoader, paths[i].Pattern, &props.DataProps, startTime, loadersData[i], errors[i])
+		if h.Metrics != nil && !GetIsPrefetchRequest(r) {
+			h.Metrics.ObserveLoader(paths[i].Pattern, time.Since(startTime), errors[i])
+		}
+		if dataFuncs.StaleOnError != nil && !isFallback {
+			if data, ok := dataFuncs.StaleOnError.apply(props, loadersData[i], errors[i]); ok {
+				h.getLogger().Warn("serving stale loader data", "pattern", paths[i].Pattern, "error", errors[i])
+				loadersData[i], errors[i] = data, nil
+				scope.addStalePattern(paths[i].Pattern)
+			}
+		}
+	}
+
+	switch h.LoaderStrategy {
+	case LoaderStrategySequential:
+		// Parent to child, so each loader can see the resolved data of its ancestors.
+		// Once a loader errors, its children are skipped, as they would be discarded anyway.
+		for i := range paths {
+			parentLoadersData := loadersData[:i:i]
+			runLoader(i, &parentLoadersData)
+			if errors[i] != nil {
+				break
+			}
+		}
+	case LoaderStrategyBounded:
+		limit := h.MaxConcurrentLoaders
+		if limit < 1 {
+			limit = 1
+		}
+		sem := make(chan struct{}, limit)
+		var wg sync.WaitGroup
+		for i := range paths {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				runLoader(i, nil)
+			}(i)
+		}
+		wg.Wait()
+	default:
+		var wg sync.WaitGroup
+		for i := range paths {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				runLoader(i, nil)
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	return loadersData, errors
+}
+
 var acceptedMethods = map[string]int{
 	"POST": 0, "PUT": 0, "PATCH": 0, "DELETE": 0,
 }
@@ -743,65 +1403,309 @@ func (h Hwy) addDataFuncsToPaths() {
 	for i, path := range *instancePaths {
 		if dataFuncs, ok := (h.DataFuncsMap)[path.Pattern]; ok {
 			(*instancePaths)[i].DataFuncs = &dataFuncs
+			if dataFuncs.Handle != nil {
+				(*instancePaths)[i].Handle = dataFuncs.Handle
+			}
 		}
 	}
 }
 
-func getBasePaths(FS fs.FS) (*PathsFile, error) {
-	pathsFile := PathsFile{}
-	file, err := FS.Open("hwy_paths.json")
-	if err != nil {
-		return nil, err
+// getPathsFile reads EmbeddedPathsFile if set, otherwise hwy_paths.json
+// from FS
+func (h Hwy) getPathsFile() (*PathsFile, error) {
+	if h.EmbeddedPathsFile != "" {
+		return DecodePathsFile([]byte(h.EmbeddedPathsFile))
 	}
-	defer file.Close()
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&pathsFile)
+	data, err := fs.ReadFile(h.FS, "hwy_paths.json")
 	if err != nil {
 		return nil, err
 	}
-	return &pathsFile, nil
+	return DecodePathsFile(data)
 }
 
+func newPath(path JSONSafePath) Path {
+	return Path{
+		Pattern:     path.Pattern,
+		Segments:    path.Segments,
+		PathType:    path.PathType,
+		OutPath:     path.OutPath,
+		SrcPath:     path.SrcPath,
+		Deps:        path.Deps,
+		Handle:      path.Handle,
+		Static:      path.Static,
+		Islands:     path.Islands,
+		CSSBundle:   path.CSSBundle,
+		CriticalCSS: path.CriticalCSS,
+		Variants:    path.Variants,
+		ClientEntry: path.ClientEntry,
+		compiled:    CompilePattern(path.Pattern),
+	}
+}
+
+// Initialize loads the paths file from FS. Problems that don't prevent
+// serving (e.g. a DataFuncsMap pattern matching no route) are reported
+// together as an *InitializeError, after initializing anyway. The route
+// table, Clock, Prefix, and the rest of what it sets up are process-wide,
+// so only one Hwy per process should be initialized; clones (see With)
+// can't be.
 func (h Hwy) Initialize() error {
+	if h.isClone {
+		return ErrInitializeClone
+	}
+	instancePrefix = HwyPrefix
+	if h.Prefix != "" {
+		instancePrefix = h.Prefix
+	}
 	if h.FS == nil {
-		return errors.New("FS is nil")
+		instanceInitErr = errors.New("FS is nil")
+		return instanceInitErr
 	}
 
-	pathsFile, err := getBasePaths(h.FS)
+	pathsFile, err := h.getPathsFile()
 	if err != nil {
+		instanceInitErr = err
 		return err
 	}
 	instanceBuildID = pathsFile.BuildID
-
-	if instancePaths == nil {
-		ip := make([]Path, 0, len(pathsFile.Paths))
-		instancePaths = &ip
+	if h.BuildIDFunc != nil {
+		instanceBuildID = h.BuildIDFunc()
 	}
+	instanceClock = getClock(h.Clock)
+
+	// Replace (rather than extend) any previously initialized paths, so
+	// Initialize can be called again, e.g. by tests
+	ip := make([]Path, 0, len(pathsFile.Paths))
+	instancePaths = &ip
+	gmpdCache = NewLRUCache(gmpdCacheSize)
+	initNegativeCache(h.NegativeCache)
 	for _, path := range pathsFile.Paths {
-		*instancePaths = append(*instancePaths, Path{
-			Pattern:  path.Pattern,
-			Segments: path.Segments,
-			PathType: path.PathType,
-			OutPath:  path.OutPath,
-			SrcPath:  path.SrcPath,
-			Deps:     path.Deps,
-		})
+		*instancePaths = append(*instancePaths, newPath(path))
 	}
 
+	// Data funcs may also be keyed by page file (see resolveDataFuncsMap)
+	pathLists := [][]JSONSafePath{pathsFile.Paths}
+	for _, tenant := range getSortedKeys(pathsFile.TenantPaths) {
+		pathLists = append(pathLists, pathsFile.TenantPaths[tenant])
+	}
+	var keyProblems []error
+	h.DataFuncsMap, keyProblems = resolveDataFuncsMap(h.DataFuncsMap, getSrcPathPatterns(pathLists...))
 	h.addDataFuncsToPaths()
 	instanceClientEntryDeps = &pathsFile.ClientEntryDeps
+	instanceIntegrity = pathsFile.Integrity
+	instanceClientEntryCSSBundle = pathsFile.ClientEntryCSSBundle
+	instanceClientEntryCriticalCSS = pathsFile.ClientEntryCriticalCSS
+	instanceClientEntries = pathsFile.ClientEntries
+	instanceLegacy = pathsFile.Legacy
+
+	keyProblems = append(keyProblems, h.initTenantPaths(pathsFile)...)
+
+	instanceInitErr = nil
+	problems := h.validatePaths(pathsFile)
+	problems = append(problems, keyProblems...)
+	problems = append(problems, h.loadMessages()...)
+	problems = append(problems, h.validateHeadProfile()...)
+	problems = append(problems, h.verifyAssets(pathsFile)...)
+	if h.Strict {
+		problems = append(problems, h.validateStrict()...)
+	}
+	if len(problems) > 0 {
+		instanceInitErr = &InitializeError{Problems: problems}
+	}
+	h.warmCaches()
+	return instanceInitErr
+}
 
-	return nil
+func (h Hwy) GetRouteData(w http.ResponseWriter, r *http.Request) (routeData *GetRouteDataOutput, err error) {
+	// Loader contexts are only canceled by Shutdown when served via
+	// GetRootHandler, so r is passed through to data funcs unchanged here
+	lifecycle := instanceLifecycle
+	if !lifecycle.begin() {
+		return nil, ErrShuttingDown
+	}
+	defer lifecycle.done()
+	r = h.withTenant(w, r)
+	if h.TracerProvider != nil {
+		ctx, span := h.startSpan(r.Context(), SpanRouteData, SpanAttribute{Key: "hwy.path", Value: r.URL.Path}, SpanAttribute{Key: "hwy.prefetch", Value: GetIsPrefetchRequest(r)})
+		defer span.End()
+		r = r.WithContext(ctx)
+	}
+	defer func() {
+		if routeData != nil {
+			routeData.nonce = GetCSPNonce(r)
+			routeData.encoder = h.Encoder
+		}
+	}()
+	if h.Metrics != nil {
+		defer func() {
+			h.observeRequest(r, routeData, err)
+		}()
+	}
+	if outcome, err := h.getRedirectRuleOutcome(r); err != nil {
+		return nil, err
+	} else if outcome != nil {
+		return &GetRouteDataOutput{
+			GuardOutcome: outcome,
+			BuildID:      instanceBuildID,
+		}, nil
+	}
+	r, locale, localeOutcome := h.resolveLocale(w, r)
+	if localeOutcome != nil {
+		return &GetRouteDataOutput{
+			GuardOutcome: localeOutcome,
+			BuildID:      instanceBuildID,
+		}, nil
+	}
+	localeFreePath := r.URL.Path
+	r = h.applyRewriteRules(r)
+
+	scope, err := h.newRequestScope(r, locale, localeFreePath)
+	if err == nil {
+		h.assignExperiments(w, r, scope)
+		h.setClientHints(w, r, scope)
+		routeData, err = h.getRouteData(w, r, nil, scope)
+	}
+	if err != nil && h.ErrorRoute != "" {
+		if errorItem := getFallbackItem(h.ErrorRoute, http.StatusInternalServerError, &[]string{}); errorItem != nil {
+			h.getLogger().Error("error getting route data", "error", err)
+			if scope == nil {
+				// The services hook or ResolveUser failed, so render without
+				// request services or a user
+				scope = &requestScope{
+					services:       h.Services.clone(),
+					locale:         locale,
+					localeFreePath: localeFreePath,
+					i18n:           h.I18n,
+					siteOrigin:     h.SiteOrigin,
+					memo:           make(map[string]*memoEntry),
+					fetcher:        h.Fetcher,
+					tracerProvider: h.TracerProvider,
+				}
+			}
+			return h.getRouteData(w, r, errorItem, scope)
+		}
+	}
+	return routeData, err
 }
 
-func (h Hwy) GetRouteData(w http.ResponseWriter, r *http.Request) (*GetRouteDataOutput, error) {
-	activePathData := getMatchingPathData(w, r)
+// getRouteData matches the request normally when item is nil
+func (h Hwy) getRouteData(w http.ResponseWriter, r *http.Request, item *gmpdItem, scope *requestScope) (*GetRouteDataOutput, error) {
+	if item == nil {
+		_, span := h.startSpan(r.Context(), SpanMatch, SpanAttribute{Key: "hwy.path", Value: getRealPath(r)})
+		var cacheHit bool
+		item, cacheHit = lookupMatchingPathItem(r, scope)
+		span.SetAttributes(SpanAttribute{Key: "hwy.cache_hit", Value: cacheHit})
+		span.End()
+		if h.Metrics != nil {
+			h.Metrics.ObserveMatchCache(cacheHit)
+		}
+		if h.CaseInsensitive && getIsUnmatched(item) {
+			if to := getCanonicalCaseURL(r, scope); to != "" {
+				return &GetRouteDataOutput{
+					GuardOutcome: &GuardOutcome{Status: http.StatusPermanentRedirect, RedirectTo: to},
+					BuildID:      instanceBuildID,
+				}, nil
+			}
+		}
+		if outcome := h.checkRateLimit(w, r, item); outcome != nil {
+			return &GetRouteDataOutput{
+				GuardOutcome: outcome,
+				BuildID:      instanceBuildID,
+			}, nil
+		}
+		if h.NotFoundRoute != "" && getIsUnmatched(item) {
+			if notFoundItem := getFallbackItem(h.NotFoundRoute, http.StatusNotFound, getBaseSplatSegments(getRealPath(r))); notFoundItem != nil {
+				item = notFoundItem
+			}
+		}
+	}
+
+	item = h.withDataFuncsOverrides(item)
+	guardOutcome, err := h.runGuards(r, item, scope)
+	if err != nil {
+		return nil, err
+	}
+	if guardOutcome != nil {
+		return &GetRouteDataOutput{
+			GuardOutcome: guardOutcome,
+			BuildID:      instanceBuildID,
+		}, nil
+	}
+
+	activePathData := h.getMatchingPathData(w, r, item, scope)
+	if outcome := activePathData.redirectOutcome; outcome != nil {
+		if !h.getIsSafeRedirect(r, outcome.RedirectTo) {
+			return nil, fmt.Errorf("%w: %q", ErrUnsafeRedirect, outcome.RedirectTo)
+		}
+		return &GetRouteDataOutput{
+			GuardOutcome: outcome,
+			BuildID:      instanceBuildID,
+			Pattern:      activePathData.getPattern(),
+		}, nil
+	}
+	if activePathData.RawResponse != nil {
+		return &GetRouteDataOutput{
+			RawResponse:    activePathData.RawResponse,
+			Status:         activePathData.RawResponse.Status,
+			BuildID:        instanceBuildID,
+			Pattern:        activePathData.getPattern(),
+			activePathData: activePathData,
+		}, nil
+	}
+
+	defaultHeadBlocks := h.getDefaultHeadBlocks(r)
+	if h.I18n != nil && activePathData.Status == 0 {
+		hreflangHeadBlocks := getHreflangHeadBlocks(h.I18n, h.SiteOrigin, activePathData.getCanonicalPath())
+		defaultHeadBlocks = append(slices.Clone(defaultHeadBlocks), hreflangHeadBlocks...)
+	}
+	if h.getIsNoindex(r, activePathData) {
+		defaultHeadBlocks = append(slices.Clone(defaultHeadBlocks), h.Noindex.getHeadBlock())
+	}
+	if routeHeadBlocks := h.getRouteHeadBlocks(r, activePathData); len(routeHeadBlocks) > 0 {
+		defaultHeadBlocks = append(slices.Clone(defaultHeadBlocks), routeHeadBlocks...)
+	}
+	if preloadHeadBlocks := h.getPreloadHeadBlocks(activePathData); len(preloadHeadBlocks) > 0 {
+		defaultHeadBlocks = append(preloadHeadBlocks, defaultHeadBlocks...)
+	}
 
-	headBlocks, err := getExportedHeadBlocks(r, activePathData, &h.DefaultHeadBlocks)
+	dedupeKeys := h.HeadDedupeKeys
+	if dedupeKeys == nil {
+		dedupeKeys = DefaultHeadDedupeKeys
+	}
+	headBlocks, err := h.getExportedHeadBlocks(r, activePathData, &defaultHeadBlocks, dedupeKeys)
+	if err != nil {
+		return nil, err
+	}
+	sorted := sortHeadBlocks(filterHeadBlocks(headBlocks, h.ExtraPermittedHeadTags))
+	if scope != nil && scope.locale != "" {
+		if _, exists := sorted.htmlAttributes["lang"]; !exists {
+			sorted.htmlAttributes = mergeAttributes(sorted.htmlAttributes, map[string]string{"lang": scope.locale})
+		}
+	}
+	h.checkPayloadBudgets(activePathData)
+	clientLoadersData, err := h.getClientLoadersData(r, activePathData.LoadersData)
 	if err != nil {
 		return nil, err
 	}
-	sorted := sortHeadBlocks(headBlocks)
+	breadcrumbs := GetBreadcrumbs(r, activePathData)
+	cssBundles := activePathData.getCSSBundles()
+	clientEntry := activePathData.getClientEntryName()
+	importURLs, deps := activePathData.getClientImportURLs(), activePathData.Deps
+	isLegacy := h.getIsLegacyBrowser(r)
+	if isLegacy {
+		importURLs, deps = getLegacyAssets(importURLs, activePathData.getIslandOutPaths(), GetClientEntryFileName(clientEntry))
+	}
+	if scope != nil && len(breadcrumbs) > 0 {
+		scope.adHocData["breadcrumbs"] = breadcrumbs
+	}
+	patterns := make([]string, 0, len(*activePathData.MatchingPaths))
+	pathTypes := make([]string, 0, len(*activePathData.MatchingPaths))
+	handles := make([]map[string]any, 0, len(*activePathData.MatchingPaths))
+	for _, path := range *activePathData.MatchingPaths {
+		patterns = append(patterns, path.Pattern)
+		pathTypes = append(pathTypes, path.PathType)
+		handles = append(handles, path.Handle)
+	}
+	stalePatterns := scope.getStalePatterns(patterns)
 	if sorted.metaHeadBlocks == nil {
 		sorted.metaHeadBlocks = &[]*HeadBlock{}
 	}
@@ -812,51 +1716,112 @@ func (h Hwy) GetRouteData(w http.ResponseWriter, r *http.Request) (*GetRouteData
 		Title:                       sorted.title,
 		MetaHeadBlocks:              sorted.metaHeadBlocks,
 		RestHeadBlocks:              sorted.restHeadBlocks,
-		LoadersData:                 activePathData.LoadersData,
-		ImportURLs:                  activePathData.ImportURLs,
+		HTMLAttributes:              sorted.htmlAttributes,
+		BodyAttributes:              sorted.bodyAttributes,
+		permittedHeadTags:           getPermittedHeadTags(h.ExtraPermittedHeadTags),
+		LoadersData:                 clientLoadersData,
+		ImportURLs:                  h.withAssetBasePrefix(importURLs),
 		OutermostErrorBoundaryIndex: activePathData.OutermostErrorBoundaryIndex,
 		SplatSegments:               activePathData.SplatSegments,
 		Params:                      activePathData.Params,
 		ActionData:                  activePathData.ActionData,
-		AdHocData:                   nil, // __TODO
+		AdHocData:                   scope.getAdHocData(),
 		BuildID:                     instanceBuildID,
-		Deps:                        activePathData.Deps,
+		Deps:                        deps,
+		Status:                      activePathData.Status,
+		Pattern:                     activePathData.getPattern(),
+		Patterns:                    &patterns,
+		PathTypes:                   &pathTypes,
+		Handles:                     &handles,
+		Islands:                     h.getIslandURLs(activePathData.MatchingPaths, isLegacy),
+		Breadcrumbs:                 breadcrumbs,
+		CSSBundles:                  cssBundles,
+		Integrity:                   getDepsIntegrity(deps, cssBundles),
+		ClientEntry:                 clientEntry,
+		AssetBasePrefix:             h.getAssetBasePrefix(),
+		DevError:                    h.getRenderPlanDevError(activePathData),
+		IsStale:                     len(stalePatterns) > 0,
+		StalePatterns:               stalePatterns,
+		activePathData:              activePathData,
+		isLegacy:                    isLegacy,
 	}, nil
 }
 
-func getExportedHeadBlocks(r *http.Request, activePathData *ActivePathData, defaultHeadBlocks *[]HeadBlock) (*[]*HeadBlock, error) {
+func (a *ActivePathData) getPattern() string {
+	if a.MatchingPaths == nil || len(*a.MatchingPaths) == 0 {
+		return ""
+	}
+	return (*a.MatchingPaths)[len(*a.MatchingPaths)-1].Pattern
+}
+
+func (h Hwy) getExportedHeadBlocks(r *http.Request, activePathData *ActivePathData, defaultHeadBlocks *[]HeadBlock, dedupeKeys []HeadDedupeKey) (*[]*HeadBlock, error) {
 	headBlocks := make([]HeadBlock, len(*defaultHeadBlocks))
 	copy(headBlocks, *defaultHeadBlocks)
 	for i, head := range *activePathData.ActiveHeads {
 		if head != nil {
 			headProps := HeadProps{
-				Request:       r,
-				Params:        activePathData.Params,
-				SplatSegments: activePathData.SplatSegments,
+				DataProps:     activePathData.scope.newDataProps(r, activePathData.Params, activePathData.SplatSegments),
 				LoaderData:    (*activePathData.LoadersData)[i],
 				ActionData:    (*activePathData.ActionData)[i],
+				canonicalPath: activePathData.getCanonicalPath(),
 			}
-			localHeadBlocks, err := (head)(&headProps)
+			_, span := h.startSpan(r.Context(), SpanHead, getPathSpanAttributes((*activePathData.MatchingPaths)[i])...)
+			startTime := time.Now()
+			localHeadBlocks, err := callDataFunc(h, r, (*activePathData.MatchingPaths)[i].Pattern, ErrorPhaseHead, func() (*[]HeadBlock, error) {
+				return (head)(&headProps)
+			})
+			endSpan(span, err)
+			h.recordInvocation(ErrorPhaseHead, (*activePathData.MatchingPaths)[i].Pattern, &headProps.DataProps, startTime, localHeadBlocks, err)
 			if err != nil {
 				return nil, err
 			}
 			headBlocks = append(headBlocks, *localHeadBlocks...)
 		}
 	}
-	return dedupeHeadBlocks(&headBlocks), nil
+	return dedupeHeadBlocks(&headBlocks, dedupeKeys), nil
 }
 
 // __TODO -- add OverrideMatchingParentsFunc that acts just like Head but lets you return simpler HeadBlocks that when matched, override the parent HeadBlocks
 // additionally, would make sense to also take an a defaultOverrideHeadBlocks arg at root as well, just like DefaultHeadBlocks
 // ALternatively, could build the concept into each Path level as a new opportunity to set a DefaultHeadBlocks slice, applicable to it and its children
 
-// __TODO test this
-func dedupeHeadBlocks(blocks *[]HeadBlock) *[]*HeadBlock {
+// HeadDedupeKey declares that head blocks with the same Tag and the same
+// value for Attribute are duplicates, in which case the later (child) block
+// replaces the earlier (parent) one in place. When Value is set, the key only
+// applies to blocks whose Attribute equals Value (e.g. link[rel=canonical]).
+type HeadDedupeKey struct {
+	Tag       string
+	Attribute string
+	Value     string
+}
+
+// Used when Hwy.HeadDedupeKeys is nil. Titles are always deduped.
+var DefaultHeadDedupeKeys = []HeadDedupeKey{
+	{Tag: "meta", Attribute: "name"},
+	{Tag: "meta", Attribute: "property"},
+	{Tag: "link", Attribute: "rel", Value: "canonical"},
+}
+
+func getDedupeKey(block *HeadBlock, keys []HeadDedupeKey) (string, bool) {
+	for _, key := range keys {
+		if block.Tag != key.Tag {
+			continue
+		}
+		value, exists := block.Attributes[key.Attribute]
+		if !exists || (key.Value != "" && value != key.Value) {
+			continue
+		}
+		return key.Tag + "[" + key.Attribute + "=" + value + "]", true
+	}
+	return "", false
+}
+
+func dedupeHeadBlocks(blocks *[]HeadBlock, keys []HeadDedupeKey) *[]*HeadBlock {
 	uniqueBlocks := make(map[string]*HeadBlock)
 	var dedupedBlocks []*HeadBlock
 
 	titleIdx := -1
-	descriptionIdx := -1
+	keyedIdxs := make(map[string]int)
 
 	for _, block := range *blocks {
 		if title := (block.Title); len(title) > 0 {
@@ -866,12 +1831,12 @@ func dedupeHeadBlocks(blocks *[]HeadBlock) *[]*HeadBlock {
 			} else {
 				dedupedBlocks[titleIdx] = &block
 			}
-		} else if block.Tag == "meta" && (block.Attributes)["name"] == "description" {
-			if descriptionIdx == -1 {
-				descriptionIdx = len(dedupedBlocks)
-				dedupedBlocks = append(dedupedBlocks, &block)
+		} else if dedupeKey, ok := getDedupeKey(&block, keys); ok {
+			if idx, exists := keyedIdxs[dedupeKey]; exists {
+				dedupedBlocks[idx] = &block
 			} else {
-				dedupedBlocks[descriptionIdx] = &block
+				keyedIdxs[dedupeKey] = len(dedupedBlocks)
+				dedupedBlocks = append(dedupedBlocks, &block)
 			}
 		} else {
 			key := stableHash(&block)
@@ -911,6 +1876,10 @@ func sortHeadBlocks(blocks *[]*HeadBlock) SortHeadBlocksOutput {
 	for _, block := range *blocks {
 		if len(block.Title) > 0 {
 			result.title = block.Title
+		} else if block.Tag == "html" {
+			result.htmlAttributes = mergeAttributes(result.htmlAttributes, block.Attributes)
+		} else if block.Tag == "body" {
+			result.bodyAttributes = mergeAttributes(result.bodyAttributes, block.Attributes)
 		} else if block.Tag == "meta" {
 			*result.metaHeadBlocks = append(*result.metaHeadBlocks, block)
 		} else {
@@ -925,45 +1894,50 @@ var metaEnd = HeadBlock{Tag: "meta", Attributes: map[string]string{"data-hwy": "
 var restStart = HeadBlock{Tag: "meta", Attributes: map[string]string{"data-hwy": "rest-start"}}
 var restEnd = HeadBlock{Tag: "meta", Attributes: map[string]string{"data-hwy": "rest-end"}}
 
-func GetHeadElements(routeData *GetRouteDataOutput) (*template.HTML, error) {
-	var htmlBuilder strings.Builder
-	titleTmpl, err := template.New("title").Parse(
+// Parsed once, as templates are safe for concurrent use
+var (
+	titleTmpl = template.Must(template.New("title").Parse(
 		`<title>{{.}}</title>` + "\n",
-	)
-	if err != nil {
-		return nil, err
-	}
-	err = titleTmpl.Execute(&htmlBuilder, routeData.Title)
+	))
+	headElsTmpl = template.Must(template.New("headblock").Parse(
+		`{{range $key, $value := .Attributes}}{{$key}}="{{$value}}" {{end}}/>` + "\n",
+	))
+	scriptBlockTmpl = template.Must(template.New("scriptblock").Parse(
+		`{{range $key, $value := .Attributes}}{{$key}}="{{$value}}" {{end}}></script>` + "\n",
+	))
+)
+
+func GetHeadElements(routeData *GetRouteDataOutput) (*template.HTML, error) {
+	htmlBuilder := getBuffer()
+	defer putBuffer(htmlBuilder)
+	err := titleTmpl.Execute(htmlBuilder, routeData.Title)
 	if err != nil {
 		return nil, err
 	}
 
-	var headBlocks = []*HeadBlock{&metaStart}
-	headBlocks = append(headBlocks, append(*routeData.MetaHeadBlocks, &metaEnd)...)
-	headBlocks = append(headBlocks, &restStart)
-	headBlocks = append(headBlocks, append(*routeData.RestHeadBlocks, &restEnd)...)
+	headBlocks := make([]*HeadBlock, 0, len(*routeData.MetaHeadBlocks)+len(*routeData.RestHeadBlocks)+4)
+	headBlocks = append(headBlocks, &metaStart)
+	headBlocks = append(headBlocks, *routeData.MetaHeadBlocks...)
+	headBlocks = append(headBlocks, &metaEnd, &restStart)
+	headBlocks = append(headBlocks, *routeData.RestHeadBlocks...)
+	headBlocks = append(headBlocks, &restEnd)
 
-	headElsTmpl, err := template.New("headblock").Parse(
-		`{{range $key, $value := .Attributes}}{{$key}}="{{$value}}" {{end}}/>` + "\n",
-	)
-	if err != nil {
-		return nil, err
-	}
-	scriptBlockTmpl, err := template.New("scriptblock").Parse(
-		`{{range $key, $value := .Attributes}}{{$key}}="{{$value}}" {{end}}></script>` + "\n",
-	)
-	if err != nil {
-		return nil, err
+	permittedHeadTags := routeData.permittedHeadTags
+	if permittedHeadTags == nil {
+		permittedHeadTags = permittedTags
 	}
 	for _, block := range headBlocks {
-		if !slices.Contains(permittedTags, block.Tag) {
+		if !slices.Contains(permittedHeadTags, block.Tag) {
 			continue
 		}
 		htmlBuilder.WriteString("<" + block.Tag + " ")
 		if block.Tag == "script" {
-			err = scriptBlockTmpl.Execute(&htmlBuilder, block)
+			if routeData.nonce != "" {
+				block = withNonceAttribute(block, routeData.nonce)
+			}
+			err = scriptBlockTmpl.Execute(htmlBuilder, block)
 		} else {
-			err = headElsTmpl.Execute(&htmlBuilder, block)
+			err = headElsTmpl.Execute(htmlBuilder, block)
 		}
 		if err != nil {
 			return nil, err
@@ -975,35 +1949,58 @@ func GetHeadElements(routeData *GetRouteDataOutput) (*template.HTML, error) {
 
 var permittedTags = []string{"meta", "base", "link", "style", "script", "noscript"}
 
+// HwyPrefix is the default Hwy.Prefix
 const HwyPrefix = "__hwy_internal__"
 
-func GetSSRInnerHTML(routeData *GetRouteDataOutput, isDev bool) (*template.HTML, error) {
-	tmpl, err := template.New("ssr").Parse(`<script>
+// Set from Hwy.Prefix by Initialize
+var instancePrefix = HwyPrefix
+
+var ssrInnerHTMLTmpl = template.Must(template.New("ssr").Parse(`<script{{if .Nonce}} nonce="{{.Nonce}}"{{end}}>
 	globalThis[Symbol.for("{{.HwyPrefix}}")] = {};
 	const x = globalThis[Symbol.for("{{.HwyPrefix}}")];
 	x.isDev = {{.IsDev}};
 	x.buildID = {{.BuildID}};
-	x.loadersData = {{.LoadersData}};
+	x.loadersData = {{if .LoadersDataJS}}{{.LoadersDataJS}}{{else}}{{.LoadersData}}{{end}};
 	x.importURLs = {{.ImportURLs}};
 	x.outermostErrorBoundaryIndex = {{.OutermostErrorBoundaryIndex}};
 	x.splatSegments = {{.SplatSegments}};
 	x.params = {{.Params}};
-	x.actionData = {{.ActionData}};
-	x.adHocData = {{.AdHocData}};
+	x.actionData = {{if .ActionDataJS}}{{.ActionDataJS}}{{else}}{{.ActionData}}{{end}};
+	x.adHocData = {{if .AdHocDataJS}}{{.AdHocDataJS}}{{else}}{{.AdHocData}}{{end}};
+	x.pattern = {{.Pattern}};
+	x.patterns = {{.Patterns}};
+	x.pathTypes = {{.PathTypes}};
+	x.handles = {{.Handles}};
+	x.assetBasePrefix = {{.AssetBasePrefix}} || "/public/";
 	const deps = {{.Deps}};
+	const integrity = {{.Integrity}} || {};
 	deps.forEach(module => {
 		const link = document.createElement('link');
-		link.rel = 'modulepreload';
-		link.href = "/public/" + module;
+		if (module.endsWith(".wasm")) {
+			link.rel = 'preload';
+			link.as = 'fetch';
+			link.type = 'application/wasm';
+			link.crossOrigin = "anonymous";
+		} else {
+			link.rel = 'modulepreload';
+			if (module.includes("hwy_worker__")) {
+				link.as = 'worker';
+			}
+		}
+		link.href = x.assetBasePrefix + module;
+		if (integrity[module]) {
+			link.integrity = integrity[module];
+			link.crossOrigin = "anonymous";
+		}
 		document.head.appendChild(link);
 	 });
-</script>`)
-	if err != nil {
-		return nil, err
-	}
-	var htmlBuilder strings.Builder
+</script>`))
+
+func GetSSRInnerHTML(routeData *GetRouteDataOutput, isDev bool) (*template.HTML, error) {
+	htmlBuilder := getBuffer()
+	defer putBuffer(htmlBuilder)
 	var dto = SSRInnerHTMLInput{
-		HwyPrefix:                   HwyPrefix,
+		HwyPrefix:                   instancePrefix,
 		IsDev:                       isDev,
 		BuildID:                     routeData.BuildID,
 		LoadersData:                 routeData.LoadersData,
@@ -1014,153 +2011,239 @@ func GetSSRInnerHTML(routeData *GetRouteDataOutput, isDev bool) (*template.HTML,
 		ActionData:                  routeData.ActionData,
 		AdHocData:                   routeData.AdHocData,
 		Deps:                        routeData.Deps,
+		Pattern:                     routeData.Pattern,
+		Patterns:                    routeData.Patterns,
+		PathTypes:                   routeData.PathTypes,
+		Handles:                     routeData.Handles,
+		Nonce:                       routeData.nonce,
+		Integrity:                   routeData.Integrity,
+		AssetBasePrefix:             routeData.AssetBasePrefix,
+	}
+	if routeData.encoder != nil {
+		var err error
+		if dto.LoadersDataJS, err = encodeScriptJS(routeData.encoder, routeData.LoadersData); err != nil {
+			return nil, err
+		}
+		if dto.ActionDataJS, err = encodeScriptJS(routeData.encoder, routeData.ActionData); err != nil {
+			return nil, err
+		}
+		if dto.AdHocDataJS, err = encodeScriptJS(routeData.encoder, routeData.AdHocData); err != nil {
+			return nil, err
+		}
 	}
-	err = tmpl.Execute(&htmlBuilder, dto)
+	err := ssrInnerHTMLTmpl.Execute(htmlBuilder, dto)
 	if err != nil {
 		return nil, err
 	}
+	if routeData.DevError != nil {
+		overlay, err := getDevErrorOverlayHTML(routeData.DevError, routeData.nonce)
+		if err != nil {
+			return nil, err
+		}
+		htmlBuilder.WriteString(string(overlay))
+	}
 	final := template.HTML(htmlBuilder.String())
 	return &final, nil
 }
 
 func GetIsJSONRequest(r *http.Request) bool {
-	queryKey := HwyPrefix + "json"
+	queryKey := instancePrefix + "json"
 	return len(r.URL.Query().Get(queryKey)) > 0
 }
 
-func matcher(pattern string, path string) matcherOutput {
-	pattern = strings.TrimSuffix(pattern, "/_index") // needs to be first
-	pattern = strings.TrimPrefix(pattern, "/")       // needs to be second
-	path = strings.TrimPrefix(path, "/")
-	patternSegments := strings.Split(pattern, "/")
-	pathSegments := strings.Split(path, "/")
-	adjPatternSegmentsLength := len(patternSegments)
-	pathSegmentsLength := len(pathSegments)
-	isCatch := patternSegments[adjPatternSegmentsLength-1] == "$"
-	if isCatch {
-		adjPatternSegmentsLength--
-	}
-	if adjPatternSegmentsLength > pathSegmentsLength {
-		return matcherOutput{}
-	}
-	matches := false
-	params := make(map[string]string)
-	if pattern == path {
-		matches = true
-	} else {
-		for i, patternSegment := range patternSegments {
-			if i < pathSegmentsLength && patternSegment == pathSegments[i] {
-				matches = true
-				continue
-			}
-			if patternSegment == "$" {
-				matches = true
-				continue
-			}
-			if strings.HasPrefix(patternSegment, "$") {
-				matches = true
-				paramKey := patternSegment[1:]
-				if len(paramKey) > 0 {
-					params[paramKey] = pathSegments[i]
-				}
-				continue
-			}
-			matches = false
-			break
-		}
-	}
-	if !matches {
-		return matcherOutput{}
-	}
-	strength := getMatchStrength(pattern, path)
-	return matcherOutput{
-		matches:            matches,
-		params:             &params,
-		score:              strength.Score,
-		realSegmentsLength: strength.RealSegmentsLength,
-	}
-}
-
 func GetDeps(matchingPaths *[]*MatchingPath) []string {
 	var deps []string
-	for _, path := range *matchingPaths {
-		if path.Deps == nil {
-			continue
+	addDeps := func(pathDeps *[]string) {
+		if pathDeps == nil {
+			return
 		}
-		for _, dep := range *path.Deps {
+		for _, dep := range *pathDeps {
 			if !slices.Contains(deps, dep) {
 				deps = append(deps, dep)
 			}
 		}
 	}
-	if instanceClientEntryDeps == nil {
-		return deps
-	}
-	for _, dep := range *instanceClientEntryDeps {
-		if !slices.Contains(deps, dep) {
-			deps = append(deps, dep)
+	for _, path := range *matchingPaths {
+		if !path.Static {
+			addDeps(path.Deps)
+		}
+		for _, island := range path.Islands {
+			addDeps(island.Deps)
 		}
 	}
+	clientEntryDeps := getClientEntryOutput(getClientEntryName(matchingPaths)).Deps
+	addDeps(&clientEntryDeps)
 	return deps
 }
 
+// encodeRouteDataJSON writes routeData to buf as the root handler sends it
+// to the client router
+func (h Hwy) encodeRouteDataJSON(r *http.Request, buf *bytes.Buffer, routeData *GetRouteDataOutput) error {
+	_, span := h.startSpan(r.Context(), SpanSerialize, SpanAttribute{Key: "hwy.format", Value: "json"})
+	var err error
+	if h.Encoder != nil {
+		var jsonBytes []byte
+		if jsonBytes, err = h.Encoder.Marshal(routeData); err == nil {
+			buf.Write(jsonBytes)
+		}
+	} else {
+		err = json.NewEncoder(buf).Encode(routeData)
+	}
+	if err == nil && h.Serialization != nil {
+		var jsonBytes []byte
+		if jsonBytes, err = h.Serialization.reshape(buf.Bytes()); err == nil {
+			buf.Reset()
+			buf.Write(jsonBytes)
+		}
+	}
+	endSpan(span, err)
+	return err
+}
+
 func (h Hwy) GetRootHandler() http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		routeData, err := h.GetRouteData(w, r)
-		if err != nil {
-			msg := "Error getting route data"
-			Log.Errorf(msg+": %v\n", err)
-			http.Error(w, msg, http.StatusInternalServerError)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var pattern string
+		if h.AccessLogger != nil {
+			accessLogWriter := &accessLogWriter{ResponseWriter: w}
+			w = accessLogWriter
+			defer func(startTime time.Time) {
+				h.logAccess(r, accessLogWriter, pattern, startTime)
+			}(time.Now())
+		}
+
+		if !h.GetIsHostAllowed(r) {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		lifecycle := instanceLifecycle
+		if !lifecycle.begin() {
+			serveShuttingDown(w)
 			return
 		}
+		defer lifecycle.done()
+		r, cancel := lifecycle.withContext(r)
+		defer cancel()
+		r = h.withTenant(w, r)
 
-		if GetIsJSONRequest(r) {
-			w.Header().Set("Content-Type", "application/json")
-			err = json.NewEncoder(w).Encode(routeData)
-			if err != nil {
-				msg := "Error encoding JSON"
-				Log.Errorf(msg+": %v\n", err)
-				http.Error(w, msg, http.StatusInternalServerError)
+		if h.ServiceWorker && r.URL.Path == ServiceWorkerPath {
+			h.serveServiceWorker(w, r)
+			return
+		}
+
+		if h.IsDev {
+			if devErr := h.getBuildDevError(); devErr != nil {
+				h.serveDevError(w, r, devErr)
+				return
+			}
+		}
+
+		if GetIsWebSocketRequest(r) {
+			var served bool
+			if pattern, served = h.serveWebSocket(w, r); served {
+				return
 			}
+		}
+		if GetIsEventStreamRequest(r) {
+			var served bool
+			if pattern, served = h.serveEventStream(w, r); served {
+				return
+			}
+		}
+
+		protocolVersion, ok := h.getProtocolVersion(r)
+		if !ok && GetIsJSONRequest(r) {
+			serveUnsupportedProtocol(w)
 			return
 		}
 
-		tmpl, err := template.ParseFS(h.FS, h.RootTemplateLocation)
+		routeData, err := h.GetRouteData(w, r)
+		if err == nil {
+			pattern = routeData.Pattern
+		}
+		if errors.Is(err, ErrShuttingDown) {
+			serveShuttingDown(w)
+			return
+		}
 		if err != nil {
-			msg := "Error loading template"
-			Log.Errorf(msg+": %v\n", err)
-			http.Error(w, msg, http.StatusInternalServerError)
+			h.serveInternalError(w, r, pattern, "Error getting route data", err)
 			return
 		}
 
-		headElements, err := GetHeadElements(routeData)
-		if err != nil {
-			msg := "Error getting head elements"
-			Log.Errorf(msg+": %v\n", err)
-			http.Error(w, msg, http.StatusInternalServerError)
+		if routeData.GuardOutcome != nil && !GetIsJSONRequest(r) {
+			routeData.GuardOutcome.serve(w, r)
 			return
 		}
 
-		ssrInnerHTML, err := GetSSRInnerHTML(routeData, true)
-		if err != nil {
-			msg := "Error getting SSR inner HTML"
-			Log.Errorf(msg+": %v\n", err)
-			http.Error(w, msg, http.StatusInternalServerError)
+		if routeData.RawResponse != nil {
+			h.serveRawResponse(w, routeData.RawResponse)
+			return
+		}
+
+		if GetIsJSONRequest(r) {
+			buf := getBuffer()
+			defer putBuffer(buf)
+			setProtocolVersion(w, routeData, protocolVersion)
+			if err := h.encodeRouteDataJSON(r, buf, routeData); err != nil {
+				h.serveInternalError(w, r, pattern, "Error encoding JSON", err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if h.PrefetchCacheControl != "" && routeData.Status == 0 && GetIsPrefetchRequest(r) {
+				w.Header().Set("Cache-Control", h.PrefetchCacheControl)
+			}
+			status := routeData.Status
+			// The client follows redirects itself from the JSON, so only
+			// denials (e.g. 403s and 429s) get their status
+			if outcome := routeData.GuardOutcome; outcome != nil && outcome.RedirectTo == "" {
+				status = outcome.status()
+			}
+			if status != 0 {
+				w.WriteHeader(status)
+			}
+			w.Write(buf.Bytes())
 			return
 		}
 
-		tmplData := map[string]any{}
-		tmplData["HeadElements"] = headElements
-		tmplData["SSRInnerHTML"] = ssrInnerHTML
-		for key, value := range h.RootTemplateData {
-			tmplData[key] = value
+		if h.GetIsFragmentRequest(r) {
+			served, err := h.serveFragment(w, r, routeData)
+			if err != nil {
+				h.serveInternalError(w, r, pattern, "Error rendering fragment", err)
+				return
+			}
+			if served {
+				return
+			}
+		}
+
+		renderProps, err := h.newRootRenderProps(r, routeData)
+		if err != nil {
+			h.serveInternalError(w, r, pattern, "Error preparing root render", err)
+			return
 		}
 
-		err = tmpl.Execute(w, tmplData)
+		buf := getBuffer()
+		defer putBuffer(buf)
+		_, span := h.startSpan(r.Context(), SpanSerialize, SpanAttribute{Key: "hwy.format", Value: "html"})
+		err = h.getRootRenderer().RenderRoot(buf, renderProps)
+		endSpan(span, err)
 		if err != nil {
-			msg := "Error executing template"
-			Log.Errorf(msg+": %v\n", err)
-			http.Error(w, msg, http.StatusInternalServerError)
+			h.serveInternalError(w, r, pattern, "Error rendering root", err)
+			return
+		}
+		if routeData.Status != 0 {
+			w.WriteHeader(routeData.Status)
 		}
+		w.Write(buf.Bytes())
 	})
+	var rootHandler http.Handler = handler
+	if h.Compression != nil {
+		rootHandler = h.Compression.Middleware(rootHandler)
+	}
+	if h.SecurityHeaders != nil {
+		securityHeaders := *h.SecurityHeaders
+		securityHeaders.assetOrigin = h.getAssetOrigin()
+		securityHeaders.hasWASM = getHasWASMDeps()
+		rootHandler = securityHeaders.Middleware(rootHandler)
+	}
+	return rootHandler
 }
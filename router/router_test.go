@@ -3,9 +3,12 @@ package router
 import (
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -37,42 +40,42 @@ func TestRouter(t *testing.T) {
 
 		// Has expected number of matching paths
 		if len(*matchingPathData.MatchingPaths) != len(path.ExpectedOutput.MatchingPaths) {
-			Log.Errorf("Path: %s", path.Path)
+			t.Logf("Path: %s", path.Path)
 			t.Errorf("Expected %d matching paths, but got %d", len(path.ExpectedOutput.MatchingPaths), len(*matchingPathData.MatchingPaths))
 		}
 
 		for i, matchingPath := range *matchingPathData.MatchingPaths {
 			// Each matching path is of the expected type
 			if matchingPath.PathType != path.ExpectedOutput.MatchingPaths[i] {
-				Log.Errorf("Path: %s", path.Path)
+				t.Logf("Path: %s", path.Path)
 				t.Errorf("Expected matching path %d to be of type %s, but got %s", i, path.ExpectedOutput.MatchingPaths[i], matchingPath.PathType)
 			}
 		}
 
 		// Has expected number of params
 		if len(*matchingPathData.Params) != len(path.ExpectedOutput.Params) {
-			Log.Errorf("Path: %s", path.Path)
+			t.Logf("Path: %s", path.Path)
 			t.Errorf("Expected %d params, but got %d", len(path.ExpectedOutput.Params), len(*matchingPathData.Params))
 		}
 
 		for key, expectedParam := range path.ExpectedOutput.Params {
 			// Each param has the expected value
 			if (*matchingPathData.Params)[key] != expectedParam {
-				Log.Errorf("Path: %s", path.Path)
+				t.Logf("Path: %s", path.Path)
 				t.Errorf("Expected param %s to be %s, but got %s", key, expectedParam, (*matchingPathData.Params)[key])
 			}
 		}
 
 		// Has expected number of splat segments
 		if matchingPathData.SplatSegments != nil && len(*matchingPathData.SplatSegments) != len(path.ExpectedOutput.SplatSegments) {
-			Log.Errorf("Path: %s", path.Path)
+			t.Logf("Path: %s", path.Path)
 			t.Errorf("Expected %d splat segments, but got %d", len(path.ExpectedOutput.SplatSegments), len(*matchingPathData.SplatSegments))
 		}
 
 		for i, expectedSplatSegment := range path.ExpectedOutput.SplatSegments {
 			// Each splat segment has the expected value
 			if (*matchingPathData.SplatSegments)[i] != expectedSplatSegment {
-				Log.Errorf("Path: %s", path.Path)
+				t.Logf("Path: %s", path.Path)
 				t.Errorf("Expected splat segment %d to be %s, but got %s", i, expectedSplatSegment, (*matchingPathData.SplatSegments)[i])
 			}
 		}
@@ -260,7 +263,7 @@ var testPaths = []testPath{
 
 func clean() {
 	os.RemoveAll("../tmp")
-	Log.Infof("removed temporary fixtures")
+	DefaultLogger.Info("removed temporary fixtures")
 }
 
 var filesToMock = []string{
@@ -301,7 +304,7 @@ func testGetMatchingPathData(path string) *ActivePathData {
 	r.URL = &url.URL{}
 	r.URL.Path = path
 	r.Method = "GET"
-	return getMatchingPathData(nil, &r)
+	return Hwy{}.getMatchingPathData(nil, &r, getMatchingPathItem(&r), nil)
 }
 
 func setup() {
@@ -317,7 +320,7 @@ func setup() {
 			panic(err)
 		}
 	}
-	Log.Infof("created temporary fixtures for testing")
+	DefaultLogger.Info("created temporary fixtures for testing")
 
 	// Run the Hwy build
 	err := Build(BuildOptions{
@@ -326,6 +329,36 @@ func setup() {
 		UnhashedOutDir: "../tmp/out",
 		ClientEntryOut: "../tmp/out",
 		ClientEntry:    "../tmp/fixtures/client.entry.tsx",
+		Integrity:      true,
+		BeforeBuild: func(buildID string) error {
+			fixtureBuildHooks = append(fixtureBuildHooks, "before "+buildID)
+			return nil
+		},
+		AfterMetafile: func(pathsFile *PathsFile) error {
+			fixtureBuildHooks = append(fixtureBuildHooks, "metafile "+pathsFile.BuildID)
+			pathsFile.ClientEntryDeps = append(pathsFile.ClientEntryDeps, "augmented.js")
+			return nil
+		},
+		OnArtifact: func(artifact BuildArtifact) error {
+			fixtureBuildHooks = append(fixtureBuildHooks, "artifact "+artifact.Name)
+			fixtureArtifacts = append(fixtureArtifacts, artifact)
+			return nil
+		},
+		AfterBuild: func(result *BuildResult) error {
+			fixtureBuildResult = result
+			return nil
+		},
+		Tasks: []BuildTask{
+			{Name: "before bundle", Run: func(result *BuildResult) error {
+				fixtureTaskResults[0] = result
+				return nil
+			}},
+			{Name: "after bundle", AfterBundle: true, Run: func(result *BuildResult) error {
+				fixtureTaskResults[1] = result
+				_, fixtureTaskPathsFileErr = os.Stat(filepath.Join(result.UnhashedOutDir, "hwy_paths.json"))
+				return nil
+			}},
+		},
 	})
 	if err != nil {
 		panic(err)
@@ -337,6 +370,7 @@ func setup() {
 	if err != nil {
 		panic(err)
 	}
+	fixturePathsFileBytes = pathsFileBytes
 	pathsFileJSON := PathsFile{}
 	err = json.Unmarshal(pathsFileBytes, &pathsFileJSON)
 	if err != nil {
@@ -356,6 +390,33 @@ func setup() {
 		})
 	}
 	instancePaths = &paths
+	instanceIntegrity = pathsFileJSON.Integrity
 
 	// Off to the races!
 }
+
+func TestRouteDataIncludesPatterns(t *testing.T) {
+	r := httptest.NewRequest("GET", "/tiger/123/456", nil)
+	routeData, err := Hwy{}.GetRouteData(httptest.NewRecorder(), r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if routeData.Pattern != "/tiger/$tiger_id/$tiger_cub_id" {
+		t.Errorf("expected deepest pattern /tiger/$tiger_id/$tiger_cub_id, got %s", routeData.Pattern)
+	}
+	expectedPathTypes := []string{PathTypeStaticLayout, PathTypeDynamicLayout, PathTypeDynamicLayout}
+	if !slices.Equal(*routeData.PathTypes, expectedPathTypes) {
+		t.Errorf("expected path types %v, got %v", expectedPathTypes, *routeData.PathTypes)
+	}
+	if len(*routeData.Patterns) != len(*routeData.ImportURLs) {
+		t.Errorf("expected patterns to be parallel to import URLs, got %v", *routeData.Patterns)
+	}
+
+	ssrInnerHTML, err := GetSSRInnerHTML(routeData, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(*ssrInnerHTML), "x.pattern = \"/tiger/$tiger_id/$tiger_cub_id\"") {
+		t.Errorf("expected pattern in SSR globals, got %s", *ssrInnerHTML)
+	}
+}
@@ -0,0 +1,134 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Route, embedded in a struct with a pattern tag, declares the route whose
+// data funcs are that struct's methods (see RegisterRoutes), e.g.:
+//
+//	type TigerRoute struct {
+//		hwy.Route `pattern:"/tiger/$tiger_id"`
+//		DB *sql.DB
+//	}
+//
+//	func (r TigerRoute) Loader(props *hwy.LoaderProps) (Tiger, error) { ... }
+type Route struct{}
+
+var ErrInvalidRouteStruct = errors.New("invalid route struct")
+
+// RouteDataFuncs can be implemented by route structs (see Route) for the
+// data funcs other than Loader, Action, and Head (e.g. Guard or Handle)
+type RouteDataFuncs interface {
+	DataFuncs() DataFuncs
+}
+
+var (
+	routeType       = reflect.TypeOf(Route{})
+	errorType       = reflect.TypeOf((*error)(nil)).Elem()
+	loaderPropsType = reflect.TypeOf((*LoaderProps)(nil))
+	actionPropsType = reflect.TypeOf((*ActionProps)(nil))
+)
+
+// RegisterRoutes returns the DataFuncsMap of route structs (see Route), or
+// pointers to them, bound by the pattern each declares rather than by a key
+// to keep in sync. Methods are discovered by name:
+//   - Loader, taking *LoaderProps and returning any type and an error
+//   - Action, taking *ActionProps and returning any type and an error
+//   - Head, of type Head
+//
+// Loader and Action return types (other than interfaces) are used as
+// LoaderOutput and ActionOutput in TypeScript generation, unless set by
+// DataFuncs (see RouteDataFuncs).
+func RegisterRoutes(routes ...any) (DataFuncsMap, error) {
+	dataFuncsMap := make(DataFuncsMap, len(routes))
+	for _, route := range routes {
+		pattern, dataFuncs, err := getRouteStructDataFuncs(route)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := dataFuncsMap[pattern]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicatePattern, pattern)
+		}
+		dataFuncsMap[pattern] = dataFuncs
+	}
+	return dataFuncsMap, nil
+}
+
+func getRouteStructDataFuncs(route any) (string, DataFuncs, error) {
+	v := reflect.ValueOf(route)
+	if !v.IsValid() || reflect.Indirect(v).Kind() != reflect.Struct {
+		return "", DataFuncs{}, fmt.Errorf("%w: %T is not a struct", ErrInvalidRouteStruct, route)
+	}
+	field, ok := reflect.Indirect(v).Type().FieldByName("Route")
+	if !ok || !field.Anonymous || field.Type != routeType {
+		return "", DataFuncs{}, fmt.Errorf("%w: %T doesn't embed Route", ErrInvalidRouteStruct, route)
+	}
+	pattern := field.Tag.Get("pattern")
+	if pattern == "" {
+		return "", DataFuncs{}, fmt.Errorf("%w: %T has no pattern tag on Route", ErrInvalidRouteStruct, route)
+	}
+
+	var dataFuncs DataFuncs
+	if routeDataFuncs, ok := route.(RouteDataFuncs); ok {
+		dataFuncs = routeDataFuncs.DataFuncs()
+	}
+	if method := v.MethodByName("Loader"); method.IsValid() {
+		call, output, ok := getDataFuncMethod(method, loaderPropsType)
+		if !ok {
+			return "", DataFuncs{}, fmt.Errorf("%w: %T.Loader has signature %s", ErrInvalidRouteStruct, route, method.Type())
+		}
+		dataFuncs.Loader = func(props *LoaderProps) (any, error) {
+			return call(reflect.ValueOf(props))
+		}
+		if dataFuncs.LoaderOutput == nil {
+			dataFuncs.LoaderOutput = output
+		}
+	}
+	if method := v.MethodByName("Action"); method.IsValid() {
+		call, output, ok := getDataFuncMethod(method, actionPropsType)
+		if !ok {
+			return "", DataFuncs{}, fmt.Errorf("%w: %T.Action has signature %s", ErrInvalidRouteStruct, route, method.Type())
+		}
+		dataFuncs.Action = func(props *ActionProps) (any, error) {
+			return call(reflect.ValueOf(props))
+		}
+		if dataFuncs.ActionOutput == nil {
+			dataFuncs.ActionOutput = output
+		}
+	}
+	if method := v.MethodByName("Head"); method.IsValid() {
+		head, ok := method.Interface().(func(*HeadProps) (*[]HeadBlock, error))
+		if !ok {
+			return "", DataFuncs{}, fmt.Errorf("%w: %T.Head has signature %s", ErrInvalidRouteStruct, route, method.Type())
+		}
+		dataFuncs.Head = head
+	}
+	return pattern, dataFuncs, nil
+}
+
+// getDataFuncMethod adapts method, if it takes propsType and returns some T
+// and an error, to return any. It also returns a T (nil when T is an
+// interface) for TypeScript generation.
+func getDataFuncMethod(method reflect.Value, propsType reflect.Type) (func(reflect.Value) (any, error), any, bool) {
+	methodType := method.Type()
+	if methodType.NumIn() != 1 || methodType.In(0) != propsType || methodType.NumOut() != 2 || methodType.Out(1) != errorType {
+		return nil, nil, false
+	}
+	call := func(props reflect.Value) (any, error) {
+		out := method.Call([]reflect.Value{props})
+		err, _ := out[1].Interface().(error)
+		return out[0].Interface(), err
+	}
+	var output any
+	switch outType := methodType.Out(0); outType.Kind() {
+	case reflect.Interface:
+	case reflect.Pointer:
+		output = reflect.New(outType.Elem()).Interface()
+	default:
+		output = reflect.Zero(outType).Interface()
+	}
+	return call, output, true
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"errors"
+	"testing"
+)
+
+type testTiger struct {
+	Name string `json:"name"`
+}
+
+type tigerRoute struct {
+	Route `pattern:"/tiger/$tiger_id"`
+	name  string
+}
+
+func (r tigerRoute) Loader(props *LoaderProps) (testTiger, error) {
+	return testTiger{Name: r.name}, nil
+}
+
+func (r *tigerRoute) Action(props *ActionProps) (*testTiger, error) {
+	return nil, errors.New("no tigers for sale")
+}
+
+func (r tigerRoute) Head(props *HeadProps) (*[]HeadBlock, error) {
+	return &[]HeadBlock{{Title: r.name}}, nil
+}
+
+func (r tigerRoute) DataFuncs() DataFuncs {
+	return DataFuncs{Handle: map[string]any{"breadcrumb": "Tiger"}}
+}
+
+type lionRoute struct {
+	Route `pattern:"/lion"`
+}
+
+func (lionRoute) Loader(props *LoaderProps) (string, int) {
+	return "", 0
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	dataFuncsMap, err := RegisterRoutes(&tigerRoute{name: "Tony"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataFuncs, ok := dataFuncsMap["/tiger/$tiger_id"]
+	if !ok {
+		t.Fatalf("expected the route bound by its pattern, got %v", dataFuncsMap)
+	}
+	data, err := dataFuncs.Loader(&LoaderProps{})
+	if err != nil || data.(testTiger).Name != "Tony" {
+		t.Errorf("expected the Loader method, got %v, %v", data, err)
+	}
+	if _, ok := dataFuncs.LoaderOutput.(testTiger); !ok {
+		t.Errorf("expected the loader's return type as LoaderOutput, got %T", dataFuncs.LoaderOutput)
+	}
+	// Pointer receiver methods are only found through pointers
+	if _, err := dataFuncs.Action(&ActionProps{}); err == nil || dataFuncs.ActionOutput.(*testTiger) == nil {
+		t.Errorf("expected the Action method, got output %v", dataFuncs.ActionOutput)
+	}
+	if blocks, _ := dataFuncs.Head(&HeadProps{}); (*blocks)[0].Title != "Tony" {
+		t.Errorf("expected the Head method, got %v", *blocks)
+	}
+	if dataFuncs.Handle["breadcrumb"] != "Tiger" {
+		t.Error("expected DataFuncs() to supply the rest")
+	}
+
+	for _, routes := range [][]any{
+		{lionRoute{}},
+		{struct{ Route }{}},
+		{testTiger{}},
+		{nil},
+	} {
+		if _, err := RegisterRoutes(routes...); !errors.Is(err, ErrInvalidRouteStruct) {
+			t.Errorf("expected ErrInvalidRouteStruct for %T, got %v", routes[0], err)
+		}
+	}
+	if _, err := RegisterRoutes(tigerRoute{}, &tigerRoute{}); !errors.Is(err, ErrDuplicatePattern) {
+		t.Errorf("expected ErrDuplicatePattern, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package router
+
+import (
+	"net/http"
+	"sync"
+)
+
+// DataProps holds the request data shared by loaders, actions, heads, and guards
+type DataProps struct {
+	Request       *http.Request
+	Params        *map[string]string
+	SplatSegments *[]string
+	Services      *Services
+	Locale        string // only set when Hwy.I18n is configured
+	// Only set when Hwy.ClientHints is configured
+	ClientHints *ClientHints
+	// Per Hwy.ResolveUser, nil when signed out
+	User any
+	// A speculative prefetch, so skip side effects and prefer cheap or
+	// cached data where possible
+	IsPrefetch bool
+
+	scope *requestScope
+}
+
+// requestScope holds everything resolved once per request, before matching
+type requestScope struct {
+	services   *Services
+	locale     string
+	adHocData  map[string]any
+	i18n       *I18nConfig
+	siteOrigin string
+
+	// The request path with any locale prefix removed, before rewrites
+	localeFreePath string
+
+	// Set when Hwy.Experiments is configured
+	experiments *experimentAssignments
+	// Set when Hwy.ClientHints is configured
+	clientHints *ClientHints
+	// Per Hwy.ResolveUser
+	user       any
+	loginRoute string
+
+	// Backs DataProps.Memo
+	memoMu sync.Mutex
+	memo   map[string]*memoEntry
+
+	// Routes whose loaders were served stale data (see DataFuncs.StaleOnError)
+	staleMu       sync.Mutex
+	stalePatterns []string
+
+	// Back DataProps.Fetch
+	fetcher        *Fetcher
+	tracerProvider TracerProvider
+}
+
+func (h Hwy) newRequestScope(r *http.Request, locale string, localeFreePath string) (*requestScope, error) {
+	services, err := h.getRequestServices(r)
+	if err != nil {
+		return nil, err
+	}
+	scope := &requestScope{
+		services:       services,
+		locale:         locale,
+		adHocData:      make(map[string]any),
+		localeFreePath: localeFreePath,
+		i18n:           h.I18n,
+		siteOrigin:     h.SiteOrigin,
+		memo:           make(map[string]*memoEntry),
+		fetcher:        h.Fetcher,
+		tracerProvider: h.TracerProvider,
+	}
+	if locale != "" {
+		scope.adHocData["locale"] = locale
+	}
+	if err := h.resolveUser(r, scope); err != nil {
+		return nil, err
+	}
+	return scope, nil
+}
+
+func (s *requestScope) newDataProps(r *http.Request, params *map[string]string, splatSegments *[]string) DataProps {
+	props := DataProps{
+		Request:       r,
+		Params:        params,
+		SplatSegments: splatSegments,
+	}
+	if r != nil {
+		props.IsPrefetch = GetIsPrefetchRequest(r)
+	}
+	if s != nil {
+		props.Services = s.services
+		props.Locale = s.locale
+		props.ClientHints = s.clientHints
+		props.User = s.user
+		props.scope = s
+	}
+	return props
+}
+
+// getAdHocData returns nil when there is nothing to send
+func (s *requestScope) getAdHocData() *map[string]*any {
+	if s == nil || len(s.adHocData) == 0 {
+		return nil
+	}
+	adHocData := make(map[string]*any, len(s.adHocData))
+	for key, value := range s.adHocData {
+		v := value
+		adHocData[key] = &v
+	}
+	return &adHocData
+}
@@ -0,0 +1,211 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SecurityHeaders configures the headers set by its Middleware (and by the
+// root handler when set as Hwy.SecurityHeaders). The zero value is a sane,
+// strict default.
+type SecurityHeaders struct {
+	// Sent over HTTPS only. Defaults to 2 years; negative disables HSTS.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+
+	// Defaults to "strict-origin-when-cross-origin"
+	ReferrerPolicy string
+
+	// Sets both X-Frame-Options and the CSP frame-ancestors directive.
+	// Defaults to FrameOptionsDeny.
+	FrameOptions FrameOptions
+
+	// The Content-Security-Policy only allows same-origin scripts (which
+	// covers the client entry and route chunks) and inline scripts carrying
+	// the request's nonce (see GetCSPNonce), which Hwy adds to its own. As
+	// Hwy.SecurityHeaders, scripts and styles from the origin of an absolute
+	// Hwy.AssetBasePrefix are allowed too, as is compiling wasm modules
+	// ('wasm-unsafe-eval') if any route imports one.
+	DisableCSP    bool
+	CSPReportOnly bool
+	// Extra script-src sources, e.g. "https://cdn.example.com"
+	ScriptSources []string
+	// Adds or replaces whole directives, e.g. {"img-src": "'self' https:"}.
+	// An empty value removes the directive.
+	CSPDirectives map[string]string
+
+	// Origin of Hwy.AssetBasePrefix, if absolute
+	assetOrigin string
+	// Whether any route depends on a wasm module, which browsers only
+	// compile with 'wasm-unsafe-eval'
+	hasWASM bool
+}
+
+type FrameOptions string
+
+const (
+	FrameOptionsDeny       FrameOptions = "DENY"
+	FrameOptionsSameOrigin FrameOptions = "SAMEORIGIN"
+)
+
+type cspNonceKey struct{}
+
+// GetCSPNonce returns the request's CSP nonce, or "" if the security headers
+// middleware didn't handle it (or CSP is disabled)
+func GetCSPNonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(cspNonceKey{}).(string)
+	return nonce
+}
+
+func (s SecurityHeaders) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("Referrer-Policy", s.getReferrerPolicy())
+		header.Set("X-Frame-Options", string(s.getFrameOptions()))
+		if hsts := s.getHSTS(); hsts != "" && getIsHTTPS(r) {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		if !s.DisableCSP {
+			nonce, err := newCSPNonce()
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			cspHeader := "Content-Security-Policy"
+			if s.CSPReportOnly {
+				cspHeader = "Content-Security-Policy-Report-Only"
+			}
+			header.Set(cspHeader, s.getCSP(nonce))
+			r = r.WithContext(context.WithValue(r.Context(), cspNonceKey{}, nonce))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s SecurityHeaders) getReferrerPolicy() string {
+	if s.ReferrerPolicy != "" {
+		return s.ReferrerPolicy
+	}
+	return "strict-origin-when-cross-origin"
+}
+
+func (s SecurityHeaders) getFrameOptions() FrameOptions {
+	if s.FrameOptions != "" {
+		return s.FrameOptions
+	}
+	return FrameOptionsDeny
+}
+
+func (s SecurityHeaders) getHSTS() string {
+	maxAge := s.HSTSMaxAge
+	if maxAge < 0 {
+		return ""
+	}
+	if maxAge == 0 {
+		maxAge = 2 * 365 * 24 * time.Hour
+	}
+	hsts := fmt.Sprintf("max-age=%d", int64(maxAge.Seconds()))
+	if s.HSTSIncludeSubdomains {
+		hsts += "; includeSubDomains"
+	}
+	if s.HSTSPreload {
+		hsts += "; preload"
+	}
+	return hsts
+}
+
+func (s SecurityHeaders) getCSP(nonce string) string {
+	frameAncestors := "'none'"
+	if s.getFrameOptions() == FrameOptionsSameOrigin {
+		frameAncestors = "'self'"
+	}
+	scriptSrc := []string{"'self'", "'nonce-" + nonce + "'"}
+	styleSrc := []string{"'self'", "'unsafe-inline'"}
+	if s.assetOrigin != "" {
+		scriptSrc = append(scriptSrc, s.assetOrigin)
+		styleSrc = append(styleSrc, s.assetOrigin)
+	}
+	if s.hasWASM {
+		scriptSrc = append(scriptSrc, "'wasm-unsafe-eval'")
+	}
+	scriptSrc = append(scriptSrc, s.ScriptSources...)
+	directives := map[string]string{
+		"default-src":     "'self'",
+		"script-src":      strings.Join(scriptSrc, " "),
+		"style-src":       strings.Join(styleSrc, " "),
+		"img-src":         "'self' data:",
+		"object-src":      "'none'",
+		"base-uri":        "'self'",
+		"form-action":     "'self'",
+		"frame-ancestors": frameAncestors,
+	}
+	for name, value := range s.CSPDirectives {
+		if value == "" {
+			delete(directives, name)
+		} else {
+			directives[name] = value
+		}
+	}
+	names := getSortedKeys(directives)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + " " + directives[name]
+	}
+	return strings.Join(parts, "; ")
+}
+
+// getHasWASMDeps reports whether the client entry or any route (of any
+// tenant) depends on a wasm module
+func getHasWASMDeps() bool {
+	hasWASM := func(deps *[]string) bool {
+		return deps != nil && slices.ContainsFunc(*deps, func(dep string) bool { return strings.HasSuffix(dep, ".wasm") })
+	}
+	if hasWASM(instanceClientEntryDeps) {
+		return true
+	}
+	allPaths := []*[]Path{instancePaths}
+	for _, paths := range instanceTenantPaths {
+		allPaths = append(allPaths, paths)
+	}
+	for _, paths := range allPaths {
+		if paths == nil {
+			continue
+		}
+		for _, path := range *paths {
+			if hasWASM(path.Deps) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withNonceAttribute copies block, leaving the route's own attributes alone
+func withNonceAttribute(block *HeadBlock, nonce string) *HeadBlock {
+	attributes := make(map[string]string, len(block.Attributes)+1)
+	for key, value := range block.Attributes {
+		attributes[key] = value
+	}
+	attributes["nonce"] = nonce
+	return &HeadBlock{Tag: block.Tag, Attributes: attributes, Title: block.Title}
+}
+
+func getIsHTTPS(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+func newCSPNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
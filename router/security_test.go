@@ -0,0 +1,142 @@
+package router
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	var nonce string
+	handler := SecurityHeaders{
+		HSTSMaxAge:    time.Hour,
+		HSTSPreload:   true,
+		FrameOptions:  FrameOptionsSameOrigin,
+		ScriptSources: []string{"https://cdn.example.com"},
+		CSPDirectives: map[string]string{"img-src": "'self' https:", "form-action": ""},
+	}.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = GetCSPNonce(r)
+	}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if nonce == "" {
+		t.Fatal("expected a CSP nonce on the request")
+	}
+	expected := map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+		"X-Frame-Options":           "SAMEORIGIN",
+		"Strict-Transport-Security": "max-age=3600; preload",
+		"Content-Security-Policy": "base-uri 'self'; default-src 'self'; frame-ancestors 'self'; " +
+			"img-src 'self' https:; object-src 'none'; " +
+			"script-src 'self' 'nonce-" + nonce + "' https://cdn.example.com; style-src 'self' 'unsafe-inline'",
+	}
+	for key, value := range expected {
+		if got := w.Header().Get(key); got != value {
+			t.Errorf("expected %s %q, got %q", key, value, got)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("expected no HSTS over plain HTTP, got %q", got)
+	}
+}
+
+func TestNonceOnHwyScripts(t *testing.T) {
+	routeData := &GetRouteDataOutput{
+		Title:          "Nonce",
+		MetaHeadBlocks: &[]*HeadBlock{},
+		RestHeadBlocks: &[]*HeadBlock{{Tag: "script", Attributes: map[string]string{"src": "/a.js"}}},
+		nonce:          "abc123",
+	}
+	headElements, err := GetHeadElements(routeData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(*headElements), `nonce="abc123"`) {
+		t.Errorf("expected nonce on head script, got %s", *headElements)
+	}
+	if _, ok := (*routeData.RestHeadBlocks)[0].Attributes["nonce"]; ok {
+		t.Error("expected route's head block attributes not to be mutated")
+	}
+
+	ssrInnerHTML, err := GetSSRInnerHTML(routeData, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(*ssrInnerHTML), `<script nonce="abc123">`) {
+		t.Errorf("expected nonce on SSR script, got %.40s", *ssrInnerHTML)
+	}
+}
+
+func TestCSPAllowsAssetOrigin(t *testing.T) {
+	for _, c := range []struct {
+		assetBasePrefix string
+		origin          string
+	}{
+		{"https://cdn.example.com/builds/{buildID}", "https://cdn.example.com"},
+		{"//cdn.example.com", "cdn.example.com"},
+		{"/assets/", ""},
+	} {
+		h := Hwy{
+			AssetBasePrefix: c.assetBasePrefix,
+			SecurityHeaders: &SecurityHeaders{},
+			RootRenderer:    HTMLTemplateRenderer{Template: template.Must(template.New("root").Parse(`<div id="root"></div>`))},
+		}
+		w := httptest.NewRecorder()
+		h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/lion", nil))
+		csp := w.Header().Get("Content-Security-Policy")
+		for _, directive := range []string{"script-src", "style-src"} {
+			var sources string
+			for _, part := range strings.Split(csp, "; ") {
+				if after, ok := strings.CutPrefix(part, directive+" "); ok {
+					sources = after
+				}
+			}
+			fields := strings.Fields(sources)
+			if c.origin == "" && len(fields) != 2 {
+				t.Errorf("%s: expected %s to allow no asset origin, got %q", c.assetBasePrefix, directive, sources)
+			}
+			if c.origin != "" && !slices.Contains(fields, c.origin) {
+				t.Errorf("%s: expected %s to allow %q, got %q", c.assetBasePrefix, directive, c.origin, sources)
+			}
+		}
+	}
+}
+
+func TestCSPAllowsWASM(t *testing.T) {
+	withRouteTable(t, []string{"lion.ui.tsx"})
+	h := Hwy{
+		SecurityHeaders: &SecurityHeaders{},
+		RootRenderer:    HTMLTemplateRenderer{Template: template.Must(template.New("root").Parse(`<div id="root"></div>`))},
+	}
+	getScriptSrc := func() []string {
+		w := httptest.NewRecorder()
+		h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/lion", nil))
+		for _, part := range strings.Split(w.Header().Get("Content-Security-Policy"), "; ") {
+			if after, ok := strings.CutPrefix(part, "script-src "); ok {
+				return strings.Fields(after)
+			}
+		}
+		t.Fatal("expected a script-src directive")
+		return nil
+	}
+
+	if sources := getScriptSrc(); slices.Contains(sources, "'wasm-unsafe-eval'") {
+		t.Errorf("expected no 'wasm-unsafe-eval' without wasm deps, got %v", sources)
+	}
+	(*instancePaths)[0].Deps = &[]string{"hwy_chunk__abc.js", "hwy_asset__def.wasm"}
+	if sources := getScriptSrc(); !slices.Contains(sources, "'wasm-unsafe-eval'") {
+		t.Errorf("expected 'wasm-unsafe-eval' with a wasm dep, got %v", sources)
+	}
+}
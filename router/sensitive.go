@@ -0,0 +1,89 @@
+package router
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// Loader data struct fields tagged `hwy:"ssr-only"` are left out of the
+// client payload (JSON responses and the hydration script), while fragments
+// and the ComponentRenderer still get them. Fields tagged `hwy:"encrypted"`
+// are sent as the base64 of a 12-byte nonce followed by the AES-GCM
+// encryption of their JSON, with the key from Hwy.PayloadKey, for user code
+// holding the same key (e.g. via WebCrypto) to decrypt.
+var clientPayloadFields = &taggedFields{options: []string{"ssr-only", "encrypted"}}
+
+var ErrMissingPayloadKey = errors.New("loader data has encrypted fields but no Hwy.PayloadKey")
+
+// getClientLoadersData returns loadersData as sent to the client, copied only
+// if any of it holds ssr-only or encrypted fields
+func (h Hwy) getClientLoadersData(r *http.Request, loadersData *[]any) (*[]any, error) {
+	if loadersData == nil {
+		return nil, nil
+	}
+	var key []byte
+	var err error
+	rewriteField := func(option string, v reflect.Value) (any, bool) {
+		if option == "ssr-only" || err != nil {
+			return nil, false
+		}
+		if key == nil {
+			if h.PayloadKey == nil {
+				err = ErrMissingPayloadKey
+				return nil, false
+			}
+			if key, err = h.PayloadKey(r); err != nil {
+				return nil, false
+			}
+		}
+		var ciphertext string
+		ciphertext, err = h.encryptPayloadField(key, v.Interface())
+		return ciphertext, err == nil
+	}
+
+	clientLoadersData := loadersData
+	for i, data := range *loadersData {
+		if data == nil || getRawResponse(data) != nil {
+			continue
+		}
+		if !clientPayloadFields.getHasTaggedFields(reflect.TypeOf(data)) {
+			continue
+		}
+		rewritten := clientPayloadFields.rewrite(data, rewriteField)
+		if err != nil {
+			return nil, err
+		}
+		if clientLoadersData == loadersData {
+			copied := make([]any, len(*loadersData))
+			copy(copied, *loadersData)
+			clientLoadersData = &copied
+		}
+		(*clientLoadersData)[i] = rewritten
+	}
+	return clientLoadersData, nil
+}
+
+func (h Hwy) encryptPayloadField(key []byte, value any) (string, error) {
+	plaintext, err := h.marshalJSON(value)
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, nil)), nil
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type sensitiveTestProfile struct {
+	Name    string `json:"name"`
+	Address string `json:"address" hwy:"ssr-only"`
+	Email   string `json:"email" hwy:"encrypted"`
+}
+
+func TestSensitiveLoaderFields(t *testing.T) {
+	profile := sensitiveTestProfile{Name: "Leo", Address: "1 Savanna Way", Email: "leo@example.com"}
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return profile, nil
+		},
+	})
+	key := []byte("0123456789abcdef0123456789abcdef")
+	h := Hwy{PayloadKey: func(r *http.Request) ([]byte, error) { return key, nil }}
+
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/sensitive-test", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadersData := *routeData.LoadersData
+	clientData, ok := loadersData[len(loadersData)-1].(map[string]any)
+	if !ok {
+		t.Fatalf("expected rewritten loader data, got %#v", loadersData[len(loadersData)-1])
+	}
+	if _, ok := clientData["address"]; ok || clientData["name"] != "Leo" {
+		t.Errorf("expected the ssr-only address left out, got %v", clientData)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(clientData["email"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil || string(plaintext) != `"leo@example.com"` {
+		t.Errorf("expected the encrypted email to decrypt, got %q (%v)", plaintext, err)
+	}
+
+	// The server side keeps everything
+	serverData := *routeData.activePathData.LoadersData
+	if serverData[len(serverData)-1] != profile {
+		t.Errorf("expected the full loader data server side, got %#v", serverData[len(serverData)-1])
+	}
+
+	_, err = Hwy{}.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/sensitive-test-no-key", nil))
+	if !errors.Is(err, ErrMissingPayloadKey) {
+		t.Errorf("expected ErrMissingPayloadKey, got %v", err)
+	}
+}
+
+func TestSensitiveLoaderFieldsAfterRedaction(t *testing.T) {
+	type account struct {
+		ID       string `json:"id"`
+		Password string `json:"password" hwy:"server-only"`
+		Address  string `json:"address" hwy:"ssr-only"`
+	}
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return account{ID: "1", Password: "hunter2", Address: "1 Savanna Way"}, nil
+		},
+	})
+	h := Hwy{LoaderDataTransforms: []LoaderDataTransform{RedactServerOnly}}
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/sensitive-redacted-test", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadersData := *routeData.LoadersData
+	clientData := loadersData[len(loadersData)-1].(map[string]any)
+	if len(clientData) != 1 || clientData["id"] != "1" {
+		t.Errorf("expected only the id sent to the client, got %v", clientData)
+	}
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+type KeyCase string
+
+const (
+	// e.g. "importURLs" (default)
+	KeyCaseCamel KeyCase = "camel"
+	// e.g. "import_urls"
+	KeyCaseSnake KeyCase = "snake"
+)
+
+// Serialization shapes the top-level keys of JSON responses (those of
+// GetRouteDataOutput, not those within loader data)
+type Serialization struct {
+	// Leaves out keys whose values are null or empty arrays or objects (e.g.
+	// splatSegments and params on routes without any), rather than sending
+	// them. Zero numbers, false, and empty strings are still sent.
+	OmitEmpty bool
+	KeyCase   KeyCase
+	// Keys (in camelCase, e.g. "deps" or "pathTypes") the client never reads,
+	// left out of the payload
+	OmitFields []string
+}
+
+// reshape applies s to jsonBytes, an encoded GetRouteDataOutput
+func (s *Serialization) reshape(jsonBytes []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		return nil, err
+	}
+	reshaped := make(map[string]json.RawMessage, len(fields))
+	for key, value := range fields {
+		if slices.Contains(s.OmitFields, key) || (s.OmitEmpty && getIsEmptyJSON(value)) {
+			continue
+		}
+		if s.KeyCase == KeyCaseSnake {
+			key = toSnakeCase(key)
+		}
+		reshaped[key] = value
+	}
+	return json.Marshal(reshaped)
+}
+
+func getIsEmptyJSON(value json.RawMessage) bool {
+	switch string(value) {
+	case "null", "[]", "{}":
+		return true
+	}
+	return false
+}
+
+// toSnakeCase converts a camelCase key, keeping acronyms (including plural
+// ones) together: "importURLs" becomes "import_urls", "buildID" "build_id"
+func toSnakeCase(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			startsWord := unicode.IsLower(prev) || unicode.IsDigit(prev)
+			// The last capital of an acronym starts a new word ("HTMLAttributes")
+			// unless followed only by a plural "s" ("URLs")
+			if unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				isPlural := runes[i+1] == 's' && (i+2 == len(runes) || unicode.IsUpper(runes[i+2]))
+				startsWord = !isPlural
+			}
+			if startsWord {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
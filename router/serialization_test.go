@@ -0,0 +1,57 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestSerialization(t *testing.T) {
+	get := func(serialization *Serialization) map[string]any {
+		h := Hwy{Serialization: serialization}
+		r := httptest.NewRequest("GET", "/lion?"+HwyPrefix+"json=1", nil)
+		w := httptest.NewRecorder()
+		h.GetRootHandler().ServeHTTP(w, r)
+		var body map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	body := get(nil)
+	if value, ok := body["splatSegments"]; !ok || value != nil {
+		t.Errorf("expected null splatSegments by default, got %v", value)
+	}
+
+	body = get(&Serialization{OmitEmpty: true, KeyCase: KeyCaseSnake, OmitFields: []string{"deps"}})
+	for _, key := range []string{"splat_segments", "splatSegments", "deps", "params"} {
+		if _, ok := body[key]; ok {
+			t.Errorf("expected no %q key", key)
+		}
+	}
+	for _, key := range []string{"import_urls", "build_id", "outermost_error_boundary_index", "loaders_data"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("expected a %q key, got %v", key, body)
+		}
+	}
+	if patterns, _ := body["patterns"].([]any); !slices.Contains(patterns, any("/lion")) {
+		t.Errorf("expected values to be left as is, got %v", body["patterns"])
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	for key, expected := range map[string]string{
+		"importURLs":                  "import_urls",
+		"buildID":                     "build_id",
+		"outermostErrorBoundaryIndex": "outermost_error_boundary_index",
+		"HTMLAttributes":              "html_attributes",
+		"cssBundles":                  "css_bundles",
+		"pattern":                     "pattern",
+	} {
+		if got := toSnakeCase(key); got != expected {
+			t.Errorf("%s: expected %q, got %q", key, expected, got)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package router
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// Services is a type-keyed bag of values (DB pools, auth clients, loggers, etc.)
+// made available to loaders, actions, and heads via their props. Values are
+// keyed by their static type, so use distinct named types when you need more
+// than one value of the same underlying type.
+type Services struct {
+	mu     sync.RWMutex
+	values map[reflect.Type]any
+}
+
+// ServicesHook runs once per request, before any loaders, and may add
+// request-scoped values to the (already cloned) services bag.
+type ServicesHook func(r *http.Request, services *Services) error
+
+func NewServices() *Services {
+	return &Services{values: make(map[reflect.Type]any)}
+}
+
+// ProvideService stores value in the bag under type T, replacing any
+// previous value of the same type.
+func ProvideService[T any](s *Services, value T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[reflect.TypeFor[T]()] = value
+}
+
+// GetService returns the value stored under type T, if any.
+func GetService[T any](s *Services) (T, bool) {
+	var zero T
+	if s == nil {
+		return zero, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[reflect.TypeFor[T]()]
+	if !ok {
+		return zero, false
+	}
+	return value.(T), true
+}
+
+func (s *Services) clone() *Services {
+	cloned := NewServices()
+	if s == nil {
+		return cloned
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for k, v := range s.values {
+		cloned.values[k] = v
+	}
+	return cloned
+}
+
+func (h Hwy) getRequestServices(r *http.Request) (*Services, error) {
+	services := h.Services.clone()
+	if h.ServicesHook != nil {
+		if err := h.ServicesHook(r, services); err != nil {
+			return nil, err
+		}
+	}
+	return services, nil
+}
@@ -0,0 +1,40 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+)
+
+type testDB struct{ name string }
+type testRequestID string
+
+func TestRequestServices(t *testing.T) {
+	base := NewServices()
+	ProvideService(base, &testDB{name: "pool"})
+
+	h := Hwy{
+		Services: base,
+		ServicesHook: func(r *http.Request, services *Services) error {
+			ProvideService(services, testRequestID(r.Header.Get("X-Request-ID")))
+			return nil
+		},
+	}
+
+	r := &http.Request{Header: http.Header{"X-Request-Id": []string{"abc"}}}
+	services, err := h.getRequestServices(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	db, ok := GetService[*testDB](services)
+	if !ok || db.name != "pool" {
+		t.Errorf("expected app-wide service to be available per request")
+	}
+	requestID, ok := GetService[testRequestID](services)
+	if !ok || requestID != "abc" {
+		t.Errorf("expected request-scoped service to be abc, got %q", requestID)
+	}
+	if _, ok := GetService[testRequestID](base); ok {
+		t.Errorf("expected request-scoped service not to leak into app-wide services")
+	}
+}
@@ -0,0 +1,162 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ServiceWorkerFileName is written to BuildOptions.UnhashedOutDir when
+// BuildOptions.ServiceWorker is set, and served at ServiceWorkerPath by the
+// root handler when Hwy.ServiceWorker is set
+const ServiceWorkerFileName = "hwy_sw.js"
+
+// ServiceWorkerPath is served at the root so the worker's scope covers the
+// whole site
+const ServiceWorkerPath = "/" + ServiceWorkerFileName
+
+// writeServiceWorker writes a service worker that precaches every built
+// script and stylesheet for buildID
+func writeServiceWorker(unhashedOutDir, hashedOutDir string, clientEntryFileNames []string, buildID, prefix string) error {
+	entries, err := os.ReadDir(hashedOutDir)
+	if err != nil {
+		return err
+	}
+	precache := make([]string, 0, len(entries)+len(clientEntryFileNames))
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".js" || ext == ".css" || ext == ".wasm") {
+			precache = append(precache, entry.Name())
+		}
+	}
+	for _, fileName := range clientEntryFileNames {
+		if !slices.Contains(precache, fileName) {
+			precache = append(precache, fileName)
+		}
+	}
+	config, err := json.Marshal(map[string]any{
+		"buildID":   buildID,
+		"precache":  precache,
+		"jsonParam": prefix + "json",
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(unhashedOutDir, ServiceWorkerFileName), []byte(fmt.Sprintf(serviceWorkerJS, config)), os.ModePerm)
+}
+
+// Assets are cache-first, as their names are content hashed. Route data JSON
+// is stale-while-revalidate, and documents are network-first, so both still
+// work offline once visited. Caches from previous builds are dropped when
+// the new worker activates.
+const serviceWorkerJS = `// Generated by Hwy. Do not edit.
+const config = %s;
+const base = new URL(new URL(location.href).searchParams.get("base") || "/public/", location.origin);
+const assetCache = "hwy-assets-" + config.buildID;
+const dataCache = "hwy-data-" + config.buildID;
+const precacheURLs = new Set(config.precache.map((file) => new URL(file, base).href));
+
+self.addEventListener("install", (event) => {
+	event.waitUntil(caches.open(assetCache).then((cache) => cache.addAll([...precacheURLs])).then(() => self.skipWaiting()));
+});
+
+self.addEventListener("activate", (event) => {
+	event.waitUntil(
+		caches.keys()
+			.then((keys) => Promise.all(keys.filter((key) => key.startsWith("hwy-") && key !== assetCache && key !== dataCache).map((key) => caches.delete(key))))
+			.then(() => self.clients.claim()),
+	);
+});
+
+self.addEventListener("fetch", (event) => {
+	const request = event.request;
+	if (request.method !== "GET") {
+		return;
+	}
+	const url = new URL(request.url);
+	if (precacheURLs.has(url.href)) {
+		event.respondWith(caches.match(request).then((cached) => cached || fetch(request)));
+	} else if (url.origin === location.origin && url.searchParams.has(config.jsonParam)) {
+		event.respondWith(staleWhileRevalidate(event, request));
+	} else if (request.mode === "navigate") {
+		event.respondWith(networkFirst(request));
+	}
+});
+
+async function staleWhileRevalidate(event, request) {
+	const cache = await caches.open(dataCache);
+	const cached = await cache.match(request);
+	const fresh = fetch(request).then((response) => {
+		if (response.ok) {
+			cache.put(request, response.clone());
+		}
+		return response;
+	});
+	if (cached) {
+		event.waitUntil(fresh.catch(() => {}));
+		return cached;
+	}
+	return fresh;
+}
+
+async function networkFirst(request) {
+	const cache = await caches.open(dataCache);
+	try {
+		const response = await fetch(request);
+		if (response.ok) {
+			cache.put(request, response.clone());
+		}
+		return response;
+	} catch (err) {
+		const cached = await cache.match(request);
+		if (cached) {
+			return cached;
+		}
+		throw err;
+	}
+}
+`
+
+// serveServiceWorker serves the worker written by Build from FS, uncached so
+// new builds are picked up promptly
+func (h Hwy) serveServiceWorker(w http.ResponseWriter, r *http.Request) {
+	content, err := fs.ReadFile(h.FS, ServiceWorkerFileName)
+	if err != nil {
+		h.getLogger().Error("error reading service worker (was it built with BuildOptions.ServiceWorker?)", "error", err)
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(content)
+}
+
+var serviceWorkerScriptTmpl = template.Must(template.New("sw").Parse(
+	`<script{{if .Nonce}} nonce="{{.Nonce}}"{{end}}>if ("serviceWorker" in navigator) navigator.serviceWorker.register({{.URL}});</script>`,
+))
+
+// getServiceWorkerScript returns the registration script, or "" unless
+// Hwy.ServiceWorker is set. The worker is told where assets are served from.
+func (h Hwy) getServiceWorkerScript(nonce string) (template.HTML, error) {
+	if !h.ServiceWorker {
+		return "", nil
+	}
+	base := strings.TrimSuffix(h.GetAssetURL(ClientEntryFileName), ClientEntryFileName)
+	buf := getBuffer()
+	defer putBuffer(buf)
+	err := serviceWorkerScriptTmpl.Execute(buf, map[string]string{
+		"URL":   ServiceWorkerPath + "?base=" + url.QueryEscape(base),
+		"Nonce": nonce,
+	})
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
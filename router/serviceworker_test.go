@@ -0,0 +1,61 @@
+package router
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWriteServiceWorker(t *testing.T) {
+	unhashedOutDir, hashedOutDir := t.TempDir(), t.TempDir()
+	for _, name := range []string{"hwy_entry__a.js", "hwy_chunk__b.js", "hwy_entry__a.css", "hwy_entry__a.js.map"} {
+		if err := os.WriteFile(filepath.Join(hashedOutDir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writeServiceWorker(unhashedOutDir, hashedOutDir, []string{ClientEntryFileName}, "1234", HwyPrefix); err != nil {
+		t.Fatal(err)
+	}
+	sw, err := os.ReadFile(filepath.Join(unhashedOutDir, ServiceWorkerFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"precache":["hwy_chunk__b.js","hwy_entry__a.css","hwy_entry__a.js","hwy_client_entry.js"]`
+	if !strings.Contains(string(sw), want) || !strings.Contains(string(sw), `"buildID":"1234"`) {
+		t.Errorf("unexpected service worker config:\n%s", strings.SplitN(string(sw), "\n", 3)[1])
+	}
+}
+
+func TestServiceWorker(t *testing.T) {
+	tmpl := template.Must(template.New("root").Parse(`{{.ServiceWorkerScript}}`))
+	h := Hwy{
+		ServiceWorker: true,
+		FS:            fstest.MapFS{ServiceWorkerFileName: {Data: []byte("// sw")}},
+		RootRenderer:  HTMLTemplateRenderer{Template: tmpl},
+	}
+
+	w := httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", ServiceWorkerPath, nil))
+	if w.Code != 200 || w.Body.String() != "// sw" || w.Header().Get("Cache-Control") != "no-cache" ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "text/javascript") {
+		t.Errorf("unexpected service worker response: %d %v %s", w.Code, w.Header(), w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/lion/service-worker", nil))
+	want := `<script>if ("serviceWorker" in navigator) navigator.serviceWorker.register("/hwy_sw.js?base=%2Fpublic%2F");</script>`
+	if w.Body.String() != want {
+		t.Errorf("got %s, want %s", w.Body, want)
+	}
+
+	h.ServiceWorker = false
+	w = httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/lion/no-service-worker", nil))
+	if w.Body.Len() != 0 {
+		t.Errorf("expected no registration script, got %s", w.Body)
+	}
+}
@@ -0,0 +1,112 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+var ErrShuttingDown = errors.New("hwy is shutting down")
+
+// lifecycle tracks in-flight renders and background work, so Shutdown can
+// wait for them
+type lifecycle struct {
+	mu           sync.Mutex
+	shuttingDown bool
+	stopping     chan struct{} // closed once shutting down
+	inFlight     sync.WaitGroup
+	// Canceled when Shutdown's deadline passes, which cancels the contexts
+	// of in-flight loaders and background work
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newLifecycle() *lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &lifecycle{stopping: make(chan struct{}), ctx: ctx, cancel: cancel}
+}
+
+var instanceLifecycle = newLifecycle()
+
+// begin returns false once shutting down. Otherwise, call done when finished.
+func (l *lifecycle) begin() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.shuttingDown {
+		return false
+	}
+	l.inFlight.Add(1)
+	return true
+}
+
+func (l *lifecycle) done() {
+	l.inFlight.Done()
+}
+
+// withContext returns r with a context that is also canceled when l's is
+func (l *lifecycle) withContext(r *http.Request) (*http.Request, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(r.Context())
+	stop := context.AfterFunc(l.ctx, cancel)
+	return r.WithContext(ctx), func() {
+		stop()
+		cancel()
+	}
+}
+
+// GoBackground runs fn in its own goroutine, tracked by Shutdown, which
+// waits for it to return and cancels its context if the deadline passes.
+// It returns ErrShuttingDown (without running fn) once shutting down.
+func (h Hwy) GoBackground(fn func(ctx context.Context)) error {
+	l := instanceLifecycle
+	if !l.begin() {
+		return ErrShuttingDown
+	}
+	go func() {
+		defer l.done()
+		fn(l.ctx)
+	}()
+	return nil
+}
+
+// Shutdown stops accepting new renders (the root handler answers 503) and
+// scheduling job runs, then waits for in-flight renders, job runs, and
+// background work to finish. If ctx is done first, their contexts are
+// canceled and ctx's error is returned. Call it after (or alongside)
+// http.Server.Shutdown.
+func (h Hwy) Shutdown(ctx context.Context) error {
+	l := instanceLifecycle
+	l.mu.Lock()
+	if !l.shuttingDown {
+		l.shuttingDown = true
+		close(l.stopping)
+	}
+	l.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		l.inFlight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		l.cancel()
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
+
+// GetIsShuttingDown reports whether Shutdown has been called
+func (h Hwy) GetIsShuttingDown() bool {
+	l := instanceLifecycle
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.shuttingDown
+}
+
+func serveShuttingDown(w http.ResponseWriter) {
+	w.Header().Set("Connection", "close")
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
@@ -0,0 +1,109 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func withTestLifecycle(t *testing.T) {
+	prev := instanceLifecycle
+	instanceLifecycle = newLifecycle()
+	t.Cleanup(func() { instanceLifecycle = prev })
+}
+
+func TestShutdownDrainsInFlightRenders(t *testing.T) {
+	withTestLifecycle(t)
+	l := instanceLifecycle
+	started, stopping, release := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			close(started)
+			// Tell the test once the in-flight render sees the shutdown
+			<-l.stopping
+			close(stopping)
+			<-release
+			return "done", nil
+		},
+	})
+
+	h := Hwy{}
+	w := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		h.GetRootHandler().ServeHTTP(w, httptest.NewRequest("GET", "/lion/shutdown-drain?"+HwyPrefix+"json=1", nil))
+		close(served)
+	}()
+	<-started
+
+	shutdownErr := make(chan error)
+	go func() { shutdownErr <- h.Shutdown(context.Background()) }()
+	<-stopping
+	if !h.GetIsShuttingDown() {
+		t.Error("expected to be shutting down")
+	}
+
+	w2 := httptest.NewRecorder()
+	h.GetRootHandler().ServeHTTP(w2, httptest.NewRequest("GET", "/lion/shutdown-new", nil))
+	if w2.Code != 503 {
+		t.Errorf("expected new render to get 503, got %d", w2.Code)
+	}
+
+	close(release)
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("expected clean shutdown, got %v", err)
+	}
+	<-served
+	if w.Code != 200 {
+		t.Errorf("expected in-flight render to finish, got %d", w.Code)
+	}
+}
+
+func TestShutdownCancelsPastDeadline(t *testing.T) {
+	withTestLifecycle(t)
+	started := make(chan struct{})
+	var loaderErr error
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			close(started)
+			<-props.Request.Context().Done()
+			loaderErr = props.Request.Context().Err()
+			return nil, loaderErr
+		},
+	})
+
+	h := Hwy{}
+	backgroundErr := make(chan error, 1)
+	if err := h.GoBackground(func(ctx context.Context) {
+		<-ctx.Done()
+		backgroundErr <- ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	served := make(chan struct{})
+	go func() {
+		h.GetRootHandler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/shutdown-deadline?"+HwyPrefix+"json=1", nil))
+		close(served)
+	}()
+	<-started
+
+	// Already past its deadline, so in-flight work is canceled right away
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	if err := h.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	<-served
+	if !errors.Is(loaderErr, context.Canceled) {
+		t.Errorf("expected loader context canceled, got %v", loaderErr)
+	}
+
+	if err := <-backgroundErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected background context canceled, got %v", err)
+	}
+	if err := h.GoBackground(func(context.Context) {}); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PathsSnapshotEntry is one route in a paths snapshot
+type PathsSnapshotEntry struct {
+	Pattern  string `json:"pattern"`
+	PathType string `json:"pathType"`
+}
+
+// GetPathsSnapshot returns the routes Build would generate from pagesSrcDir,
+// sorted by pattern
+func GetPathsSnapshot(pagesSrcDir string) []PathsSnapshotEntry {
+	paths := walkPages(pagesSrcDir)
+	entries := make([]PathsSnapshotEntry, 0, len(paths))
+	for _, path := range paths {
+		entries = append(entries, PathsSnapshotEntry{Pattern: path.Pattern, PathType: path.PathType})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Pattern < entries[j].Pattern
+	})
+	return entries
+}
+
+// WritePathsSnapshot writes the current routes of pagesSrcDir to snapshotFile
+func WritePathsSnapshot(pagesSrcDir string, snapshotFile string) error {
+	snapshotBytes, err := json.MarshalIndent(GetPathsSnapshot(pagesSrcDir), "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(snapshotFile), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(snapshotFile, append(snapshotBytes, '\n'), 0644)
+}
+
+// CheckPathsSnapshot compares the current routes of pagesSrcDir against the
+// checked-in snapshotFile, returning an error listing every route added,
+// removed, or changed in type (nil when they are the same)
+func CheckPathsSnapshot(pagesSrcDir string, snapshotFile string) error {
+	snapshotBytes, err := os.ReadFile(snapshotFile)
+	if err != nil {
+		return err
+	}
+	var expected []PathsSnapshotEntry
+	if err := json.Unmarshal(snapshotBytes, &expected); err != nil {
+		return fmt.Errorf("reading paths snapshot %s: %w", snapshotFile, err)
+	}
+	changes := DiffPathsSnapshots(expected, GetPathsSnapshot(pagesSrcDir))
+	if len(changes) == 0 {
+		return nil
+	}
+	return errors.New("routes differ from snapshot " + snapshotFile + ":\n  " + strings.Join(changes, "\n  "))
+}
+
+// DiffPathsSnapshots returns one readable line per difference, sorted by pattern
+func DiffPathsSnapshots(expected []PathsSnapshotEntry, actual []PathsSnapshotEntry) []string {
+	expectedTypes := make(map[string]string, len(expected))
+	for _, entry := range expected {
+		expectedTypes[entry.Pattern] = entry.PathType
+	}
+	actualTypes := make(map[string]string, len(actual))
+	for _, entry := range actual {
+		actualTypes[entry.Pattern] = entry.PathType
+	}
+
+	var changes []string
+	for _, pattern := range getSortedKeys(expectedTypes) {
+		actualType, exists := actualTypes[pattern]
+		switch {
+		case !exists:
+			changes = append(changes, fmt.Sprintf("- removed %s (%s)", pattern, expectedTypes[pattern]))
+		case actualType != expectedTypes[pattern]:
+			changes = append(changes, fmt.Sprintf("~ changed %s (%s -> %s)", pattern, expectedTypes[pattern], actualType))
+		}
+	}
+	for _, pattern := range getSortedKeys(actualTypes) {
+		if _, exists := expectedTypes[pattern]; !exists {
+			changes = append(changes, fmt.Sprintf("+ added %s (%s)", pattern, actualTypes[pattern]))
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return strings.Fields(changes[i])[2] < strings.Fields(changes[j])[2]
+	})
+	return changes
+}
@@ -0,0 +1,58 @@
+package router
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPathsSnapshot(t *testing.T) {
+	pagesSrcDir := filepath.Join(t.TempDir(), "pages")
+	writePage := func(name string) {
+		target := filepath.Join(pagesSrcDir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writePage("_index.ui.tsx")
+	writePage("lion.ui.tsx")
+
+	snapshotFile := filepath.Join(t.TempDir(), "paths.snapshot.json")
+	if err := WritePathsSnapshot(pagesSrcDir, snapshotFile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := CheckPathsSnapshot(pagesSrcDir, snapshotFile); err != nil {
+		t.Errorf("expected snapshot to match, got %v", err)
+	}
+
+	writePage("lion/$.ui.tsx")
+	err := CheckPathsSnapshot(pagesSrcDir, snapshotFile)
+	if err == nil || !strings.Contains(err.Error(), "+ added /lion/$ (non-ultimate-splat)") {
+		t.Errorf("expected added route in error, got %v", err)
+	}
+}
+
+func TestDiffPathsSnapshots(t *testing.T) {
+	expected := []PathsSnapshotEntry{
+		{Pattern: "/bear", PathType: PathTypeStaticLayout},
+		{Pattern: "/lion", PathType: PathTypeStaticLayout},
+	}
+	actual := []PathsSnapshotEntry{
+		{Pattern: "/bear", PathType: PathTypeDynamicLayout},
+		{Pattern: "/tiger", PathType: PathTypeStaticLayout},
+	}
+	changes := DiffPathsSnapshots(expected, actual)
+	expectedChanges := []string{
+		"~ changed /bear (static-layout -> dynamic-layout)",
+		"- removed /lion (static-layout)",
+		"+ added /tiger (static-layout)",
+	}
+	if !slices.Equal(changes, expectedChanges) {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expectedChanges, "\n"), strings.Join(changes, "\n"))
+	}
+}
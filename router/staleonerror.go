@@ -0,0 +1,139 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultStaleOnErrorMaxItems = 1_000
+
+// StaleOnError caches a route's successful loader data (see
+// DataFuncs.StaleOnError), serving the last of it for the same key when the
+// loader fails (other than with NotFound, Gone, or PermanentRedirect),
+// rather than rendering the error boundary. Responses serving it list the
+// route in GetRouteDataOutput.StalePatterns. Data served by a
+// CircuitBreaker's Fallback isn't cached, but breakers without one fail
+// with ErrCircuitOpen, which serves stale data too.
+type StaleOnError struct {
+	// How long after loading data may still be served. Zero means as long
+	// as it's cached.
+	MaxAge time.Duration
+	// Defaults to 1,000
+	MaxItems int
+	// Keys the cached data. Defaults to the tenant, locale, params, splat
+	// segments, and the request's credentials (its Cookie and Authorization
+	// headers), so one user's data is never served to another. Loaders whose
+	// data depends on anything else (e.g. the query) must set it, as may
+	// those wanting to share data between users.
+	Key func(*LoaderProps) string
+
+	once  sync.Once
+	cache *cache
+}
+
+type staleEntry struct {
+	data     any
+	loadedAt time.Time
+}
+
+// apply caches data if the loader succeeded, or returns the cached data if
+// it failed
+func (s *StaleOnError) apply(props *LoaderProps, data any, err error) (any, bool) {
+	s.once.Do(func() {
+		maxItems := s.MaxItems
+		if maxItems <= 0 {
+			maxItems = defaultStaleOnErrorMaxItems
+		}
+		s.cache = NewLRUCache(maxItems)
+	})
+	key := s.getKey(props)
+	if err == nil {
+		if getRawResponse(data) == nil {
+			s.cache.Set(key, &staleEntry{data: data, loadedAt: instanceClock.Now()}, false)
+		}
+		return nil, false
+	}
+	if !getIsLoaderFailure(err) {
+		return nil, false
+	}
+	cached, ok := s.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := cached.(*staleEntry)
+	if s.MaxAge > 0 && instanceClock.Now().Sub(entry.loadedAt) > s.MaxAge {
+		return nil, false
+	}
+	return entry.data, true
+}
+
+func (s *StaleOnError) getKey(props *LoaderProps) string {
+	if s.Key != nil {
+		return s.Key(props)
+	}
+	var b strings.Builder
+	if props.Request != nil {
+		b.WriteString(GetTenant(props.Request))
+	}
+	b.WriteString("\x00" + props.Locale)
+	if props.Params != nil {
+		for _, key := range getSortedKeys(*props.Params) {
+			b.WriteString("\x00" + key + "=" + (*props.Params)[key])
+		}
+	}
+	if props.SplatSegments != nil {
+		b.WriteString("\x00" + strings.Join(*props.SplatSegments, "/"))
+	}
+	if props.Request != nil {
+		b.WriteString("\x00" + getCredentialsHash(props.Request.Header))
+	}
+	return b.String()
+}
+
+// getCredentialsHash identifies the user (or session) of a request by
+// whatever credentials it carries, without keeping them in memory
+func getCredentialsHash(header http.Header) string {
+	cookie, authorization := header.Values("Cookie"), header.Values("Authorization")
+	if len(cookie) == 0 && len(authorization) == 0 {
+		return ""
+	}
+	hash := sha256.New()
+	for _, value := range cookie {
+		hash.Write([]byte("c" + value + "\x00"))
+	}
+	for _, value := range authorization {
+		hash.Write([]byte("a" + value + "\x00"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (s *requestScope) addStalePattern(pattern string) {
+	if s == nil {
+		return
+	}
+	s.staleMu.Lock()
+	defer s.staleMu.Unlock()
+	s.stalePatterns = append(s.stalePatterns, pattern)
+}
+
+// getStalePatterns returns those of patterns (the rendered routes) that were
+// served stale data, in order
+func (s *requestScope) getStalePatterns(patterns []string) []string {
+	if s == nil {
+		return nil
+	}
+	s.staleMu.Lock()
+	defer s.staleMu.Unlock()
+	var stalePatterns []string
+	for _, pattern := range patterns {
+		if slices.Contains(s.stalePatterns, pattern) {
+			stalePatterns = append(stalePatterns, pattern)
+		}
+	}
+	return stalePatterns
+}
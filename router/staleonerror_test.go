@@ -0,0 +1,122 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStaleOnError(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	prevClock := instanceClock
+	instanceClock = clock
+	t.Cleanup(func() { instanceClock = prevClock })
+
+	var loaderErr error
+	loads := 0
+	setTestDataFuncs(t, "/lion", &DataFuncs{
+		StaleOnError: &StaleOnError{MaxAge: time.Minute},
+		Loader: func(*LoaderProps) (any, error) {
+			loads++
+			if loaderErr != nil {
+				return nil, loaderErr
+			}
+			return loads, nil
+		},
+	})
+	get := func(url string) *GetRouteDataOutput {
+		routeData, err := Hwy{}.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return routeData
+	}
+
+	fresh := get("/lion/stale")
+	if fresh.IsStale || (*fresh.LoadersData)[0] != 1 {
+		t.Fatalf("expected fresh data, got %v", (*fresh.LoadersData)[0])
+	}
+
+	loaderErr = errors.New("upstream down")
+	routeData := get("/lion/stale")
+	if !routeData.IsStale || !slices.Equal(routeData.StalePatterns, []string{"/lion"}) || (*routeData.LoadersData)[0] != 1 {
+		t.Errorf("expected the last successful data, flagged stale, got %v %v", (*routeData.LoadersData)[0], routeData.StalePatterns)
+	}
+	if routeData.OutermostErrorBoundaryIndex != fresh.OutermostErrorBoundaryIndex {
+		t.Error("expected stale data not to render the error boundary")
+	}
+	if routeData := get("/lion/other"); routeData.IsStale {
+		t.Error("expected no stale data for other params")
+	}
+
+	loaderErr = NotFound()
+	if routeData := get("/lion/stale"); routeData.IsStale {
+		t.Error("expected NotFound not to serve stale data")
+	}
+
+	loaderErr = errors.New("upstream down")
+	clock.Advance(2 * time.Minute)
+	if routeData := get("/lion/stale"); routeData.IsStale {
+		t.Error("expected data older than MaxAge not to be served")
+	}
+}
+
+func TestStaleOnErrorSkipsFallbackData(t *testing.T) {
+	var loaderErr error
+	setTestDataFuncs(t, "/lion", &DataFuncs{
+		StaleOnError: &StaleOnError{},
+		CircuitBreaker: &CircuitBreaker{
+			Threshold: 1,
+			Fallback:  func(*LoaderProps, error) (any, error) { return "fallback", nil },
+		},
+		Loader: func(*LoaderProps) (any, error) { return "roar", loaderErr },
+	})
+	get := func() *GetRouteDataOutput {
+		routeData, err := Hwy{}.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/stale-breaker", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return routeData
+	}
+
+	get()
+	loaderErr = errors.New("upstream down")
+	if routeData := get(); routeData.IsStale || (*routeData.LoadersData)[0] != "fallback" {
+		t.Errorf("expected the open breaker's fallback, got %v", (*routeData.LoadersData)[0])
+	}
+}
+
+func TestStaleOnErrorKeepsUsersApart(t *testing.T) {
+	var loaderErr error
+	setTestDataFuncs(t, "/lion", &DataFuncs{
+		StaleOnError: &StaleOnError{},
+		Loader: func(props *LoaderProps) (any, error) {
+			if loaderErr != nil {
+				return nil, loaderErr
+			}
+			cookie, _ := props.Request.Cookie("session")
+			return "data of " + cookie.Value, nil
+		},
+	})
+	get := func(session string) *GetRouteDataOutput {
+		r := httptest.NewRequest("GET", "/lion/account", nil)
+		r.AddCookie(&http.Cookie{Name: "session", Value: session})
+		routeData, err := Hwy{}.GetRouteData(httptest.NewRecorder(), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return routeData
+	}
+
+	get("alice")
+	loaderErr = errors.New("upstream down")
+	if routeData := get("bob"); routeData.IsStale {
+		t.Errorf("expected no stale data for another user, got %v", (*routeData.LoadersData)[0])
+	}
+	if routeData := get("alice"); !routeData.IsStale || (*routeData.LoadersData)[0] != "data of alice" {
+		t.Errorf("expected the user's own stale data, got %v", (*routeData.LoadersData)[0])
+	}
+}
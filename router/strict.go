@@ -0,0 +1,78 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// validateStrict checks the freshly loaded instancePaths for incomplete data
+// funcs (see Hwy.Strict)
+func (h Hwy) validateStrict() []error {
+	var problems []error
+	permittedHeadTags := getPermittedHeadTags(h.ExtraPermittedHeadTags)
+	for _, path := range *instancePaths {
+		dataFuncs := path.DataFuncs
+		if dataFuncs == nil {
+			dataFuncs = &DataFuncs{}
+		}
+		isLayout := path.PathType == PathTypeStaticLayout || path.PathType == PathTypeDynamicLayout
+		if isLayout && dataFuncs.Loader == nil {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrMissingLoader, path.Pattern))
+		}
+		if dataFuncs.Action != nil && (dataFuncs.ActionInput == nil || dataFuncs.ActionOutput == nil) {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrMissingActionTypes, path.Pattern))
+		}
+		if dataFuncs.Head != nil {
+			for _, tag := range getDryRunHeadTags(path, dataFuncs.Head) {
+				if !slices.Contains(permittedHeadTags, tag) {
+					problems = append(problems, fmt.Errorf("%w: %s returns <%s>", ErrDisallowedHeadTag, path.Pattern, tag))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// getDryRunHeadTags calls head with placeholder props (each param set to its
+// own name, no loader data) and returns the tags of the blocks it returns,
+// other than titles and html/body attribute blocks. Heads that error or panic
+// without real data are skipped.
+func getDryRunHeadTags(path Path, head Head) (tags []string) {
+	defer func() {
+		if recover() != nil {
+			tags = nil
+		}
+	}()
+	params := make(map[string]string)
+	if path.Segments != nil {
+		for _, segment := range *path.Segments {
+			if compound := parseCompoundSegment(segment); compound != nil {
+				for _, name := range compound.names {
+					params[name] = name
+				}
+			} else if name, ok := strings.CutPrefix(segment, "$"); ok && name != "" {
+				params[name] = name
+			}
+		}
+	}
+	r, err := http.NewRequest("GET", ResolvePattern(path.Pattern, params, nil), nil)
+	if err != nil {
+		return nil
+	}
+	props := &HeadProps{DataProps: (*requestScope)(nil).newDataProps(r, &params, &[]string{})}
+	blocks, err := head(props)
+	if err != nil || blocks == nil {
+		return nil
+	}
+	for _, block := range *blocks {
+		if block.Title != "" || block.Tag == "html" || block.Tag == "body" {
+			continue
+		}
+		if !slices.Contains(tags, block.Tag) {
+			tags = append(tags, block.Tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,60 @@
+package router
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateStrict(t *testing.T) {
+	setTestDataFuncs(t, "/lion", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) { return nil, nil },
+		Head: func(props *HeadProps) (*[]HeadBlock, error) {
+			return &[]HeadBlock{{Title: "Lion"}, {Tag: "meta"}, {Tag: "iframe"}, {Tag: "iframe"}}, nil
+		},
+	})
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Action: func(props *ActionProps) (any, error) { return nil, nil },
+		// Needs real loader data, so it's skipped rather than reported
+		Head: func(props *HeadProps) (*[]HeadBlock, error) {
+			return &[]HeadBlock{{Tag: props.LoaderData.(string)}}, nil
+		},
+	})
+	var headParams map[string]string
+	setTestDataFuncs(t, "/tiger/$tiger_id", &DataFuncs{
+		Loader:       func(props *LoaderProps) (any, error) { return nil, nil },
+		Action:       func(props *ActionProps) (any, error) { return nil, nil },
+		ActionInput:  struct{}{},
+		ActionOutput: struct{}{},
+		Head: func(props *HeadProps) (*[]HeadBlock, error) {
+			headParams = *props.Params
+			return &[]HeadBlock{{Tag: "link"}}, nil
+		},
+	})
+
+	var messages []string
+	for _, problem := range (Hwy{}).validateStrict() {
+		messages = append(messages, problem.Error())
+		if !errors.Is(problem, ErrMissingLoader) && !errors.Is(problem, ErrMissingActionTypes) && !errors.Is(problem, ErrDisallowedHeadTag) {
+			t.Errorf("unexpected problem: %v", problem)
+		}
+	}
+	joined := strings.Join(messages, "\n")
+	for _, expected := range []string{
+		ErrMissingLoader.Error() + ": /tiger\n",
+		ErrMissingActionTypes.Error() + ": /lion/$",
+		ErrDisallowedHeadTag.Error() + ": /lion returns <iframe>",
+	} {
+		if strings.Count(joined+"\n", expected) != 1 {
+			t.Errorf("expected exactly one %q, got:\n%s", expected, joined)
+		}
+	}
+	for _, unexpected := range []string{": /lion\n", ": /tiger/$tiger_id\n", "/tiger/$tiger_id returns"} {
+		if strings.Contains(joined+"\n", unexpected) {
+			t.Errorf("unexpected %q in:\n%s", unexpected, joined)
+		}
+	}
+	if headParams["tiger_id"] != "tiger_id" {
+		t.Errorf("expected placeholder params, got %v", headParams)
+	}
+}
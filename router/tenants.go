@@ -0,0 +1,163 @@
+package router
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Tenants layers per-tenant route trees over the base one, so e.g. a
+// white-label tenant can replace a few pages (see BuildOptions.TenantPagesDirs)
+// or their data funcs without forking the whole pages directory. A tenant's
+// tree is the base tree with its override routes replacing (by pattern) or
+// adding to the base routes. Requests whose tenant has no overrides use the
+// base tree.
+type Tenants struct {
+	// Consulted first. Returning "" falls through to Header and ByHost.
+	Resolve func(*http.Request) string
+	// Request header naming the tenant, e.g. "X-Tenant"
+	Header string
+	// Host (without port) to tenant. A leading dot (".example.com") also
+	// matches subdomains, the closest match winning.
+	ByHost map[string]string
+	// Per-tenant data funcs, replacing those of Hwy.DataFuncsMap by pattern
+	DataFuncsMaps map[string]DataFuncsMap
+}
+
+// resolve returns the request's tenant, or "" for none
+func (t *Tenants) resolve(r *http.Request) string {
+	if t.Resolve != nil {
+		if tenant := t.Resolve(r); tenant != "" {
+			return tenant
+		}
+	}
+	if t.Header != "" {
+		if tenant := r.Header.Get(t.Header); tenant != "" {
+			return tenant
+		}
+	}
+	if len(t.ByHost) == 0 {
+		return ""
+	}
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	if tenant, ok := t.ByHost[host]; ok {
+		return tenant
+	}
+	for i := strings.IndexByte(host, '.'); i != -1; {
+		if tenant, ok := t.ByHost[host[i:]]; ok {
+			return tenant
+		}
+		next := strings.IndexByte(host[i+1:], '.')
+		if next == -1 {
+			break
+		}
+		i += next + 1
+	}
+	return ""
+}
+
+// Tenant name to its merged route tree, for tenants with overrides
+var instanceTenantPaths map[string]*[]Path
+
+type tenantContextKey struct{}
+
+// withTenant stores the request's tenant (see Hwy.Tenants) in its context,
+// unless it's already there. Responses then vary by Tenants.Header, whether
+// or not the request has it, so w (if non-nil) gets a Vary header.
+func (h Hwy) withTenant(w http.ResponseWriter, r *http.Request) *http.Request {
+	if h.Tenants == nil {
+		return r
+	}
+	if _, ok := r.Context().Value(tenantContextKey{}).(string); ok {
+		return r
+	}
+	if w != nil && h.Tenants.Header != "" {
+		w.Header().Add("Vary", h.Tenants.Header)
+	}
+	return r.WithContext(context.WithValue(r.Context(), tenantContextKey{}, h.Tenants.resolve(r)))
+}
+
+// GetTenant returns the tenant resolved for r via Hwy.Tenants, or "" if
+// there is none. Tenants without overrides are still reported, e.g. for
+// theming.
+func GetTenant(r *http.Request) string {
+	tenant, _ := r.Context().Value(tenantContextKey{}).(string)
+	return tenant
+}
+
+// getTenantPaths returns the route tree for r's tenant, and the tenant's name
+// if it has overrides (for keying caches)
+func getTenantPaths(r *http.Request) (*[]Path, string) {
+	if instanceTenantPaths != nil {
+		if tenant := GetTenant(r); tenant != "" {
+			if paths, ok := instanceTenantPaths[tenant]; ok {
+				return paths, tenant
+			}
+		}
+	}
+	return instancePaths, ""
+}
+
+// initTenantPaths merges each tenant's override routes and data funcs over
+// the (already initialized) base tree, returning any problems resolving the
+// tenants' data funcs keys
+func (h Hwy) initTenantPaths(pathsFile *PathsFile) []error {
+	instanceTenantPaths = nil
+	if h.Tenants == nil {
+		return nil
+	}
+	tenants := make(map[string]bool, len(pathsFile.TenantPaths)+len(h.Tenants.DataFuncsMaps))
+	for tenant := range pathsFile.TenantPaths {
+		tenants[tenant] = true
+	}
+	for tenant := range h.Tenants.DataFuncsMaps {
+		tenants[tenant] = true
+	}
+	if len(tenants) == 0 {
+		return nil
+	}
+
+	var problems []error
+	instanceTenantPaths = make(map[string]*[]Path, len(tenants))
+	for _, tenant := range getSortedKeys(tenants) {
+		dataFuncsMap, keyProblems := resolveDataFuncsMap(h.Tenants.DataFuncsMaps[tenant], getSrcPathPatterns(pathsFile.Paths, pathsFile.TenantPaths[tenant]))
+		problems = append(problems, keyProblems...)
+		paths := make([]Path, len(*instancePaths), len(*instancePaths)+len(pathsFile.TenantPaths[tenant]))
+		copy(paths, *instancePaths)
+		for _, override := range pathsFile.TenantPaths[tenant] {
+			path := newPath(override)
+			if dataFuncs, ok := h.DataFuncsMap[path.Pattern]; ok {
+				path.DataFuncs = &dataFuncs
+				if dataFuncs.Handle != nil {
+					path.Handle = dataFuncs.Handle
+				}
+			}
+			replaced := false
+			for i := range paths {
+				if paths[i].Pattern == path.Pattern {
+					paths[i] = path
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				paths = append(paths, path)
+			}
+		}
+		for i, path := range paths {
+			if dataFuncs, ok := dataFuncsMap[path.Pattern]; ok {
+				paths[i].DataFuncs = &dataFuncs
+				if dataFuncs.Handle != nil {
+					paths[i].Handle = dataFuncs.Handle
+				}
+			}
+		}
+		instanceTenantPaths[tenant] = &paths
+	}
+	return problems
+}
@@ -0,0 +1,111 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestTenantsResolve(t *testing.T) {
+	tenants := &Tenants{
+		Resolve: func(r *http.Request) string { return r.URL.Query().Get("tenant") },
+		Header:  "X-Tenant",
+		ByHost:  map[string]string{"acme.example.com": "acme", ".globex.com": "globex"},
+	}
+	for _, tc := range []struct {
+		url, header, want string
+	}{
+		{"http://example.com/?tenant=initech", "acme", "initech"},
+		{"http://example.com/", "acme", "acme"},
+		{"http://acme.example.com:8080/", "", "acme"},
+		{"http://shop.eu.globex.com/", "", "globex"},
+		{"http://globex.com/", "", ""},
+		{"http://example.com/", "", ""},
+	} {
+		r := httptest.NewRequest("GET", tc.url, nil)
+		if tc.header != "" {
+			r.Header.Set("X-Tenant", tc.header)
+		}
+		if got := tenants.resolve(r); got != tc.want {
+			t.Errorf("%s (header %q): expected %q, got %q", tc.url, tc.header, tc.want, got)
+		}
+	}
+}
+
+func TestTenantOverrides(t *testing.T) {
+	override := "tenant/lion.ui.tsx"
+	h := Hwy{Tenants: &Tenants{
+		Header: "X-Tenant",
+		DataFuncsMaps: map[string]DataFuncsMap{
+			"acme": {"/lion/$": {Loader: func(props *LoaderProps) (any, error) { return "acme cub", nil }}},
+		},
+	}}
+	h.initTenantPaths(&PathsFile{TenantPaths: map[string][]JSONSafePath{
+		"acme": {
+			{Pattern: "/lion", Segments: &[]string{"lion"}, PathType: PathTypeStaticLayout, SrcPath: override, OutPath: "acme_lion.js"},
+			{Pattern: "/acme-only", Segments: &[]string{"acme-only"}, PathType: PathTypeStaticLayout, OutPath: "acme_only.js"},
+		},
+	}})
+	t.Cleanup(func() { instanceTenantPaths = nil })
+
+	get := func(path, tenant string) *GetRouteDataOutput {
+		r := httptest.NewRequest("GET", path, nil)
+		if tenant != "" {
+			r.Header.Set("X-Tenant", tenant)
+		}
+		routeData, err := h.GetRouteData(httptest.NewRecorder(), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return routeData
+	}
+
+	acme := get("/lion/tenants", "acme")
+	if (*acme.ImportURLs)[0] != "/acme_lion.js" {
+		t.Errorf("expected the tenant's page to replace the base one, got %v", *acme.ImportURLs)
+	}
+	if len(*acme.LoadersData) != 2 || (*acme.LoadersData)[1] != "acme cub" {
+		t.Errorf("expected the tenant's loader, got %v", *acme.LoadersData)
+	}
+
+	// The same URL for other tenants is matched (and cached) separately
+	for _, tenant := range []string{"", "initech"} {
+		base := get("/lion/tenants", tenant)
+		if (*base.ImportURLs)[0] == "/acme_lion.js" || (*base.LoadersData)[1] == "acme cub" {
+			t.Errorf("tenant %q: expected the base tree, got %v %v", tenant, *base.ImportURLs, *base.LoadersData)
+		}
+	}
+
+	if got := get("/acme-only", "acme").Pattern; got != "/acme-only" {
+		t.Errorf("expected the tenant's added route to match, got %q", got)
+	}
+	if got := get("/acme-only", "").Pattern; got == "/acme-only" {
+		t.Error("expected the tenant's added route to be absent from the base tree")
+	}
+}
+
+func TestTenantHeaderVary(t *testing.T) {
+	h := Hwy{Tenants: &Tenants{Header: "X-Tenant"}}
+	for _, tenant := range []string{"acme", ""} {
+		r := httptest.NewRequest("GET", "/lion", nil)
+		if tenant != "" {
+			r.Header.Set("X-Tenant", tenant)
+		}
+		w := httptest.NewRecorder()
+		if _, err := h.GetRouteData(w, r); err != nil {
+			t.Fatal(err)
+		}
+		if vary := w.Header().Values("Vary"); !slices.Contains(vary, "X-Tenant") {
+			t.Errorf("tenant %q: expected Vary to include X-Tenant, got %v", tenant, vary)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	if _, err := (Hwy{Tenants: &Tenants{ByHost: map[string]string{"acme.com": "acme"}}}).GetRouteData(w, httptest.NewRequest("GET", "/lion", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if vary := w.Header().Values("Vary"); slices.Contains(vary, "X-Tenant") {
+		t.Errorf("expected no tenant header in Vary without Tenants.Header, got %v", vary)
+	}
+}
@@ -0,0 +1,76 @@
+package router
+
+import (
+	"context"
+)
+
+// TracerProvider mirrors the shape of OpenTelemetry's trace.TracerProvider,
+// so a thin adapter around an OTel provider (or any other tracing backend)
+// can be passed as Hwy.TracerProvider.
+type TracerProvider interface {
+	Tracer(name string) Tracer
+}
+
+type Tracer interface {
+	Start(ctx context.Context, spanName string, attrs ...SpanAttribute) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttributes(attrs ...SpanAttribute)
+	RecordError(err error)
+	End()
+}
+
+type SpanAttribute struct {
+	Key   string
+	Value any
+}
+
+const tracerName = "github.com/sjc5/hwy-go"
+
+// Span names
+const (
+	SpanRouteData = "hwy.route_data"
+	SpanMatch     = "hwy.match"
+	SpanGuard     = "hwy.guard"
+	SpanLoader    = "hwy.loader"
+	SpanAction    = "hwy.action"
+	SpanHead      = "hwy.head"
+	SpanSerialize = "hwy.serialize"
+	SpanFetch     = "hwy.fetch" // per upstream request of DataProps.Fetch
+
+	SpanRenderComponents = "hwy.render_components"
+)
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...SpanAttribute) {}
+func (noopSpan) RecordError(error)              {}
+func (noopSpan) End()                           {}
+
+// startSpan is a no-op unless Hwy.TracerProvider is set
+func (h Hwy) startSpan(ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span) {
+	return startSpan(h.TracerProvider, ctx, name, attrs...)
+}
+
+func startSpan(tracerProvider TracerProvider, ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span) {
+	if tracerProvider == nil {
+		return ctx, noopSpan{}
+	}
+	return tracerProvider.Tracer(tracerName).Start(ctx, name, attrs...)
+}
+
+func getPathSpanAttributes(path *DecoratedPath) []SpanAttribute {
+	return []SpanAttribute{
+		{Key: "hwy.pattern", Value: path.Pattern},
+		{Key: "hwy.path_type", Value: path.PathType},
+	}
+}
+
+// endSpan records err (if any) and ends span
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type recordedSpan struct {
+	name  string
+	attrs map[string]any
+	err   error
+}
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *testTracer) Tracer(string) Tracer { return t }
+
+func (t *testTracer) Start(ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span) {
+	span := &recordedSpan{name: name, attrs: map[string]any{}}
+	span.SetAttributes(attrs...)
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return ctx, span
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...SpanAttribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+func (s *recordedSpan) End()                  {}
+
+func (t *testTracer) find(name string) []*recordedSpan {
+	var found []*recordedSpan
+	for _, span := range t.spans {
+		if span.name == name {
+			found = append(found, span)
+		}
+	}
+	return found
+}
+
+func TestTracingSpans(t *testing.T) {
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return nil, errors.New("boom")
+		},
+	})
+
+	tracer := &testTracer{}
+	h := Hwy{TracerProvider: tracer}
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest("GET", "/lion/tracing-test", nil)
+		if _, err := h.GetRouteData(httptest.NewRecorder(), r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	matches := tracer.find(SpanMatch)
+	if len(matches) != 2 || matches[0].attrs["hwy.cache_hit"] != false || matches[1].attrs["hwy.cache_hit"] != true {
+		t.Errorf("expected a cache miss then a cache hit, got %v", matches)
+	}
+	loaders := tracer.find(SpanLoader)
+	if len(loaders) != 2 || loaders[0].attrs["hwy.pattern"] != "/lion/$" || loaders[0].err == nil {
+		t.Errorf("expected failing loader spans for /lion/$, got %v", loaders)
+	}
+	if len(tracer.find(SpanRouteData)) != 2 {
+		t.Errorf("expected a route data span per request")
+	}
+}
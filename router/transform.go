@@ -0,0 +1,293 @@
+package router
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// LoaderDataTransform rewrites a loader's return value before it is
+// serialized, e.g. to redact fields or reformat times. An error is handled
+// like one returned by the loader itself.
+type LoaderDataTransform func(pattern string, data any) (any, error)
+
+// transformLoadersData runs Hwy.LoaderDataTransforms over the data of every
+// loader that didn't fail
+func (h Hwy) transformLoadersData(r *http.Request, item *gmpdItem, loadersData []any, errs []error) {
+	if len(h.LoaderDataTransforms) == 0 {
+		return
+	}
+	paths := *item.FullyDecoratedMatchingPaths
+	for i := range loadersData {
+		if errs[i] != nil || loadersData[i] == nil || getRawResponse(loadersData[i]) != nil {
+			continue
+		}
+		pattern := paths[i].Pattern
+		loadersData[i], errs[i] = callDataFunc(h, r, pattern, ErrorPhaseLoader, func() (any, error) {
+			data := loadersData[i]
+			for _, transform := range h.LoaderDataTransforms {
+				var err error
+				if data, err = transform(pattern, data); err != nil {
+					return nil, err
+				}
+			}
+			return data, nil
+		})
+	}
+}
+
+// RedactServerOnly is a LoaderDataTransform that drops struct fields tagged
+// `hwy:"server-only"`, at any depth, so server structs can be returned from
+// loaders directly. Structs containing such fields become maps keyed (and
+// filtered) per their json tags; everything else is left as is.
+func RedactServerOnly(pattern string, data any) (any, error) {
+	return serverOnlyFields.rewrite(data, nil), nil
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// taggedFields finds struct fields whose hwy tag has any of options, so data
+// holding them can be rewritten without them (or with their values replaced)
+type taggedFields struct {
+	options []string
+	cache   sync.Map // reflect.Type -> bool
+}
+
+var serverOnlyFields = &taggedFields{options: []string{"server-only"}}
+
+// fieldRewrite returns the value to send in place of a tagged field's (given
+// the option it was tagged with), or false to drop the field
+type fieldRewrite func(option string, v reflect.Value) (any, bool)
+
+// rewrite returns data with its tagged fields dropped, or rewritten by
+// rewriteField if it isn't nil
+func (f *taggedFields) rewrite(data any, rewriteField fieldRewrite) any {
+	v := reflect.ValueOf(data)
+	if !v.IsValid() || !f.getHasTaggedFields(v.Type()) {
+		return data
+	}
+	return f.rewriteValue(v, rewriteField)
+}
+
+func (f *taggedFields) getTagOption(field reflect.StructField) (string, bool) {
+	for _, option := range strings.Split(field.Tag.Get("hwy"), ",") {
+		if slices.Contains(f.options, option) {
+			return option, true
+		}
+	}
+	return "", false
+}
+
+// taggedValue keeps the value of a field tagged for another taggedFields
+// (e.g. "ssr-only" through RedactServerOnly), so rewriting it into a map
+// doesn't lose the tag
+type taggedValue struct {
+	option string
+	value  any
+}
+
+func (v taggedValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value)
+}
+
+var taggedValueType = reflect.TypeFor[taggedValue]()
+
+// getIsJSONField reports whether encoding/json considers field: exported
+// fields, plus embedded structs (whose exported fields are promoted)
+func getIsJSONField(field reflect.StructField) bool {
+	if field.IsExported() {
+		return true
+	}
+	return field.Anonymous && field.Type.Kind() == reflect.Struct
+}
+
+func getHasCustomMarshaling(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+// getHasTaggedFields reports whether values of t can contain a tagged field,
+// so everything else can skip rewriting entirely
+func (f *taggedFields) getHasTaggedFields(t reflect.Type) bool {
+	if cached, ok := f.cache.Load(t); ok {
+		return cached.(bool)
+	}
+	has := f.hasTaggedFields(t, make(map[reflect.Type]bool))
+	f.cache.Store(t, has)
+	return has
+}
+
+// Only the root result is cached, as results for types inside a cycle are
+// incomplete until the cycle's root is done
+func (f *taggedFields) hasTaggedFields(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t == taggedValueType {
+		return true
+	}
+	if visiting[t] || getHasCustomMarshaling(t) {
+		return false
+	}
+	visiting[t] = true
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return f.hasTaggedFields(t.Elem(), visiting)
+	case reflect.Interface:
+		// Only known at runtime
+		return true
+	case reflect.Struct:
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !getIsJSONField(field) {
+				continue
+			}
+			if _, ok := f.getTagOption(field); ok || f.hasTaggedFields(field.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *taggedFields) rewriteValue(v reflect.Value, rewriteField fieldRewrite) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !f.getHasTaggedFields(v.Type()) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return f.rewriteValue(v.Elem(), rewriteField)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = f.rewriteValue(v.Index(i), rewriteField)
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := getMapKeyString(iter.Key())
+			tagged, ok := iter.Value().Interface().(taggedValue)
+			if !ok {
+				m[key] = f.rewriteValue(iter.Value(), rewriteField)
+				continue
+			}
+			if !slices.Contains(f.options, tagged.option) {
+				m[key] = taggedValue{tagged.option, f.rewriteValue(reflect.ValueOf(tagged.value), rewriteField)}
+			} else if rewriteField != nil {
+				if value, keep := rewriteField(tagged.option, reflect.ValueOf(tagged.value)); keep {
+					m[key] = value
+				}
+			}
+		}
+		return m
+	case reflect.Struct:
+		return f.rewriteStruct(v, rewriteField)
+	}
+	return v.Interface()
+}
+
+func (f *taggedFields) rewriteStruct(v reflect.Value, rewriteField fieldRewrite) map[string]any {
+	m := make(map[string]any)
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		option, isTagged := f.getTagOption(field)
+		if !getIsJSONField(field) || (isTagged && rewriteField == nil) {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		fieldValue := v.Field(i)
+		// Promote embedded struct fields, as encoding/json does
+		if field.Anonymous && name == "" && !isTagged && reflect.Indirect(fieldValue).Kind() == reflect.Struct {
+			if fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil() {
+				continue
+			}
+			for key, value := range f.rewriteStruct(reflect.Indirect(fieldValue), rewriteField) {
+				if _, exists := m[key]; !exists {
+					m[key] = value
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(","+options+",", ",omitempty,") && getIsEmptyJSONValue(fieldValue) {
+			continue
+		}
+		if isTagged {
+			if value, keep := rewriteField(option, fieldValue); keep {
+				m[name] = value
+			}
+			continue
+		}
+		if option, ok := f.getOtherTagOption(field); ok {
+			m[name] = taggedValue{option, f.rewriteValue(fieldValue, rewriteField)}
+			continue
+		}
+		m[name] = f.rewriteValue(fieldValue, rewriteField)
+	}
+	return m
+}
+
+// getOtherTagOption returns the option of a field tagged for the client
+// payload (see clientPayloadFields), when f is rewriting for something else
+func (f *taggedFields) getOtherTagOption(field reflect.StructField) (string, bool) {
+	if f == clientPayloadFields {
+		return "", false
+	}
+	return clientPayloadFields.getTagOption(field)
+}
+
+// getIsEmptyJSONValue matches encoding/json's definition of empty for omitempty
+func getIsEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+func getMapKeyString(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	keyBytes, _ := json.Marshal(key.Interface())
+	return strings.Trim(string(keyBytes), `"`)
+}
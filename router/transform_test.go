@@ -0,0 +1,93 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAccount struct {
+	ID           int       `json:"id"`
+	PasswordHash string    `json:"passwordHash" hwy:"server-only"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Nickname     string    `json:"nickname,omitempty"`
+}
+
+type testOrg struct {
+	testAccount
+	Name    string         `json:"name"`
+	Members []*testAccount `json:"members"`
+	Extra   any            `json:"extra"`
+	APIKey  string         `hwy:"server-only"`
+	Parent  *testOrg       `json:"parent,omitempty"`
+}
+
+func TestRedactServerOnly(t *testing.T) {
+	org := testOrg{
+		testAccount: testAccount{ID: 1, PasswordHash: "x"},
+		Name:        "acme",
+		Members:     []*testAccount{{ID: 2, PasswordHash: "y", Nickname: "bo"}},
+		Extra:       map[string]any{"owner": testAccount{ID: 3, PasswordHash: "z"}},
+		APIKey:      "secret",
+	}
+	redacted, err := RedactServerOnly("/org", &org)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonBytes, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(jsonBytes)
+	for _, leaked := range []string{"passwordHash", "APIKey", "secret", `"parent"`, `"nickname":""`} {
+		if strings.Contains(got, leaked) {
+			t.Errorf("expected %s to be dropped: %s", leaked, got)
+		}
+	}
+	for _, expected := range []string{`"id":1`, `"name":"acme"`, `"nickname":"bo"`, `"owner":{`, `"createdAt":"0001-01-01T00:00:00Z"`} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("expected %s: %s", expected, got)
+		}
+	}
+
+	plain := struct{ A int }{1}
+	if data, _ := RedactServerOnly("/plain", plain); data != plain {
+		t.Error("expected data without server-only fields to pass through unchanged")
+	}
+}
+
+func TestLoaderDataTransforms(t *testing.T) {
+	setTestDataFuncs(t, "/lion", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return testAccount{ID: 1, PasswordHash: "x"}, nil
+		},
+	})
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return "fail", nil
+		},
+	})
+
+	h := Hwy{LoaderDataTransforms: []LoaderDataTransform{
+		RedactServerOnly,
+		func(pattern string, data any) (any, error) {
+			if data == "fail" {
+				return nil, errors.New("transform failed")
+			}
+			return data, nil
+		},
+	}}
+	routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/lion/transform-test", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := (*routeData.LoadersData)[0].(map[string]any); !ok || data["passwordHash"] != nil || data["id"] != 1 {
+		t.Errorf("expected redacted parent data, got %#v", (*routeData.LoadersData)[0])
+	}
+	if len(*routeData.LoadersData) != 2 || (*routeData.LoadersData)[1] != nil {
+		t.Errorf("expected transform error to be handled as a loader error, got %v", *routeData.LoadersData)
+	}
+}
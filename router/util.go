@@ -1,7 +1,79 @@
 package router
 
 import (
+	"fmt"
+	"log/slog"
+	"strings"
+
 	"github.com/sjc5/kit/pkg/colorlog"
 )
 
-var Log = colorlog.Log{Label: "Hwy"}
+// Logger is used for build and runtime logs. Fields are alternating
+// key/value pairs, as with log/slog (so a *slog.Logger satisfies Logger).
+type Logger interface {
+	Debug(msg string, fields ...any)
+	Info(msg string, fields ...any)
+	Warn(msg string, fields ...any)
+	Error(msg string, fields ...any)
+}
+
+var _ Logger = (*slog.Logger)(nil)
+
+// Used wherever no Logger is configured
+var DefaultLogger Logger = &colorLogger{log: colorlog.Log{Label: "Hwy"}}
+
+// NewSlogLogger adapts a *slog.Logger (nil means slog.Default()) to Logger
+func NewSlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		return slog.Default()
+	}
+	return l
+}
+
+type colorLogger struct {
+	log colorlog.Log
+}
+
+func (l *colorLogger) Debug(msg string, fields ...any) {
+	l.log.Info(formatLogFields(msg, fields))
+}
+
+func (l *colorLogger) Info(msg string, fields ...any) {
+	l.log.Info(formatLogFields(msg, fields))
+}
+
+func (l *colorLogger) Warn(msg string, fields ...any) {
+	l.log.Warning(formatLogFields(msg, fields))
+}
+
+func (l *colorLogger) Error(msg string, fields ...any) {
+	l.log.Error(formatLogFields(msg, fields))
+}
+
+func formatLogFields(msg string, fields []any) string {
+	var sb strings.Builder
+	sb.WriteString(msg)
+	for i := 0; i < len(fields); i += 2 {
+		sb.WriteString(" ")
+		if i+1 < len(fields) {
+			sb.WriteString(fmt.Sprintf("%v=%v", fields[i], fields[i+1]))
+		} else {
+			sb.WriteString(fmt.Sprintf("%v", fields[i]))
+		}
+	}
+	return sb.String()
+}
+
+func (h Hwy) getLogger() Logger {
+	if h.Logger != nil {
+		return h.Logger
+	}
+	return DefaultLogger
+}
+
+func (opts BuildOptions) getLogger() Logger {
+	if opts.Logger != nil {
+		return opts.Logger
+	}
+	return DefaultLogger
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFormatLogFields(t *testing.T) {
+	got := formatLogFields("build completed", []any{"duration", "1s", "dangling"})
+	if got != "build completed duration=1s dangling" {
+		t.Errorf("unexpected formatted message: %q", got)
+	}
+}
+
+func TestHwyLoggerReceivesLoaderErrors(t *testing.T) {
+	setTestDataFuncs(t, "/lion/$", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			return nil, errors.New("boom")
+		},
+	})
+
+	var buf bytes.Buffer
+	h := Hwy{Logger: NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))}
+	r := httptest.NewRequest("GET", "/lion/logger-test", nil)
+
+	if _, err := h.GetRouteData(httptest.NewRecorder(), r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "level=ERROR") || !strings.Contains(buf.String(), "error=boom") {
+		t.Errorf("expected loader error in log output, got %q", buf.String())
+	}
+}
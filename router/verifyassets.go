@@ -0,0 +1,86 @@
+package router
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+)
+
+// verifyAssets checks that every file the paths file references exists in
+// Hwy.AssetsFS, and matches its integrity hash when it has one
+func (h Hwy) verifyAssets(pathsFile *PathsFile) []error {
+	if h.AssetsFS == nil {
+		return nil
+	}
+	var problems []error
+	for _, name := range getReferencedAssets(pathsFile) {
+		expected := pathsFile.Integrity[name]
+		if expected == "" {
+			if _, err := fs.Stat(h.AssetsFS, name); err != nil {
+				problems = append(problems, fmt.Errorf("%w: %s", ErrMissingAsset, name))
+			}
+			continue
+		}
+		content, err := fs.ReadFile(h.AssetsFS, name)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrMissingAsset, name))
+		} else if getSRIHash(content) != expected {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrAssetIntegrity, name))
+		}
+	}
+	return problems
+}
+
+// getReferencedAssets returns the names (relative to HashedOutDir) of every
+// built file (route modules, their deps, and stylesheets) referenced by
+// pathsFile, sorted
+func getReferencedAssets(pathsFile *PathsFile) []string {
+	seen := make(map[string]bool)
+	add := func(names ...string) {
+		for _, name := range names {
+			if name != "" {
+				seen[name] = true
+			}
+		}
+	}
+	addDeps := func(deps *[]string) {
+		if deps != nil {
+			add(*deps...)
+		}
+	}
+	addPaths := func(paths []JSONSafePath) {
+		for _, path := range paths {
+			add(path.OutPath, path.CSSBundle)
+			addDeps(path.Deps)
+			for _, variant := range path.Variants {
+				add(variant.OutPath, variant.CSSBundle)
+				addDeps(variant.Deps)
+			}
+			for _, island := range path.Islands {
+				add(island.OutPath)
+				addDeps(island.Deps)
+			}
+		}
+	}
+	addPaths(pathsFile.Paths)
+	for _, tenantPaths := range pathsFile.TenantPaths {
+		addPaths(tenantPaths)
+	}
+	add(pathsFile.ClientEntryDeps...)
+	add(pathsFile.ClientEntryCSSBundle)
+	for _, clientEntry := range pathsFile.ClientEntries {
+		add(clientEntry.Deps...)
+		add(clientEntry.CSSBundle)
+	}
+	for _, legacyOutput := range pathsFile.Legacy {
+		add(legacyOutput.OutPath)
+		add(legacyOutput.Deps...)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,47 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestVerifyAssets(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+
+	paths := GetPathsFromPageFiles("tiger.ui.tsx", "lion.ui.tsx")
+	paths[0].OutPath, paths[0].Deps, paths[0].CSSBundle = "tiger.js", &[]string{"tiger.js", "shared.js"}, "tiger.css"
+	paths[1].OutPath, paths[1].Deps = "lion.js", &[]string{"lion.js", "shared.js"}
+	pathsFile := PathsFile{
+		Paths:           paths,
+		ClientEntryDeps: []string{"shared.js"},
+		Integrity:       map[string]string{"lion.js": getSRIHash([]byte("old lion"))},
+	}
+	pathsFileBytes, _ := json.Marshal(pathsFile)
+	h := Hwy{
+		FS: fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}},
+		AssetsFS: fstest.MapFS{
+			"tiger.js":  &fstest.MapFile{Data: []byte("tiger")},
+			"shared.js": &fstest.MapFile{Data: []byte("shared")},
+			"lion.js":   &fstest.MapFile{Data: []byte("new lion")},
+		},
+	}
+	err := h.Initialize()
+	var initErr *InitializeError
+	if !errors.As(err, &initErr) || len(initErr.Problems) != 2 {
+		t.Fatalf("expected two problems, got %v", err)
+	}
+	if !errors.Is(initErr.Problems[0], ErrAssetIntegrity) || !strings.Contains(initErr.Problems[0].Error(), "lion.js") {
+		t.Errorf("expected lion.js to fail its integrity check, got %v", initErr.Problems[0])
+	}
+	if !errors.Is(initErr.Problems[1], ErrMissingAsset) || !strings.Contains(initErr.Problems[1].Error(), "tiger.css") {
+		t.Errorf("expected tiger.css to be missing, got %v", initErr.Problems[1])
+	}
+
+	if names := getReferencedAssets(&pathsFile); !slices.Equal(names, []string{"lion.js", "shared.js", "tiger.css", "tiger.js"}) {
+		t.Errorf("unexpected referenced assets %v", names)
+	}
+}
@@ -0,0 +1,59 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// getStaticRouteURLs returns the URL of every route in paths whose pattern
+// has no params or splats, once each
+func getStaticRouteURLs(paths *[]Path) []string {
+	var urls []string
+	for _, path := range *paths {
+		if path.PathType != PathTypeStaticLayout && path.PathType != PathTypeIndex {
+			continue
+		}
+		if strings.Contains(path.Pattern, "$") {
+			continue
+		}
+		url := ResolvePattern(path.Pattern, nil, nil)
+		if !slices.Contains(urls, url) {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// warmCaches populates the matcher cache (and, with Hwy.WarmLoaders, runs
+// the loaders) for every static route of the base and tenant route trees
+func (h Hwy) warmCaches() {
+	if !h.WarmMatcherCache && !h.WarmLoaders {
+		return
+	}
+	trees := map[string]*[]Path{"": instancePaths}
+	for tenant, paths := range instanceTenantPaths {
+		trees[tenant] = paths
+	}
+	logger := h.getLogger()
+	for _, tenant := range getSortedKeys(trees) {
+		for _, url := range getStaticRouteURLs(trees[tenant]) {
+			r, err := http.NewRequestWithContext(context.WithValue(context.Background(), tenantContextKey{}, tenant), "GET", url, nil)
+			if err != nil {
+				logger.Error("error warming route", "url", url, "error", err)
+				continue
+			}
+			if !h.WarmLoaders {
+				lookupMatchingPathItem(r, nil)
+				continue
+			}
+			// As a prefetch, so loaders skip side effects and metrics
+			// aren't skewed
+			r.Header.Set("Sec-Purpose", "prefetch")
+			if _, err := h.GetRouteData(nil, r); err != nil {
+				logger.Error("error warming route", "url", url, "tenant", tenant, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package router
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetStaticRouteURLs(t *testing.T) {
+	withRouteTable(t, []string{
+		"_index.ui.tsx",
+		"lion.ui.tsx",
+		"lion/_index.ui.tsx",
+		"lion/$id.ui.tsx",
+		"tiger/$.ui.tsx",
+		"about.ui.tsx",
+	})
+	urls := getStaticRouteURLs(instancePaths)
+	expected := []string{"/", "/lion", "/about"}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected %v, got %v", expected, urls)
+	}
+}
+
+func TestWarmMatcherCache(t *testing.T) {
+	withRouteTable(t, []string{"_index.ui.tsx", "lion.ui.tsx", "lion/_index.ui.tsx", "lion/$id.ui.tsx"})
+	Hwy{WarmMatcherCache: true}.warmCaches()
+
+	for _, url := range []string{"/", "/lion"} {
+		if _, ok := gmpdCache.Get(url); !ok {
+			t.Errorf("expected %s to be cached", url)
+		}
+	}
+	if _, cached := lookupMatchingPathItem(httptest.NewRequest("GET", "/lion", nil), nil); !cached {
+		t.Error("expected the first request to be served from cache")
+	}
+	if _, ok := gmpdCache.Get("/lion/123"); ok {
+		t.Error("expected dynamic routes not to be warmed")
+	}
+}
+
+func TestWarmLoaders(t *testing.T) {
+	var calls int
+	var isPrefetch bool
+	setTestDataFuncs(t, "/dashboard", &DataFuncs{
+		Loader: func(props *LoaderProps) (any, error) {
+			calls++
+			isPrefetch = props.IsPrefetch
+			return nil, nil
+		},
+	})
+	prevCache := gmpdCache
+	gmpdCache = NewLRUCache(gmpdCacheSize)
+	t.Cleanup(func() { gmpdCache = prevCache })
+
+	Hwy{WarmLoaders: true}.warmCaches()
+	// For /dashboard and /dashboard/customers
+	if calls != 2 || !isPrefetch {
+		t.Errorf("expected the layout's loader to run twice as a prefetch, got %d calls (prefetch: %v)", calls, isPrefetch)
+	}
+	if _, ok := gmpdCache.Get("/dashboard"); !ok {
+		t.Error("expected /dashboard to be cached")
+	}
+}
@@ -0,0 +1,258 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// WebSocketHandler serves a WebSocket connection upgraded from a request for
+// its route. The connection is closed when it returns.
+type WebSocketHandler func(*WebSocketProps) error
+
+type WebSocketProps struct {
+	DataProps
+	Context context.Context // canceled when the handler returns
+	Conn    *WebSocketConn
+}
+
+type WebSocketMessageType int
+
+const (
+	WebSocketText   WebSocketMessageType = 1
+	WebSocketBinary WebSocketMessageType = 2
+)
+
+const (
+	wsOpContinuation = 0x0
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+
+	wsMaxMessageSize = 1 << 20
+	wsAcceptGUID     = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+var ErrWebSocketMessageTooLarge = errors.New("websocket message too large")
+
+// WebSocketConn is a server-side WebSocket connection. Reads must come from a
+// single goroutine; writes are safe for concurrent use.
+type WebSocketConn struct {
+	conn    net.Conn
+	br      *bufio.Reader
+	writeMu sync.Mutex
+	closed  bool
+}
+
+// ReadMessage returns the next text or binary message, answering pings along
+// the way. It returns io.EOF once the client closes the connection.
+func (c *WebSocketConn) ReadMessage() (WebSocketMessageType, []byte, error) {
+	var messageType WebSocketMessageType
+	var message []byte
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			c.writeFrame(wsOpClose, payload)
+			return 0, nil, io.EOF
+		case wsOpContinuation:
+			if messageType == 0 {
+				return 0, nil, errors.New("unexpected websocket continuation frame")
+			}
+		default:
+			messageType = WebSocketMessageType(opcode)
+		}
+		if len(message)+len(payload) > wsMaxMessageSize {
+			return 0, nil, ErrWebSocketMessageTooLarge
+		}
+		message = append(message, payload...)
+		if fin {
+			return messageType, message, nil
+		}
+	}
+}
+
+func (c *WebSocketConn) WriteMessage(messageType WebSocketMessageType, data []byte) error {
+	return c.writeFrame(byte(messageType), data)
+}
+
+// Close sends a normal closure frame and closes the underlying connection
+func (c *WebSocketConn) Close() error {
+	c.writeFrame(wsOpClose, []byte{0x03, 0xE8})
+	return c.conn.Close()
+}
+
+func (c *WebSocketConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.br, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	if header[1]&0x80 == 0 {
+		err = errors.New("unmasked websocket client frame")
+		return
+	}
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > wsMaxMessageSize {
+		err = ErrWebSocketMessageTooLarge
+		return
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+func (c *WebSocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return net.ErrClosed
+	}
+	if opcode == wsOpClose {
+		c.closed = true
+	}
+	frame := make([]byte, 0, len(payload)+10)
+	frame = append(frame, 0x80|opcode)
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+	frame = append(frame, payload...)
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// GetIsWebSocketRequest reports whether r asks to upgrade to a WebSocket
+func GetIsWebSocketRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+// By default, only same-origin (or origin-less, non-browser) upgrades are
+// allowed, as browsers don't apply CORS to WebSockets
+func (h Hwy) checkWebSocketOrigin(r *http.Request) bool {
+	if h.WebSocketCheckOrigin != nil {
+		return h.WebSocketCheckOrigin(r)
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func getWebSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// serveWebSocket upgrades to a WebSocket served by the deepest matched route
+// with a WebSocket handler. It returns false (having written nothing) if
+// there is none.
+func (h Hwy) serveWebSocket(w http.ResponseWriter, r *http.Request) (string, bool) {
+	route, ok := h.matchStreamRoute(w, r, func(d *DataFuncs) bool { return d.WebSocket != nil })
+	if !ok {
+		return "", false
+	}
+	wsPath := route.path
+	if route.served {
+		return wsPath.Pattern, true
+	}
+	r = route.r
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet || key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return wsPath.Pattern, true
+	}
+	if !h.checkWebSocketOrigin(r) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return wsPath.Pattern, true
+	}
+
+	netConn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		h.getLogger().Error("websocket hijacking unsupported", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return wsPath.Pattern, true
+	}
+	conn := &WebSocketConn{conn: netConn, br: brw.Reader}
+	defer conn.Close()
+
+	_, err = netConn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + getWebSocketAccept(key) + "\r\n\r\n"))
+	if err != nil {
+		return wsPath.Pattern, true
+	}
+
+	// The request context isn't canceled once the connection is hijacked, but
+	// Shutdown still cancels it past its deadline
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	defer cancel()
+	stop := context.AfterFunc(instanceLifecycle.ctx, cancel)
+	defer stop()
+	_, err = callDataFunc(h, r, wsPath.Pattern, ErrorPhaseWebSocket, func() (any, error) {
+		return nil, wsPath.DataFuncs.WebSocket(&WebSocketProps{
+			DataProps: route.dataProps,
+			Context:   ctx,
+			Conn:      conn,
+		})
+	})
+	if err != nil && !errors.Is(err, io.EOF) {
+		h.getLogger().Error("websocket error", "error", err)
+	}
+	return wsPath.Pattern, true
+}
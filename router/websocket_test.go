@@ -0,0 +1,97 @@
+package router
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebSocketEcho(t *testing.T) {
+	setTestDataFuncs(t, "/tiger/$tiger_id", &DataFuncs{
+		WebSocket: func(props *WebSocketProps) error {
+			for {
+				messageType, message, err := props.Conn.ReadMessage()
+				if err != nil {
+					return err
+				}
+				reply := (*props.Params)["tiger_id"] + ":" + string(message)
+				if err := props.Conn.WriteMessage(messageType, []byte(reply)); err != nil {
+					return err
+				}
+			}
+		},
+	})
+	server := httptest.NewServer(Hwy{}.GetRootHandler())
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+
+	req, _ := http.NewRequest("GET", server.URL+"/tiger/ws-1", nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected status 101, got %d", res.StatusCode)
+	}
+	// Example accept value from RFC 6455
+	if got := res.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("unexpected accept header %q", got)
+	}
+
+	mask := []byte{1, 2, 3, 4}
+	payload := []byte("hello")
+	frame := []byte{0x81, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(br, header); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, header[1]&0x7F)
+	if _, err := io.ReadFull(br, reply); err != nil {
+		t.Fatal(err)
+	}
+	if header[0] != 0x81 || string(reply) != "ws-1:hello" {
+		t.Errorf("expected text frame \"ws-1:hello\", got opcode byte %x and %q", header[0], reply)
+	}
+}
+
+func TestWebSocketRejectsCrossOrigin(t *testing.T) {
+	setTestDataFuncs(t, "/tiger/$tiger_id", &DataFuncs{
+		WebSocket: func(props *WebSocketProps) error { return nil },
+	})
+	r := httptest.NewRequest("GET", "/tiger/ws-2", nil)
+	r.Header.Set("Upgrade", "websocket")
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	r.Header.Set("Sec-WebSocket-Version", "13")
+	r.Header.Set("Origin", "https://evil.example")
+	w := httptest.NewRecorder()
+	Hwy{}.GetRootHandler().ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", w.Code)
+	}
+}
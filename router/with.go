@@ -0,0 +1,106 @@
+package router
+
+import (
+	"errors"
+	"maps"
+)
+
+var ErrInitializeClone = errors.New("clones of Hwy can't be initialized")
+
+// Option overrides a field of a clone of Hwy (see With)
+type Option func(*Hwy)
+
+// With returns a clone of h with opts applied, sharing its route table (so
+// Initialize isn't called again) but not its overrides, e.g. to stub single
+// loaders in table-driven tests, or for preview environments. Fields read
+// per request can be overridden, e.g. cache policies like
+// PrefetchCacheControl, but those Initialize sets up process-wide (e.g. FS,
+// Clock, and Prefix) can't, as clones share them and can't be initialized
+// (see ErrInitializeClone).
+func (h *Hwy) With(opts ...Option) *Hwy {
+	clone := *h
+	clone.dataFuncsOverrides = maps.Clone(h.dataFuncsOverrides)
+	clone.isClone = true
+	for _, opt := range opts {
+		opt(&clone)
+	}
+	return &clone
+}
+
+// WithDataFuncs replaces the data funcs of routes, by pattern, once the clone
+// has matched a request (so Enabled and Experiment are still those of the
+// route table)
+func WithDataFuncs(dataFuncsMap DataFuncsMap) Option {
+	return func(h *Hwy) {
+		if h.dataFuncsOverrides == nil {
+			h.dataFuncsOverrides = make(DataFuncsMap, len(dataFuncsMap))
+		}
+		maps.Copy(h.dataFuncsOverrides, dataFuncsMap)
+	}
+}
+
+// WithDefaultHeadBlocks replaces DefaultHeadBlocks
+func WithDefaultHeadBlocks(headBlocks []HeadBlock) Option {
+	return func(h *Hwy) {
+		h.DefaultHeadBlocks = headBlocks
+	}
+}
+
+// withDataFuncsOverrides returns a copy of item whose matched routes have
+// the clone's overridden data funcs, leaving the (cached) item alone
+func (h Hwy) withDataFuncsOverrides(item *gmpdItem) *gmpdItem {
+	if len(h.dataFuncsOverrides) == 0 || item == nil {
+		return item
+	}
+	isOverridden := false
+	if item.MatchingPaths != nil {
+		for _, path := range *item.MatchingPaths {
+			if _, ok := h.dataFuncsOverrides[path.Pattern]; ok {
+				isOverridden = true
+				break
+			}
+		}
+	}
+	if !isOverridden {
+		return item
+	}
+
+	overridden := *item
+	matchingPaths := make([]*MatchingPath, len(*item.MatchingPaths))
+	for i, path := range *item.MatchingPaths {
+		matchingPaths[i] = path
+		if dataFuncs, ok := h.dataFuncsOverrides[path.Pattern]; ok {
+			pathCopy := *path
+			pathCopy.DataFuncs = &dataFuncs
+			if dataFuncs.Handle != nil {
+				pathCopy.Handle = dataFuncs.Handle
+			}
+			matchingPaths[i] = &pathCopy
+		}
+	}
+	overridden.MatchingPaths = &matchingPaths
+	if item.FullyDecoratedMatchingPaths != nil {
+		decoratedPaths := make([]*DecoratedPath, len(*item.FullyDecoratedMatchingPaths))
+		for i, path := range *item.FullyDecoratedMatchingPaths {
+			decoratedPaths[i] = path
+			if dataFuncs, ok := h.dataFuncsOverrides[path.Pattern]; ok {
+				pathCopy := *path
+				pathCopy.DataFuncs = &dataFuncs
+				if dataFuncs.Handle != nil {
+					pathCopy.Handle = dataFuncs.Handle
+				}
+				decoratedPaths[i] = &pathCopy
+			}
+		}
+		overridden.FullyDecoratedMatchingPaths = &decoratedPaths
+	}
+	return &overridden
+}
+
+// WithInvocationRecorder sets InvocationRecorder, e.g. to record the calls of
+// one test's requests
+func WithInvocationRecorder(rec *InvocationRecorder) Option {
+	return func(h *Hwy) {
+		h.InvocationRecorder = rec
+	}
+}
@@ -0,0 +1,93 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestWith(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+
+	paths := GetPathsFromPageFiles("with/tiger.ui.tsx", "with/lion.ui.tsx")
+	pathsFileBytes, _ := json.Marshal(PathsFile{Paths: paths, BuildID: "1"})
+	h := &Hwy{
+		FS:                fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}},
+		DefaultHeadBlocks: []HeadBlock{{Title: "Zoo"}},
+		DataFuncsMap: DataFuncsMap{
+			"/with/tiger": {Loader: func(*LoaderProps) (any, error) { return "real tiger", nil }},
+			"/with/lion":  {Loader: func(*LoaderProps) (any, error) { return "real lion", nil }},
+		},
+	}
+	if err := h.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	preview := h.With(
+		WithDataFuncs(DataFuncsMap{"/with/tiger": {Loader: func(*LoaderProps) (any, error) { return "stub tiger", nil }}}),
+		WithDefaultHeadBlocks([]HeadBlock{{Title: "Preview"}}),
+	)
+	get := func(h *Hwy, url string) *GetRouteDataOutput {
+		routeData, err := h.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return routeData
+	}
+
+	// Requested through both, so the clone sees the cached match
+	if data := (*get(h, "/with/tiger").LoadersData)[0]; data != "real tiger" {
+		t.Errorf("expected the original to keep its loader, got %v", data)
+	}
+	routeData := get(preview, "/with/tiger")
+	if data := (*routeData.LoadersData)[0]; data != "stub tiger" {
+		t.Errorf("expected the clone's stubbed loader, got %v", data)
+	}
+	if routeData.Title != "Preview" {
+		t.Errorf("expected the clone's default head blocks, got title %q", routeData.Title)
+	}
+	if data := (*get(preview, "/with/lion").LoadersData)[0]; data != "real lion" {
+		t.Errorf("expected loaders not overridden to run, got %v", data)
+	}
+	if data := (*get(h, "/with/tiger").LoadersData)[0]; data != "real tiger" {
+		t.Errorf("expected the clone not to affect the original, got %v", data)
+	}
+
+	// Clones of clones start from their overrides
+	nested := preview.With(WithDataFuncs(DataFuncsMap{"/with/lion": {}}))
+	if len(nested.dataFuncsOverrides) != 2 || len(preview.dataFuncsOverrides) != 1 {
+		t.Error("expected a clone's overrides to be copied, not shared")
+	}
+}
+
+func TestWithSharesProcessState(t *testing.T) {
+	withRouteTable(t, nil) // restores the fixture routes afterwards
+	prevClock := instanceClock
+	t.Cleanup(func() { instanceClock = prevClock })
+
+	pathsFileBytes, _ := json.Marshal(PathsFile{Paths: GetPathsFromPageFiles("with/tiger.ui.tsx"), BuildID: "1"})
+	clock := NewFakeClock(time.Unix(0, 0))
+	h := &Hwy{
+		FS:    fstest.MapFS{"hwy_paths.json": &fstest.MapFile{Data: pathsFileBytes}},
+		Clock: clock,
+	}
+	if err := h.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	clone := h.With(func(h *Hwy) {
+		h.Clock = NewFakeClock(time.Unix(100, 0))
+		h.Prefix = "__clone__"
+	})
+	if err := clone.Initialize(); !errors.Is(err, ErrInitializeClone) {
+		t.Errorf("expected ErrInitializeClone, got %v", err)
+	}
+	if instanceClock != clock || instancePrefix != HwyPrefix {
+		t.Errorf("expected the clone to leave the instance's clock and prefix, got %v %q", instanceClock, instancePrefix)
+	}
+	if _, err := clone.GetRouteData(httptest.NewRecorder(), httptest.NewRequest("GET", "/with/tiger", nil)); err != nil {
+		t.Errorf("expected the clone to serve from the shared route table, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Matches new Worker(new URL("./worker.ts", import.meta.url)) (or
+// SharedWorker), capturing the specifier (double or single quoted)
+var workerURLRe = regexp.MustCompile(`new\s+(?:Shared)?Worker\(\s*new\s+URL\(\s*(?:"(\.[^"\n]+)"|'(\.[^'\n]+)')\s*,\s*import\.meta\.url\s*\)`)
+
+var workerLoaders = map[string]api.Loader{
+	".js":  api.LoaderJS,
+	".mjs": api.LoaderJS,
+	".cjs": api.LoaderJS,
+	".jsx": api.LoaderJSX,
+	".ts":  api.LoaderTS,
+	".mts": api.LoaderTS,
+	".cts": api.LoaderTS,
+	".tsx": api.LoaderTSX,
+}
+
+// workerBuilds builds the module workers an esbuild build's sources start
+// with new Worker(new URL("./worker.ts", import.meta.url)), which esbuild
+// leaves alone, each into "hwy_worker__[hash].js" in the build's Outdir, and
+// points the URLs at them
+type workerBuilds struct {
+	mu        sync.Mutex
+	outputs   map[string]string   // worker source path to output file name
+	importers map[string][]string // importing source path to output file names
+	inputs    []string            // of the worker builds, for the build cache
+	files     []string            // output file names of the worker builds
+}
+
+func (w *workerBuilds) plugin() api.Plugin {
+	return api.Plugin{
+		Name: "hwy-workers",
+		Setup: func(build api.PluginBuild) {
+			opts := *build.InitialOptions
+			build.OnLoad(api.OnLoadOptions{Filter: `\.[cm]?[jt]sx?$`, Namespace: "file"}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+				source, err := os.ReadFile(args.Path)
+				if err != nil || !bytes.Contains(source, []byte("import.meta.url")) {
+					return api.OnLoadResult{}, err
+				}
+				matches := workerURLRe.FindAllSubmatchIndex(source, -1)
+				if matches == nil {
+					return api.OnLoadResult{}, nil
+				}
+				var b strings.Builder
+				last := 0
+				for _, match := range matches {
+					start, end := match[2], match[3]
+					if start == -1 {
+						start, end = match[4], match[5]
+					}
+					workerPath := filepath.Join(filepath.Dir(args.Path), string(source[start:end]))
+					outputName, err := w.build(opts, workerPath)
+					if err != nil {
+						return api.OnLoadResult{}, err
+					}
+					w.addImporter(args.Path, outputName)
+					b.Write(source[last:start])
+					b.WriteString("./" + outputName)
+					last = end
+				}
+				b.Write(source[last:])
+				contents := b.String()
+				return api.OnLoadResult{
+					Contents:   &contents,
+					Loader:     workerLoaders[filepath.Ext(args.Path)],
+					ResolveDir: filepath.Dir(args.Path),
+				}, nil
+			})
+		},
+	}
+}
+
+// build builds the worker at workerPath with opts, those of the build
+// importing it, returning its output file name
+func (w *workerBuilds) build(opts api.BuildOptions, workerPath string) (string, error) {
+	w.mu.Lock()
+	outputName, ok := w.outputs[workerPath]
+	w.mu.Unlock()
+	if ok {
+		return outputName, nil
+	}
+	opts.EntryPoints = []string{workerPath}
+	opts.Splitting = false
+	opts.EntryNames = "hwy_worker__[hash]"
+	opts.Metafile = true
+	opts.Plugins = []api.Plugin{w.plugin()}
+	result := api.Build(opts)
+	if len(result.Errors) > 0 {
+		return "", newBuildError(result.Errors[0])
+	}
+	metafile := MetafileJSON{}
+	if err := json.Unmarshal([]byte(result.Metafile), &metafile); err != nil {
+		return "", err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, output := range metafile.Outputs {
+		if output.EntryPoint != "" && strings.HasSuffix(key, ".js") {
+			outputName = filepath.Base(key)
+		}
+		w.files = append(w.files, filepath.Base(key))
+	}
+	for input := range metafile.Inputs {
+		w.inputs = append(w.inputs, input)
+	}
+	if w.outputs == nil {
+		w.outputs = make(map[string]string)
+	}
+	w.outputs[workerPath] = outputName
+	return outputName, nil
+}
+
+func (w *workerBuilds) addImporter(importerPath string, outputName string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.importers == nil {
+		w.importers = make(map[string][]string)
+	}
+	if !slices.Contains(w.importers[importerPath], outputName) {
+		w.importers[importerPath] = append(w.importers[importerPath], outputName)
+	}
+}
+
+// getWorkerDeps returns the workers started by the sources bundled into deps
+// (output file names of metafile), as those aren't among their imports
+func (w *workerBuilds) getWorkerDeps(metafile *MetafileJSON, deps []string) []string {
+	if len(w.importers) == 0 {
+		return nil
+	}
+	var workerDeps []string
+	for key, output := range metafile.Outputs {
+		if !slices.Contains(deps, filepath.Base(key)) {
+			continue
+		}
+		for input := range output.Inputs {
+			inputPath, err := filepath.Abs(input)
+			if err != nil {
+				continue
+			}
+			for _, outputName := range w.importers[inputPath] {
+				if !slices.Contains(deps, outputName) && !slices.Contains(workerDeps, outputName) {
+					workerDeps = append(workerDeps, outputName)
+				}
+			}
+		}
+	}
+	slices.Sort(workerDeps)
+	return workerDeps
+}
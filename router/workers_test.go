@@ -0,0 +1,82 @@
+package router
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildWorkersAndWasm(t *testing.T) {
+	// Like the fixtures, relative to the working directory, as esbuild
+	// reports entry points
+	dir := "../tmp/workers"
+	files := map[string]string{
+		"client.entry.ts":         `console.log("entry")`,
+		"pages/tiger.ui.ts":       `import wasmURL from "../add.wasm"; export const worker = new Worker(new URL('../workers/count.ts', import.meta.url), {type: "module"}); export default wasmURL`,
+		"workers/count.ts":        `self.onmessage = (e: MessageEvent) => self.postMessage(e.data + 1)`,
+		"add.wasm":                "\x00asm\x01\x00\x00\x00",
+		"pages/lion.ui.ts":        `export default "lion"`,
+		"pages/lion/_index.ui.ts": `export default "lion index"`,
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(dir, "out")
+	err := build(BuildOptions{
+		PagesSrcDir:    filepath.Join(dir, "pages"),
+		HashedOutDir:   out,
+		UnhashedOutDir: out,
+		ClientEntryOut: out,
+		ClientEntry:    filepath.Join(dir, "client.entry.ts"),
+		Logger:         NewSlogLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pathsFileBytes, err := os.ReadFile(filepath.Join(out, "hwy_paths.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pathsFile, err := DecodePathsFile(pathsFileBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tiger JSONSafePath
+	for _, path := range pathsFile.Paths {
+		if path.Pattern == "/tiger" {
+			tiger = path
+		}
+	}
+	var wasm, worker string
+	for _, dep := range *tiger.Deps {
+		switch {
+		case strings.HasPrefix(dep, "hwy_asset__") && strings.HasSuffix(dep, ".wasm"):
+			wasm = dep
+		case strings.HasPrefix(dep, "hwy_worker__"):
+			worker = dep
+		}
+	}
+	if wasm == "" || worker == "" {
+		t.Fatalf("expected the wasm module and the worker in the route's deps, got %v", *tiger.Deps)
+	}
+	module, _ := os.ReadFile(filepath.Join(out, tiger.OutPath))
+	if !strings.Contains(string(module), "./"+worker) || !strings.Contains(string(module), "./"+wasm) {
+		t.Errorf("expected the route module to reference the built worker and wasm module, got %s", module)
+	}
+	workerModule, err := os.ReadFile(filepath.Join(out, worker))
+	if err != nil || !strings.Contains(string(workerModule), "postMessage") {
+		t.Errorf("expected the built worker, got %s (%v)", workerModule, err)
+	}
+	for _, path := range pathsFile.Paths {
+		if path.Pattern != "/tiger" && slices.Contains(*path.Deps, worker) {
+			t.Errorf("expected only the importing route to depend on the worker, got %s", path.Pattern)
+		}
+	}
+}